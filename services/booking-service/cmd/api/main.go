@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	_ "booking-service/docs"
@@ -17,6 +21,7 @@ import (
 
 	"booking-service/internal/handlers"
 	"booking-service/internal/messaging"
+	"booking-service/internal/realtime"
 	"booking-service/internal/repositories"
 	"booking-service/internal/services"
 
@@ -93,15 +98,93 @@ func main() {
 	eventService := services.NewEventService(eventRepo)
 	eventHandler := handlers.NewEventHandler(eventService)
 
+	// Price tiers
+	priceTierRepo := repositories.NewPriceTierRepository(db)
+	priceTierService := services.NewPriceTierService(priceTierRepo, eventRepo)
+	priceTierHandler := handlers.NewPriceTierHandler(priceTierService)
+
+	// Admisión general
+	gaRepo := repositories.NewGeneralAdmissionRepository(db)
+	gaService := services.NewGeneralAdmissionService(gaRepo, eventRepo)
+	gaHandler := handlers.NewGeneralAdmissionHandler(gaService)
+
+	// Salas WebSocket de selección de asientos; con varias réplicas usar el broadcaster de Postgres
+	var roomBroadcaster realtime.Broadcaster = realtime.NewMemoryBroadcaster()
+	var pgBroadcaster *realtime.PostgresBroadcaster
+	if cfg.SeatSocketBroadcaster == "postgres" {
+		pgBroadcaster = realtime.NewPostgresBroadcaster(db, cfg.DBUrl, cfg.SeatSocketChannel)
+		roomBroadcaster = pgBroadcaster
+	}
+	seatHub := realtime.NewSeatHub(roomBroadcaster, cfg.SeatSocketPresenceTTL, cfg.SeatStreamBuffer)
+
+	// Stream en vivo de cambios de asientos (SSE); recibe del mismo broadcaster que las salas
+	seatBus := realtime.NewSeatBus(cfg.SeatStreamHistory, cfg.SeatStreamBuffer)
+	seatBus.Listen(roomBroadcaster)
+	seatStreamHandler := handlers.NewSeatStreamHandler(seatBus, cfg.SeatStreamHeartbeat)
+
+	// Los repositorios publican después de cada commit; el hub lo reparte a todas las réplicas
+	var seatPublishers repositories.SeatChangePublisher = seatHub
+
+	// Ventana de venta y códigos de preventa
+	presaleRepo := repositories.NewPresaleRepository(db)
+	presaleService := services.NewPresaleService(presaleRepo)
+	presaleHandler := handlers.NewPresaleHandler(presaleService)
+
+	// Sala de espera para las salidas a la venta con mucha demanda
+	waitingRoomRepo := repositories.NewWaitingRoomRepository(db)
+	waitingRoomService := services.NewWaitingRoomService(waitingRoomRepo, cfg.WaitingRoomSecret, cfg.WaitingRoomAdmissionWindow)
+	waitingRoomHandler := handlers.NewWaitingRoomHandler(waitingRoomService)
+	guardQueue := waitingRoomHandler.RequireAdmission()
+
+	// Límites de compra por usuario, email y tarjeta
+	purchaseLimitRepo := repositories.NewPurchaseLimitRepository(db)
+	purchaseLimitService := services.NewPurchaseLimitService(purchaseLimitRepo)
+
+	// Códigos promocionales
+	promoRepo := repositories.NewPromoCodeRepository(db)
+	promoService := services.NewPromoService(promoRepo, eventRepo)
+	promoHandler := handlers.NewPromoCodeHandler(promoService)
+
+	// Pasarelas de pago: Stripe es la de por defecto, MercadoPago solo si hay access token
+	stripeProvider := services.NewStripeProvider(services.StripeConfig{
+		SecretKey:        cfg.StripeSecretKey,
+		WebhookSecret:    cfg.StripeWebhookSecret,
+		WebhookTolerance: cfg.StripeWebhookTolerance,
+	})
+	var mercadoPagoProvider *services.MercadoPagoProvider
+	var extraProviders []services.PaymentProvider
+	if cfg.MercadoPagoAccessToken != "" {
+		mercadoPagoProvider = services.NewMercadoPagoProvider(services.MercadoPagoConfig{
+			AccessToken:     cfg.MercadoPagoAccessToken,
+			WebhookSecret:   cfg.MercadoPagoWebhookSecret,
+			BaseURL:         cfg.MercadoPagoAPIURL,
+			NotificationURL: cfg.MercadoPagoNotificationURL,
+		})
+		extraProviders = append(extraProviders, mercadoPagoProvider)
+	}
+	paymentProviders := services.NewPaymentProviders(stripeProvider, extraProviders...)
+
+	// Las órdenes del hold se cancelan al liberarlo
+	bookingOrderRepo := repositories.NewBookingOrderRepository(db)
+
 	// Seats
-	seatRepo := repositories.NewSeatRepository(db)
-	seatService := services.NewSeatService(seatRepo, eventRepo)
-	seatHandler := handlers.NewSeatHandler(seatService)
+	seatRepo := repositories.NewSeatRepository(db, seatPublishers)
+	seatService := services.NewSeatService(seatRepo, eventRepo, priceTierRepo, gaRepo, presaleRepo, bookingOrderRepo, paymentProviders, cfg.HoldPolicy)
+	seatHandler := handlers.NewSeatHandler(seatService, waitingRoomService, purchaseLimitService)
+	seatSocketHandler := handlers.NewSeatSocketHandler(seatService, seatHub, purchaseLimitService)
+
+	// Venues
+	venueRepo := repositories.NewVenueRepository(db)
+	venueService := services.NewVenueService(venueRepo, eventRepo, seatRepo, priceTierRepo)
+	venueHandler := handlers.NewVenueHandler(venueService)
+
+	// Cargos e impuestos por evento o recinto
+	pricingRepo := repositories.NewPricingRepository(db)
+	pricingService := services.NewPricingService(pricingRepo, eventRepo, venueRepo)
+	pricingHandler := handlers.NewPricingHandler(pricingService)
 
 	// Booking Orders
-	bookingOrderRepo := repositories.NewBookingOrderRepository(db)
-	bookingOrderService := services.NewBookingOrderService(bookingOrderRepo, seatRepo, eventRepo)
-	bookingOrderHandler := handlers.NewBookingOrderHandler(bookingOrderService)
+	bookingOrderService := services.NewBookingOrderService(bookingOrderRepo, seatRepo, eventRepo, seatService, paymentProviders)
 
 	// Checkout
 	checkoutRepo := repositories.NewCheckoutRepository(db)
@@ -110,7 +193,7 @@ func main() {
 
 	// Ticket
 	ticketRepo := repositories.NewTicketRepository(db)
-	ticketService := services.NewTicketService(ticketRepo, bookingOrderRepo, seatRepo, eventRepo)
+	ticketService := services.NewTicketService(ticketRepo, bookingOrderRepo, seatRepo, eventRepo, gaRepo)
 	pdfService := services.NewPDFService()
 	ticketHandler := handlers.NewTicketHandler(ticketService, pdfService, bookingOrderService, checkoutService)
 
@@ -133,6 +216,11 @@ func main() {
 	emailService := services.NewEmailService(emailRepo, workersInt)
 	emailHandler := handlers.NewEmailHandler(emailService)
 
+	// Refunds
+	refundRepo := repositories.NewRefundRepository(db, seatPublishers)
+	refundService := services.NewRefundService(bookingOrderRepo, seatRepo, checkoutRepo, refundRepo, paymentProviders, emailService)
+	bookingOrderHandler := handlers.NewBookingOrderHandler(bookingOrderService, refundService)
+
 	// Queue AWS SQS
 	ctx := context.Background()
 	envs := config.LoadConfig()
@@ -142,7 +230,40 @@ func main() {
 		log.Fatal(err)
 	}
 
-	sqsHandler := handlers.NewSQSHandler(sqsClient)
+	if envs.StripeWebhookSecret == "" {
+		log.Println("⚠️ STRIPE_WEBHOOK_SECRET no configurado: los webhooks de Stripe serán rechazados")
+	}
+	sqsHandler := handlers.NewSQSHandler(sqsClient, stripeProvider, envs.StripeWebhookTolerance)
+	var mercadoPagoHandler *handlers.SQSHandler
+	if mercadoPagoProvider != nil {
+		mercadoPagoHandler = handlers.NewSQSHandler(sqsClient, mercadoPagoProvider, envs.MercadoPagoWebhookTolerance)
+	}
+
+	// Worker que libera asientos con bloqueo vencido
+	appCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	lockReaper := services.NewLockReaper(seatRepo, bookingOrderRepo, eventRepo, gaRepo, paymentProviders, cfg.LockReaperInterval)
+	lockReaperDone := lockReaper.Start(appCtx)
+
+	seatHubDone := seatHub.Start(appCtx)
+	seatBusDone := seatBus.Start(appCtx)
+	waitingRoomDone := waitingRoomService.Start(appCtx, time.Minute)
+	refundsDone := refundService.Start(appCtx, time.Minute)
+	var roomListenerDone <-chan struct{}
+	if pgBroadcaster != nil {
+		roomListenerDone = pgBroadcaster.Start(appCtx)
+	}
+
+	// Consumer de pagos: aplica en una sola transacción lo que antes hacía la Lambda vía HTTP
+	var paymentConsumerDone <-chan struct{}
+	if cfg.PaymentConsumerEnabled {
+		paymentRepo := repositories.NewPaymentRepository(db, seatPublishers)
+		processedEventRepo := repositories.NewProcessedPaymentEventRepository(db)
+		paymentService := services.NewPaymentService(paymentRepo, processedEventRepo, paymentProviders, emailService, paymentProviders)
+		paymentConsumer := messaging.NewPaymentConsumer(sqsClient, paymentService, cfg.PaymentConsumerWait)
+		paymentConsumerDone = paymentConsumer.Start(appCtx)
+	}
 
 	guardUserJWT := middleware.UserMiddleware()
 
@@ -171,6 +292,67 @@ func main() {
 			// Actualizo la disponibilidad de asientos de un evento. Se debe ejecutar con la confirmacion de un pago satisfactorio.
 			events.PATCH("/availability/:id", eventHandler.UpdateAvailabilityForEvent)
 			events.DELETE("/:id", guardUserJWT, eventHandler.DeleteEvent)
+
+			// Categorías de precio del evento
+			events.GET("/:id/tiers", guardUserJWT, priceTierHandler.GetTiers)
+			events.POST("/:id/tiers", guardUserJWT, priceTierHandler.CreateTier)
+			events.GET("/:id/tiers/:tierId", guardUserJWT, priceTierHandler.GetTier)
+			events.PATCH("/:id/tiers/:tierId", guardUserJWT, priceTierHandler.UpdateTier)
+			events.DELETE("/:id/tiers/:tierId", guardUserJWT, priceTierHandler.DeleteTier)
+			events.POST("/:id/tiers/:tierId/reprice", guardUserJWT, priceTierHandler.RepriceTier) // Reprecia los asientos no vendidos
+
+			// Sectores de admisión general (cupo sin asientos numerados)
+			events.GET("/:id/ga-sections", guardUserJWT, gaHandler.GetSections)
+			events.POST("/:id/ga-sections", guardUserJWT, gaHandler.CreateSection)
+			events.GET("/:id/ga-sections/:sectionId", guardUserJWT, gaHandler.GetSection)
+			events.DELETE("/:id/ga-sections/:sectionId", guardUserJWT, gaHandler.DeleteSection)
+
+			// Códigos de preventa y canje (habilita a comprar entre presaleStart y salesStart)
+			events.GET("/:id/presale-codes", guardUserJWT, presaleHandler.GetCodes)
+			events.POST("/:id/presale-codes", guardUserJWT, presaleHandler.CreateCode)
+			events.DELETE("/:id/presale-codes/:codeId", guardUserJWT, presaleHandler.DeleteCode)
+			events.POST("/:id/presale/redeem", guardUserJWT, presaleHandler.Redeem)
+
+			// Genera todo el inventario de asientos desde el plano de un recinto
+			events.POST("/:id/seats/generate-from-venue", guardUserJWT, venueHandler.GenerateSeats)
+			// Elige y bloquea los mejores asientos disponibles de una sección
+			events.POST("/:id/best-available", guardUserJWT, guardQueue, seatHandler.BestAvailable)
+			// Sala de espera: pase firmado con el lugar en la fila y consulta del estado
+			events.POST("/:id/queue", guardUserJWT, waitingRoomHandler.JoinQueue)
+			events.GET("/:id/queue", guardUserJWT, waitingRoomHandler.GetQueueStatus)
+			// Cambios de estado de los asientos en vivo (Server-Sent Events), público como el mapa
+			events.GET("/:id/seats/stream", seatStreamHandler.StreamSeats)
+			// Sala WebSocket: presencia de otros usuarios y hold/release por el socket
+			events.GET("/:id/seats/ws", guardUserJWT, guardQueue, seatSocketHandler.Connect)
+		}
+		venues := v1.Group("/venues")
+		{
+			venues.POST("", guardUserJWT, venueHandler.CreateVenue) // JSON o CSV
+			venues.GET("", guardUserJWT, venueHandler.GetVenues)
+			venues.GET("/:id", guardUserJWT, venueHandler.GetVenue)
+			venues.DELETE("/:id", guardUserJWT, venueHandler.DeleteVenue)
+		}
+		promoCodes := v1.Group("/promo-codes")
+		{
+			// Códigos promocionales: por evento o globales, se aplican en el checkout
+			promoCodes.POST("", guardUserJWT, promoHandler.CreateCode)
+			promoCodes.GET("", guardUserJWT, promoHandler.GetCodes)
+			promoCodes.GET("/:id", guardUserJWT, promoHandler.GetCode)
+			promoCodes.DELETE("/:id", guardUserJWT, promoHandler.DeleteCode)
+		}
+		feeRules := v1.Group("/fee-rules")
+		{
+			// Cargos por entrada: los del evento reemplazan a los de su recinto
+			feeRules.POST("", guardUserJWT, pricingHandler.CreateFeeRule)
+			feeRules.GET("", guardUserJWT, pricingHandler.GetFeeRules)
+			feeRules.DELETE("/:id", guardUserJWT, pricingHandler.DeleteFeeRule)
+		}
+		taxRates := v1.Group("/tax-rates")
+		{
+			// Impuestos (IVA) sobre entradas y cargos
+			taxRates.POST("", guardUserJWT, pricingHandler.CreateTaxRate)
+			taxRates.GET("", guardUserJWT, pricingHandler.GetTaxRates)
+			taxRates.DELETE("/:id", guardUserJWT, pricingHandler.DeleteTaxRate)
 		}
 		seats := v1.Group("/seats")
 		{
@@ -181,6 +363,8 @@ func main() {
 			seats.GET("/event/:eventId", seatHandler.GetSeatsByEventId)
 			seats.PATCH("/:id", guardUserJWT, seatHandler.UpdateSeat)             // Para cambiar el estatus del asiento
 			seats.PATCH("/lock/:id/uid/:uid", guardUserJWT, seatHandler.LockSeat) // Para bloquear un asiento
+			seats.POST("/hold/:holdId/extend", guardUserJWT, seatHandler.ExtendHold) // Extender un hold propio
+			seats.DELETE("/hold/:holdId", guardUserJWT, seatHandler.ReleaseHold)     // Liberar un hold propio
 		}
 		bookingOrders := v1.Group("/booking-orders")
 		{
@@ -188,8 +372,10 @@ func main() {
 			bookingOrders.POST("", guardUserJWT, bookingOrderHandler.CreateBookingOrder)
 			bookingOrders.GET("", guardUserJWT, bookingOrderHandler.GetBookingOrders)
 			bookingOrders.GET("/:id", guardUserJWT, bookingOrderHandler.GetBookingOrderById)
+			bookingOrders.GET("/:id/history", guardUserJWT, bookingOrderHandler.GetBookingOrderHistory)
 			bookingOrders.GET("/user/:id", guardUserJWT, bookingOrderHandler.GetAllOrderForUserID)
 			bookingOrders.PATCH("/:id", guardUserJWT, bookingOrderHandler.UpdateBookingOrder)
+			bookingOrders.POST("/:id/refund", guardUserJWT, bookingOrderHandler.RefundBookingOrder)
 		}
 		checkouts := v1.Group("/checkouts")
 		{
@@ -201,11 +387,14 @@ func main() {
 		sqsMessaging := v1.Group("/sqs")
 		{
 			sqsMessaging.POST("/messaging", sqsHandler.Send)
+			if mercadoPagoHandler != nil {
+				sqsMessaging.POST("/messaging/mercadopago", mercadoPagoHandler.Send)
+			}
 		}
 		// Creacion de checkout session
 		stripe := v1.Group("/stripe")
 		{
-			stripe.POST("/create/checkout/session", guardUserJWT, handlers.CreateCartCheckoutSession(seatService, bookingOrderService))
+			stripe.POST("/create/checkout/session", guardUserJWT, handlers.CreateCartCheckoutSession(seatService, gaService, bookingOrderService, waitingRoomService, purchaseLimitService, promoService, pricingService, paymentProviders, cfg.CheckoutBaseURL))
 		}
 		// ✅ Generacion de ticket (NUEVO)
 		tickets := v1.Group("/tickets")
@@ -236,8 +425,43 @@ func main() {
 		}
 	}
 
-	log.Printf("Server starting on port %s...", cfg.Port)
-	if err := r.Run(":" + cfg.Port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	srv := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: r,
+	}
+
+	go func() {
+		log.Printf("Server starting on port %s...", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	<-appCtx.Done()
+	log.Println("Apagando servidor...")
+
+	// Corto los streams SSE y los WebSockets para que no retengan el shutdown
+	seatBus.Close()
+	seatHub.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error al apagar el servidor: %v", err)
+	}
+
+	<-lockReaperDone
+	<-seatHubDone
+	<-seatBusDone
+	<-waitingRoomDone
+	<-refundsDone
+	if roomListenerDone != nil {
+		<-roomListenerDone
+	}
+	_ = roomBroadcaster.Close()
+	if paymentConsumerDone != nil {
+		<-paymentConsumerDone
 	}
+	emailService.Shutdown()
 }
@@ -25,7 +25,19 @@ type Config struct {
 	AWSRegion   string
 	SQSQueueUrl string
 
-	StripeSecretKey string
+	StripeSecretKey        string
+	StripeWebhookSecret    string
+	StripeWebhookTolerance time.Duration
+
+	// MercadoPago es opcional: sin access token no se registra la pasarela
+	MercadoPagoAccessToken      string
+	MercadoPagoWebhookSecret    string
+	MercadoPagoAPIURL           string
+	MercadoPagoNotificationURL  string
+	MercadoPagoWebhookTolerance time.Duration
+
+	// URL base del front a la que vuelve el usuario después de pagar
+	CheckoutBaseURL string
 
 	Smtp_Host string
 	Smtp_Port string
@@ -38,6 +50,29 @@ type Config struct {
 	DbMaxIdleConns    int
 	DbConnMaxLifeTime time.Duration
 	DbConnMaxIdleTime time.Duration
+
+	LockReaperInterval time.Duration
+	HoldPolicy         HoldPolicy
+
+	PaymentConsumerEnabled bool
+	PaymentConsumerWait    time.Duration
+
+	// Stream SSE del mapa de asientos: heartbeat, cambios guardados por evento para retomar
+	// y cambios pendientes tolerados por cliente antes de desconectarlo
+	SeatStreamHeartbeat time.Duration
+	SeatStreamHistory   int
+	SeatStreamBuffer    int
+
+	// Salas WebSocket de selección de asientos: broadcaster entre réplicas (memory o postgres),
+	// canal de LISTEN/NOTIFY y cuánto vive la presencia de una conexión sin refrescarse
+	SeatSocketBroadcaster string
+	SeatSocketChannel     string
+	SeatSocketPresenceTTL time.Duration
+
+	// Sala de espera: secreto de los pases (por defecto JWT_SECRET) y tiempo que un usuario
+	// admitido puede usar la selección de asientos
+	WaitingRoomSecret          string
+	WaitingRoomAdmissionWindow time.Duration
 }
 
 func LoadConfig() *Config {
@@ -61,7 +96,17 @@ func LoadConfig() *Config {
 		AWSRegion:   getEnv("AWS_REGION", "us-east-1"),
 		SQSQueueUrl: getEnv("SQS_QUEUE_URL", "sdsdsdsdsd"),
 
-		StripeSecretKey: getEnv("STRIPE_SECRET_KEY", "sk_test_XXXXXXXXXXXXXXXXXXXX"),
+		StripeSecretKey:        getEnv("STRIPE_SECRET_KEY", "sk_test_XXXXXXXXXXXXXXXXXXXX"),
+		StripeWebhookSecret:    getEnv("STRIPE_WEBHOOK_SECRET", ""),
+		StripeWebhookTolerance: getEnvDurationOrDefault("STRIPE_WEBHOOK_TOLERANCE", 5*time.Minute),
+
+		MercadoPagoAccessToken:      getEnv("MERCADOPAGO_ACCESS_TOKEN", ""),
+		MercadoPagoWebhookSecret:    getEnv("MERCADOPAGO_WEBHOOK_SECRET", ""),
+		MercadoPagoAPIURL:           getEnv("MERCADOPAGO_API_URL", ""),
+		MercadoPagoNotificationURL:  getEnv("MERCADOPAGO_NOTIFICATION_URL", ""),
+		MercadoPagoWebhookTolerance: getEnvDurationOrDefault("MERCADOPAGO_WEBHOOK_TOLERANCE", 5*time.Minute),
+
+		CheckoutBaseURL: getEnv("CHECKOUT_BASE_URL", getEnv("STRIPE_SUCCESS_URL", "")),
 
 		Smtp_Host: getEnv("SMTP_HOST", "smtp.gmail.com"),
 		Smtp_Port: getEnv("SMTP_PORT", "587"),
@@ -74,6 +119,23 @@ func LoadConfig() *Config {
 		DbMaxIdleConns:    getEnvIntOrDefault("DB_MAX_IDLE_CONNS", 10),
 		DbConnMaxLifeTime: getEnvDurationOrDefault("DB_CONN_MAX_LIFETIME", getEnvDurationOrDefault("DB_CONN_MAX_LIFE_TIME", 5*time.Minute)),
 		DbConnMaxIdleTime: getEnvDurationOrDefault("DB_CONN_MAX_IDLE_TIME", 1*time.Minute),
+
+		LockReaperInterval: getEnvDurationOrDefault("LOCK_REAPER_INTERVAL", 1*time.Minute),
+		HoldPolicy:         loadHoldPolicy(),
+
+		PaymentConsumerEnabled: getEnvBoolOrDefault("PAYMENT_CONSUMER_ENABLED", true),
+		PaymentConsumerWait:    getEnvDurationOrDefault("PAYMENT_CONSUMER_WAIT", 20*time.Second),
+
+		SeatStreamHeartbeat: getEnvDurationOrDefault("SEAT_STREAM_HEARTBEAT", 15*time.Second),
+		SeatStreamHistory:   getEnvIntOrDefault("SEAT_STREAM_HISTORY", 1000),
+		SeatStreamBuffer:    getEnvIntOrDefault("SEAT_STREAM_BUFFER", 256),
+
+		SeatSocketBroadcaster: getEnv("SEAT_SOCKET_BROADCASTER", "memory"),
+		SeatSocketChannel:     getEnv("SEAT_SOCKET_CHANNEL", "seat_rooms"),
+		SeatSocketPresenceTTL: getEnvDurationOrDefault("SEAT_SOCKET_PRESENCE_TTL", 30*time.Second),
+
+		WaitingRoomSecret:          getEnv("WAITING_ROOM_SECRET", os.Getenv("JWT_SECRET")),
+		WaitingRoomAdmissionWindow: getEnvDurationOrDefault("WAITING_ROOM_ADMISSION_WINDOW", 15*time.Minute),
 	}
 }
 
@@ -97,6 +159,19 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 	return v
 }
 
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	v, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Printf("Warning: invalid boolean for %s (%s), using default %t", key, valueStr, defaultValue)
+		return defaultValue
+	}
+	return v
+}
+
 func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
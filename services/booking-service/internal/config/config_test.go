@@ -48,3 +48,53 @@ func TestLoadConfig_UsesNewLifetimeVarWithFallback(t *testing.T) {
 		t.Fatalf("unexpected pool config: %+v", cfg)
 	}
 }
+
+func TestLoadConfig_WebhookTolerancePerProvider(t *testing.T) {
+	t.Setenv("STRIPE_WEBHOOK_TOLERANCE", "5m")
+	t.Setenv("MERCADOPAGO_WEBHOOK_TOLERANCE", "10m")
+
+	cfg := LoadConfig()
+	if cfg.StripeWebhookTolerance != 5*time.Minute || cfg.MercadoPagoWebhookTolerance != 10*time.Minute {
+		t.Fatalf("unexpected webhook tolerances: stripe=%v mercadopago=%v", cfg.StripeWebhookTolerance, cfg.MercadoPagoWebhookTolerance)
+	}
+}
+
+func TestHoldPolicy_TTLForPrecedence(t *testing.T) {
+	t.Setenv("HOLD_TTL", "12m")
+	t.Setenv("HOLD_TTL_BY_EVENT", "e1=30m, bad, e2=nope")
+	t.Setenv("HOLD_TTL_BY_SECTION", "vip=20m")
+	t.Setenv("HOLD_MAX_SEATS_PER_USER", "4")
+
+	p := LoadConfig().HoldPolicy
+	if got := p.TTLFor("e1", "VIP"); got != 30*time.Minute {
+		t.Fatalf("expected event override, got %v", got)
+	}
+	if got := p.TTLFor("e2", "Vip"); got != 20*time.Minute {
+		t.Fatalf("expected section override, got %v", got)
+	}
+	if got := p.TTLFor("e3", "GENERAL"); got != 12*time.Minute {
+		t.Fatalf("expected default ttl, got %v", got)
+	}
+	if p.MaxSeatsPerUser != 4 {
+		t.Fatalf("expected max seats 4, got %d", p.MaxSeatsPerUser)
+	}
+	if len(p.SeatRules) != 1 || p.SeatRules[0] != "orphan" {
+		t.Fatalf("expected orphan rule by default, got %v", p.SeatRules)
+	}
+
+	if got := (HoldPolicy{}).TTLFor("e1", "VIP"); got != DefaultHoldTTL {
+		t.Fatalf("expected package default for zero policy, got %v", got)
+	}
+}
+
+func TestHoldPolicy_SeatRules(t *testing.T) {
+	t.Setenv("HOLD_SEAT_RULES", " orphan , other,")
+	if got := LoadConfig().HoldPolicy.SeatRules; len(got) != 2 || got[0] != "orphan" || got[1] != "other" {
+		t.Fatalf("unexpected rules: %v", got)
+	}
+
+	t.Setenv("HOLD_SEAT_RULES", "none")
+	if got := LoadConfig().HoldPolicy.SeatRules; len(got) != 0 {
+		t.Fatalf("expected rules disabled, got %v", got)
+	}
+}
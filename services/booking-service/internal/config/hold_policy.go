@@ -0,0 +1,97 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultHoldTTL es el tiempo de bloqueo de un asiento cuando no hay configuración
+const DefaultHoldTTL = 15 * time.Minute
+
+// HoldPolicy define cuánto dura el bloqueo temporal de asientos y cuántos puede retener un usuario
+type HoldPolicy struct {
+	DefaultTTL time.Duration
+	EventTTL   map[string]time.Duration // TTL por ID de evento (tiene prioridad)
+	SectionTTL map[string]time.Duration // TTL por sección ("VIP", "PLATEA", ...)
+
+	// Máximo de asientos bloqueados por usuario y evento (0 = sin límite)
+	MaxSeatsPerUser int
+
+	// Extensiones de un hold: cuántas veces se puede extender y cuánto suma cada una
+	MaxExtensions int
+	ExtensionTTL  time.Duration
+
+	// Reglas de selección que se validan al bloquear (ej: "orphan"); cada evento decide si las activa
+	SeatRules []string
+}
+
+// TTLFor devuelve el TTL aplicable: evento > sección > default
+func (p HoldPolicy) TTLFor(eventID, section string) time.Duration {
+	if ttl, ok := p.EventTTL[eventID]; ok && ttl > 0 {
+		return ttl
+	}
+	if ttl, ok := p.SectionTTL[strings.ToUpper(section)]; ok && ttl > 0 {
+		return ttl
+	}
+	if p.DefaultTTL > 0 {
+		return p.DefaultTTL
+	}
+	return DefaultHoldTTL
+}
+
+func loadHoldPolicy() HoldPolicy {
+	return HoldPolicy{
+		DefaultTTL:      getEnvDurationOrDefault("HOLD_TTL", DefaultHoldTTL),
+		EventTTL:        getEnvDurationMap("HOLD_TTL_BY_EVENT", false),
+		SectionTTL:      getEnvDurationMap("HOLD_TTL_BY_SECTION", true),
+		MaxSeatsPerUser: getEnvIntOrDefault("HOLD_MAX_SEATS_PER_USER", 10),
+		MaxExtensions:   getEnvIntOrDefault("HOLD_MAX_EXTENSIONS", 1),
+		ExtensionTTL:    getEnvDurationOrDefault("HOLD_EXTENSION_TTL", 5*time.Minute),
+		SeatRules:       getEnvList("HOLD_SEAT_RULES", "orphan"),
+	}
+}
+
+// getEnvList parsea una lista separada por comas; vacía si la variable es "none"
+func getEnvList(key, defaultValue string) []string {
+	raw := getEnv(key, defaultValue)
+	if strings.EqualFold(strings.TrimSpace(raw), "none") {
+		return nil
+	}
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// getEnvDurationMap parsea "clave=duracion,clave2=duracion" (ej: "VIP=20m,GENERAL=5m")
+func getEnvDurationMap(key string, upperKeys bool) map[string]time.Duration {
+	out := map[string]time.Duration{}
+	raw := os.Getenv(key)
+	if raw == "" {
+		return out
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			log.Printf("Warning: invalid entry in %s (%s), skipping", key, pair)
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			log.Printf("Warning: invalid duration in %s (%s), skipping", key, pair)
+			continue
+		}
+		k = strings.TrimSpace(k)
+		if upperKeys {
+			k = strings.ToUpper(k)
+		}
+		out[k] = d
+	}
+	return out
+}
@@ -3,9 +3,11 @@ package database
 import (
 	"booking-service/internal/config"
 	"booking-service/internal/models"
+	"booking-service/pkg/money"
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"time"
 
@@ -99,14 +101,101 @@ func RunMigrations(db *gorm.DB) error {
 	log.Println("   -> Iniciando automigrate manual...")
 	err := db.AutoMigrate(
 		&models.Event{},
+		&models.PriceTier{},
+		&models.Venue{},
+		&models.VenueSeat{},
 		&models.Seat{},
+		&models.GASection{},
+		&models.GAHold{},
 		&models.BookingOrder{},
 		&models.Checkout{},
 		&models.TicketPDF{},
+		&models.ProcessedPaymentEvent{},
+		&models.BookingOrderStatusHistory{},
+		&models.Refund{},
+		&models.WaitingRoomEntry{},
+		&models.PresaleCode{},
+		&models.PresaleAccess{},
+		&models.PromoCode{},
+		&models.PromoRedemption{},
+		&models.OrderLine{},
+		&models.FeeRule{},
+		&models.TaxRate{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
+
+	// Antes locked_at guardaba la expiración del bloqueo; se copia a lock_expires_at
+	if err := db.Exec(`
+		UPDATE seats SET lock_expires_at = locked_at
+		WHERE status = 'LOCKED' AND lock_expires_at IS NULL AND locked_at IS NOT NULL
+	`).Error; err != nil {
+		return fmt.Errorf("failed to backfill lock_expires_at: %w", err)
+	}
+
+	if err := migrateLegacyPrices(db); err != nil {
+		return fmt.Errorf("failed to migrate prices: %w", err)
+	}
 	log.Println("✅ Migrations completed")
 	return nil
 }
+
+// migrateLegacyPrices pasa los precios viejos a money.Money (unidades menores + moneda).
+// events.price ya estaba en centavos; seats.price era un float en unidades de la moneda del
+// evento y se escala según sus decimales (CLP y PYG no tienen centavos).
+// Es idempotente: las columnas viejas se borran al terminar
+func migrateLegacyPrices(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		m := tx.Migrator()
+
+		if m.HasColumn("events", "price") {
+			if err := tx.Exec(`UPDATE events SET price_amount = price WHERE price IS NOT NULL`).Error; err != nil {
+				return err
+			}
+			if err := m.DropColumn("events", "price"); err != nil {
+				return err
+			}
+			log.Println("   -> events.price migrado a price_amount")
+		}
+
+		if m.HasColumn("seats", "price") {
+			// Un asiento sin evento queda en la moneda por defecto
+			const seatCurrency = `COALESCE((SELECT e.price_currency FROM events e WHERE e.id::text = seats.event_id), ?)`
+			var currencies []string
+			if err := tx.Raw(`SELECT DISTINCT `+seatCurrency+` FROM seats WHERE price IS NOT NULL`, money.DefaultCurrency).
+				Scan(&currencies).Error; err != nil {
+				return err
+			}
+			for _, currency := range currencies {
+				factor := int64(math.Pow10(money.Exponent(currency)))
+				if err := tx.Exec(`UPDATE seats SET price_amount = ROUND(price::numeric * ?)::bigint
+					WHERE price IS NOT NULL AND `+seatCurrency+` = ?`, factor, money.DefaultCurrency, currency).Error; err != nil {
+					return err
+				}
+			}
+			if err := m.DropColumn("seats", "price"); err != nil {
+				return err
+			}
+			log.Println("   -> seats.price migrado a price_amount")
+		}
+
+		// La moneda es la del evento: asientos y órdenes la heredan.
+		// Van por separado porque con PrepareStmt no se pueden mandar varias sentencias juntas
+		statements := []string{
+			`UPDATE seats s SET price_currency = e.price_currency
+			 FROM events e
+			 WHERE s.event_id = e.id::text AND s.price_currency <> e.price_currency`,
+			`UPDATE checkouts SET currency = UPPER(currency) WHERE currency <> UPPER(currency)`,
+			`UPDATE booking_orders o SET currency = c.currency
+			 FROM checkouts c
+			 WHERE c.order_id = o.id::text AND o.currency <> c.currency`,
+		}
+		for _, stmt := range statements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -34,3 +34,74 @@ func TestDatabase_Integration_InitMigrateClose(t *testing.T) {
 		t.Fatalf("close db failed: %v", err)
 	}
 }
+
+func TestDatabase_Integration_MigrateLegacyPrices(t *testing.T) {
+	dsn := os.Getenv("BOOKING_IT_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("BOOKING_IT_DATABASE_URL not set; skipping DB integration")
+	}
+
+	db := InitDB(t.Context(), &config.Config{DBUrl: dsn, DbMaxOpenConns: 2, DbMaxIdleConns: 1})
+	defer CloseDB(db)
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("run migrations failed: %v", err)
+	}
+
+	// Se recrean las columnas viejas como estaban antes de money.Money
+	eventID := "7e57a000-0000-0000-0000-000000000001"
+	seatID := "7e57a000-0000-0000-0000-000000000002"
+	clpEventID := "7e57a000-0000-0000-0000-000000000003"
+	clpSeatID := "7e57a000-0000-0000-0000-000000000004"
+	setup := []string{
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS price bigint`,
+		`ALTER TABLE seats ADD COLUMN IF NOT EXISTS price double precision`,
+		`DELETE FROM seats WHERE id IN ('` + seatID + `', '` + clpSeatID + `')`,
+		`DELETE FROM events WHERE id IN ('` + eventID + `', '` + clpEventID + `')`,
+		`INSERT INTO events (id, name, date, price, price_currency, created_at, updated_at) VALUES ('` + eventID + `', 'Legacy', now(), 150000, 'USD', now(), now())`,
+		`INSERT INTO seats (id, number, event_id, price, created_at, updated_at) VALUES ('` + seatID + `', 'A1', '` + eventID + `', 25.5, now(), now())`,
+		`INSERT INTO events (id, name, date, price, price_currency, created_at, updated_at) VALUES ('` + clpEventID + `', 'Legacy CLP', now(), 15000, 'CLP', now(), now())`,
+		`INSERT INTO seats (id, number, event_id, price, created_at, updated_at) VALUES ('` + clpSeatID + `', 'A1', '` + clpEventID + `', 15000, now(), now())`,
+	}
+	for _, stmt := range setup {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("setup %q failed: %v", stmt, err)
+		}
+	}
+	defer db.Exec(`DELETE FROM seats WHERE id IN ?`, []string{seatID, clpSeatID})
+	defer db.Exec(`DELETE FROM events WHERE id IN ?`, []string{eventID, clpEventID})
+
+	if err := migrateLegacyPrices(db); err != nil {
+		t.Fatalf("migrate prices failed: %v", err)
+	}
+
+	var event struct {
+		PriceAmount   int64
+		PriceCurrency string
+	}
+	db.Raw(`SELECT price_amount, price_currency FROM events WHERE id = ?`, eventID).Scan(&event)
+	if event.PriceAmount != 150000 || event.PriceCurrency != "USD" {
+		t.Fatalf("unexpected event price %+v", event)
+	}
+
+	var seat struct {
+		PriceAmount   int64
+		PriceCurrency string
+	}
+	db.Raw(`SELECT price_amount, price_currency FROM seats WHERE id = ?`, seatID).Scan(&seat)
+	if seat.PriceAmount != 2550 || seat.PriceCurrency != "USD" {
+		t.Fatalf("expected seat price in cents and event currency, got %+v", seat)
+	}
+
+	// El peso chileno no tiene centavos: 15000 CLP siguen siendo 15000
+	db.Raw(`SELECT price_amount, price_currency FROM seats WHERE id = ?`, clpSeatID).Scan(&seat)
+	if seat.PriceAmount != 15000 || seat.PriceCurrency != "CLP" {
+		t.Fatalf("expected CLP seat price without scaling, got %+v", seat)
+	}
+
+	if db.Migrator().HasColumn("seats", "price") || db.Migrator().HasColumn("events", "price") {
+		t.Fatalf("expected legacy price columns to be dropped")
+	}
+	if err := migrateLegacyPrices(db); err != nil {
+		t.Fatalf("expected migration to be idempotent: %v", err)
+	}
+}
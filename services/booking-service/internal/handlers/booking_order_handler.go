@@ -3,6 +3,8 @@ package handlers
 import (
 	"booking-service/internal/models"
 	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -11,10 +13,11 @@ import (
 
 type BookingOrderHandler struct {
 	service *services.BookingOrderService
+	refunds *services.RefundService
 }
 
-func NewBookingOrderHandler(service *services.BookingOrderService) *BookingOrderHandler {
-	return &BookingOrderHandler{service: service}
+func NewBookingOrderHandler(service *services.BookingOrderService, refunds *services.RefundService) *BookingOrderHandler {
+	return &BookingOrderHandler{service: service, refunds: refunds}
 }
 
 // CreateBookingOrder godoc
@@ -66,22 +69,28 @@ func (h *BookingOrderHandler) GetBookingOrders(c *gin.Context) {
 }
 
 type updateBookingOrderReq struct {
-	Status            models.PaymentStatus `json:"status" binding:"required"`
-	PaymentProviderID string               `json:"paymentProviderId"`
+	Status            models.PaymentStatus `json:"status" binding:"required"` // CANCELLED (las llamadas internas, cualquier estado)
+	Version           *int                 `json:"version"`                   // Opcional: versión leída por el cliente
+	Reason            string               `json:"reason"`
+	PaymentProviderID string               `json:"paymentProviderId"` // Solo llamadas internas
 }
 
 // UpdateBookingOrder godoc
-// @Summary Actualizar booking order
-// @Description Actualiza un registro de booking order
+// @Summary Cancelar booking order
+// @Description El dueño de una orden PENDING la cancela (status CANCELLED): se cierra la sesión de pago y se liberan los asientos del hold. Las llamadas internas (X-Internal-Secret, p. ej. la Lambda payment-processor) pueden pedir cualquier estado, validado por la máquina de estados
 // @Tags booking-order
 // @Accept json
 // @Produce json
 // @Param id path string true "ID del booking order"
-// @Param bookingOrder body updateBookingOrderReq true "Datos del booking order"
+// @Param bookingOrder body updateBookingOrderReq true "Estado, versión y motivo"
 // @Success 200 {object} models.BookingOrder "Booking order actualizado exitosamente"
-// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 400 {object} map[string]string "Datos inválidos o estado distinto de CANCELLED"
+// @Failure 401 {object} map[string]string "Usuario no autenticado"
+// @Failure 403 {object} map[string]string "La orden no pertenece al usuario"
+// @Failure 404 {object} map[string]string "Booking order no encontrado"
+// @Failure 409 {object} map[string]string "Transición no permitida o versión desactualizada"
 // @Failure 500 {object} map[string]string "Error interno del servidor"
-// @Router /booking-order/{id} [put]
+// @Router /booking-orders/{id} [patch]
 // @Security BearerAuth
 func (h *BookingOrderHandler) UpdateBookingOrder(c *gin.Context) {
 	id := c.Param("id")
@@ -91,21 +100,56 @@ func (h *BookingOrderHandler) UpdateBookingOrder(c *gin.Context) {
 		return
 	}
 
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
 	var req updateBookingOrderReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	switch req.Status {
-	case models.PaymentPending, models.PaymentCompleted, models.PaymentFailed:
-	default:
+	if !req.Status.IsValid() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
 		return
 	}
 
-	if err := h.service.UpdateBookingOrder(id, req.Status, req.PaymentProviderID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Las llamadas internas (fallback de la Lambda payment-processor) aplican cualquier
+	// transición válida; el dueño solo puede cancelar
+	var updateErr error
+	switch {
+	case userID == internalUserID:
+		updateErr = h.service.ChangeBookingOrderStatus(id, services.OrderStatusChange{
+			To:                req.Status,
+			PaymentProviderID: req.PaymentProviderID,
+			ExpectedVersion:   req.Version,
+			ChangedBy:         internalUserID,
+			Reason:            req.Reason,
+		})
+	case req.Status != models.PaymentCancelled:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only CANCELLED can be requested"})
+		return
+	default:
+		updateErr = h.service.CancelOrder(id, userID, req.Version, req.Reason)
+	}
+
+	if err := updateErr; err != nil {
+		var transitionErr *utils.InvalidTransitionError
+		switch {
+		case errors.Is(err, utils.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking order not found"})
+		case errors.Is(err, utils.ErrOrderForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.As(err, &transitionErr):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "from": transitionErr.From, "to": transitionErr.To})
+		case errors.Is(err, utils.ErrOrderVersionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -118,6 +162,84 @@ func (h *BookingOrderHandler) UpdateBookingOrder(c *gin.Context) {
 	c.JSON(http.StatusOK, bookingOrder)
 }
 
+type refundBookingOrderReq struct {
+	SeatIDs []string `json:"seatIds"` // Opcional: sin asientos se reembolsa toda la orden
+	Reason  string   `json:"reason"`
+}
+
+// RefundBookingOrder godoc
+// @Summary Reembolsar booking order
+// @Description Reembolsa la orden completa o solo los asientos indicados; los asientos vuelven a estar disponibles
+// @Tags booking-order
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del booking order"
+// @Param refund body refundBookingOrderReq false "Asientos a reembolsar y motivo"
+// @Success 200 {object} map[string]interface{} "Orden y reembolso aplicados"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "Usuario no autenticado"
+// @Failure 403 {object} map[string]string "La orden no pertenece al usuario"
+// @Failure 404 {object} map[string]string "Booking order no encontrado"
+// @Failure 409 {object} map[string]string "La orden no admite reembolso o ya hay uno en curso"
+// @Failure 422 {object} map[string]string "Asientos no reembolsables"
+// @Failure 502 {object} map[string]string "La pasarela rechazó el reembolso"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /booking-orders/{id}/refund [post]
+// @Security BearerAuth
+func (h *BookingOrderHandler) RefundBookingOrder(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req refundBookingOrderReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	for _, seatID := range req.SeatIDs {
+		if _, err := uuid.Parse(seatID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seat UUID format"})
+			return
+		}
+	}
+
+	order, refund, err := h.refunds.RefundOrder(c.Request.Context(), id, userID, req.SeatIDs, req.Reason)
+	if err != nil {
+		var transitionErr *utils.InvalidTransitionError
+		switch {
+		case errors.Is(err, utils.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking order not found"})
+		case errors.Is(err, utils.ErrOrderForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.As(err, &transitionErr):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "from": transitionErr.From, "to": transitionErr.To})
+		case errors.Is(err, utils.ErrRefundInProgress),
+			errors.Is(err, utils.ErrNothingToRefund),
+			errors.Is(err, utils.ErrOrderVersionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrInvalidRefundSeats):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrRefundFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "refund": refund})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order": order, "refund": refund})
+}
+
 // GetBookingOrderById godoc
 // @Summary Obtener booking order por ID
 // @Description Obtiene un registro de booking order por su ID
@@ -154,6 +276,33 @@ func (h *BookingOrderHandler) GetBookingOrderById(c *gin.Context) {
 	c.JSON(http.StatusOK, bookingOrder)
 }
 
+// GetBookingOrderHistory godoc
+// @Summary Historial de estados de un booking order
+// @Description Obtiene los cambios de estado de un booking order (quién, qué y cuándo)
+// @Tags booking-order
+// @Produce json
+// @Param id path string true "ID del booking order"
+// @Success 200 {array} models.BookingOrderStatusHistory "Historial de estados"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /booking-order/{id}/history [get]
+// @Security BearerAuth
+func (h *BookingOrderHandler) GetBookingOrderHistory(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	history, err := h.service.FindStatusHistory(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // GetAllOrderForUserID godoc
 // @Summary Obtener todos los booking orders por ID de usuario
 // @Description Obtiene todos los registros de booking orders por ID de usuario
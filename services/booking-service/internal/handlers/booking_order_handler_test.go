@@ -6,9 +6,31 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
+// stubOrderRepo devuelve siempre la misma orden y registra los cambios de estado
+type stubOrderRepo struct {
+	repositories.BookingOrderRepository
+	order   models.BookingOrder
+	changes []*repositories.StatusChange
+}
+
+func (r *stubOrderRepo) FindByID(string) (*models.BookingOrder, error) {
+	order := r.order
+	return &order, nil
+}
+
+func (r *stubOrderRepo) ChangeStatus(change *repositories.StatusChange) error {
+	r.changes = append(r.changes, change)
+	r.order.Status = change.To
+	return nil
+}
+
 func TestBookingOrderHandler_CreateBookingOrder_BadJSON(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	h := &BookingOrderHandler{}
@@ -38,19 +60,130 @@ func TestBookingOrderHandler_GetBookingOrderById_InvalidUUID(t *testing.T) {
 	}
 }
 
-func TestBookingOrderHandler_UpdateBookingOrder_InvalidStatus(t *testing.T) {
+func TestBookingOrderHandler_UpdateBookingOrder_Validation(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	h := &BookingOrderHandler{}
 	r := gin.New()
-	r.PATCH("/booking-orders/:id", h.UpdateBookingOrder)
+	r.PATCH("/booking-orders/:id", func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set("userID", user)
+		}
+		h.UpdateBookingOrder(c)
+	})
 
-	body := `{"status":"BAD_STATUS"}`
-	req := httptest.NewRequest(http.MethodPatch, "/booking-orders/11111111-1111-1111-1111-111111111111", bytes.NewBufferString(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	path := "/booking-orders/11111111-1111-1111-1111-111111111111"
+	cases := []struct {
+		name string
+		user string
+		body string
+		want int
+	}{
+		{"not authenticated", "", `{"status":"CANCELLED"}`, http.StatusUnauthorized},
+		{"invalid status", "u1", `{"status":"BAD_STATUS"}`, http.StatusBadRequest},
+		{"only cancellation", "u1", `{"status":"COMPLETED"}`, http.StatusBadRequest},
+		{"internal invalid status", "internal", `{"status":"BAD_STATUS"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Test-User", tc.user)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+// Fallback de la Lambda payment-processor: la llamada interna aplica transiciones válidas
+func TestBookingOrderHandler_UpdateBookingOrder_Internal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/booking-orders/11111111-1111-1111-1111-111111111111"
+
+	cases := []struct {
+		name string
+		from models.PaymentStatus
+		want int
+	}{
+		{"pending to completed", models.PaymentPending, http.StatusOK},
+		{"illegal transition", models.PaymentCancelled, http.StatusConflict},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &stubOrderRepo{order: models.BookingOrder{BaseModel: models.BaseModel{ID: "11111111-1111-1111-1111-111111111111"}, Status: tc.from}}
+			h := NewBookingOrderHandler(services.NewBookingOrderService(repo, nil, nil, nil, nil), nil)
+			r := gin.New()
+			r.PATCH("/booking-orders/:id", func(c *gin.Context) {
+				c.Set("userID", internalUserID)
+				h.UpdateBookingOrder(c)
+			})
 
+			req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(`{"status":"COMPLETED","paymentProviderId":"pi_1"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d: %s", tc.want, w.Code, w.Body.String())
+			}
+			if tc.want == http.StatusOK && (len(repo.changes) != 1 || repo.changes[0].PaymentProviderID != "pi_1" || repo.changes[0].ChangedBy != internalUserID) {
+				t.Fatalf("unexpected status changes: %+v", repo.changes)
+			}
+			if tc.want != http.StatusOK && len(repo.changes) != 0 {
+				t.Fatalf("expected no status change, got %+v", repo.changes)
+			}
+		})
+	}
+}
+
+func TestBookingOrderHandler_GetBookingOrderHistory_InvalidUUID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &BookingOrderHandler{}
+	r := gin.New()
+	r.GET("/booking-orders/:id/history", h.GetBookingOrderHistory)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/booking-orders/not-uuid/history", nil))
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
 }
+
+func TestBookingOrderHandler_RefundBookingOrder_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &BookingOrderHandler{}
+	r := gin.New()
+	r.POST("/booking-orders/:id/refund", func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set("userID", user)
+		}
+		h.RefundBookingOrder(c)
+	})
+
+	orderID := "11111111-1111-1111-1111-111111111111"
+	cases := []struct {
+		name string
+		path string
+		user string
+		body string
+		want int
+	}{
+		{"invalid order id", "/booking-orders/bad/refund", "u1", "", http.StatusBadRequest},
+		{"not authenticated", "/booking-orders/" + orderID + "/refund", "", "", http.StatusUnauthorized},
+		{"bad json", "/booking-orders/" + orderID + "/refund", "u1", "{", http.StatusBadRequest},
+		{"invalid seat id", "/booking-orders/" + orderID + "/refund", "u1", `{"seatIds":["nope"]}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Test-User", tc.user)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
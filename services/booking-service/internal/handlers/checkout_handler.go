@@ -38,8 +38,8 @@ func (h *CheckoutHandler) Create(c *gin.Context) {
 
 	if checkout.OrderID == "" ||
 		checkout.PaymentIntentID == "" ||
-		checkout.Currency == "" ||
-		checkout.Amount <= 0 ||
+		checkout.Total.Currency == "" ||
+		checkout.Total.Amount <= 0 ||
 		checkout.CustomerEmail == "" ||
 		checkout.CustomerName == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -3,6 +3,8 @@ package handlers
 import (
 	"booking-service/internal/services"
 	"booking-service/pkg/domain"
+	"booking-service/pkg/money"
+	"math"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -31,7 +33,9 @@ type SendPurchaseRequest struct {
 	To      string  `json:"to" binding:"required,email"`
 	Name    string  `json:"name" binding:"required"`
 	OrderId string  `json:"orderId" binding:"required"`
-	Amount  float64 `json:"amount" binding:"required"`
+	Amount  float64 `json:"amount" binding:"required"` // En unidades menores (centavos)
+	// Currency es el código ISO 4217 del importe; vacío es la moneda por defecto
+	Currency string `json:"currency"`
 }
 
 func (h *EmailHandler) SendSync(c *gin.Context) {
@@ -41,8 +45,15 @@ func (h *EmailHandler) SendSync(c *gin.Context) {
 		return
 	}
 
+	currency, err := money.NormalizeCurrency(req.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	ctx := c.Request.Context()
-	if err := h.service.SendPurchaseEmail(ctx, req.To, req.Name, req.OrderId, req.Amount); err != nil {
+	amount := money.New(int64(math.Round(req.Amount)), currency)
+	if err := h.service.SendPurchaseEmail(ctx, req.To, req.Name, req.OrderId, amount, nil); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"booking-service/internal/models"
 	"booking-service/pkg/domain"
+	"booking-service/pkg/money"
 	"bytes"
 	"context"
 	"errors"
@@ -15,15 +17,18 @@ import (
 type mockEmailService struct {
 	sendAsyncFn        func(*domain.Email) error
 	sendBulkFn         func([]*domain.Email)
-	sendPurchaseEmailFn func(context.Context, string, string, string, float64) error
+	sendPurchaseEmailFn func(context.Context, string, string, string, money.Money) error
 }
 
 func (m *mockEmailService) SendAsync(e *domain.Email) error { return m.sendAsyncFn(e) }
 func (m *mockEmailService) SendBulk(e []*domain.Email)      { m.sendBulkFn(e) }
 func (m *mockEmailService) Shutdown()                        {}
-func (m *mockEmailService) SendPurchaseEmail(ctx context.Context, to, name, orderId string, amount float64) error {
+func (m *mockEmailService) SendPurchaseEmail(ctx context.Context, to, name, orderId string, amount money.Money, _ []models.OrderLine) error {
 	return m.sendPurchaseEmailFn(ctx, to, name, orderId, amount)
 }
+func (m *mockEmailService) SendRefundEmail(context.Context, string, string, string, money.Money, int) error {
+	return nil
+}
 
 func TestEmailHandler_SendAsync_QueueFull(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -55,3 +60,32 @@ func TestEmailHandler_SendBulk_Accepted(t *testing.T) {
 		t.Fatalf("expected 202 and bulk call, got code=%d called=%v", w.Code, called)
 	}
 }
+
+func TestEmailHandler_SendSync_UsesCurrency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var got money.Money
+	h := NewEmailHandler(&mockEmailService{sendPurchaseEmailFn: func(_ context.Context, _, _, _ string, amount money.Money) error {
+		got = amount
+		return nil
+	}})
+	r := gin.New()
+	r.POST("/emails/send-sync", h.SendSync)
+
+	send := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/emails/send-sync", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send(`{"to":"a@a.com","name":"Ana","orderId":"o1","amount":15000,"currency":"clp"}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if got != money.New(15000, "CLP") {
+		t.Fatalf("expected CLP amount, got %+v", got)
+	}
+	if code := send(`{"to":"a@a.com","name":"Ana","orderId":"o1","amount":15000,"currency":"XYZ"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown currency, got %d", code)
+	}
+}
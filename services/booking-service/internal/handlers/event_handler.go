@@ -3,6 +3,8 @@ package handlers
 import (
 	"booking-service/internal/models"
 	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -125,7 +127,7 @@ func (h *EventHandler) GetAllEvents(c *gin.Context) {
 // @Param id path string true "ID del evento"
 // @Param event body models.Event true "Datos del evento"
 // @Success 200 {object} map[string]string "Evento actualizado exitosamente"
-// @Failure 400 {object} map[string]string "Formato JSON inválido"
+// @Failure 400 {object} map[string]string "Formato JSON inválido o cambio de moneda"
 // @Failure 401 {object} map[string]string "No autorizado"
 // @Failure 404 {object} map[string]string "Evento no encontrado"
 // @Failure 500 {object} map[string]string "Error interno del servidor"
@@ -144,6 +146,8 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 	if err := h.service.UpdateEvent(id, &event); err != nil {
 		if err.Error() == "Cannot update: event not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, utils.ErrEventCurrencyChange) || errors.Is(err, utils.ErrInvalidWaitingRoom) || errors.Is(err, utils.ErrInvalidSalesWindow) || errors.Is(err, utils.ErrInvalidPurchaseLimit) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
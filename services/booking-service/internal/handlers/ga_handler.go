@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GeneralAdmissionHandler struct {
+	service *services.GeneralAdmissionService
+}
+
+// Constructor
+func NewGeneralAdmissionHandler(service *services.GeneralAdmissionService) *GeneralAdmissionHandler {
+	return &GeneralAdmissionHandler{service: service}
+}
+
+// CreateSection godoc
+// @Summary Crear sector de admisión general
+// @Description Crea un sector sin asientos numerados (campo, pista) con un cupo y un precio por entrada
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param section body models.GASection true "Nombre, capacidad y precio del sector"
+// @Success 201 {object} models.GASection "Sector creado"
+// @Failure 400 {object} map[string]string "Datos inválidos o moneda distinta a la del evento"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 409 {object} map[string]string "Ya existe un sector con ese nombre"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/ga-sections [post]
+// @Security BearerAuth
+// POST /events/:id/ga-sections
+func (h *GeneralAdmissionHandler) CreateSection(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return
+	}
+
+	var section models.GASection
+	if err := c.ShouldBindJSON(&section); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	if err := h.service.CreateSection(eventID, &section); err != nil {
+		respondGAError(c, err, "Failed to create general admission section")
+		return
+	}
+
+	c.JSON(http.StatusCreated, section)
+}
+
+// GetSections godoc
+// @Summary Listar sectores de admisión general
+// @Description Lista los sectores de admisión general del evento con su cupo, entradas bloqueadas y vendidas
+// @Tags events
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Success 200 {array} models.GASection
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/ga-sections [get]
+// @Security BearerAuth
+// GET /events/:id/ga-sections
+func (h *GeneralAdmissionHandler) GetSections(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return
+	}
+
+	sections, err := h.service.GetSections(eventID)
+	if err != nil {
+		respondGAError(c, err, "Failed to fetch general admission sections")
+		return
+	}
+
+	c.JSON(http.StatusOK, sections)
+}
+
+// GetSection godoc
+// @Summary Obtener sector de admisión general
+// @Tags events
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param sectionId path string true "ID del sector"
+// @Success 200 {object} models.GASection
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Sector no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/ga-sections/{sectionId} [get]
+// @Security BearerAuth
+// GET /events/:id/ga-sections/:sectionId
+func (h *GeneralAdmissionHandler) GetSection(c *gin.Context) {
+	eventID, sectionID, ok := gaSectionParams(c)
+	if !ok {
+		return
+	}
+
+	section, err := h.service.GetSection(eventID, sectionID)
+	if err != nil {
+		respondGAError(c, err, "Failed to fetch general admission section")
+		return
+	}
+
+	c.JSON(http.StatusOK, section)
+}
+
+// DeleteSection godoc
+// @Summary Borrar sector de admisión general
+// @Description Borra un sector que todavía no tiene entradas bloqueadas ni vendidas
+// @Tags events
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param sectionId path string true "ID del sector"
+// @Success 200 {object} map[string]string "Sector borrado"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Sector no encontrado"
+// @Failure 409 {object} map[string]string "El sector tiene entradas bloqueadas o vendidas"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/ga-sections/{sectionId} [delete]
+// @Security BearerAuth
+// DELETE /events/:id/ga-sections/:sectionId
+func (h *GeneralAdmissionHandler) DeleteSection(c *gin.Context) {
+	eventID, sectionID, ok := gaSectionParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSection(eventID, sectionID); err != nil {
+		respondGAError(c, err, "Failed to delete general admission section")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "General admission section deleted successfully"})
+}
+
+func gaSectionParams(c *gin.Context) (string, string, bool) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return "", "", false
+	}
+	sectionID := c.Param("sectionId")
+	if _, err := uuid.Parse(sectionID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return "", "", false
+	}
+	return eventID, sectionID, true
+}
+
+func respondGAError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, utils.ErrInvalidGASection), errors.Is(err, utils.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrEventNotFound), errors.Is(err, utils.ErrGASectionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrGASectionNameTaken), errors.Is(err, utils.ErrGASectionInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
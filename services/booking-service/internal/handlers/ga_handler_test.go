@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGeneralAdmissionHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &GeneralAdmissionHandler{}
+	r := gin.New()
+	r.GET("/events/:id/ga-sections", h.GetSections)
+	r.POST("/events/:id/ga-sections", h.CreateSection)
+	r.DELETE("/events/:id/ga-sections/:sectionId", h.DeleteSection)
+
+	const eventID = "11111111-1111-1111-1111-111111111111"
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"invalid event id", http.MethodGet, "/events/bad/ga-sections", ""},
+		{"invalid json", http.MethodPost, "/events/" + eventID + "/ga-sections", `{`},
+		{"invalid section id", http.MethodDelete, "/events/" + eventID + "/ga-sections/bad", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RedeemPresaleReq es el código de preventa que ingresa el usuario
+type RedeemPresaleReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type PresaleHandler struct {
+	service *services.PresaleService
+}
+
+// Constructor
+func NewPresaleHandler(service *services.PresaleService) *PresaleHandler {
+	return &PresaleHandler{service: service}
+}
+
+// CreateCode godoc
+// @Summary Crear código de preventa
+// @Description Crea un código que habilita a comprar durante la preventa del evento (desde presaleStart hasta salesStart).
+// @Description maxUses es la cantidad de usuarios que pueden canjearlo (1 por defecto, un solo uso); con userId solo ese usuario puede canjearlo
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param code body models.PresaleCode true "Código, usos máximos y usuario (opcional)"
+// @Success 201 {object} models.PresaleCode "Código creado"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 409 {object} map[string]string "El código ya existe en el evento"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/presale-codes [post]
+// @Security BearerAuth
+// POST /events/:id/presale-codes
+func (h *PresaleHandler) CreateCode(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok || !requireInternal(c) {
+		return
+	}
+
+	var code models.PresaleCode
+	if err := c.ShouldBindJSON(&code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	if err := h.service.CreateCode(eventID, &code); err != nil {
+		respondPresaleError(c, err, "Failed to create presale code")
+		return
+	}
+
+	c.JSON(http.StatusCreated, code)
+}
+
+// GetCodes godoc
+// @Summary Listar códigos de preventa
+// @Description Lista los códigos de preventa del evento con sus usos
+// @Tags events
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Success 200 {array} models.PresaleCode
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/presale-codes [get]
+// @Security BearerAuth
+// GET /events/:id/presale-codes
+func (h *PresaleHandler) GetCodes(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok || !requireInternal(c) {
+		return
+	}
+
+	codes, err := h.service.GetCodes(eventID)
+	if err != nil {
+		respondPresaleError(c, err, "Failed to fetch presale codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// DeleteCode godoc
+// @Summary Borrar código de preventa
+// @Description Borra el código; los usuarios que ya lo canjearon conservan el acceso a la preventa
+// @Tags events
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param codeId path string true "ID del código"
+// @Success 200 {object} map[string]string "Código borrado"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Código no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/presale-codes/{codeId} [delete]
+// @Security BearerAuth
+// DELETE /events/:id/presale-codes/:codeId
+func (h *PresaleHandler) DeleteCode(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok || !requireInternal(c) {
+		return
+	}
+	codeID := c.Param("codeId")
+	if _, err := uuid.Parse(codeID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	if err := h.service.DeleteCode(eventID, codeID); err != nil {
+		respondPresaleError(c, err, "Failed to delete presale code")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Presale code deleted successfully"})
+}
+
+// Redeem godoc
+// @Summary Canjear código de preventa
+// @Description Canjea un código de preventa para el usuario autenticado: desde presaleStart puede bloquear asientos y pagar antes de la venta general.
+// @Description Canjear de nuevo no gasta otro uso del código
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param body body RedeemPresaleReq true "Código de preventa"
+// @Success 200 {object} models.PresaleAccess "Acceso a la preventa"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "La venta del evento terminó"
+// @Failure 404 {object} map[string]string "Evento o código no encontrado"
+// @Failure 409 {object} map[string]string "El código no tiene usos disponibles"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/presale/redeem [post]
+// @Security BearerAuth
+// POST /events/:id/presale/redeem
+func (h *PresaleHandler) Redeem(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var body RedeemPresaleReq
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	access, err := h.service.Redeem(eventID, userID, body.Code)
+	if err != nil {
+		respondPresaleError(c, err, "Failed to redeem presale code")
+		return
+	}
+
+	c.JSON(http.StatusOK, access)
+}
+
+func respondPresaleError(c *gin.Context, err error, fallback string) {
+	if respondSalesWindowError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, utils.ErrInvalidPresaleCode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrEventNotFound), errors.Is(err, utils.ErrPresaleCodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrPresaleCodeTaken), errors.Is(err, utils.ErrPresaleCodeExhausted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// respondSalesWindowError responde 403 si el evento no está a la venta para el usuario, con
+// las fechas de apertura de la venta y de la preventa
+func respondSalesWindowError(c *gin.Context, err error) bool {
+	var notStarted *utils.SalesNotStartedError
+	switch {
+	case errors.As(err, &notStarted):
+		body := gin.H{
+			"error":      err.Error(),
+			"salesStart": notStarted.SalesStart.Format(time.RFC3339),
+		}
+		if notStarted.PresaleStart != nil {
+			body["presaleStart"] = notStarted.PresaleStart.Format(time.RFC3339)
+		}
+		c.JSON(http.StatusForbidden, body)
+	case errors.Is(err, utils.ErrSalesEnded):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"booking-service/pkg/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPresaleHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &PresaleHandler{}
+	r := gin.New()
+	internal := func(c *gin.Context) { c.Set("userID", "internal") }
+	r.POST("/events/:id/presale-codes", internal, h.CreateCode)
+	r.DELETE("/events/:id/presale-codes/:codeId", internal, h.DeleteCode)
+	r.POST("/events/:id/presale/redeem", h.Redeem)
+	r.GET("/auth/events/:id/presale-codes", func(c *gin.Context) { c.Set("userID", "u1") }, h.GetCodes)
+	r.POST("/auth/events/:id/presale-codes", func(c *gin.Context) { c.Set("userID", "u1") }, h.CreateCode)
+	r.POST("/auth/events/:id/presale/redeem", func(c *gin.Context) { c.Set("userID", "u1") }, h.Redeem)
+
+	const eventID = "11111111-1111-1111-1111-111111111111"
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"create invalid event id", http.MethodPost, "/events/bad/presale-codes", `{"code":"FANS"}`, http.StatusBadRequest},
+		{"create invalid json", http.MethodPost, "/events/" + eventID + "/presale-codes", `{`, http.StatusBadRequest},
+		{"delete invalid code id", http.MethodDelete, "/events/" + eventID + "/presale-codes/bad", "", http.StatusBadRequest},
+		{"list codes as regular user", http.MethodGet, "/auth/events/" + eventID + "/presale-codes", "", http.StatusForbidden},
+		{"create code as regular user", http.MethodPost, "/auth/events/" + eventID + "/presale-codes", `{"code":"FANS"}`, http.StatusForbidden},
+		{"redeem missing user", http.MethodPost, "/events/" + eventID + "/presale/redeem", `{"code":"FANS"}`, http.StatusUnauthorized},
+		{"redeem missing code", http.MethodPost, "/auth/events/" + eventID + "/presale/redeem", `{}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRespondPresaleError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"invalid", utils.ErrInvalidPresaleCode, http.StatusBadRequest},
+		{"event not found", utils.ErrEventNotFound, http.StatusNotFound},
+		{"code not found", utils.ErrPresaleCodeNotFound, http.StatusNotFound},
+		{"taken", utils.ErrPresaleCodeTaken, http.StatusConflict},
+		{"exhausted", utils.ErrPresaleCodeExhausted, http.StatusConflict},
+		{"sales ended", utils.ErrSalesEnded, http.StatusForbidden},
+		{"other", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondPresaleError(c, tc.err, "fallback")
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRespondSalesWindowError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	salesStart := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	presaleStart := salesStart.Add(-24 * time.Hour)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if !respondSalesWindowError(c, &utils.SalesNotStartedError{SalesStart: salesStart, PresaleStart: &presaleStart}) {
+		t.Fatalf("expected sales window error handled")
+	}
+	var body map[string]string
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusForbidden || body["salesStart"] != "2026-05-01T12:00:00Z" || body["presaleStart"] != "2026-04-30T12:00:00Z" {
+		t.Fatalf("unexpected response %d %v", w.Code, body)
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	if respondSalesWindowError(c, utils.ErrHoldLimitExceeded) {
+		t.Fatalf("expected other errors not handled")
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/services"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PriceTierHandler struct {
+	service *services.PriceTierService
+}
+
+// Constructor
+func NewPriceTierHandler(service *services.PriceTierService) *PriceTierHandler {
+	return &PriceTierHandler{service: service}
+}
+
+// RepriceTierReq es el nuevo precio de la categoría (unidades menores, moneda del evento)
+type RepriceTierReq struct {
+	Price *money.Money `json:"price" binding:"required"`
+}
+
+// RepriceTierRes es la categoría con su nuevo precio, la cantidad de asientos actualizados y
+// la de asientos bloqueados que conservan su precio
+type RepriceTierRes struct {
+	Tier          *models.PriceTier `json:"tier"`
+	SeatsRepriced int64             `json:"seatsRepriced"`
+	SeatsSkipped  int64             `json:"seatsSkipped"`
+}
+
+// CreateTier godoc
+// @Summary Crear categoría de precio
+// @Description Crea una categoría de asientos (nombre, precio, color, capacidad) para el evento
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param tier body models.PriceTier true "Datos de la categoría"
+// @Success 201 {object} models.PriceTier "Categoría creada"
+// @Failure 400 {object} map[string]string "Datos inválidos o moneda distinta a la del evento"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 409 {object} map[string]string "Ya existe una categoría con ese nombre"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/tiers [post]
+// @Security BearerAuth
+// POST /events/:id/tiers
+func (h *PriceTierHandler) CreateTier(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return
+	}
+
+	var tier models.PriceTier
+	if err := c.ShouldBindJSON(&tier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	if err := h.service.CreateTier(eventID, &tier); err != nil {
+		respondTierError(c, err, "Failed to create price tier")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tier)
+}
+
+// GetTiers godoc
+// @Summary Listar categorías de precio
+// @Description Lista las categorías de asientos del evento
+// @Tags events
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Success 200 {array} models.PriceTier
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/tiers [get]
+// @Security BearerAuth
+// GET /events/:id/tiers
+func (h *PriceTierHandler) GetTiers(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return
+	}
+
+	tiers, err := h.service.GetTiers(eventID)
+	if err != nil {
+		respondTierError(c, err, "Failed to fetch price tiers")
+		return
+	}
+
+	c.JSON(http.StatusOK, tiers)
+}
+
+// GetTier godoc
+// @Summary Obtener categoría de precio
+// @Tags events
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param tierId path string true "ID de la categoría"
+// @Success 200 {object} models.PriceTier
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Categoría no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/tiers/{tierId} [get]
+// @Security BearerAuth
+// GET /events/:id/tiers/:tierId
+func (h *PriceTierHandler) GetTier(c *gin.Context) {
+	eventID, tierID, ok := tierParams(c)
+	if !ok {
+		return
+	}
+
+	tier, err := h.service.GetTier(eventID, tierID)
+	if err != nil {
+		respondTierError(c, err, "Failed to fetch price tier")
+		return
+	}
+
+	c.JSON(http.StatusOK, tier)
+}
+
+// UpdateTier godoc
+// @Summary Actualizar categoría de precio
+// @Description Cambia nombre, color, capacidad o descripción. El precio se cambia con /reprice
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param tierId path string true "ID de la categoría"
+// @Param tier body models.PriceTierUpdate true "Campos a modificar"
+// @Success 200 {object} models.PriceTier
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Categoría no encontrada"
+// @Failure 409 {object} map[string]string "Nombre repetido o capacidad menor a los asientos asignados"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/tiers/{tierId} [patch]
+// @Security BearerAuth
+// PATCH /events/:id/tiers/:tierId
+func (h *PriceTierHandler) UpdateTier(c *gin.Context) {
+	eventID, tierID, ok := tierParams(c)
+	if !ok {
+		return
+	}
+
+	var changes models.PriceTierUpdate
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	tier, err := h.service.UpdateTier(eventID, tierID, changes)
+	if err != nil {
+		respondTierError(c, err, "Failed to update price tier")
+		return
+	}
+
+	c.JSON(http.StatusOK, tier)
+}
+
+// DeleteTier godoc
+// @Summary Borrar categoría de precio
+// @Description Borra una categoría que ya no tiene asientos asignados
+// @Tags events
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param tierId path string true "ID de la categoría"
+// @Success 200 {object} map[string]string "Categoría borrada"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Categoría no encontrada"
+// @Failure 409 {object} map[string]string "La categoría tiene asientos"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/tiers/{tierId} [delete]
+// @Security BearerAuth
+// DELETE /events/:id/tiers/:tierId
+func (h *PriceTierHandler) DeleteTier(c *gin.Context) {
+	eventID, tierID, ok := tierParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTier(eventID, tierID); err != nil {
+		respondTierError(c, err, "Failed to delete price tier")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price tier deleted successfully"})
+}
+
+// RepriceTier godoc
+// @Summary Cambiar el precio de una categoría
+// @Description Cambia el precio de la categoría y de sus asientos disponibles en una sola operación. Los asientos vendidos o bloqueados conservan su precio; los bloqueados se informan en seatsSkipped
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param tierId path string true "ID de la categoría"
+// @Param body body RepriceTierReq true "Nuevo precio"
+// @Success 200 {object} RepriceTierRes
+// @Failure 400 {object} map[string]string "Precio inválido o moneda distinta a la del evento"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Evento o categoría no encontrados"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/tiers/{tierId}/reprice [post]
+// @Security BearerAuth
+// POST /events/:id/tiers/:tierId/reprice
+func (h *PriceTierHandler) RepriceTier(c *gin.Context) {
+	eventID, tierID, ok := tierParams(c)
+	if !ok {
+		return
+	}
+
+	var body RepriceTierReq
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	tier, result, err := h.service.RepriceTier(eventID, tierID, *body.Price)
+	if err != nil {
+		respondTierError(c, err, "Failed to reprice tier")
+		return
+	}
+
+	c.JSON(http.StatusOK, RepriceTierRes{Tier: tier, SeatsRepriced: result.Repriced, SeatsSkipped: result.Skipped})
+}
+
+func eventIDParam(c *gin.Context) (string, bool) {
+	eventID := c.Param("id")
+	if _, err := uuid.Parse(eventID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return "", false
+	}
+	return eventID, true
+}
+
+func tierParams(c *gin.Context) (string, string, bool) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return "", "", false
+	}
+	tierID := c.Param("tierId")
+	if _, err := uuid.Parse(tierID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return "", "", false
+	}
+	return eventID, tierID, true
+}
+
+func respondTierError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, utils.ErrInvalidTier), errors.Is(err, utils.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrEventNotFound), errors.Is(err, utils.ErrTierNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrTierNameTaken), errors.Is(err, utils.ErrTierInUse), errors.Is(err, utils.ErrTierFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
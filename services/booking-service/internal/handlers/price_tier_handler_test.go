@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPriceTierHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &PriceTierHandler{}
+	r := gin.New()
+	r.GET("/events/:id/tiers", h.GetTiers)
+	r.PATCH("/events/:id/tiers/:tierId", h.UpdateTier)
+	r.POST("/events/:id/tiers/:tierId/reprice", h.RepriceTier)
+
+	const eventID = "11111111-1111-1111-1111-111111111111"
+	const tierID = "22222222-2222-2222-2222-222222222222"
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"invalid event id", http.MethodGet, "/events/bad/tiers", ""},
+		{"invalid tier id", http.MethodPatch, "/events/" + eventID + "/tiers/bad", `{"name":"VIP"}`},
+		{"invalid json", http.MethodPatch, "/events/" + eventID + "/tiers/" + tierID, `{`},
+		{"missing price", http.MethodPost, "/events/" + eventID + "/tiers/" + tierID + "/reprice", `{}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PricingHandler struct {
+	service *services.PricingService
+}
+
+// Constructor
+func NewPricingHandler(service *services.PricingService) *PricingHandler {
+	return &PricingHandler{service: service}
+}
+
+// CreateFeeRule godoc
+// @Summary Crear regla de cargos
+// @Description Crea un cargo por entrada (type SERVICE o FACILITY) de un evento (eventId) o de un recinto (venueId): rateBps en puntos básicos sobre el valor nominal (1000 = 10%) más un importe fijo amount.
+// @Description Si el evento tiene reglas propias reemplazan a las de su recinto
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param rule body models.FeeRule true "Evento o recinto, tipo, nombre y cargo"
+// @Success 201 {object} models.FeeRule "Regla creada"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Evento o recinto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /fee-rules [post]
+// @Security BearerAuth
+// POST /fee-rules
+func (h *PricingHandler) CreateFeeRule(c *gin.Context) {
+	if !requireInternal(c) {
+		return
+	}
+
+	var rule models.FeeRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	if !validScopeIDs(c, rule.EventID, rule.VenueID) {
+		return
+	}
+
+	if err := h.service.CreateFeeRule(&rule); err != nil {
+		respondPricingError(c, err, "Failed to create fee rule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// GetFeeRules godoc
+// @Summary Listar reglas de cargos
+// @Description Lista las reglas de cargos, filtradas por evento o por recinto
+// @Tags pricing
+// @Produce json
+// @Param eventId query string false "ID del evento"
+// @Param venueId query string false "ID del recinto"
+// @Success 200 {array} models.FeeRule
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /fee-rules [get]
+// @Security BearerAuth
+// GET /fee-rules
+func (h *PricingHandler) GetFeeRules(c *gin.Context) {
+	eventID, venueID, ok := scopeQuery(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.service.GetFeeRules(eventID, venueID)
+	if err != nil {
+		respondPricingError(c, err, "Failed to fetch fee rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteFeeRule godoc
+// @Summary Borrar regla de cargos
+// @Description Borra la regla; las órdenes ya creadas conservan sus líneas
+// @Tags pricing
+// @Produce json
+// @Param id path string true "ID de la regla"
+// @Success 200 {object} map[string]string "Regla borrada"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Regla no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /fee-rules/{id} [delete]
+// @Security BearerAuth
+// DELETE /fee-rules/:id
+func (h *PricingHandler) DeleteFeeRule(c *gin.Context) {
+	if !requireInternal(c) {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	if err := h.service.DeleteFeeRule(id); err != nil {
+		respondPricingError(c, err, "Failed to delete fee rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fee rule deleted successfully"})
+}
+
+// CreateTaxRate godoc
+// @Summary Crear impuesto
+// @Description Crea un impuesto (por ejemplo IVA 21%, rateBps 2100) de un evento o de un recinto. appliesTo limita las líneas gravadas (TICKET, SERVICE_FEE, FACILITY_FEE); vacío grava entradas y cargos.
+// @Description Si el evento tiene impuestos propios reemplazan a los de su recinto
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param rate body models.TaxRate true "Evento o recinto, nombre, alícuota y líneas gravadas"
+// @Success 201 {object} models.TaxRate "Impuesto creado"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Evento o recinto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /tax-rates [post]
+// @Security BearerAuth
+// POST /tax-rates
+func (h *PricingHandler) CreateTaxRate(c *gin.Context) {
+	if !requireInternal(c) {
+		return
+	}
+
+	var rate models.TaxRate
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	if !validScopeIDs(c, rate.EventID, rate.VenueID) {
+		return
+	}
+
+	if err := h.service.CreateTaxRate(&rate); err != nil {
+		respondPricingError(c, err, "Failed to create tax rate")
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// GetTaxRates godoc
+// @Summary Listar impuestos
+// @Description Lista los impuestos, filtrados por evento o por recinto
+// @Tags pricing
+// @Produce json
+// @Param eventId query string false "ID del evento"
+// @Param venueId query string false "ID del recinto"
+// @Success 200 {array} models.TaxRate
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /tax-rates [get]
+// @Security BearerAuth
+// GET /tax-rates
+func (h *PricingHandler) GetTaxRates(c *gin.Context) {
+	eventID, venueID, ok := scopeQuery(c)
+	if !ok {
+		return
+	}
+
+	rates, err := h.service.GetTaxRates(eventID, venueID)
+	if err != nil {
+		respondPricingError(c, err, "Failed to fetch tax rates")
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// DeleteTaxRate godoc
+// @Summary Borrar impuesto
+// @Description Borra el impuesto; las órdenes ya creadas conservan sus líneas
+// @Tags pricing
+// @Produce json
+// @Param id path string true "ID del impuesto"
+// @Success 200 {object} map[string]string "Impuesto borrado"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Impuesto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /tax-rates/{id} [delete]
+// @Security BearerAuth
+// DELETE /tax-rates/:id
+func (h *PricingHandler) DeleteTaxRate(c *gin.Context) {
+	if !requireInternal(c) {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	if err := h.service.DeleteTaxRate(id); err != nil {
+		respondPricingError(c, err, "Failed to delete tax rate")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tax rate deleted successfully"})
+}
+
+// validScopeIDs controla el formato del evento y el recinto de una regla
+func validScopeIDs(c *gin.Context, ids ...*string) bool {
+	for _, id := range ids {
+		if id == nil || *id == "" {
+			continue
+		}
+		if _, err := uuid.Parse(*id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+			return false
+		}
+	}
+	return true
+}
+
+func scopeQuery(c *gin.Context) (string, string, bool) {
+	eventID, venueID := c.Query("eventId"), c.Query("venueId")
+	if !validScopeIDs(c, &eventID, &venueID) {
+		return "", "", false
+	}
+	return eventID, venueID, true
+}
+
+func respondPricingError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, utils.ErrInvalidFeeRule), errors.Is(err, utils.ErrInvalidTaxRate), errors.Is(err, utils.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrEventNotFound), errors.Is(err, utils.ErrVenueNotFound),
+		errors.Is(err, utils.ErrFeeRuleNotFound), errors.Is(err, utils.ErrTaxRateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPricingHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &PricingHandler{}
+	r := gin.New()
+	internal := func(c *gin.Context) { c.Set("userID", "internal") }
+	user := func(c *gin.Context) { c.Set("userID", "u1") }
+	r.POST("/fee-rules", internal, h.CreateFeeRule)
+	r.GET("/fee-rules", h.GetFeeRules)
+	r.DELETE("/fee-rules/:id", internal, h.DeleteFeeRule)
+	r.POST("/tax-rates", internal, h.CreateTaxRate)
+	r.GET("/tax-rates", h.GetTaxRates)
+	r.DELETE("/tax-rates/:id", internal, h.DeleteTaxRate)
+	r.POST("/auth/fee-rules", user, h.CreateFeeRule)
+	r.DELETE("/auth/fee-rules/:id", user, h.DeleteFeeRule)
+	r.POST("/auth/tax-rates", user, h.CreateTaxRate)
+	r.DELETE("/auth/tax-rates/:id", user, h.DeleteTaxRate)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"create fee invalid json", http.MethodPost, "/fee-rules", `{`, http.StatusBadRequest},
+		{"create fee invalid event id", http.MethodPost, "/fee-rules", `{"eventId":"bad","name":"Servicio"}`, http.StatusBadRequest},
+		{"create fee invalid venue id", http.MethodPost, "/fee-rules", `{"venueId":"bad","name":"Servicio"}`, http.StatusBadRequest},
+		{"list fees invalid venue id", http.MethodGet, "/fee-rules?venueId=bad", "", http.StatusBadRequest},
+		{"delete fee invalid id", http.MethodDelete, "/fee-rules/bad", "", http.StatusBadRequest},
+		{"create tax invalid json", http.MethodPost, "/tax-rates", `{`, http.StatusBadRequest},
+		{"create tax invalid event id", http.MethodPost, "/tax-rates", `{"eventId":"bad","name":"IVA"}`, http.StatusBadRequest},
+		{"list taxes invalid event id", http.MethodGet, "/tax-rates?eventId=bad", "", http.StatusBadRequest},
+		{"delete tax invalid id", http.MethodDelete, "/tax-rates/bad", "", http.StatusBadRequest},
+		{"create fee as regular user", http.MethodPost, "/auth/fee-rules", `{"name":"Servicio"}`, http.StatusForbidden},
+		{"delete fee as regular user", http.MethodDelete, "/auth/fee-rules/11111111-1111-1111-1111-111111111111", "", http.StatusForbidden},
+		{"create tax as regular user", http.MethodPost, "/auth/tax-rates", `{"name":"IVA"}`, http.StatusForbidden},
+		{"delete tax as regular user", http.MethodDelete, "/auth/tax-rates/11111111-1111-1111-1111-111111111111", "", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRespondPricingError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"invalid fee", utils.ErrInvalidFeeRule, http.StatusBadRequest},
+		{"invalid tax", utils.ErrInvalidTaxRate, http.StatusBadRequest},
+		{"currency", utils.ErrCurrencyMismatch, http.StatusBadRequest},
+		{"event not found", utils.ErrEventNotFound, http.StatusNotFound},
+		{"venue not found", utils.ErrVenueNotFound, http.StatusNotFound},
+		{"fee not found", utils.ErrFeeRuleNotFound, http.StatusNotFound},
+		{"tax not found", utils.ErrTaxRateNotFound, http.StatusNotFound},
+		{"other", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondPricingError(c, tc.err, "fallback")
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PromoCodeHandler struct {
+	service *services.PromoService
+}
+
+// Constructor
+func NewPromoCodeHandler(service *services.PromoService) *PromoCodeHandler {
+	return &PromoCodeHandler{service: service}
+}
+
+// CreateCode godoc
+// @Summary Crear código promocional
+// @Description Crea un código de descuento para el checkout. type PERCENT usa percentOff (1 a 100) sobre cada entrada; FIXED descuenta amountOff del total de las entradas alcanzadas.
+// @Description Sin eventId vale para todos los eventos y sin sections para todas las secciones. maxUses y maxUsesPerUser en 0 no tienen tope
+// @Tags promo-codes
+// @Accept json
+// @Produce json
+// @Param code body models.PromoCode true "Código, descuento, restricciones y validez"
+// @Success 201 {object} models.PromoCode "Código creado"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 409 {object} map[string]string "El código ya existe"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /promo-codes [post]
+// @Security BearerAuth
+// POST /promo-codes
+func (h *PromoCodeHandler) CreateCode(c *gin.Context) {
+	if !requireInternal(c) {
+		return
+	}
+
+	var promo models.PromoCode
+	if err := c.ShouldBindJSON(&promo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	if promo.EventID != nil && *promo.EventID != "" {
+		if _, err := uuid.Parse(*promo.EventID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+			return
+		}
+	}
+
+	if err := h.service.CreateCode(&promo); err != nil {
+		respondPromoError(c, err, "Failed to create promo code")
+		return
+	}
+
+	c.JSON(http.StatusCreated, promo)
+}
+
+// GetCodes godoc
+// @Summary Listar códigos promocionales
+// @Description Lista los códigos con sus usos; con eventId, los del evento y los globales
+// @Tags promo-codes
+// @Produce json
+// @Param eventId query string false "ID del evento"
+// @Success 200 {array} models.PromoCode
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /promo-codes [get]
+// @Security BearerAuth
+// GET /promo-codes
+func (h *PromoCodeHandler) GetCodes(c *gin.Context) {
+	if !requireInternal(c) {
+		return
+	}
+
+	eventID := c.Query("eventId")
+	if eventID != "" {
+		if _, err := uuid.Parse(eventID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+			return
+		}
+	}
+
+	codes, err := h.service.GetCodes(eventID)
+	if err != nil {
+		respondPromoError(c, err, "Failed to fetch promo codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// GetCode godoc
+// @Summary Obtener código promocional
+// @Description Devuelve el código con sus usos
+// @Tags promo-codes
+// @Produce json
+// @Param id path string true "ID del código"
+// @Success 200 {object} models.PromoCode
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Código no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /promo-codes/{id} [get]
+// @Security BearerAuth
+// GET /promo-codes/:id
+func (h *PromoCodeHandler) GetCode(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	promo, err := h.service.GetCode(id)
+	if err != nil {
+		respondPromoError(c, err, "Failed to fetch promo code")
+		return
+	}
+
+	c.JSON(http.StatusOK, promo)
+}
+
+// DeleteCode godoc
+// @Summary Borrar código promocional
+// @Description Borra el código; las órdenes que ya lo usaron conservan su descuento
+// @Tags promo-codes
+// @Produce json
+// @Param id path string true "ID del código"
+// @Success 200 {object} map[string]string "Código borrado"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Solo llamadas internas (X-Internal-Secret)"
+// @Failure 404 {object} map[string]string "Código no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /promo-codes/{id} [delete]
+// @Security BearerAuth
+// DELETE /promo-codes/:id
+func (h *PromoCodeHandler) DeleteCode(c *gin.Context) {
+	if !requireInternal(c) {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	if err := h.service.DeleteCode(id); err != nil {
+		respondPromoError(c, err, "Failed to delete promo code")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code deleted successfully"})
+}
+
+func respondPromoError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, utils.ErrInvalidPromoCode), errors.Is(err, utils.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrEventNotFound), errors.Is(err, utils.ErrPromoCodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrPromoCodeTaken), errors.Is(err, utils.ErrPromoCodeExhausted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrPromoCodeInactive), errors.Is(err, utils.ErrPromoNotApplicable):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPromoCodeHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &PromoCodeHandler{}
+	r := gin.New()
+	internal := func(c *gin.Context) { c.Set("userID", "internal") }
+	user := func(c *gin.Context) { c.Set("userID", "u1") }
+	r.POST("/promo-codes", internal, h.CreateCode)
+	r.GET("/promo-codes", internal, h.GetCodes)
+	r.GET("/promo-codes/:id", h.GetCode)
+	r.DELETE("/promo-codes/:id", internal, h.DeleteCode)
+	r.POST("/auth/promo-codes", user, h.CreateCode)
+	r.GET("/auth/promo-codes", user, h.GetCodes)
+	r.DELETE("/auth/promo-codes/:id", user, h.DeleteCode)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"create invalid json", http.MethodPost, "/promo-codes", `{`, http.StatusBadRequest},
+		{"create invalid event id", http.MethodPost, "/promo-codes", `{"code":"FANS10","eventId":"bad"}`, http.StatusBadRequest},
+		{"list invalid event id", http.MethodGet, "/promo-codes?eventId=bad", "", http.StatusBadRequest},
+		{"get invalid id", http.MethodGet, "/promo-codes/bad", "", http.StatusBadRequest},
+		{"delete invalid id", http.MethodDelete, "/promo-codes/bad", "", http.StatusBadRequest},
+		{"create as regular user", http.MethodPost, "/auth/promo-codes", `{"code":"FANS10"}`, http.StatusForbidden},
+		{"list as regular user", http.MethodGet, "/auth/promo-codes", "", http.StatusForbidden},
+		{"delete as regular user", http.MethodDelete, "/auth/promo-codes/11111111-1111-1111-1111-111111111111", "", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRespondPromoError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"invalid", utils.ErrInvalidPromoCode, http.StatusBadRequest},
+		{"currency", utils.ErrCurrencyMismatch, http.StatusBadRequest},
+		{"not found", utils.ErrPromoCodeNotFound, http.StatusNotFound},
+		{"event not found", utils.ErrEventNotFound, http.StatusNotFound},
+		{"taken", utils.ErrPromoCodeTaken, http.StatusConflict},
+		{"exhausted", utils.ErrPromoCodeExhausted, http.StatusConflict},
+		{"inactive", utils.ErrPromoCodeInactive, http.StatusUnprocessableEntity},
+		{"not applicable", utils.ErrPromoNotApplicable, http.StatusUnprocessableEntity},
+		{"other", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondPromoError(c, tc.err, "fallback")
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkPurchaseLimit controla los límites de compra del evento antes de bloquear o cobrar
+// quantity entradas para userID. Sin servicio de límites no controla nada
+func checkPurchaseLimit(c *gin.Context, limits *services.PurchaseLimitService, eventID, userID string, quantity int) bool {
+	if limits == nil {
+		return true
+	}
+
+	err := limits.Check(eventID, buyerFrom(c, userID), quantity)
+	if err == nil {
+		return true
+	}
+	if !respondPurchaseLimitError(c, err) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check purchase limits"})
+	}
+	return false
+}
+
+// buyerFrom arma el comprador con el email del JWT, si lo trae
+func buyerFrom(c *gin.Context, userID string) models.Buyer {
+	return models.Buyer{UserID: userID, Email: c.GetString("userEmail")}
+}
+
+// respondPurchaseLimitError responde 422 con el límite superado y las entradas que le quedan
+func respondPurchaseLimitError(c *gin.Context, err error) bool {
+	var exceeded *utils.PurchaseLimitError
+	if !errors.As(err, &exceeded) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":     err.Error(),
+		"limit":     exceeded.Limit,
+		"max":       exceeded.Max,
+		"purchased": exceeded.Purchased,
+		"remaining": exceeded.Remaining(),
+	})
+	return true
+}
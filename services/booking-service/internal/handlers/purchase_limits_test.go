@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"booking-service/pkg/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckPurchaseLimit_WithoutService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if !checkPurchaseLimit(c, nil, "e1", "u1", 10) {
+		t.Fatalf("expected purchase allowed without limits service")
+	}
+}
+
+func TestRespondPurchaseLimitError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if !respondPurchaseLimitError(c, &utils.PurchaseLimitError{Limit: "email", Max: 4, Purchased: 3, Requested: 2}) {
+		t.Fatalf("expected purchase limit error handled")
+	}
+	var body struct {
+		Limit     string `json:"limit"`
+		Max       int    `json:"max"`
+		Purchased int    `json:"purchased"`
+		Remaining int    `json:"remaining"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusUnprocessableEntity || body.Limit != "email" || body.Max != 4 || body.Purchased != 3 || body.Remaining != 1 {
+		t.Fatalf("unexpected response %d %+v", w.Code, body)
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	if respondPurchaseLimitError(c, utils.ErrHoldLimitExceeded) {
+		t.Fatalf("expected other errors not handled")
+	}
+}
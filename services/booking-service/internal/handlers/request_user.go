@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Usuario que el middleware asigna a las llamadas con X-Internal-Secret
+const internalUserID = "internal"
+
+// requestUser devuelve el usuario del JWT. Si el cliente manda un userId (body o path) tiene que
+// ser el mismo: el usuario de la compra, la preventa y los límites nunca sale del cliente.
+// Las llamadas internas pueden actuar en nombre del userId que mandan
+func requestUser(c *gin.Context, claimed string) (string, bool) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return "", false
+	}
+	if claimed == "" || claimed == userID {
+		return userID, true
+	}
+	if userID == internalUserID {
+		return claimed, true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "userId does not match the authenticated user"})
+	return "", false
+}
+
+// requireInternal deja pasar solo a las llamadas internas (X-Internal-Secret); se usa en las
+// rutas de administración que exponen datos de todos los usuarios
+func requireInternal(c *gin.Context) bool {
+	if c.GetString("userID") == internalUserID {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Only internal callers can manage this resource"})
+	return false
+}
@@ -13,11 +13,13 @@ import (
 )
 
 type SeatHandler struct {
-	service *services.SeatService
+	service     *services.SeatService
+	waitingRoom *services.WaitingRoomService   // Opcional
+	limits      *services.PurchaseLimitService // Opcional
 }
 
-func NewSeatHandler(service *services.SeatService) *SeatHandler {
-	return &SeatHandler{service: service}
+func NewSeatHandler(service *services.SeatService, waitingRoom *services.WaitingRoomService, limits *services.PurchaseLimitService) *SeatHandler {
+	return &SeatHandler{service: service, waitingRoom: waitingRoom, limits: limits}
 }
 
 // CreateSeat Crea un nuevo asiento
@@ -28,8 +30,10 @@ func NewSeatHandler(service *services.SeatService) *SeatHandler {
 // @Produce json
 // @Param seat body models.Seat true "Datos del asiento"
 // @Success 201 {object} models.Seat "Asiento creado satisfactoriamente"
-// @Failure 400 {object} map[string]string "Formato JSON inválido"
+// @Failure 400 {object} map[string]string "Formato JSON inválido o moneda distinta a la del evento"
 // @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Evento o categoría no encontrados"
+// @Failure 409 {object} map[string]string "La categoría no tiene más capacidad"
 // @Failure 500 {object} map[string]string "Error al crear el asiento"
 // @Router /seats [post]
 // @Security BearerAuth
@@ -43,7 +47,16 @@ func (h *SeatHandler) CreateSeat(c *gin.Context) {
 	}
 
 	if err := h.service.CreateSeat(&seat); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create seat"})
+		switch {
+		case errors.Is(err, utils.ErrCurrencyMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrEventNotFound), errors.Is(err, utils.ErrTierNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrTierFull):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create seat"})
+		}
 		return
 	}
 
@@ -174,33 +187,243 @@ func (h *SeatHandler) UpdateSeat(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Seat updated successfully"})
 }
 
-// LockSeat Bloquear por 15 minutos. Requisitos: recibir uid y id del asiento
+// LockSeat Bloquea el asiento según la política de bloqueo. Requisitos: recibir uid y id del asiento
 // @Summary Bloquear asiento
-// @Description Bloquear asiento por 15 minutos
+// @Description Bloquear asiento temporalmente (TTL configurable por evento/sección)
 // @Tags Seats
 // @Accept json
 // @Produce json
 // @Param id path string true "ID del asiento"
-// @Param uid path string true "UID del usuario"
+// @Param uid path string true "UID del usuario (tiene que ser el del JWT)"
+// @Param X-Queue-Token header string false "Pase de la sala de espera, si el evento la tiene"
 // @Success 200 {object} map[string]string "Asiento bloqueado satisfactoriamente"
 // @Failure 400 {object} map[string]string "Formato UUID inválido"
 // @Failure 401 {object} map[string]string "No autorizado"
 // @Failure 404 {object} map[string]string "Asiento no encontrado"
+// @Failure 409 {object} map[string]interface{} "La selección deja butacas sueltas (incluye alternativa sugerida)"
+// @Failure 403 {object} map[string]interface{} "uid distinto del usuario autenticado, fuera de la ventana de venta (salesStart, presaleStart) o pase de la sala de espera inválido, vencido o todavía no admitido"
+// @Failure 422 {object} map[string]interface{} "Límite de asientos bloqueados o límite de compra del evento alcanzado (limit, max, remaining)"
+// @Failure 428 {object} map[string]string "El evento tiene sala de espera y falta el header X-Queue-Token"
 // @Failure 500 {object} map[string]string "Error al bloquear el asiento"
 // @Router /seats/lock/{id}/uid/{uid} [patch]
 // @Security BearerAuth
 // PATCH /seats/lock/:id/uid/:uid
 func (h *SeatHandler) LockSeat(c *gin.Context) {
 	id := c.Param("id")
-	uid := c.Param("uid")
+	uid, ok := requestUser(c, c.Param("uid"))
+	if !ok {
+		return
+	}
+
+	// El evento sale del asiento; si no existe el bloqueo falla abajo con el error de siempre
+	if h.waitingRoom != nil || h.limits != nil {
+		if seat, err := h.service.GetSeat(id); err == nil {
+			if !requireAdmission(c, h.waitingRoom, seat.EventID) || !checkPurchaseLimit(c, h.limits, seat.EventID, uid, 1) {
+				return
+			}
+		}
+	}
 
-	if err := h.service.LockSeat(id, uid); err != nil {
+	hold, err := h.service.LockSeat(id, uid)
+	if err != nil {
+		if respondSeatRuleError(c, err) || respondSalesWindowError(c, err) {
+			return
+		}
+		if errors.Is(err, utils.ErrHoldLimitExceeded) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":   "Seat locked successfully",
-		"expiresAt": time.Now().Add(15 * time.Minute).Format(time.RFC3339),
+		"holdId":    hold.ID,
+		"expiresAt": hold.ExpiresAt.Format(time.RFC3339),
 	})
 }
+
+// ExtendHold Extiende la expiración de un hold del usuario autenticado
+// @Summary Extender hold de asientos
+// @Description Extiende el bloqueo de todos los asientos de un hold (máximo de extensiones configurable)
+// @Tags Seats
+// @Produce json
+// @Param holdId path string true "ID del hold"
+// @Success 200 {object} models.SeatHold "Hold extendido satisfactoriamente"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "El hold no pertenece al usuario"
+// @Failure 404 {object} map[string]string "Hold no encontrado o vencido"
+// @Failure 409 {object} map[string]string "Máximo de extensiones alcanzado"
+// @Failure 500 {object} map[string]string "Error al extender el hold"
+// @Router /seats/hold/{holdId}/extend [post]
+// @Security BearerAuth
+// POST /seats/hold/:holdId/extend
+func (h *SeatHandler) ExtendHold(c *gin.Context) {
+	holdID, userID, ok := holdRequest(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.ExtendHold(holdID, userID)
+	if err != nil {
+		respondHoldError(c, err, "Failed to extend hold")
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// ReleaseHold Libera todos los asientos de un hold del usuario autenticado
+// @Summary Liberar hold de asientos
+// @Description Devuelve al inventario todos los asientos de un hold
+// @Tags Seats
+// @Produce json
+// @Param holdId path string true "ID del hold"
+// @Success 200 {object} map[string]string "Hold liberado satisfactoriamente"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "El hold no pertenece al usuario"
+// @Failure 404 {object} map[string]string "Hold no encontrado o vencido"
+// @Failure 500 {object} map[string]string "Error al liberar el hold"
+// @Router /seats/hold/{holdId} [delete]
+// @Security BearerAuth
+// DELETE /seats/hold/:holdId
+func (h *SeatHandler) ReleaseHold(c *gin.Context) {
+	holdID, userID, ok := holdRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ReleaseHold(holdID, userID); err != nil {
+		respondHoldError(c, err, "Failed to release hold")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hold released successfully"})
+}
+
+// BestAvailableReq pide N asientos de una sección; contiguous (por defecto true) exige que estén juntos
+type BestAvailableReq struct {
+	Section    string `json:"section" binding:"required"`
+	Quantity   int    `json:"quantity" binding:"required,min=1"`
+	Contiguous *bool  `json:"contiguous,omitempty"`
+}
+
+// BestAvailableRes es el hold creado con los asientos elegidos
+type BestAvailableRes struct {
+	HoldID    string        `json:"holdId"`
+	ExpiresAt time.Time     `json:"expiresAt"`
+	Seats     []models.Seat `json:"seats"`
+}
+
+// BestAvailable Elige y bloquea los mejores asientos disponibles de una sección
+// @Summary Mejores asientos disponibles
+// @Description Elige los mejores asientos de la sección (filas delanteras, bloques centrados, sin cruzar pasillos) y los bloquea para el usuario autenticado
+// @Tags Seats
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param body body BestAvailableReq true "Sección, cantidad y si deben estar juntos"
+// @Param X-Queue-Token header string false "Pase de la sala de espera, si el evento la tiene"
+// @Success 200 {object} BestAvailableRes "Asientos bloqueados"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 409 {object} map[string]string "No hay suficientes asientos (juntos) disponibles"
+// @Failure 403 {object} map[string]interface{} "Fuera de la ventana de venta (salesStart, presaleStart) o pase de la sala de espera inválido, vencido o todavía no admitido"
+// @Failure 422 {object} map[string]interface{} "Límite de asientos bloqueados o límite de compra del evento alcanzado (limit, max, remaining)"
+// @Failure 428 {object} map[string]string "El evento tiene sala de espera y falta el header X-Queue-Token"
+// @Failure 500 {object} map[string]string "Error al elegir asientos"
+// @Router /events/{id}/best-available [post]
+// @Security BearerAuth
+// POST /events/:id/best-available
+func (h *SeatHandler) BestAvailable(c *gin.Context) {
+	eventID := c.Param("id")
+	if _, err := uuid.Parse(eventID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var body BestAvailableReq
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	contiguous := body.Contiguous == nil || *body.Contiguous
+
+	if !checkPurchaseLimit(c, h.limits, eventID, userID, body.Quantity) {
+		return
+	}
+
+	hold, seats, err := h.service.FindBestAvailable(eventID, body.Section, body.Quantity, contiguous, userID)
+	if err != nil {
+		if respondSeatRuleError(c, err) || respondSalesWindowError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, utils.ErrInvalidSeatRequest):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrNoAdjacentSeats), errors.Is(err, utils.ErrSeatsUnavailable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrHoldLimitExceeded):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to select seats"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, BestAvailableRes{HoldID: hold.ID, ExpiresAt: hold.ExpiresAt, Seats: seats})
+}
+
+// respondSeatRuleError responde 409 con los asientos que quedarían sueltos y una alternativa válida
+func respondSeatRuleError(c *gin.Context, err error) bool {
+	var violation *utils.SeatRuleError
+	if !errors.As(err, &violation) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":            err.Error(),
+		"rule":             violation.Rule,
+		"strandedSeatIds":  violation.SeatIDs,
+		"suggestedSeatIds": violation.SuggestedSeatIDs,
+	})
+	return true
+}
+
+// holdRequest valida el holdId y obtiene el userID del JWT
+func holdRequest(c *gin.Context) (string, string, bool) {
+	holdID := c.Param("holdId")
+	if _, err := uuid.Parse(holdID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return "", "", false
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return "", "", false
+	}
+
+	return holdID, userID, true
+}
+
+func respondHoldError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, utils.ErrHoldNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrHoldForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrHoldExtensionLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
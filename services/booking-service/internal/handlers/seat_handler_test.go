@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"booking-service/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -33,3 +38,123 @@ func TestSeatHandler_GetSeatsByEventId_InvalidUUID(t *testing.T) {
 		t.Fatalf("expected 502, got %d", w.Code)
 	}
 }
+
+func TestSeatHandler_HoldEndpoints_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &SeatHandler{}
+	r := gin.New()
+	r.POST("/seats/hold/:holdId/extend", h.ExtendHold)
+	r.DELETE("/seats/hold/:holdId", h.ReleaseHold)
+
+	t.Run("invalid hold id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/seats/hold/bad", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("missing user", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/seats/hold/11111111-1111-1111-1111-111111111111/extend", nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", w.Code)
+		}
+	})
+}
+
+func TestSeatHandler_LockSeat_RequiresSameUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &SeatHandler{}
+	r := gin.New()
+	r.PATCH("/seats/lock/:id/uid/:uid", func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set("userID", user)
+		}
+		h.LockSeat(c)
+	})
+
+	cases := []struct {
+		name string
+		user string
+		want int
+	}{
+		{"not authenticated", "", http.StatusUnauthorized},
+		{"other user", "u2", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/seats/lock/11111111-1111-1111-1111-111111111111/uid/u1", nil)
+			req.Header.Set("X-Test-User", tc.user)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestSeatHandler_BestAvailable_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &SeatHandler{}
+	authed := gin.New()
+	authed.Use(func(c *gin.Context) { c.Set("userID", "u1") })
+	authed.POST("/events/:id/best-available", h.BestAvailable)
+	anonymous := gin.New()
+	anonymous.POST("/events/:id/best-available", h.BestAvailable)
+
+	const path = "/events/11111111-1111-1111-1111-111111111111/best-available"
+	cases := []struct {
+		name string
+		r    *gin.Engine
+		path string
+		body string
+		want int
+	}{
+		{"invalid event id", authed, "/events/bad/best-available", `{"section":"PLATEA","quantity":2}`, http.StatusBadRequest},
+		{"missing user", anonymous, path, `{"section":"PLATEA","quantity":2}`, http.StatusUnauthorized},
+		{"missing section", authed, path, `{"quantity":2}`, http.StatusBadRequest},
+		{"zero quantity", authed, path, `{"section":"PLATEA","quantity":0}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			tc.r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRespondSeatRuleError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	if respondSeatRuleError(c, errors.New("other")) {
+		t.Fatalf("expected other errors to be ignored")
+	}
+
+	err := fmt.Errorf("lock: %w", &utils.SeatRuleError{Rule: "orphan", SeatIDs: []string{"s1"}, SuggestedSeatIDs: []string{"s2", "s3"}})
+	if !respondSeatRuleError(c, err) {
+		t.Fatalf("expected seat rule error to be handled")
+	}
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", w.Code)
+	}
+	var body struct {
+		Rule      string   `json:"rule"`
+		Stranded  []string `json:"strandedSeatIds"`
+		Suggested []string `json:"suggestedSeatIds"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if body.Rule != "orphan" || len(body.Stranded) != 1 || len(body.Suggested) != 2 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
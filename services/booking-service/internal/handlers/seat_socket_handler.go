@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/internal/realtime"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+)
+
+const (
+	socketWriteWait  = 10 * time.Second
+	socketPongWait   = 60 * time.Second
+	socketPingPeriod = socketPongWait * 9 / 10
+	socketMaxMessage = 4096
+)
+
+// SeatSocketCommand es lo que manda el cliente por el socket
+type SeatSocketCommand struct {
+	Type      string   `json:"type"` // hover, hold, release, extend
+	RequestID string   `json:"requestId,omitempty"`
+	SeatIDs   []string `json:"seatIds,omitempty"`
+	HoldID    string   `json:"holdId,omitempty"`
+}
+
+// SeatSocketReply es la respuesta a un comando (held, released, extended o error)
+type SeatSocketReply struct {
+	Type      string           `json:"type"`
+	RequestID string           `json:"requestId,omitempty"`
+	Hold      *models.SeatHold `json:"hold,omitempty"`
+	Status    int              `json:"status,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Details   gin.H            `json:"details,omitempty"`
+}
+
+// SeatSocketWelcome es el primer mensaje: el ID de la conexión y lo que miran los demás
+type SeatSocketWelcome struct {
+	Type     string              `json:"type"`
+	ConnID   string              `json:"connId"`
+	Presence map[string][]string `json:"presence"`
+}
+
+// socketSession es lo que la conexión sabe del usuario al abrirse
+type socketSession struct {
+	// Pase de la sala de espera con el que se conectó, si el evento tiene sala
+	pass  *models.QueuePass
+	buyer models.Buyer
+}
+
+type SeatSocketHandler struct {
+	service  *services.SeatService
+	hub      *realtime.SeatHub
+	limits   *services.PurchaseLimitService // Opcional
+	upgrader websocket.Upgrader
+	// Comandos por segundo y ráfaga permitidos por conexión
+	commandRate  rate.Limit
+	commandBurst int
+}
+
+// Constructor
+func NewSeatSocketHandler(service *services.SeatService, hub *realtime.SeatHub, limits *services.PurchaseLimitService) *SeatSocketHandler {
+	return &SeatSocketHandler{
+		service: service,
+		hub:     hub,
+		limits:  limits,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// El CORS del servicio acepta cualquier origen y la sesión va en el JWT, no en cookies
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		commandRate:  10,
+		commandBurst: 20,
+	}
+}
+
+// Connect godoc
+// @Summary Sala WebSocket de selección de asientos
+// @Description Abre un WebSocket en la sala del evento. Llega un mensaje welcome con la presencia actual y después presence/leave (asientos que miran otros usuarios) y seats (cambios de estado).
+// @Description El cliente manda hover (seatIds que está mirando), hold (seatIds), release (holdId) y extend (holdId) con un requestId opcional; las respuestas son held, released, extended o error.
+// @Description Los navegadores pueden mandar el JWT en ?access_token= porque no permiten headers en el handshake.
+// @Tags Seats
+// @Param id path string true "ID del evento"
+// @Param access_token query string false "JWT (alternativa al header Authorization)"
+// @Param queueToken query string false "Pase de la sala de espera, si el evento la tiene"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]interface{} "Pase de la sala de espera inválido, vencido o todavía no admitido"
+// @Failure 428 {object} map[string]string "El evento tiene sala de espera y falta el pase"
+// @Failure 503 {object} map[string]string "Sala no disponible"
+// @Router /events/{id}/seats/ws [get]
+// @Security BearerAuth
+// GET /events/:id/seats/ws
+func (h *SeatSocketHandler) Connect(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if h.hub == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "seat room not available"})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// El upgrader ya respondió con el error
+		return
+	}
+	defer conn.Close()
+
+	client, presence, err := h.hub.Join(eventID, userID)
+	if err != nil {
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()), time.Now().Add(socketWriteWait))
+		return
+	}
+
+	client.Send(SeatSocketWelcome{Type: "welcome", ConnID: client.ID, Presence: presence})
+
+	writerDone := make(chan struct{})
+	go h.writeLoop(conn, client, writerDone)
+
+	h.readLoop(conn, client, socketSession{pass: queuePassFrom(c), buyer: buyerFrom(c, userID)})
+	h.hub.Leave(client)
+	<-writerDone
+}
+
+// readLoop procesa los comandos del cliente hasta que se corta la conexión
+func (h *SeatSocketHandler) readLoop(conn *websocket.Conn, client *realtime.Client, session socketSession) {
+	conn.SetReadLimit(socketMaxMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	})
+
+	limiter := rate.NewLimiter(h.commandRate, h.commandBurst)
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd SeatSocketCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			client.Send(SeatSocketReply{Type: "error", Status: http.StatusBadRequest, Error: "invalid message"})
+			continue
+		}
+
+		if !limiter.Allow() {
+			client.Send(SeatSocketReply{Type: "error", RequestID: cmd.RequestID, Status: http.StatusTooManyRequests, Error: "too many messages"})
+			continue
+		}
+
+		if reply := h.handleCommand(client, cmd, session); reply != nil {
+			client.Send(*reply)
+		}
+	}
+}
+
+// writeLoop es el único que escribe en la conexión: mensajes del hub, respuestas y pings
+func (h *SeatSocketHandler) writeLoop(conn *websocket.Conn, client *realtime.Client, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(socketPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, open := <-client.C():
+			_ = conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if !open {
+				code, reason := websocket.CloseNormalClosure, ""
+				if client.Lagged() {
+					// El cliente reconecta y recibe la presencia completa en el welcome
+					code, reason = websocket.CloseTryAgainLater, "client too slow"
+					log.Printf("⚠️ Seat socket: cliente lento desconectado (evento %s)", client.EventID)
+				}
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+				// Corta el ReadMessage pendiente si el cliente no responde al cierre
+				_ = conn.SetReadDeadline(time.Now().Add(socketWriteWait))
+				return
+			}
+			if err := conn.WriteJSON(msg); err != nil {
+				_ = conn.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteWait)); err != nil {
+				_ = conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// handleCommand aplica un comando con la misma lógica que los endpoints REST
+func (h *SeatSocketHandler) handleCommand(client *realtime.Client, cmd SeatSocketCommand, session socketSession) *SeatSocketReply {
+	switch cmd.Type {
+	case "hover":
+		h.hub.Hover(client, cmd.SeatIDs)
+		return nil
+	case "hold":
+		if len(cmd.SeatIDs) == 0 {
+			return &SeatSocketReply{Type: "error", RequestID: cmd.RequestID, Status: http.StatusBadRequest, Error: "seatIds is required"}
+		}
+		for _, id := range cmd.SeatIDs {
+			if _, err := uuid.Parse(id); err != nil {
+				return &SeatSocketReply{Type: "error", RequestID: cmd.RequestID, Status: http.StatusBadRequest, Error: "Invalid UUID format"}
+			}
+		}
+		// La conexión puede seguir abierta después de que vence la admisión de la sala de espera
+		if session.pass != nil && !session.pass.Admitted(time.Now()) {
+			return socketError(cmd.RequestID, utils.ErrQueueAdmissionExpired, "")
+		}
+		if h.limits != nil {
+			if err := h.limits.Check(client.EventID, session.buyer, len(cmd.SeatIDs)); err != nil {
+				return socketError(cmd.RequestID, err, "Failed to check purchase limits")
+			}
+		}
+		// La admisión y los límites se validaron para el evento de la sala
+		hold, err := h.service.LockEventSeats(client.EventID, cmd.SeatIDs, client.UserID)
+		if err != nil {
+			return socketError(cmd.RequestID, err, "Failed to lock seats")
+		}
+		return &SeatSocketReply{Type: "held", RequestID: cmd.RequestID, Hold: hold}
+	case "release", "extend":
+		if _, err := uuid.Parse(cmd.HoldID); err != nil {
+			return &SeatSocketReply{Type: "error", RequestID: cmd.RequestID, Status: http.StatusBadRequest, Error: "Invalid UUID format"}
+		}
+		if cmd.Type == "extend" {
+			hold, err := h.service.ExtendHold(cmd.HoldID, client.UserID)
+			if err != nil {
+				return socketError(cmd.RequestID, err, "Failed to extend hold")
+			}
+			return &SeatSocketReply{Type: "extended", RequestID: cmd.RequestID, Hold: hold}
+		}
+		if err := h.service.ReleaseHold(cmd.HoldID, client.UserID); err != nil {
+			return socketError(cmd.RequestID, err, "Failed to release hold")
+		}
+		return &SeatSocketReply{Type: "released", RequestID: cmd.RequestID, Hold: &models.SeatHold{ID: cmd.HoldID, UserID: client.UserID}}
+	default:
+		return &SeatSocketReply{Type: "error", RequestID: cmd.RequestID, Status: http.StatusBadRequest, Error: "unknown message type: " + cmd.Type}
+	}
+}
+
+// socketError traduce los errores del SeatService a los mismos status que la API REST
+func socketError(requestID string, err error, fallback string) *SeatSocketReply {
+	reply := &SeatSocketReply{Type: "error", RequestID: requestID, Error: err.Error()}
+
+	var violation *utils.SeatRuleError
+	var unavailable *utils.SeatsUnavailableError
+	var notStarted *utils.SalesNotStartedError
+	var exceeded *utils.PurchaseLimitError
+	switch {
+	case errors.As(err, &violation):
+		reply.Status = http.StatusConflict
+		reply.Details = gin.H{
+			"rule":             violation.Rule,
+			"strandedSeatIds":  violation.SeatIDs,
+			"suggestedSeatIds": violation.SuggestedSeatIDs,
+		}
+	case errors.As(err, &unavailable):
+		reply.Status = http.StatusConflict
+		reply.Details = gin.H{"seatIds": unavailable.SeatIDs}
+	case errors.As(err, &exceeded):
+		reply.Status = http.StatusUnprocessableEntity
+		reply.Details = gin.H{"limit": exceeded.Limit, "max": exceeded.Max, "purchased": exceeded.Purchased, "remaining": exceeded.Remaining()}
+	case errors.Is(err, utils.ErrHoldLimitExceeded):
+		reply.Status = http.StatusUnprocessableEntity
+	case errors.Is(err, utils.ErrInvalidSeatRequest):
+		reply.Status = http.StatusBadRequest
+	case errors.Is(err, utils.ErrHoldNotFound):
+		reply.Status = http.StatusNotFound
+	case errors.Is(err, utils.ErrHoldForbidden):
+		reply.Status = http.StatusForbidden
+	case errors.Is(err, utils.ErrHoldExtensionLimit):
+		reply.Status = http.StatusConflict
+	case errors.Is(err, utils.ErrQueueAdmissionExpired):
+		reply.Status = http.StatusForbidden
+	case errors.As(err, &notStarted):
+		reply.Status = http.StatusForbidden
+		reply.Details = gin.H{"salesStart": notStarted.SalesStart, "presaleStart": notStarted.PresaleStart}
+	case errors.Is(err, utils.ErrSalesEnded):
+		reply.Status = http.StatusForbidden
+	default:
+		reply.Status = http.StatusInternalServerError
+		reply.Error = fallback
+	}
+	return reply
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestSeatSocketHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewSeatSocketHandler(nil, realtime.NewSeatHub(realtime.NewMemoryBroadcaster(), time.Minute, 10), nil)
+	r := gin.New()
+	r.GET("/events/:id/seats/ws", h.Connect)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/bad/seats/ws", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/11111111-1111-1111-1111-111111111111/seats/ws", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestSeatSocketHandler_PresenceAndCommandErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const eventID = "11111111-1111-1111-1111-111111111111"
+	hub := realtime.NewSeatHub(realtime.NewMemoryBroadcaster(), time.Minute, 10)
+	h := NewSeatSocketHandler(nil, hub, nil)
+	r := gin.New()
+	r.GET("/events/:id/seats/ws", func(c *gin.Context) { c.Set("userID", c.Query("user")) }, h.Connect)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	dial := func(user string) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/events/" + eventID + "/seats/ws?user=" + user
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial failed: %v", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		return conn
+	}
+	read := func(conn *websocket.Conn) map[string]any {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		return msg
+	}
+
+	alice := dial("u1")
+	defer alice.Close()
+	welcome := read(alice)
+	if welcome["type"] != "welcome" || welcome["connId"] == "" {
+		t.Fatalf("unexpected welcome: %+v", welcome)
+	}
+
+	bob := dial("u2")
+	defer bob.Close()
+	read(bob)
+
+	_ = alice.WriteJSON(SeatSocketCommand{Type: "hover", SeatIDs: []string{"s1"}})
+	if msg := read(bob); msg["type"] != realtime.RoomPresence || msg["connId"] != welcome["connId"] {
+		t.Fatalf("unexpected presence: %+v", msg)
+	}
+
+	cases := []struct {
+		name    string
+		payload string
+		status  float64
+	}{
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"unknown type", `{"type":"dance","requestId":"r1"}`, http.StatusBadRequest},
+		{"hold without seats", `{"type":"hold","requestId":"r2"}`, http.StatusBadRequest},
+		{"hold invalid seat", `{"type":"hold","requestId":"r3","seatIds":["bad"]}`, http.StatusBadRequest},
+		{"release invalid hold", `{"type":"release","requestId":"r4","holdId":"bad"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_ = alice.WriteMessage(websocket.TextMessage, []byte(tc.payload))
+			if msg := read(alice); msg["type"] != "error" || msg["status"] != tc.status {
+				t.Fatalf("unexpected reply: %+v", msg)
+			}
+		})
+	}
+
+	_ = alice.Close()
+	if msg := read(bob); msg["type"] != realtime.RoomLeave || msg["connId"] != welcome["connId"] {
+		t.Fatalf("unexpected leave: %+v", msg)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tiempo que espera el navegador antes de reconectar un EventSource cortado
+const seatStreamRetry = 3 * time.Second
+
+type SeatStreamHandler struct {
+	bus       *realtime.SeatBus
+	heartbeat time.Duration
+}
+
+// Constructor
+func NewSeatStreamHandler(bus *realtime.SeatBus, heartbeat time.Duration) *SeatStreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &SeatStreamHandler{bus: bus, heartbeat: heartbeat}
+}
+
+// StreamSeats godoc
+// @Summary Stream de cambios de asientos (SSE)
+// @Description Mantiene abierta una conexión Server-Sent Events con los cambios de estado de los asientos del evento (event: seat).
+// @Description Con el header Last-Event-ID (o ?lastEventId=) se reenvían los cambios perdidos; si no se puede retomar llega event: reset y hay que volver a pedir el mapa.
+// @Tags Seats
+// @Produce text/event-stream
+// @Param id path string true "ID del evento"
+// @Param Last-Event-ID header string false "Último ID recibido"
+// @Success 200 {string} string "Stream de eventos"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 503 {object} map[string]string "Stream no disponible"
+// @Router /events/{id}/seats/stream [get]
+// GET /events/:id/seats/stream
+func (h *SeatStreamHandler) StreamSeats(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	if h.bus == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "seat stream not available"})
+		return
+	}
+	sub, backlog, resumed, err := h.bus.Subscribe(eventID, lastEventID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "seat stream not available"})
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Sin buffering en nginx/ALB
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", seatStreamRetry.Milliseconds())
+	if !resumed {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, change := range backlog {
+		if err := h.writeChange(w, change); err != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case change, open := <-sub.C():
+			if !open {
+				// Cliente lento o servidor apagándose: el navegador reconecta y retoma desde su último ID
+				if sub.Lagged() {
+					log.Printf("⚠️ Seat stream: cliente lento desconectado (evento %s)", eventID)
+				}
+				return
+			}
+			if err := h.writeChange(w, change); err != nil {
+				return
+			}
+			w.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
+}
+
+func (h *SeatStreamHandler) writeChange(w io.Writer, change models.SeatChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: seat\ndata: %s\n\n", h.bus.EventIDFor(change), data)
+	return err
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSeatStreamHandler_InvalidUUID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewSeatStreamHandler(realtime.NewSeatBus(0, 0), time.Second)
+	r := gin.New()
+	r.GET("/events/:id/seats/stream", h.StreamSeats)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/bad/seats/stream", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestSeatStreamHandler_StreamsChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const eventID = "11111111-1111-1111-1111-111111111111"
+	bus := realtime.NewSeatBus(0, 0)
+	h := NewSeatStreamHandler(bus, time.Hour)
+	r := gin.New()
+	r.GET("/events/:id/seats/stream", h.StreamSeats)
+
+	req := httptest.NewRequest(http.MethodGet, "/events/"+eventID+"/seats/stream", nil)
+	req.Header.Set("Last-Event-ID", "otro-1")
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(w, req)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for bus.Subscribers(eventID) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("handler never subscribed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	bus.PublishSeatChanges([]models.SeatChange{{EventID: eventID, SeatID: "s1", Status: models.StatusSold}})
+	// Al cerrar el bus el handler primero entrega lo pendiente y después termina
+	bus.Close()
+	<-done
+
+	body := w.Body.String()
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	// El ID de otro proceso no se puede retomar: el cliente tiene que pedir el mapa de nuevo
+	if !strings.Contains(body, "event: reset") {
+		t.Fatalf("expected reset event, got %q", body)
+	}
+	if !strings.Contains(body, "id: "+bus.EventIDFor(models.SeatChange{Seq: 1})+"\nevent: seat\n") || !strings.Contains(body, `"seatId":"s1"`) {
+		t.Fatalf("expected seat change, got %q", body)
+	}
+}
@@ -2,120 +2,152 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"booking-service/internal/messaging"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v76/webhook"
 )
 
+// Tamaño máximo aceptado para el body del webhook (Stripe envía payloads chicos)
+const maxWebhookBodyBytes = 64 << 10
+
 type SQSHandler struct {
-	sqs *messaging.SQSClient
+	sqs       messaging.Sender
+	provider  services.PaymentProvider
+	tolerance time.Duration
+	replays   *replayGuard
 }
 
-func NewSQSHandler(sqs *messaging.SQSClient) *SQSHandler {
-	return &SQSHandler{sqs: sqs}
+// NewSQSHandler recibe la pasarela que verifica y traduce los webhooks; tolerance es la
+// ventana durante la que se recuerdan las firmas para rechazar replays
+func NewSQSHandler(sqs messaging.Sender, provider services.PaymentProvider, tolerance time.Duration) *SQSHandler {
+	if tolerance <= 0 {
+		tolerance = webhook.DefaultTolerance
+	}
+	return &SQSHandler{
+		sqs:       sqs,
+		provider:  provider,
+		tolerance: tolerance,
+		replays:   newReplayGuard(),
+	}
 }
 
-// Estructuras para parsear el JSON que envía Stripe
-type StripeWebhookReq struct {
-	ID     string     `json:"id"`
-	Type   string     `json:"type"`
-	Object string     `json:"object"`
-	Data   StripeData `json:"data"`
+// replayGuard recuerda las firmas ya aceptadas mientras siguen dentro de la tolerancia.
+// Stripe firma cada reintento con un timestamp nuevo, así que una firma repetida es un replay.
+// Es un caché en memoria de cada proceso: con varias réplicas, o después de un reinicio, el
+// mismo webhook puede volver a encolarse. Solo corta replays baratos antes de SQS; lo que
+// garantiza que un evento se aplique una única vez es la tabla de eventos de pago procesados
+// (processed_payment_events) que consulta el consumer
+type replayGuard struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
 }
 
-type StripeData struct {
-	Object StripeObject `json:"object"`
+func newReplayGuard() *replayGuard {
+	return &replayGuard{seen: make(map[string]time.Time)}
 }
 
-type StripeObject struct {
-	ID            string         `json:"id"`             // El PaymentIntent ID o Session ID
-	PaymentStatus string         `json:"payment_status"` // "paid", "unpaid"
-	Status        string         `json:"status"`         // payment_intent.* usa "succeeded", "requires_payment_method", etc.
-	Metadata      StripeMetadata `json:"metadata"`
-	AmountTotal   float64        `json:"amount_total"`
-	Amount        float64        `json:"amount"`
-	PaymentIntent string         `json:"payment_intent"`
-}
+// remember devuelve false si la firma ya fue vista y no venció
+func (g *replayGuard) remember(signature string, now time.Time, ttl time.Duration) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-type StripeMetadata struct {
-	UserID  string `json:"user_id"`
-	SeatIDs string `json:"seat_ids"`
-	EventID string `json:"event_id"`
-	OrderID string `json:"order_id"` // <--- ESTO ES LO IMPORTANTE
-}
+	for sig, expiresAt := range g.seen {
+		if !now.Before(expiresAt) {
+			delete(g.seen, sig)
+		}
+	}
 
-// Mensaje interno que enviamos a SQS y lee la Lambda
-type BookingMessage struct {
-	UserID            string  `json:"userId"`
-	Amount            float64 `json:"amount"`
-	Status            string  `json:"status"`
-	SeatIDs           string  `json:"seatIds"`
-	EventID           string  `json:"eventId"`
-	PaymentProviderID string  `json:"paymentProviderId"`
-	OrderID           string  `json:"orderId"` // <--- Para que la Lambda sepa qué actualizar
-	// Campo para evitar la duplicacion
-	Nonce string `json:"nonce"`
-	// Observabilidad / idempotencia
-	StripeEventID   string `json:"stripeEventId"`
-	StripeEventType string `json:"stripeEventType"`
+	if _, ok := g.seen[signature]; ok {
+		return false
+	}
+	g.seen[signature] = now.Add(ttl)
+	return true
 }
 
+// Estructuras del webhook de Stripe (documentación Swagger)
+type StripeWebhookReq = services.StripeWebhookReq
+
+// BookingMessage es el mensaje interno que enviamos a SQS
+type BookingMessage = messaging.BookingMessage
+
 // Send Envía un mensaje a SQS con los datos del webhook de Stripe
 // @Summary Enviar mensaje a SQS
-// @Description Envía un mensaje a SQS con los datos del webhook de Stripe
+// @Description Verifica la firma Stripe-Signature del webhook y envía un mensaje a SQS con sus datos
 // @Tags SQS
 // @Accept json
 // @Produce json
+// @Param Stripe-Signature header string true "Firma del webhook de Stripe"
 // @Param body body StripeWebhookReq true "Datos del webhook de Stripe"
 // @Success 200 {object} map[string]string "Mensaje enviado exitosamente"
-// @Failure 400 {object} map[string]string "Solicitud inválida"
+// @Failure 400 {object} map[string]string "Solicitud inválida o firma inválida"
+// @Failure 409 {object} map[string]string "Webhook ya recibido"
 // @Failure 500 {object} map[string]string "Error interno del servidor"
 // @Router /sqs/messaging [post]
 func (h *SQSHandler) Send(c *gin.Context) {
-	var stripeReq StripeWebhookReq
-	if err := c.ShouldBindJSON(&stripeReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook json: " + err.Error()})
+	if h.provider == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "payment provider not configured"})
 		return
 	}
 
-	stripeEventID := strings.TrimSpace(stripeReq.ID)
-	if stripeEventID == "" {
-		stripeEventID = uuid.NewString()
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodyBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read webhook body"})
+		return
 	}
-
-	// Construimos el mensaje interno
-	status := strings.TrimSpace(stripeReq.Data.Object.PaymentStatus)
-	if status == "" {
-		status = strings.TrimSpace(stripeReq.Data.Object.Status)
+	if len(payload) > maxWebhookBodyBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "webhook body too large"})
+		return
 	}
 
-	amount := stripeReq.Data.Object.AmountTotal
-	if amount == 0 {
-		amount = stripeReq.Data.Object.Amount
+	msg, err := h.provider.ParseWebhook(c.Request.Context(), services.WebhookRequest{
+		Payload: payload,
+		Headers: c.Request.Header,
+		Query:   c.Request.URL.Query(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrWebhookIgnored):
+			c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": err.Error()})
+		case errors.Is(err, utils.ErrWebhookNotConfigured):
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrInvalidWebhookPayload):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook json: " + err.Error()})
+		case errors.Is(err, utils.ErrInvalidWebhookSignature), errors.Is(err, utils.ErrWebhookSignatureExpired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			// La pasarela no respondió (p. ej. al consultar el pago): que reintente la notificación
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		}
+		return
 	}
 
-	internalMsg := BookingMessage{
-		UserID:            stripeReq.Data.Object.Metadata.UserID,
-		Amount:            amount, // Stripe manda centavos, ajusta si guardas float
-		SeatIDs:           stripeReq.Data.Object.Metadata.SeatIDs,
-		EventID:           stripeReq.Data.Object.Metadata.EventID,
-		Status:            status,
-		PaymentProviderID: strings.TrimSpace(stripeReq.Data.Object.PaymentIntent),
-		OrderID:           stripeReq.Data.Object.Metadata.OrderID, // <--- Pasamos el ID de la orden pendiente
-		Nonce:             stripeEventID,
-		StripeEventID:     stripeEventID,
-		StripeEventType:   strings.TrimSpace(stripeReq.Type),
+	if signature := c.GetHeader("Stripe-Signature"); signature != "" && !h.replays.remember(signature, time.Now(), h.tolerance) {
+		c.JSON(http.StatusConflict, gin.H{"error": "webhook already received"})
+		return
 	}
 
-	if internalMsg.PaymentProviderID == "" {
-		internalMsg.PaymentProviderID = strings.TrimSpace(stripeReq.Data.Object.ID)
+	internalMsg := *msg
+	stripeEventID := internalMsg.StripeEventID
+	if stripeEventID == "" {
+		stripeEventID = uuid.NewString()
+		internalMsg.StripeEventID = stripeEventID
 	}
 
-	if strings.TrimSpace(internalMsg.UserID) == "" || strings.TrimSpace(internalMsg.SeatIDs) == "" || strings.TrimSpace(internalMsg.OrderID) == "" {
+	// En una sesión vencida o un pago fallido los asientos se toman de la orden: alcanza con su ID
+	missingPaymentData := !internalMsg.IsFailure() &&
+		(strings.TrimSpace(internalMsg.UserID) == "" || strings.TrimSpace(internalMsg.SeatIDs) == "")
+	if strings.TrimSpace(internalMsg.OrderID) == "" || missingPaymentData {
 		c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": "missing required metadata"})
 		return
 	}
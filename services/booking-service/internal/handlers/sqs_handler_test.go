@@ -2,37 +2,318 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"booking-service/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stripe/stripe-go/v76/webhook"
 )
 
-func TestSQSHandler_Send_ValidationPaths(t *testing.T) {
+const testWebhookSecret = "whsec_test_secret"
+
+type fakeSender struct {
+	bodies []string
+	dedup  []string
+}
+
+func (f *fakeSender) Send(_ context.Context, body, _ string, dedupID string) (string, error) {
+	f.bodies = append(f.bodies, body)
+	f.dedup = append(f.dedup, dedupID)
+	return "msg-1", nil
+}
+
+func signedWebhookRequest(t *testing.T, payload string, ts time.Time, secret string) *http.Request {
+	t.Helper()
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload:   []byte(payload),
+		Secret:    secret,
+		Timestamp: ts,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/sqs", bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Stripe-Signature", signed.Header)
+	return req
+}
+
+func newTestStripeProvider(secret string) *services.StripeProvider {
+	return services.NewStripeProvider(services.StripeConfig{WebhookSecret: secret, WebhookTolerance: 5 * time.Minute})
+}
+
+func newTestSQSRouter(sender *fakeSender) *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	h := &SQSHandler{}
+	h := NewSQSHandler(sender, newTestStripeProvider(testWebhookSecret), 5*time.Minute)
 	r := gin.New()
 	r.POST("/sqs", h.Send)
+	return r
+}
+
+const paidSessionPayload = `{"id":"evt_1","type":"checkout.session.completed","data":{"object":{"id":"cs_1","payment_status":"paid","amount_total":5000,"payment_intent":"pi_1","metadata":{"user_id":"u1","seat_ids":"s1,s2","event_id":"e1","order_id":"o1"}}}}`
 
+func TestSQSHandler_Send_ValidationPaths(t *testing.T) {
 	t.Run("bad json", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodPost, "/sqs", bytes.NewBufferString("{"))
-		req.Header.Set("Content-Type", "application/json")
+		r := newTestSQSRouter(&fakeSender{})
 		w := httptest.NewRecorder()
-		r.ServeHTTP(w, req)
+		r.ServeHTTP(w, signedWebhookRequest(t, "{", time.Now(), testWebhookSecret))
 		if w.Code != http.StatusBadRequest {
 			t.Fatalf("expected 400, got %d", w.Code)
 		}
 	})
 
 	t.Run("missing metadata ignored", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
 		payload := `{"id":"evt_1","type":"checkout.session.completed","data":{"object":{"payment_status":"paid","metadata":{"user_id":"","seat_ids":"","order_id":""}}}}`
-		req := httptest.NewRequest(http.MethodPost, "/sqs", bytes.NewBufferString(payload))
-		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
-		r.ServeHTTP(w, req)
+		r.ServeHTTP(w, signedWebhookRequest(t, payload, time.Now(), testWebhookSecret))
 		if w.Code != http.StatusOK {
 			t.Fatalf("expected 200, got %d", w.Code)
 		}
+		if len(sender.bodies) != 0 {
+			t.Fatalf("expected nothing queued, got %d messages", len(sender.bodies))
+		}
+	})
+
+	t.Run("secret not configured", func(t *testing.T) {
+		h := NewSQSHandler(&fakeSender{}, newTestStripeProvider(""), 0)
+		r := gin.New()
+		r.POST("/sqs", h.Send)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, paidSessionPayload, time.Now(), testWebhookSecret))
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestSQSHandler_Send_Signature(t *testing.T) {
+	t.Run("valid signature is queued", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, paidSessionPayload, time.Now(), testWebhookSecret))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if len(sender.bodies) != 1 {
+			t.Fatalf("expected 1 queued message, got %d", len(sender.bodies))
+		}
+
+		var msg BookingMessage
+		if err := json.Unmarshal([]byte(sender.bodies[0]), &msg); err != nil {
+			t.Fatalf("invalid queued message: %v", err)
+		}
+		if msg.OrderID != "o1" || msg.StripeEventID != "evt_1" || msg.PaymentProviderID != "pi_1" || msg.Status != "paid" || msg.Provider != "STRIPE" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+		if sender.dedup[0] != "evt_1" {
+			t.Fatalf("expected dedup id evt_1, got %s", sender.dedup[0])
+		}
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
+		req := httptest.NewRequest(http.MethodPost, "/sqs", bytes.NewBufferString(paidSessionPayload))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest || len(sender.bodies) != 0 {
+			t.Fatalf("expected 400 without queueing, got %d (%d queued)", w.Code, len(sender.bodies))
+		}
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
+		req := signedWebhookRequest(t, paidSessionPayload, time.Now(), testWebhookSecret)
+		tampered := bytes.Replace([]byte(paidSessionPayload), []byte(`"order_id":"o1"`), []byte(`"order_id":"o2"`), 1)
+		req.Body = io.NopCloser(bytes.NewReader(tampered))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest || len(sender.bodies) != 0 {
+			t.Fatalf("expected 400 without queueing, got %d (%d queued)", w.Code, len(sender.bodies))
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, paidSessionPayload, time.Now(), "whsec_other"))
+		if w.Code != http.StatusBadRequest || len(sender.bodies) != 0 {
+			t.Fatalf("expected 400 without queueing, got %d (%d queued)", w.Code, len(sender.bodies))
+		}
+	})
+
+	t.Run("stale signature", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, paidSessionPayload, time.Now().Add(-10*time.Minute), testWebhookSecret))
+		if w.Code != http.StatusBadRequest || len(sender.bodies) != 0 {
+			t.Fatalf("expected 400 without queueing, got %d (%d queued)", w.Code, len(sender.bodies))
+		}
+	})
+
+	t.Run("replayed delivery", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
+		ts := time.Now()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, paidSessionPayload, ts, testWebhookSecret))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected first delivery 200, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, paidSessionPayload, ts, testWebhookSecret))
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected replay 409, got %d", w.Code)
+		}
+		if len(sender.bodies) != 1 {
+			t.Fatalf("expected replay not to be queued, got %d messages", len(sender.bodies))
+		}
+
+		// Un reintento legítimo de Stripe viene firmado con otro timestamp
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, paidSessionPayload, ts.Add(time.Second), testWebhookSecret))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected re-signed retry 200, got %d", w.Code)
+		}
+	})
+}
+
+func TestSQSHandler_Send_FailureEvents(t *testing.T) {
+	payloads := map[string]string{
+		"checkout.session.expired": `{"id":"evt_exp","type":"checkout.session.expired","data":{"object":{"id":"cs_1","status":"expired","payment_status":"unpaid","metadata":{"order_id":"o1"}}}}`,
+		"payment_intent.canceled":  `{"id":"evt_cancel","type":"payment_intent.canceled","data":{"object":{"id":"pi_1","status":"canceled","metadata":{"order_id":"o1"}}}}`,
+	}
+	for eventType, payload := range payloads {
+		t.Run(eventType, func(t *testing.T) {
+			sender := &fakeSender{}
+			r := newTestSQSRouter(sender)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, signedWebhookRequest(t, payload, time.Now(), testWebhookSecret))
+			if w.Code != http.StatusOK || len(sender.bodies) != 1 {
+				t.Fatalf("expected failure event queued, got %d (%d queued)", w.Code, len(sender.bodies))
+			}
+
+			var msg BookingMessage
+			if err := json.Unmarshal([]byte(sender.bodies[0]), &msg); err != nil {
+				t.Fatalf("invalid queued message: %v", err)
+			}
+			if !msg.IsFailure() || msg.IsPaid() || msg.OrderID != "o1" || msg.StripeEventType != eventType {
+				t.Fatalf("unexpected message: %+v", msg)
+			}
+		})
+	}
+
+	// Un intento rechazado no termina la orden: el comprador puede reintentar en la misma sesión
+	t.Run("failed payment attempt is not a failure", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
+		payload := `{"id":"evt_fail","type":"payment_intent.payment_failed","data":{"object":{"id":"pi_1","status":"requires_payment_method","metadata":{"user_id":"u1","order_id":"o1"}}}}`
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, payload, time.Now(), testWebhookSecret))
+		if w.Code != http.StatusOK || len(sender.bodies) != 0 {
+			t.Fatalf("expected ignored event, got %d (%d queued)", w.Code, len(sender.bodies))
+		}
+	})
+
+	t.Run("missing order is ignored", func(t *testing.T) {
+		sender := &fakeSender{}
+		r := newTestSQSRouter(sender)
+		payload := `{"id":"evt_exp","type":"checkout.session.expired","data":{"object":{"id":"cs_1","status":"expired","metadata":{}}}}`
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, signedWebhookRequest(t, payload, time.Now(), testWebhookSecret))
+		if w.Code != http.StatusOK || len(sender.bodies) != 0 {
+			t.Fatalf("expected ignored event, got %d (%d queued)", w.Code, len(sender.bodies))
+		}
 	})
 }
+
+func TestSQSHandler_Send_UsesProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sender := &fakeSender{}
+	h := NewSQSHandler(sender, services.NewFakePaymentProvider(), time.Minute)
+	r := gin.New()
+	r.POST("/sqs", h.Send)
+
+	payload := `{"userId":"u1","seatIds":"s1","orderId":"o1","status":"paid","stripeEventId":"evt_fake"}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/sqs", bytes.NewBufferString(payload)))
+	if w.Code != http.StatusOK || len(sender.bodies) != 1 {
+		t.Fatalf("expected message queued, got %d (%d queued)", w.Code, len(sender.bodies))
+	}
+
+	var msg BookingMessage
+	if err := json.Unmarshal([]byte(sender.bodies[0]), &msg); err != nil {
+		t.Fatalf("invalid queued message: %v", err)
+	}
+	if msg.Provider != services.FakeProviderName || msg.OrderID != "o1" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestSQSHandler_Send_MercadoPago(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/payments/123" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id":123,"status":"approved","transaction_amount":50,"currency_id":"ARS","external_reference":"o1","metadata":{"user_id":"u1","seat_ids":"s1"}}`))
+	}))
+	defer api.Close()
+
+	sender := &fakeSender{}
+	h := NewSQSHandler(sender, services.NewMercadoPagoProvider(services.MercadoPagoConfig{BaseURL: api.URL}), time.Minute)
+	r := gin.New()
+	r.POST("/sqs/mercadopago", h.Send)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/sqs/mercadopago?topic=merchant_order&id=9", nil))
+	if w.Code != http.StatusOK || len(sender.bodies) != 0 {
+		t.Fatalf("expected ignored topic, got %d (%d queued)", w.Code, len(sender.bodies))
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/sqs/mercadopago?topic=payment&id=404", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 when the payment cannot be fetched, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/sqs/mercadopago?topic=payment&id=123", nil))
+	if w.Code != http.StatusOK || len(sender.bodies) != 1 || sender.dedup[0] != "mp:123:approved" {
+		t.Fatalf("expected payment queued, got %d (%v)", w.Code, sender.dedup)
+	}
+	var msg BookingMessage
+	if err := json.Unmarshal([]byte(sender.bodies[0]), &msg); err != nil {
+		t.Fatalf("invalid queued message: %v", err)
+	}
+	if msg.Provider != services.MercadoPagoProviderName || msg.OrderID != "o1" || !msg.IsPaid() {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestReplayGuard_ForgetsExpiredSignatures(t *testing.T) {
+	g := newReplayGuard()
+	now := time.Now()
+	if !g.remember("sig", now, time.Minute) {
+		t.Fatalf("expected first signature to be accepted")
+	}
+	if g.remember("sig", now.Add(30*time.Second), time.Minute) {
+		t.Fatalf("expected signature to be rejected inside the window")
+	}
+	if !g.remember("sig", now.Add(2*time.Minute), time.Minute) {
+		t.Fatalf("expected signature to be forgotten after the window")
+	}
+}
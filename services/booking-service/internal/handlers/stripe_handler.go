@@ -1,17 +1,19 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
 	"booking-service/internal/models"
 	"booking-service/internal/services"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/stripe/stripe-go/v76"
-	checkoutsession "github.com/stripe/stripe-go/v76/checkout/session"
 )
 
 type SeatStruc struct {
@@ -22,12 +24,19 @@ type TicketItem struct {
 	Name    string    `json:"name"`
 	Amount  int64     `json:"amount"`
 	SeatIds SeatStruc `json:"seatIds"`
+	// Entradas de admisión general: sector y cantidad en vez de asiento
+	GASectionId string `json:"gaSectionId,omitempty"`
+	Quantity    int    `json:"quantity,omitempty"`
 }
 
 type CreateCartCheckoutReq struct {
 	UserId   string       `json:"userId"`
 	Currency string       `json:"currency"`
 	Items    []TicketItem `json:"items"`
+	// Pasarela elegida (STRIPE, MERCADOPAGO); vacío usa la de por defecto
+	Provider string `json:"provider"`
+	// Código promocional (opcional)
+	PromoCode string `json:"promoCode"`
 }
 
 type ResponseCartCheckoutReq struct {
@@ -35,32 +44,44 @@ type ResponseCartCheckoutReq struct {
 	UserId         string       `json:"userId"`
 	Currency       string       `json:"currency"`
 	Items          []TicketItem `json:"items"`
+	HoldID         string       `json:"holdId"`
+	ExpiresAt      time.Time    `json:"expiresAt"`
+	// Total a pagar y descuentos del código promocional por línea
+	Total       money.Money              `json:"total"`
+	PromoCode   string                   `json:"promoCode,omitempty"`
+	Adjustments []models.OrderAdjustment `json:"adjustments,omitempty"`
+	// Detalle de la orden: entradas, descuentos, cargos e impuestos
+	Lines []models.OrderLine `json:"lines,omitempty"`
 }
 
-// CreateCartCheckoutSession Crea una sesión de pago en Stripe para un carrito de tickets
+// CreateCartCheckoutSession Crea una sesión de pago en la pasarela para un carrito de tickets
 // @Summary Crear sesión de pago Stripe para carrito
-// @Description Crea una sesión de pago en Stripe para un carrito de tickets
+// @Description Crea una sesión de pago en la pasarela elegida (Stripe por defecto, o MercadoPago) para un carrito de tickets.
+// @Description Cada item es un asiento (seatIds.id) o entradas de admisión general (gaSectionId + quantity).
+// @Description Con promoCode el descuento se guarda por línea en la orden (adjustments) y la pasarela cobra el total ya descontado.
+// @Description Los cargos por servicio y del recinto y los impuestos del evento se suman como líneas de la orden (lines) y como items de la pasarela
 // @Tags Stripe
 // @Accept json
 // @Produce json
 // @Param body body CreateCartCheckoutReq true "Datos del carrito"
+// @Param X-Queue-Token header string false "Pase de la sala de espera, si el evento la tiene"
 // @Success 200 {object} map[string]string "Sesión de pago creada exitosamente"
 // @Failure 400 {object} map[string]string "Solicitud inválida"
 // @Failure 401 {object} map[string]string "No autorizado"
-// @Failure 404 {object} map[string]string "Asiento no encontrado"
-// @Failure 409 {object} map[string]string "Asiento no disponible"
+// @Failure 404 {object} map[string]string "Asiento, sector o código promocional no encontrado"
+// @Failure 409 {object} map[string]interface{} "Asientos no disponibles (seatIds), sector sin cupo (sectionIds) o código promocional sin usos"
+// @Failure 403 {object} map[string]interface{} "userId distinto del usuario autenticado, fuera de la ventana de venta (salesStart, presaleStart) o pase de la sala de espera inválido, vencido o todavía no admitido"
+// @Failure 422 {object} map[string]interface{} "Límite de asientos bloqueados, límite de compra del evento alcanzado (limit, max, remaining) o código promocional vencido o que no aplica al carrito"
+// @Failure 428 {object} map[string]string "El evento tiene sala de espera y falta el header X-Queue-Token"
 // @Failure 500 {object} map[string]string "Error interno del servidor"
 // @Router /stripe/cart/checkout [post]
 // @Security BearerAuth
-func CreateCartCheckoutSession(seatService *services.SeatService, orderService *services.BookingOrderService) gin.HandlerFunc {
+func CreateCartCheckoutSession(seatService *services.SeatService, gaService *services.GeneralAdmissionService, orderService *services.BookingOrderService, waitingRoom *services.WaitingRoomService, limits *services.PurchaseLimitService, promos *services.PromoService, pricing *services.PricingService, providers *services.PaymentProviders, baseURL string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-
-		stripeKey := os.Getenv("STRIPE_SECRET_KEY")
-		if stripeKey == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "STRIPE_SECRET_KEY missing"})
+		if providers == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "payment provider not configured"})
 			return
 		}
-		stripe.Key = stripeKey
 
 		var body CreateCartCheckoutReq
 		if err := c.ShouldBindJSON(&body); err != nil {
@@ -68,24 +89,79 @@ func CreateCartCheckoutSession(seatService *services.SeatService, orderService *
 			return
 		}
 
+		provider, err := providers.Get(body.Provider)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		if len(body.Items) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "cart is empty"})
 			return
 		}
 
-		currency := strings.ToLower(strings.TrimSpace(body.Currency))
-		if currency == "" {
-			currency = "usd"
+		// El comprador es el del JWT: preventa, límites, hold y orden se validan contra él
+		userID, ok := requestUser(c, body.UserId)
+		if !ok {
+			return
 		}
 
-		var lineItems []*stripe.CheckoutSessionLineItemParams
+		var lineItems []services.CheckoutItem
 		var allSeatIds []string
-		var totalAmount int64 = 0
+		var gaItems []models.GAItem
+		var prices []money.Money
 		var eventID string
 
 		var enrichedItems []TicketItem
 
 		for _, item := range body.Items {
+			if item.GASectionId != "" {
+				if item.Quantity <= 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be positive for section: " + item.GASectionId})
+					return
+				}
+				if gaService == nil {
+					c.JSON(http.StatusNotFound, gin.H{"error": "General admission section not found: " + item.GASectionId})
+					return
+				}
+
+				section, err := gaService.FindSection(item.GASectionId)
+				if err != nil {
+					c.JSON(http.StatusNotFound, gin.H{"error": "General admission section not found: " + item.GASectionId})
+					return
+				}
+
+				if eventID == "" {
+					eventID = section.EventID
+				} else if eventID != section.EventID {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot mix events"})
+					return
+				}
+
+				// Precio por entrada del sector; el total suma una vez por entrada
+				realName := fmt.Sprintf("Admisión general - %s", section.Name)
+				for i := 0; i < item.Quantity; i++ {
+					prices = append(prices, section.Price)
+				}
+				gaItems = append(gaItems, models.GAItem{SectionID: section.ID, Section: section.Name, Quantity: item.Quantity})
+
+				enrichedItems = append(enrichedItems, TicketItem{
+					Name:        realName,
+					Amount:      section.Price.Amount,
+					GASectionId: section.ID,
+					Quantity:    item.Quantity,
+				})
+
+				lineItems = append(lineItems, services.CheckoutItem{
+					SeatID:   section.ID,
+					Name:     realName,
+					Amount:   section.Price.Amount,
+					Quantity: int64(item.Quantity),
+					Section:  section.Name,
+				})
+				continue
+			}
+
 			seatID := item.SeatIds.Id
 			if seatID == "" {
 				continue
@@ -104,20 +180,13 @@ func CreateCartCheckoutSession(seatService *services.SeatService, orderService *
 				return
 			}
 
-			if seat.Status != models.StatusAvailable {
-				c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Seat %s not available", seat.Number)})
-				return
-			}
-
-			realAmount := int64(seat.Price * 100)
+			// El precio y la moneda salen del asiento (moneda del evento), nunca del cliente
+			realAmount := seat.Price.Amount
 			realName := fmt.Sprintf("Asiento %s - %s", seat.Number, seat.Section)
 
-			totalAmount += realAmount
+			prices = append(prices, seat.Price)
 			allSeatIds = append(allSeatIds, seatID)
 
-			// Bloqueo
-			seatService.LockSeat(seatID, body.UserId)
-
 			enrichedItems = append(enrichedItems, TicketItem{
 				Name:   realName,
 				Amount: realAmount,
@@ -126,83 +195,198 @@ func CreateCartCheckoutSession(seatService *services.SeatService, orderService *
 				},
 			})
 
-			lineItems = append(lineItems, &stripe.CheckoutSessionLineItemParams{
-				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-					Currency:   stripe.String(currency),
-					UnitAmount: stripe.Int64(realAmount),
-					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-						Name: stripe.String(realName),
-					},
-				},
-				Quantity: stripe.Int64(1),
+			lineItems = append(lineItems, services.CheckoutItem{
+				SeatID:   seatID,
+				Name:     realName,
+				Amount:   realAmount,
+				Quantity: 1,
+				Section:  seat.Section,
 			})
 		}
 
-		if len(allSeatIds) == 0 {
+		if len(allSeatIds) == 0 && len(gaItems) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "No valid seats provided"})
 			return
 		}
 
+		currency, err := money.NormalizeCurrency(prices[0].Currency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if requested := strings.TrimSpace(body.Currency); requested != "" && !strings.EqualFold(requested, currency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: event is sold in %s", utils.ErrCurrencyMismatch, currency)})
+			return
+		}
+		total, err := money.Sum(currency, prices...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute total: " + err.Error()})
+			return
+		}
+
+		// Eventos con sala de espera: solo entran los usuarios admitidos
+		if !requireAdmission(c, waitingRoom, eventID) {
+			return
+		}
+
+		quantity := len(allSeatIds) + models.GATicketCount(gaItems)
+		buyer := buyerFrom(c, userID)
+		if !checkPurchaseLimit(c, limits, eventID, buyer.UserID, quantity) {
+			return
+		}
+
+		// Entradas a valor nominal, base de los cargos por entrada
+		faceItems := lineItems
+
+		// El código se valida antes de bloquear; su uso se registra al crear la orden
+		var promo *services.PromoQuote
+		if code := strings.TrimSpace(body.PromoCode); code != "" {
+			if promos == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": utils.ErrPromoCodeNotFound.Error()})
+				return
+			}
+			promo, err = promos.Apply(code, eventID, userID, currency, lineItems)
+			if err != nil {
+				respondPromoError(c, err, "Failed to apply promo code")
+				return
+			}
+			lineItems = promo.Items
+			total.Amount -= promo.Discount
+		}
+
+		// Cargos e impuestos del evento: la pasarela cobra el total del detalle
+		var lines []models.OrderLine
+		if pricing != nil {
+			quote, err := pricing.Price(eventID, currency, faceItems, promo)
+			if err != nil {
+				if errors.Is(err, utils.ErrEventNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price order: " + err.Error()})
+				return
+			}
+			lineItems = quote.Items
+			total = quote.Total
+			lines = quote.Lines
+		}
+
+		// Bloqueo atómico: o se bloquean todos los asientos y entradas o ninguno
+		hold, err := seatService.HoldCart(allSeatIds, gaItems, userID)
+		if err != nil {
+			if respondSeatRuleError(c, err) || respondSalesWindowError(c, err) {
+				return
+			}
+			var unavailable *utils.SeatsUnavailableError
+			if errors.As(err, &unavailable) {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":   "Some seats are not available",
+					"seatIds": unavailable.SeatIDs,
+				})
+				return
+			}
+			var soldOut *utils.GASoldOutError
+			if errors.As(err, &soldOut) {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":      "Not enough general admission tickets available",
+					"sectionIds": soldOut.SectionIDs,
+				})
+				return
+			}
+			if errors.Is(err, utils.ErrGASectionNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, utils.ErrHoldLimitExceeded) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock seats: " + err.Error()})
+			return
+		}
+
 		body.Items = enrichedItems
 
 		order := &models.BookingOrder{
-			UserID:  body.UserId,
+			UserID:  userID,
 			Status:  models.PaymentPending,
 			SeatIDs: allSeatIds,
-			Amount:  totalAmount,
+			GAItems: hold.GAItems,
+			Total:   total,
+			HoldID:  &hold.ID,
+			// Para los límites de compra por evento, usuario y email
+			EventID:       &eventID,
+			Quantity:      quantity,
+			CustomerEmail: models.NormalizeEmail(buyer.Email),
+			// La orden queda atada a la pasarela que va a cobrarla
+			PaymentProvider: provider.Name(),
+			Lines:           lines,
+		}
+		if promo != nil {
+			order.PromoCodeID = &promo.Promo.ID
+			order.PromoCode = promo.Promo.Code
+			order.Adjustments = promo.Adjustments
 		}
 
 		if err := orderService.CreateBookingOrder(order); err != nil {
+			_ = seatService.ReleaseHold(hold.ID, userID)
+			// Otro checkout gastó el último uso del código mientras tanto
+			if errors.Is(err, utils.ErrPromoCodeExhausted) || errors.Is(err, utils.ErrPromoCodeNotFound) {
+				respondPromoError(c, err, "Failed to apply promo code")
+				return
+			}
+			// Otra compra simultánea del mismo comprador ocupó el cupo
+			if respondPurchaseLimitError(c, err) {
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
 			return
 		}
 
-		seatsMetadata := strings.Join(allSeatIds, ",")
-		if len(seatsMetadata) > 450 {
-			seatsMetadata = "many_seats_check_db"
-		}
-
-		baseURL := os.Getenv("STRIPE_SUCCESS_URL")
-		successURLWithParam := fmt.Sprintf("%s/dentro/checkout/success?session_id={CHECKOUT_SESSION_ID}&order_id=%s", baseURL, order.ID)
+		successURLWithParam := fmt.Sprintf("%s/dentro/checkout/success?order_id=%s", baseURL, order.ID)
 		errorURL := fmt.Sprintf("%s/dentro/checkout/cancel", baseURL)
 
-		params := &stripe.CheckoutSessionParams{
-			Mode:      stripe.String(string(stripe.CheckoutSessionModePayment)),
-			LineItems: lineItems,
-
-			SuccessURL: stripe.String(successURLWithParam),
-			CancelURL:  stripe.String(errorURL),
-			PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
-				Metadata: map[string]string{
-					"user_id":  body.UserId,
-					"seat_ids": seatsMetadata,
-					"event_id": eventID,
-					"order_id": order.ID,
-				},
-			},
-			Metadata: map[string]string{
-				"user_id":  body.UserId,
-				"seat_ids": seatsMetadata,
-				"event_id": eventID,
-				"order_id": order.ID,
-			},
+		session, err := provider.CreateSession(c.Request.Context(), services.CheckoutSessionRequest{
+			OrderID:    order.ID,
+			UserID:     userID,
+			EventID:    eventID,
+			SeatIDs:    allSeatIds,
+			GAItems:    hold.GAItems,
+			Email:      buyer.Email,
+			PromoCode:  order.PromoCode,
+			Currency:   currency,
+			Items:      lineItems,
+			SuccessURL: successURLWithParam,
+			CancelURL:  errorURL,
+			ExpiresAt:  hold.ExpiresAt,
+		})
+		if err != nil {
+			// La orden pasa a FAILED antes de liberar el hold, que si no la cancelaría
+			_ = orderService.UpdateBookingOrderStatus(order.ID, models.PaymentFailed)
+			_ = seatService.ReleaseHold(hold.ID, userID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// Si la orden se cancela antes de pagarse, la sesión se cierra en la pasarela
+		if err := orderService.AttachPaymentSession(order.ID, session.ID); err != nil {
+			log.Printf("⚠️ No se pudo guardar la sesión de pago %s de la orden %s: %v", session.ID, order.ID, err)
 		}
 
 		responsePayload := &ResponseCartCheckoutReq{
 			OrderBookingId: order.ID,
-			UserId:         body.UserId,
-			Currency:       body.Currency,
+			UserId:         userID,
+			Currency:       currency,
 			Items:          body.Items,
-		}
-
-		s, err := checkoutsession.New(params)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+			HoldID:         hold.ID,
+			ExpiresAt:      hold.ExpiresAt,
+			Total:          total,
+			PromoCode:      order.PromoCode,
+			Adjustments:    order.Adjustments,
+			Lines:          order.Lines,
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"url":          s.URL,
+			"url":          session.URL,
 			"dataCheckout": BuildCheckoutResponse(responsePayload),
 		})
 	}
@@ -214,5 +398,11 @@ func BuildCheckoutResponse(callback *ResponseCartCheckoutReq) gin.H {
 		"userId":         callback.UserId,
 		"currency":       callback.Currency,
 		"items":          callback.Items,
+		"holdId":         callback.HoldID,
+		"expiresAt":      callback.ExpiresAt.Format(time.RFC3339),
+		"total":          callback.Total,
+		"promoCode":      callback.PromoCode,
+		"adjustments":    callback.Adjustments,
+		"lines":          callback.Lines,
 	}
 }
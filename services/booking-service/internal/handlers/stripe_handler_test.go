@@ -6,16 +6,17 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"booking-service/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
 func TestStripeHandler_CreateCartCheckoutSession_Validations(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Run("missing stripe key", func(t *testing.T) {
-		t.Setenv("STRIPE_SECRET_KEY", "")
+	t.Run("missing payment provider", func(t *testing.T) {
 		r := gin.New()
-		r.POST("/stripe", CreateCartCheckoutSession(nil, nil))
+		r.POST("/stripe", CreateCartCheckoutSession(nil, nil, nil, nil, nil, nil, nil, nil, ""))
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/stripe", bytes.NewBufferString(`{}`)))
 		if w.Code != http.StatusInternalServerError {
@@ -24,9 +25,8 @@ func TestStripeHandler_CreateCartCheckoutSession_Validations(t *testing.T) {
 	})
 
 	t.Run("bad body", func(t *testing.T) {
-		t.Setenv("STRIPE_SECRET_KEY", "sk_test_x")
 		r := gin.New()
-		r.POST("/stripe", CreateCartCheckoutSession(nil, nil))
+		r.POST("/stripe", CreateCartCheckoutSession(nil, nil, nil, nil, nil, nil, nil, services.NewPaymentProviders(services.NewFakePaymentProvider()), ""))
 		req := httptest.NewRequest(http.MethodPost, "/stripe", bytes.NewBufferString("{"))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
@@ -36,10 +36,61 @@ func TestStripeHandler_CreateCartCheckoutSession_Validations(t *testing.T) {
 		}
 	})
 
+	t.Run("unknown provider", func(t *testing.T) {
+		r := gin.New()
+		r.POST("/stripe", CreateCartCheckoutSession(nil, nil, nil, nil, nil, nil, nil, services.NewPaymentProviders(services.NewFakePaymentProvider()), ""))
+		req := httptest.NewRequest(http.MethodPost, "/stripe", bytes.NewBufferString(`{"userId":"u1","provider":"paypal","items":[{"seatIds":{"id":"s1"}}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("buyer must be the authenticated user", func(t *testing.T) {
+		cases := []struct {
+			name string
+			user string
+			want int
+		}{
+			{"not authenticated", "", http.StatusUnauthorized},
+			{"other user", "u2", http.StatusForbidden},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				r := gin.New()
+				r.POST("/stripe", func(c *gin.Context) {
+					if tc.user != "" {
+						c.Set("userID", tc.user)
+					}
+				}, CreateCartCheckoutSession(nil, nil, nil, nil, nil, nil, nil, services.NewPaymentProviders(services.NewFakePaymentProvider()), ""))
+				req := httptest.NewRequest(http.MethodPost, "/stripe", bytes.NewBufferString(`{"userId":"u1","items":[{"seatIds":{"id":"s1"}}]}`))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != tc.want {
+					t.Fatalf("expected %d, got %d", tc.want, w.Code)
+				}
+			})
+		}
+	})
+
+	t.Run("general admission without quantity", func(t *testing.T) {
+		r := gin.New()
+		r.POST("/stripe", func(c *gin.Context) { c.Set("userID", "u1") }, CreateCartCheckoutSession(nil, nil, nil, nil, nil, nil, nil, services.NewPaymentProviders(services.NewFakePaymentProvider()), ""))
+		req := httptest.NewRequest(http.MethodPost, "/stripe", bytes.NewBufferString(`{"userId":"u1","items":[{"gaSectionId":"ga1","quantity":0}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+
 	t.Run("empty items", func(t *testing.T) {
-		t.Setenv("STRIPE_SECRET_KEY", "sk_test_x")
 		r := gin.New()
-		r.POST("/stripe", CreateCartCheckoutSession(nil, nil))
+		r.POST("/stripe", CreateCartCheckoutSession(nil, nil, nil, nil, nil, nil, nil, services.NewPaymentProviders(services.NewFakePaymentProvider()), ""))
 		req := httptest.NewRequest(http.MethodPost, "/stripe", bytes.NewBufferString(`{"userId":"u1","currency":"usd","items":[]}`))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type VenueHandler struct {
+	service *services.VenueService
+}
+
+// Constructor
+func NewVenueHandler(service *services.VenueService) *VenueHandler {
+	return &VenueHandler{service: service}
+}
+
+// GenerateSeatsReq indica el recinto y, opcionalmente, la categoría de precio de cada sección
+type GenerateSeatsReq struct {
+	VenueID string            `json:"venueId" binding:"required,uuid"`
+	Tiers   map[string]string `json:"tiers,omitempty"` // sección -> tierId
+}
+
+// GenerateSeatsRes resume el inventario generado
+type GenerateSeatsRes struct {
+	EventID      string `json:"eventId"`
+	VenueID      string `json:"venueId"`
+	SeatsCreated int    `json:"seatsCreated"`
+}
+
+// CreateVenue godoc
+// @Summary Importar recinto
+// @Description Crea un recinto con su plano. Acepta JSON (models.VenueLayout) o CSV (Content-Type text/csv) con encabezado section,row,number,x,y,aisle_left,aisle_right; con CSV el nombre y la dirección van por query
+// @Tags venues
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param layout body models.VenueLayout true "Plano del recinto"
+// @Param name query string false "Nombre del recinto (solo CSV)"
+// @Param address query string false "Dirección del recinto (solo CSV)"
+// @Success 201 {object} models.Venue "Recinto creado"
+// @Failure 400 {object} map[string]string "Plano inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /venues [post]
+// @Security BearerAuth
+// POST /venues
+func (h *VenueHandler) CreateVenue(c *gin.Context) {
+	var venue *models.Venue
+	var err error
+
+	if strings.HasPrefix(c.ContentType(), "text/csv") {
+		venue, err = services.ParseVenueCSV(c.Request.Body, c.Query("name"), c.Query("address"))
+	} else {
+		venue, err = services.ParseVenueJSON(c.Request.Body)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.CreateVenue(venue); err != nil {
+		respondVenueError(c, err, "Failed to create venue")
+		return
+	}
+
+	c.JSON(http.StatusCreated, venue)
+}
+
+// GetVenues godoc
+// @Summary Listar recintos
+// @Tags venues
+// @Produce json
+// @Success 200 {array} models.Venue
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /venues [get]
+// @Security BearerAuth
+// GET /venues
+func (h *VenueHandler) GetVenues(c *gin.Context) {
+	venues, err := h.service.GetVenues()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch venues"})
+		return
+	}
+
+	c.JSON(http.StatusOK, venues)
+}
+
+// GetVenue godoc
+// @Summary Obtener recinto
+// @Description Devuelve el recinto con todas sus butacas
+// @Tags venues
+// @Produce json
+// @Param id path string true "ID del recinto"
+// @Success 200 {object} models.Venue
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Recinto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /venues/{id} [get]
+// @Security BearerAuth
+// GET /venues/:id
+func (h *VenueHandler) GetVenue(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	venue, err := h.service.GetVenue(id)
+	if err != nil {
+		respondVenueError(c, err, "Failed to fetch venue")
+		return
+	}
+
+	c.JSON(http.StatusOK, venue)
+}
+
+// DeleteVenue godoc
+// @Summary Borrar recinto
+// @Description Borra el recinto y su plano; los asientos ya generados para eventos no cambian
+// @Tags venues
+// @Produce json
+// @Param id path string true "ID del recinto"
+// @Success 200 {object} map[string]string "Recinto borrado"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Recinto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /venues/{id} [delete]
+// @Security BearerAuth
+// DELETE /venues/:id
+func (h *VenueHandler) DeleteVenue(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	if err := h.service.DeleteVenue(id); err != nil {
+		respondVenueError(c, err, "Failed to delete venue")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Venue deleted successfully"})
+}
+
+// GenerateSeats godoc
+// @Summary Generar asientos desde un recinto
+// @Description Crea todo el inventario de asientos del evento a partir del plano del recinto, en una sola transacción
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param body body GenerateSeatsReq true "Recinto y categorías por sección"
+// @Success 201 {object} GenerateSeatsRes
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Evento, recinto o categoría no encontrados"
+// @Failure 409 {object} map[string]string "El evento ya tiene asientos o una categoría no tiene capacidad"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/seats/generate-from-venue [post]
+// @Security BearerAuth
+// POST /events/:id/seats/generate-from-venue
+func (h *VenueHandler) GenerateSeats(c *gin.Context) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return
+	}
+
+	var body GenerateSeatsReq
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	seats, err := h.service.GenerateSeats(eventID, body.VenueID, body.Tiers)
+	if err != nil {
+		respondVenueError(c, err, "Failed to generate seats")
+		return
+	}
+
+	c.JSON(http.StatusCreated, GenerateSeatsRes{EventID: eventID, VenueID: body.VenueID, SeatsCreated: len(seats)})
+}
+
+func respondVenueError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, utils.ErrInvalidVenue):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrVenueNotFound), errors.Is(err, utils.ErrEventNotFound), errors.Is(err, utils.ErrTierNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrEventHasSeats), errors.Is(err, utils.ErrTierFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVenueHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &VenueHandler{}
+	r := gin.New()
+	r.POST("/venues", h.CreateVenue)
+	r.GET("/venues/:id", h.GetVenue)
+	r.POST("/events/:id/seats/generate-from-venue", h.GenerateSeats)
+
+	const eventID = "11111111-1111-1111-1111-111111111111"
+
+	cases := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+	}{
+		{"invalid venue id", http.MethodGet, "/venues/bad", "", ""},
+		{"malformed json layout", http.MethodPost, "/venues", "application/json", `{`},
+		{"json layout without seats", http.MethodPost, "/venues", "application/json", `{"name":"Teatro","sections":[]}`},
+		{"csv without name", http.MethodPost, "/venues", "text/csv", "section,row,number\nA,1,1\n"},
+		{"invalid event id", http.MethodPost, "/events/bad/seats/generate-from-venue", "application/json", `{"venueId":"` + eventID + `"}`},
+		{"missing venue id", http.MethodPost, "/events/" + eventID + "/seats/generate-from-venue", "application/json", `{}`},
+		{"venue id not uuid", http.MethodPost, "/events/" + eventID + "/seats/generate-from-venue", "application/json", `{"venueId":"x"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/services"
+	"booking-service/pkg/utils"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header con el pase de la sala de espera; el WebSocket lo recibe en ?queueToken=
+const queueTokenHeader = "X-Queue-Token"
+
+type WaitingRoomHandler struct {
+	service *services.WaitingRoomService
+}
+
+// Constructor
+func NewWaitingRoomHandler(service *services.WaitingRoomService) *WaitingRoomHandler {
+	return &WaitingRoomHandler{service: service}
+}
+
+// JoinQueue godoc
+// @Summary Entrar a la sala de espera
+// @Description Pone al usuario en la fila del evento y devuelve un pase firmado con su lugar. Si el evento no tiene sala de espera responde admitted=true sin token.
+// @Description El pase se manda en el header X-Queue-Token a la selección de asientos y al checkout una vez admitido. Entrar de nuevo devuelve el mismo lugar mientras el pase esté vigente.
+// @Tags Waiting room
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Success 200 {object} models.QueueStatus "Lugar en la fila"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/queue [post]
+// @Security BearerAuth
+// POST /events/:id/queue
+func (h *WaitingRoomHandler) JoinQueue(c *gin.Context) {
+	eventID, userID, ok := queueRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Join(eventID, userID)
+	if err != nil {
+		respondQueueError(c, err, "Failed to join waiting room")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetQueueStatus godoc
+// @Summary Estado en la sala de espera
+// @Description Devuelve cuántas personas hay delante, la espera estimada y si el pase ya está admitido
+// @Tags Waiting room
+// @Produce json
+// @Param id path string true "ID del evento"
+// @Param X-Queue-Token header string true "Pase de la sala de espera"
+// @Success 200 {object} models.QueueStatus "Lugar en la fila"
+// @Failure 400 {object} map[string]string "Formato UUID inválido"
+// @Failure 401 {object} map[string]string "No autorizado"
+// @Failure 403 {object} map[string]string "Pase inválido o vencido"
+// @Failure 428 {object} map[string]string "Falta el pase"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /events/{id}/queue [get]
+// @Security BearerAuth
+// GET /events/:id/queue
+func (h *WaitingRoomHandler) GetQueueStatus(c *gin.Context) {
+	eventID, userID, ok := queueRequest(c)
+	if !ok {
+		return
+	}
+
+	token := queueToken(c)
+	if token == "" {
+		respondQueueError(c, utils.ErrQueueTokenRequired, "")
+		return
+	}
+
+	status, err := h.service.Status(eventID, userID, token)
+	if err != nil {
+		respondQueueError(c, err, "Failed to fetch waiting room status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// RequireAdmission protege las rutas /events/:id/... de selección de asientos: si el evento
+// tiene sala de espera exige un pase admitido del usuario autenticado
+func (h *WaitingRoomHandler) RequireAdmission() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, ok := eventIDParam(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if !requireAdmission(c, h.service, eventID) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireAdmission controla el pase cuando el evento se conoce recién dentro del handler
+// (checkout, bloqueo por asiento). Sin servicio de sala de espera no controla nada
+func requireAdmission(c *gin.Context, waitingRoom *services.WaitingRoomService, eventID string) bool {
+	if waitingRoom == nil {
+		return true
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return false
+	}
+
+	pass, err := waitingRoom.CheckAdmission(eventID, userID, queueToken(c))
+	if err != nil {
+		respondQueueError(c, err, "Failed to check waiting room admission")
+		return false
+	}
+	if pass != nil {
+		c.Set("queuePass", pass)
+	}
+	return true
+}
+
+// queuePassFrom devuelve el pase validado por requireAdmission, si el evento tiene sala de espera
+func queuePassFrom(c *gin.Context) *models.QueuePass {
+	if pass, ok := c.Get("queuePass"); ok {
+		return pass.(*models.QueuePass)
+	}
+	return nil
+}
+
+func queueRequest(c *gin.Context) (string, string, bool) {
+	eventID, ok := eventIDParam(c)
+	if !ok {
+		return "", "", false
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return "", "", false
+	}
+
+	return eventID, userID, true
+}
+
+func queueToken(c *gin.Context) string {
+	if token := c.GetHeader(queueTokenHeader); token != "" {
+		return token
+	}
+	return c.Query("queueToken")
+}
+
+func respondQueueError(c *gin.Context, err error, fallback string) {
+	var notAdmitted *utils.QueueNotAdmittedError
+	switch {
+	case errors.As(err, &notAdmitted):
+		wait := time.Until(notAdmitted.AdmitAt)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(max(wait, 0).Seconds()))))
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":    err.Error(),
+			"admitAt":  notAdmitted.AdmitAt.Format(time.RFC3339),
+			"position": notAdmitted.Position,
+		})
+	case errors.Is(err, utils.ErrQueueTokenRequired):
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrInvalidQueueToken), errors.Is(err, utils.ErrQueueAdmissionExpired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrEventNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"booking-service/pkg/utils"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWaitingRoomHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &WaitingRoomHandler{}
+	r := gin.New()
+	r.POST("/events/:id/queue", h.JoinQueue)
+	r.GET("/events/:id/queue", func(c *gin.Context) { c.Set("userID", "u1") }, h.GetQueueStatus)
+
+	const eventID = "11111111-1111-1111-1111-111111111111"
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"invalid event id", http.MethodPost, "/events/bad/queue", http.StatusBadRequest},
+		{"missing user", http.MethodPost, "/events/" + eventID + "/queue", http.StatusUnauthorized},
+		{"missing token", http.MethodGet, "/events/" + eventID + "/queue", http.StatusPreconditionRequired},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRespondQueueError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not admitted", &utils.QueueNotAdmittedError{AdmitAt: time.Now().Add(90 * time.Second), Position: 12}, http.StatusForbidden},
+		{"token required", utils.ErrQueueTokenRequired, http.StatusPreconditionRequired},
+		{"invalid token", utils.ErrInvalidQueueToken, http.StatusForbidden},
+		{"expired", utils.ErrQueueAdmissionExpired, http.StatusForbidden},
+		{"event not found", utils.ErrEventNotFound, http.StatusNotFound},
+		{"other", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondQueueError(c, tc.err, "fallback")
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	respondQueueError(c, &utils.QueueNotAdmittedError{AdmitAt: time.Now().Add(90 * time.Second), Position: 12}, "")
+	if retry := w.Header().Get("Retry-After"); retry != "90" && retry != "89" {
+		t.Fatalf("unexpected Retry-After %q", retry)
+	}
+}
+
+func TestRequireAdmission_WithoutWaitingRoom(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if !requireAdmission(c, nil, "e1") || queuePassFrom(c) != nil {
+		t.Fatalf("expected requests allowed without waiting room service")
+	}
+}
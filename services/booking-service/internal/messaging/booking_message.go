@@ -0,0 +1,79 @@
+package messaging
+
+import "strings"
+
+// BookingMessage es el mensaje interno que se encola con los datos del pago de Stripe
+type BookingMessage struct {
+	UserID            string  `json:"userId"`
+	Amount            float64 `json:"amount"`
+	Currency          string  `json:"currency,omitempty"`
+	Status            string  `json:"status"`
+	SeatIDs           string  `json:"seatIds"`
+	GAItems           string  `json:"gaItems,omitempty"` // Admisión general: "sectorId:cantidad,..."
+	EventID           string  `json:"eventId"`
+	PaymentProviderID string  `json:"paymentProviderId"`
+	OrderID           string  `json:"orderId"` // <--- Para saber qué orden actualizar
+	// Campo para evitar la duplicacion
+	Nonce string `json:"nonce"`
+	// Observabilidad / idempotencia
+	StripeEventID   string `json:"stripeEventId"`
+	StripeEventType string `json:"stripeEventType"`
+	// Pasarela que originó el evento (STRIPE si viene vacío)
+	Provider string `json:"provider,omitempty"`
+}
+
+// SeatIDsInOrder reemplaza los IDs de asientos cuando no entran en la metadata de la pasarela
+const SeatIDsInOrder = "many_seats_check_db"
+
+// SeatsInOrder indica que el mensaje no trae los IDs de asientos: valen los de la orden
+func (m BookingMessage) SeatsInOrder() bool {
+	return strings.TrimSpace(m.SeatIDs) == SeatIDsInOrder
+}
+
+// SeatIDList devuelve los IDs de asientos del mensaje ("a,b,c") sin vacíos
+func (m BookingMessage) SeatIDList() []string {
+	if m.SeatsInOrder() {
+		return nil
+	}
+	var ids []string
+	for _, id := range strings.Split(m.SeatIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Eventos de Stripe sobre pagos no completados. payment_intent.payment_failed no termina la
+// orden: el comprador puede reintentar con otra tarjeta en la misma sesión de Checkout
+const (
+	EventCheckoutSessionExpired = "checkout.session.expired"
+	EventPaymentIntentFailed    = "payment_intent.payment_failed"
+	EventPaymentIntentCanceled  = "payment_intent.canceled"
+)
+
+// Eventos de MercadoPago (topic "payment" + estado del pago) sobre pagos no completados. Un pago
+// rechazado no termina la orden: Checkout Pro deja reintentar con otro medio de pago
+const (
+	EventMercadoPagoRejected  = "payment.rejected"
+	EventMercadoPagoCancelled = "payment.cancelled"
+)
+
+// IsFailure indica si el evento termina la orden: una sesión vencida o un pago cancelado. Un
+// intento de pago rechazado no la termina porque el comprador todavía puede reintentar
+func (m BookingMessage) IsFailure() bool {
+	switch strings.TrimSpace(m.StripeEventType) {
+	case EventCheckoutSessionExpired, EventPaymentIntentCanceled, EventMercadoPagoCancelled:
+		return true
+	}
+	return false
+}
+
+// IsPaid indica si el estado reportado por la pasarela corresponde a un pago completado
+func (m BookingMessage) IsPaid() bool {
+	switch strings.ToLower(strings.TrimSpace(m.Status)) {
+	case "paid", "complete", "completed", "succeeded", "approved":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrDiscardMessage indica que el mensaje nunca podrá procesarse (datos inválidos,
+// orden inexistente, etc.) y debe eliminarse de la cola en lugar de reintentarse
+var ErrDiscardMessage = errors.New("message cannot be processed")
+
+// PaymentHandler aplica un evento de pago recibido de la cola
+type PaymentHandler interface {
+	HandlePayment(ctx context.Context, msg BookingMessage) error
+}
+
+// PaymentConsumer consume los mensajes de pago de la cola y los aplica en proceso
+type PaymentConsumer struct {
+	queue   Receiver
+	handler PaymentHandler
+
+	batchSize      int
+	wait           time.Duration
+	processTimeout time.Duration
+	retryBase      time.Duration
+	retryMax       time.Duration
+}
+
+func NewPaymentConsumer(queue Receiver, handler PaymentHandler, wait time.Duration) *PaymentConsumer {
+	if wait <= 0 {
+		wait = 20 * time.Second
+	}
+	return &PaymentConsumer{
+		queue:          queue,
+		handler:        handler,
+		batchSize:      10,
+		wait:           wait,
+		processTimeout: 30 * time.Second,
+		retryBase:      10 * time.Second,
+		retryMax:       5 * time.Minute,
+	}
+}
+
+// Start lanza el consumer en segundo plano. El canal devuelto se cierra cuando terminó
+// de procesar el lote en curso luego de cancelar el contexto
+func (c *PaymentConsumer) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		failures := 0
+		for ctx.Err() == nil {
+			if _, err := c.PollOnce(ctx); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				failures++
+				delay := c.backoff(failures)
+				log.Printf("⚠️ Payment consumer: error leyendo la cola (reintento en %v): %v", delay, err)
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(delay):
+				}
+				continue
+			}
+			failures = 0
+		}
+
+		log.Println("🛑 Payment consumer detenido")
+	}()
+
+	return done
+}
+
+// PollOnce recibe un lote de mensajes y los procesa. Devuelve la cantidad de mensajes confirmados
+func (c *PaymentConsumer) PollOnce(ctx context.Context) (int, error) {
+	msgs, err := c.queue.Receive(ctx, c.batchSize, c.wait)
+	if err != nil {
+		return 0, err
+	}
+
+	acked := 0
+	for _, m := range msgs {
+		if c.process(m) {
+			acked++
+		}
+	}
+	return acked, nil
+}
+
+// process aplica un mensaje y decide si se confirma o se reintenta más tarde.
+// Usa un contexto propio para que un apagado no corte una transacción a la mitad
+func (c *PaymentConsumer) process(m Message) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), c.processTimeout)
+	defer cancel()
+
+	err := c.handle(ctx, m)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrDiscardMessage):
+		log.Printf("🗑️ Payment consumer: descartando mensaje %s: %v", m.ID, err)
+	default:
+		delay := c.backoff(m.ReceiveCount)
+		log.Printf("⚠️ Payment consumer: mensaje %s falló (intento %d, reintento en %v): %v", m.ID, m.ReceiveCount, delay, err)
+		if err := c.queue.ChangeVisibility(ctx, m.ReceiptHandle, delay); err != nil {
+			log.Printf("⚠️ Payment consumer: no se pudo reprogramar el mensaje %s: %v", m.ID, err)
+		}
+		return false
+	}
+
+	if err := c.queue.Delete(ctx, m.ReceiptHandle); err != nil {
+		log.Printf("⚠️ Payment consumer: no se pudo confirmar el mensaje %s: %v", m.ID, err)
+		return false
+	}
+	return true
+}
+
+func (c *PaymentConsumer) handle(ctx context.Context, m Message) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+
+	var msg BookingMessage
+	if err := json.Unmarshal([]byte(m.Body), &msg); err != nil {
+		return fmt.Errorf("%w: invalid json: %v", ErrDiscardMessage, err)
+	}
+	return c.handler.HandlePayment(ctx, msg)
+}
+
+// backoff exponencial a partir del número de intento, acotado por retryMax
+func (c *PaymentConsumer) backoff(attempt int) time.Duration {
+	delay := c.retryBase
+	for i := 1; i < attempt && delay < c.retryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, c.retryMax)
+}
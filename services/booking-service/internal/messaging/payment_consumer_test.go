@@ -0,0 +1,138 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeQueue es una cola en memoria que registra los acks y reintentos
+type fakeQueue struct {
+	msgs       []Message
+	receiveErr error
+
+	deleted    []string
+	visibility map[string]time.Duration
+}
+
+func (q *fakeQueue) Receive(context.Context, int, time.Duration) ([]Message, error) {
+	if q.receiveErr != nil {
+		return nil, q.receiveErr
+	}
+	msgs := q.msgs
+	q.msgs = nil
+	return msgs, nil
+}
+
+func (q *fakeQueue) Delete(_ context.Context, receiptHandle string) error {
+	q.deleted = append(q.deleted, receiptHandle)
+	return nil
+}
+
+func (q *fakeQueue) ChangeVisibility(_ context.Context, receiptHandle string, timeout time.Duration) error {
+	if q.visibility == nil {
+		q.visibility = make(map[string]time.Duration)
+	}
+	q.visibility[receiptHandle] = timeout
+	return nil
+}
+
+type fakePaymentHandler struct {
+	handled []BookingMessage
+	errFor  map[string]error
+}
+
+func (h *fakePaymentHandler) HandlePayment(_ context.Context, msg BookingMessage) error {
+	h.handled = append(h.handled, msg)
+	return h.errFor[msg.OrderID]
+}
+
+func TestPaymentConsumer_PollOnce(t *testing.T) {
+	queue := &fakeQueue{msgs: []Message{
+		{ID: "1", ReceiptHandle: "ok", Body: `{"orderId":"o1","seatIds":"s1","status":"paid"}`, ReceiveCount: 1},
+		{ID: "2", ReceiptHandle: "poison", Body: `{`, ReceiveCount: 1},
+		{ID: "3", ReceiptHandle: "discard", Body: `{"orderId":"o3","seatIds":"s3","status":"paid"}`, ReceiveCount: 1},
+		{ID: "4", ReceiptHandle: "retry", Body: `{"orderId":"o4","seatIds":"s4","status":"paid"}`, ReceiveCount: 3},
+		{ID: "5", ReceiptHandle: "panic", Body: `{"orderId":"panic","seatIds":"s5","status":"paid"}`, ReceiveCount: 1},
+	}}
+	handler := &fakePaymentHandler{errFor: map[string]error{
+		"o3": fmt.Errorf("%w: order not found", ErrDiscardMessage),
+		"o4": errors.New("db down"),
+	}}
+	consumer := NewPaymentConsumer(queue, panickingHandler{handler}, time.Second)
+
+	acked, err := consumer.PollOnce(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if acked != 3 {
+		t.Fatalf("expected 3 acked messages, got %d (%v)", acked, queue.deleted)
+	}
+
+	want := map[string]bool{"ok": true, "poison": true, "discard": true}
+	for _, h := range queue.deleted {
+		if !want[h] {
+			t.Fatalf("unexpected delete of %s", h)
+		}
+	}
+
+	if got := queue.visibility["retry"]; got != 40*time.Second {
+		t.Fatalf("expected 40s backoff on third attempt, got %v", got)
+	}
+	if _, ok := queue.visibility["panic"]; !ok {
+		t.Fatalf("expected panicking message to be retried later")
+	}
+}
+
+func TestPaymentConsumer_StartStopsOnCancel(t *testing.T) {
+	queue := &fakeQueue{msgs: []Message{{ID: "1", ReceiptHandle: "ok", Body: `{"orderId":"o1"}`}}}
+	handler := chanHandler(make(chan BookingMessage, 1))
+	consumer := NewPaymentConsumer(queue, handler, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := consumer.Start(ctx)
+
+	select {
+	case <-handler:
+	case <-time.After(time.Second):
+		t.Fatalf("message was not consumed")
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("consumer did not stop")
+	}
+}
+
+func TestPaymentConsumer_Backoff(t *testing.T) {
+	c := NewPaymentConsumer(&fakeQueue{}, &fakePaymentHandler{}, 0)
+	if got := c.backoff(0); got != 10*time.Second {
+		t.Fatalf("expected base backoff, got %v", got)
+	}
+	if got := c.backoff(2); got != 20*time.Second {
+		t.Fatalf("expected 20s, got %v", got)
+	}
+	if got := c.backoff(50); got != 5*time.Minute {
+		t.Fatalf("expected backoff capped at 5m, got %v", got)
+	}
+}
+
+type panickingHandler struct{ *fakePaymentHandler }
+
+func (h panickingHandler) HandlePayment(ctx context.Context, msg BookingMessage) error {
+	if msg.OrderID == "panic" {
+		panic("boom")
+	}
+	return h.fakePaymentHandler.HandlePayment(ctx, msg)
+}
+
+type chanHandler chan BookingMessage
+
+func (h chanHandler) HandlePayment(_ context.Context, msg BookingMessage) error {
+	h <- msg
+	return nil
+}
@@ -2,13 +2,36 @@ package messaging
 
 import (
 	"context"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
+// Sender abstrae el envío de mensajes a la cola
+type Sender interface {
+	Send(ctx context.Context, body string, messageGroupID string, messageDeduplicationID string) (string, error)
+}
+
+// Message es un mensaje recibido de la cola
+type Message struct {
+	ID            string
+	ReceiptHandle string
+	Body          string
+	ReceiveCount  int
+}
+
+// Receiver abstrae el consumo de la cola (SQS en producción, un fake en tests/local)
+type Receiver interface {
+	Receive(ctx context.Context, maxMessages int, wait time.Duration) ([]Message, error)
+	Delete(ctx context.Context, receiptHandle string) error
+	ChangeVisibility(ctx context.Context, receiptHandle string, timeout time.Duration) error
+}
+
 type SQSClient struct {
 	client   *sqs.Client
 	queueURL string
@@ -47,3 +70,49 @@ func (c *SQSClient) Send(ctx context.Context, body string, messageGroupID string
 	}
 	return aws.ToString(out.MessageId), nil
 }
+
+// Receive hace long-polling de hasta maxMessages mensajes (SQS admite 1..10 y hasta 20s de espera)
+func (c *SQSClient) Receive(ctx context.Context, maxMessages int, wait time.Duration) ([]Message, error) {
+	out, err := c.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(c.queueURL),
+		MaxNumberOfMessages: int32(min(max(maxMessages, 1), 10)),
+		WaitTimeSeconds:     int32(min(wait/time.Second, 20)),
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameApproximateReceiveCount,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]Message, 0, len(out.Messages))
+	for _, m := range out.Messages {
+		count, _ := strconv.Atoi(m.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+		msgs = append(msgs, Message{
+			ID:            aws.ToString(m.MessageId),
+			ReceiptHandle: aws.ToString(m.ReceiptHandle),
+			Body:          aws.ToString(m.Body),
+			ReceiveCount:  count,
+		})
+	}
+	return msgs, nil
+}
+
+// Delete confirma (ack) un mensaje procesado
+func (c *SQSClient) Delete(ctx context.Context, receiptHandle string) error {
+	_, err := c.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(c.queueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	})
+	return err
+}
+
+// ChangeVisibility reprograma cuándo vuelve a estar visible un mensaje (reintento diferido)
+func (c *SQSClient) ChangeVisibility(ctx context.Context, receiptHandle string, timeout time.Duration) error {
+	_, err := c.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(c.queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: int32(timeout / time.Second),
+	})
+	return err
+}
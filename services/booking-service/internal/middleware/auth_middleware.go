@@ -58,6 +58,10 @@ func UserMiddleware() gin.HandlerFunc {
 
 		c.Set("userID", userID)
 		c.Set("user_id", userID)
+		// El email (si el token lo trae) se usa para los límites de compra por email
+		if email, ok := claims["email"].(string); ok {
+			c.Set("userEmail", email)
+		}
 
 		c.Next()
 	}
@@ -91,6 +91,26 @@ func TestUserMiddleware(t *testing.T) {
 		}
 	})
 
+	t.Run("email claim", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "secret")
+		r := gin.New()
+		r.Use(UserMiddleware())
+		r.GET("/x", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"email": c.GetString("userEmail")})
+		})
+
+		token := makeJWT(t, "secret", jwt.MapClaims{"sub": "u1", "email": "fan@example.com"})
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body map[string]string
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != http.StatusOK || body["email"] != "fan@example.com" {
+			t.Fatalf("expected email in context, got %d %v", w.Code, body)
+		}
+	})
+
 	t.Run("missing id and sub", func(t *testing.T) {
 		t.Setenv("JWT_SECRET", "secret")
 		r := gin.New()
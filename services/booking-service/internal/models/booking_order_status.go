@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// orderTransitions define los cambios de estado permitidos para una BookingOrder
+var orderTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentPending:           {PaymentCompleted, PaymentFailed, PaymentExpired, PaymentCancelled},
+	PaymentCompleted:         {PaymentRefunded, PaymentPartiallyRefunded},
+	PaymentPartiallyRefunded: {PaymentRefunded, PaymentPartiallyRefunded},
+}
+
+// IsValid indica si el estado es uno de los estados conocidos de una orden
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case PaymentPending, PaymentCompleted, PaymentFailed, PaymentCancelled, PaymentExpired,
+		PaymentRefunded, PaymentPartiallyRefunded:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo indica si una orden en este estado puede pasar al estado next
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// BookingOrderStatusHistory registra cada cambio de estado de una orden: quién, qué y cuándo
+type BookingOrderStatusHistory struct {
+	ID         string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	OrderID    string        `gorm:"not null;index" json:"orderId"`
+	FromStatus PaymentStatus `gorm:"type:varchar(30)" json:"fromStatus"`
+	ToStatus   PaymentStatus `gorm:"type:varchar(30);not null" json:"toStatus"`
+	Version    int           `gorm:"not null" json:"version"`
+	ChangedBy  string        `gorm:"type:text;not null" json:"changedBy"`
+	Reason     string        `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+func (BookingOrderStatusHistory) TableName() string {
+	return "booking_order_status_history"
+}
@@ -0,0 +1,30 @@
+package models_test
+
+import (
+	"testing"
+
+	"booking-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, models.PaymentPending.CanTransitionTo(models.PaymentCompleted))
+	assert.True(t, models.PaymentPending.CanTransitionTo(models.PaymentExpired))
+	assert.True(t, models.PaymentCompleted.CanTransitionTo(models.PaymentPartiallyRefunded))
+	assert.True(t, models.PaymentPartiallyRefunded.CanTransitionTo(models.PaymentRefunded))
+
+	assert.False(t, models.PaymentCompleted.CanTransitionTo(models.PaymentPending))
+	assert.False(t, models.PaymentCompleted.CanTransitionTo(models.PaymentFailed))
+	assert.False(t, models.PaymentPending.CanTransitionTo(models.PaymentRefunded))
+	assert.False(t, models.PaymentRefunded.CanTransitionTo(models.PaymentPartiallyRefunded))
+	assert.False(t, models.PaymentFailed.CanTransitionTo(models.PaymentCompleted))
+}
+
+func TestPaymentStatus_IsValid(t *testing.T) {
+	assert.True(t, models.PaymentExpired.IsValid())
+	assert.False(t, models.PaymentStatus("BAD").IsValid())
+}
+
+func TestBookingOrderStatusHistory_TableName(t *testing.T) {
+	assert.Equal(t, "booking_order_status_history", models.BookingOrderStatusHistory{}.TableName())
+}
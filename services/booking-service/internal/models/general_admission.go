@@ -0,0 +1,131 @@
+package models
+
+import (
+	"booking-service/pkg/money"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GASection es un sector de admisión general (campo, pista): en vez de un Seat por entrada
+// tiene un cupo que se descuenta al bloquear y se confirma al pagar
+type GASection struct {
+	BaseModel
+
+	EventID  string      `gorm:"type:uuid;not null;index" json:"eventId"`
+	Name     string      `gorm:"not null" json:"name"`
+	Price    money.Money `gorm:"embedded;embeddedPrefix:price_" json:"price"` // En la moneda del evento
+	Capacity int         `gorm:"not null" json:"capacity"`
+
+	// Contadores del cupo: entradas en holds vigentes y entradas vendidas
+	Held int `gorm:"not null;default:0" json:"held"`
+	Sold int `gorm:"not null;default:0" json:"sold"`
+}
+
+func (GASection) TableName() string {
+	return "ga_sections"
+}
+
+// Available devuelve las entradas que todavía se pueden bloquear
+func (s GASection) Available() int {
+	return max(s.Capacity-s.Held-s.Sold, 0)
+}
+
+type GAHoldStatus string
+
+const (
+	GAHoldActive   GAHoldStatus = "ACTIVE"
+	GAHoldSold     GAHoldStatus = "SOLD"
+	GAHoldReleased GAHoldStatus = "RELEASED"
+)
+
+// GAHold registra cuántas entradas de un sector se bloquearon bajo un hold. Comparte el
+// HoldID con los asientos del mismo carrito
+type GAHold struct {
+	BaseModel
+
+	HoldID     string       `gorm:"type:uuid;not null;index" json:"holdId"`
+	SectionID  string       `gorm:"type:uuid;not null;index" json:"sectionId"`
+	EventID    string       `gorm:"type:uuid;not null" json:"eventId"`
+	UserID     string       `gorm:"not null" json:"userId"`
+	Quantity   int          `gorm:"not null" json:"quantity"`
+	Status     GAHoldStatus `gorm:"type:varchar(20);default:'ACTIVE';index" json:"status"`
+	ExpiresAt  time.Time    `gorm:"index" json:"expiresAt"`
+	Extensions int          `gorm:"default:0" json:"extensions"`
+	TicketID   *string      `json:"ticketId,omitempty"` // Ticket que las incluye una vez vendidas
+}
+
+func (GAHold) TableName() string {
+	return "ga_holds"
+}
+
+// GAItem son las entradas de admisión general de un carrito u orden
+type GAItem struct {
+	SectionID string `json:"sectionId"`
+	Section   string `json:"section,omitempty"` // Nombre del sector, para mostrar
+	Quantity  int    `json:"quantity"`
+}
+
+// MergeGAItems suma las cantidades por sector y descarta las vacías, ordenado por sector
+func MergeGAItems(items []GAItem) []GAItem {
+	bySection := make(map[string]*GAItem, len(items))
+	for _, item := range items {
+		id := strings.TrimSpace(item.SectionID)
+		if id == "" || item.Quantity <= 0 {
+			continue
+		}
+		if merged, ok := bySection[id]; ok {
+			merged.Quantity += item.Quantity
+			continue
+		}
+		bySection[id] = &GAItem{SectionID: id, Section: item.Section, Quantity: item.Quantity}
+	}
+	merged := make([]GAItem, 0, len(bySection))
+	for _, item := range bySection {
+		merged = append(merged, *item)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].SectionID < merged[j].SectionID })
+	return merged
+}
+
+// FormatGAItems serializa las entradas para la metadata de la pasarela ("sector:2,sector:1")
+func FormatGAItems(items []GAItem) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range MergeGAItems(items) {
+		parts = append(parts, fmt.Sprintf("%s:%d", item.SectionID, item.Quantity))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseGAItems es la inversa de FormatGAItems; ignora los pares mal formados
+func ParseGAItems(raw string) []GAItem {
+	var items []GAItem
+	for _, part := range strings.Split(raw, ",") {
+		id, quantity, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(quantity)
+		if err != nil {
+			continue
+		}
+		items = append(items, GAItem{SectionID: id, Quantity: n})
+	}
+	return MergeGAItems(items)
+}
+
+// SameGAItems compara dos listas de entradas sin importar el orden ni cómo estén agrupadas
+func SameGAItems(a, b []GAItem) bool {
+	return FormatGAItems(a) == FormatGAItems(b)
+}
+
+// GATicketCount devuelve el total de entradas de admisión general
+func GATicketCount(items []GAItem) int {
+	total := 0
+	for _, item := range items {
+		total += item.Quantity
+	}
+	return total
+}
@@ -0,0 +1,41 @@
+package models
+
+import "testing"
+
+func TestGAItems_FormatParseAndCompare(t *testing.T) {
+	items := []GAItem{
+		{SectionID: "b", Section: "CAMPO VIP", Quantity: 1},
+		{SectionID: "a", Section: "CAMPO", Quantity: 2},
+		{SectionID: "b", Quantity: 2},
+		{SectionID: "c", Quantity: 0},
+	}
+
+	merged := MergeGAItems(items)
+	if len(merged) != 2 || merged[0].SectionID != "a" || merged[1].Quantity != 3 || merged[1].Section != "CAMPO VIP" {
+		t.Fatalf("unexpected merge: %+v", merged)
+	}
+	if got := FormatGAItems(items); got != "a:2,b:3" {
+		t.Fatalf("unexpected format: %q", got)
+	}
+	if got := ParseGAItems("b:3, a:2,bad,x:y"); !SameGAItems(got, items) {
+		t.Fatalf("expected parsed items to match, got %+v", got)
+	}
+	if SameGAItems(items, []GAItem{{SectionID: "a", Quantity: 2}}) {
+		t.Fatalf("expected different items")
+	}
+	if GATicketCount(merged) != 5 {
+		t.Fatalf("expected 5 tickets, got %d", GATicketCount(merged))
+	}
+	if len(ParseGAItems("")) != 0 {
+		t.Fatalf("expected no items")
+	}
+}
+
+func TestGASection_Available(t *testing.T) {
+	if got := (GASection{Capacity: 10, Held: 3, Sold: 5}).Available(); got != 2 {
+		t.Fatalf("expected 2, got %d", got)
+	}
+	if got := (GASection{Capacity: 1, Held: 1, Sold: 1}).Available(); got != 0 {
+		t.Fatalf("expected 0, got %d", got)
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+// OrderLineKind es el tipo de una línea de la orden
+type OrderLineKind string
+
+const (
+	LineTicket      OrderLineKind = "TICKET"       // Entrada a valor nominal
+	LineDiscount    OrderLineKind = "DISCOUNT"     // Descuento de un código promocional (negativo)
+	LineServiceFee  OrderLineKind = "SERVICE_FEE"  // Cargo por servicio
+	LineFacilityFee OrderLineKind = "FACILITY_FEE" // Cargo del recinto
+	LineTax         OrderLineKind = "TAX"          // Impuesto (IVA)
+)
+
+// OrderLine es una línea del detalle de la orden. Las líneas suman el total de la orden
+type OrderLine struct {
+	BaseModel
+
+	OrderID     string        `gorm:"type:uuid;not null;index" json:"orderId"`
+	Position    int           `gorm:"not null;default:0" json:"position"` // Orden para mostrar
+	Kind        OrderLineKind `gorm:"type:varchar(20);not null" json:"kind"`
+	ItemID      string        `json:"itemId,omitempty"` // Asiento, sector, regla de cargo o impuesto
+	Description string        `gorm:"not null" json:"description"`
+	Quantity    int           `gorm:"not null;default:1" json:"quantity"`
+	UnitAmount  int64         `gorm:"not null" json:"unitAmount"` // En centavos
+	Amount      int64         `gorm:"not null" json:"amount"`     // UnitAmount * Quantity
+	Currency    string        `gorm:"type:varchar(3);not null" json:"currency"`
+}
+
+func (OrderLine) TableName() string {
+	return "order_lines"
+}
+
+// OrderTotals resume las líneas de la orden por tipo, en centavos
+type OrderTotals struct {
+	FaceValue int64  `json:"faceValue"`
+	Discount  int64  `json:"discount"` // Negativo
+	Fees      int64  `json:"fees"`
+	Taxes     int64  `json:"taxes"`
+	Total     int64  `json:"total"`
+	Currency  string `json:"currency"`
+}
+
+// TotalsOf suma las líneas por tipo
+func TotalsOf(lines []OrderLine) OrderTotals {
+	var totals OrderTotals
+	for _, line := range lines {
+		switch line.Kind {
+		case LineTicket:
+			totals.FaceValue += line.Amount
+		case LineDiscount:
+			totals.Discount += line.Amount
+		case LineServiceFee, LineFacilityFee:
+			totals.Fees += line.Amount
+		case LineTax:
+			totals.Taxes += line.Amount
+		}
+		totals.Total += line.Amount
+		totals.Currency = line.Currency
+	}
+	return totals
+}
+
+// Breakdown agrupa las líneas para mostrarlas en el ticket y el email: todas las entradas en
+// una línea, todos los descuentos en otra y los cargos e impuestos por descripción, en el
+// orden original
+func Breakdown(lines []OrderLine) []OrderLine {
+	var grouped []OrderLine
+	index := make(map[string]int)
+	for _, line := range lines {
+		key := string(line.Kind) + "|" + line.Description
+		switch line.Kind {
+		case LineTicket:
+			key = string(LineTicket)
+			line.Description = "Entradas"
+		case LineDiscount:
+			key = string(LineDiscount)
+			line.Description = "Descuentos"
+		}
+		if i, ok := index[key]; ok {
+			grouped[i].Quantity += line.Quantity
+			grouped[i].Amount += line.Amount
+			continue
+		}
+		index[key] = len(grouped)
+		line.UnitAmount = 0
+		grouped = append(grouped, line)
+	}
+	return grouped
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// SalesPhase es la etapa de venta de un evento en un momento dado
+type SalesPhase string
+
+const (
+	SalesScheduled SalesPhase = "SCHEDULED" // Todavía no abrió ni la preventa
+	SalesPresale   SalesPhase = "PRESALE"   // Solo compran los usuarios con código de preventa
+	SalesOpen      SalesPhase = "ON_SALE"
+	SalesEnded     SalesPhase = "ENDED"
+)
+
+// SalesPhase calcula la etapa de venta del evento según SalesStart, SalesEnd y PresaleStart
+func (e Event) SalesPhase(now time.Time) SalesPhase {
+	switch {
+	case e.SalesEnd != nil && !now.Before(*e.SalesEnd):
+		return SalesEnded
+	case e.SalesStart == nil || !now.Before(*e.SalesStart):
+		return SalesOpen
+	case e.PresaleStart != nil && !now.Before(*e.PresaleStart):
+		return SalesPresale
+	default:
+		return SalesScheduled
+	}
+}
+
+// PresaleCode habilita la preventa de un evento a quien lo canjea. MaxUses = 1 es un código de
+// un solo uso; si UserID está, solo ese usuario puede canjearlo
+type PresaleCode struct {
+	BaseModel
+
+	EventID string  `gorm:"type:uuid;not null;uniqueIndex:idx_presale_code_event_code" json:"eventId"`
+	Code    string  `gorm:"not null;uniqueIndex:idx_presale_code_event_code" json:"code"` // Se guarda en mayúsculas
+	MaxUses int     `gorm:"not null;default:1" json:"maxUses"`
+	Uses    int     `gorm:"not null;default:0" json:"uses"`
+	UserID  *string `gorm:"index" json:"userId,omitempty"`
+}
+
+func (PresaleCode) TableName() string {
+	return "presale_codes"
+}
+
+// PresaleAccess registra que el usuario canjeó un código y puede comprar en la preventa
+type PresaleAccess struct {
+	BaseModel
+
+	EventID string `gorm:"type:uuid;not null;uniqueIndex:idx_presale_access_event_user" json:"eventId"`
+	UserID  string `gorm:"not null;uniqueIndex:idx_presale_access_event_user" json:"userId"`
+	CodeID  string `gorm:"type:uuid;not null;index" json:"codeId"`
+}
+
+func (PresaleAccess) TableName() string {
+	return "presale_accesses"
+}
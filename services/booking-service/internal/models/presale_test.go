@@ -0,0 +1,27 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSalesPhase(t *testing.T) {
+	at := func(h int) *time.Time {
+		v := time.Date(2026, 5, 1, h, 0, 0, 0, time.UTC)
+		return &v
+	}
+	event := models.Event{PresaleStart: at(10), SalesStart: at(12), SalesEnd: at(20)}
+
+	assert.Equal(t, models.SalesScheduled, event.SalesPhase(*at(9)))
+	assert.Equal(t, models.SalesPresale, event.SalesPhase(*at(10)))
+	assert.Equal(t, models.SalesOpen, event.SalesPhase(*at(12)))
+	assert.Equal(t, models.SalesEnded, event.SalesPhase(*at(20)))
+
+	// Sin ventana configurada se vende siempre
+	assert.Equal(t, models.SalesOpen, models.Event{}.SalesPhase(*at(9)))
+	// Sin preventa, antes de SalesStart no compra nadie
+	assert.Equal(t, models.SalesScheduled, models.Event{SalesStart: at(12)}.SalesPhase(*at(11)))
+}
@@ -0,0 +1,24 @@
+package models
+
+import "booking-service/pkg/money"
+
+// PriceTier es una categoría de asientos de un evento (VIP, Platea, ...) con su precio.
+// Los asientos la referencian con TierID y guardan una copia del precio al momento de venderse
+type PriceTier struct {
+	BaseModel
+
+	EventID     string      `gorm:"type:uuid;not null;index" json:"eventId"`
+	Name        string      `gorm:"not null" json:"name"`
+	Price       money.Money `gorm:"embedded;embeddedPrefix:price_" json:"price"` // En la moneda del evento
+	Color       string      `gorm:"type:varchar(20)" json:"color,omitempty"`     // Color para el mapa de asientos (ej: #FFD700)
+	Capacity    int         `gorm:"default:0" json:"capacity"`                   // Máximo de asientos de la categoría; 0 es sin límite
+	Description string      `json:"description,omitempty"`
+}
+
+// PriceTierUpdate son los datos editables de una categoría (PATCH); el precio se cambia con reprice
+type PriceTierUpdate struct {
+	Name        *string `json:"name"`
+	Color       *string `json:"color"`
+	Capacity    *int    `json:"capacity"`
+	Description *string `json:"description"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import "booking-service/pkg/money"
+
+// FeeType es el tipo de cargo por entrada
+type FeeType string
+
+const (
+	FeeService  FeeType = "SERVICE"  // Cargo por servicio de la ticketera
+	FeeFacility FeeType = "FACILITY" // Cargo del recinto
+)
+
+// FeeRule es un cargo por entrada: un porcentaje del valor nominal (RateBps, en puntos básicos:
+// 1000 = 10%) más un importe fijo. Es de un evento o de un recinto; si el evento tiene reglas
+// propias reemplazan a las de su recinto
+type FeeRule struct {
+	BaseModel
+
+	EventID *string `gorm:"type:uuid;index" json:"eventId,omitempty"`
+	VenueID *string `gorm:"type:uuid;index" json:"venueId,omitempty"`
+	Type    FeeType `gorm:"type:varchar(20);not null" json:"type"`
+	Name    string  `gorm:"not null" json:"name"` // Como se muestra al comprador
+	RateBps int     `gorm:"not null;default:0" json:"rateBps"`
+	// Importe fijo por entrada (columnas amount_amount y amount_currency)
+	Amount money.Money `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+}
+
+func (FeeRule) TableName() string {
+	return "fee_rules"
+}
+
+// LineKind devuelve el tipo de línea de orden que genera el cargo
+func (r FeeRule) LineKind() OrderLineKind {
+	if r.Type == FeeFacility {
+		return LineFacilityFee
+	}
+	return LineServiceFee
+}
+
+// FeeFor calcula el cargo de una entrada con ese valor nominal, en centavos
+func (r FeeRule) FeeFor(faceValue int64) int64 {
+	return r.Amount.Amount + applyBps(faceValue, r.RateBps)
+}
+
+// TaxRate es un impuesto (por ejemplo IVA 21%) que se suma sobre las líneas de la orden de los
+// tipos de AppliesTo (vacío: entradas y cargos). Como las reglas de cargos, es de un evento o
+// de un recinto
+type TaxRate struct {
+	BaseModel
+
+	EventID   *string         `gorm:"type:uuid;index" json:"eventId,omitempty"`
+	VenueID   *string         `gorm:"type:uuid;index" json:"venueId,omitempty"`
+	Name      string          `gorm:"not null" json:"name"`
+	RateBps   int             `gorm:"not null" json:"rateBps"` // 2100 = 21%
+	AppliesTo []OrderLineKind `gorm:"serializer:json" json:"appliesTo,omitempty"`
+}
+
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
+
+// Applies indica si el impuesto grava las líneas de ese tipo. Los descuentos siguen a las entradas
+func (t TaxRate) Applies(kind OrderLineKind) bool {
+	if kind == LineDiscount {
+		kind = LineTicket
+	}
+	if kind == LineTax {
+		return false
+	}
+	if len(t.AppliesTo) == 0 {
+		return true
+	}
+	for _, k := range t.AppliesTo {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// TaxOn calcula el impuesto sobre una base imponible, en centavos
+func (t TaxRate) TaxOn(base int64) int64 {
+	return applyBps(base, t.RateBps)
+}
+
+// applyBps calcula amount * bps / 10000 redondeando al centavo más cercano
+func applyBps(amount int64, bps int) int64 {
+	if amount <= 0 || bps <= 0 {
+		return 0
+	}
+	return (amount*int64(bps) + 5000) / 10000
+}
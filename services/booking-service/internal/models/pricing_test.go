@@ -0,0 +1,60 @@
+package models_test
+
+import (
+	"testing"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeRuleFeeFor(t *testing.T) {
+	rule := models.FeeRule{RateBps: 1000, Amount: money.New(150, "ARS")}
+	assert.Equal(t, int64(1150), rule.FeeFor(10000))
+	// 12,5% de 999 = 124,875: redondea a 125
+	assert.Equal(t, int64(125), models.FeeRule{RateBps: 1250}.FeeFor(999))
+	assert.Equal(t, models.LineServiceFee, models.FeeRule{}.LineKind())
+	assert.Equal(t, models.LineFacilityFee, models.FeeRule{Type: models.FeeFacility}.LineKind())
+}
+
+func TestTaxRateApplies(t *testing.T) {
+	all := models.TaxRate{RateBps: 2100}
+	assert.True(t, all.Applies(models.LineTicket))
+	assert.True(t, all.Applies(models.LineDiscount))
+	assert.True(t, all.Applies(models.LineServiceFee))
+	assert.False(t, all.Applies(models.LineTax))
+	assert.Equal(t, int64(2100), all.TaxOn(10000))
+	assert.Equal(t, int64(0), all.TaxOn(-500))
+
+	feesOnly := models.TaxRate{RateBps: 2100, AppliesTo: []models.OrderLineKind{models.LineServiceFee}}
+	assert.False(t, feesOnly.Applies(models.LineTicket))
+	assert.False(t, feesOnly.Applies(models.LineDiscount))
+	assert.True(t, feesOnly.Applies(models.LineServiceFee))
+	assert.False(t, feesOnly.Applies(models.LineFacilityFee))
+}
+
+func TestOrderLinesTotalsAndBreakdown(t *testing.T) {
+	lines := []models.OrderLine{
+		{Kind: models.LineTicket, Description: "Asiento 1 - PLATEA", Quantity: 1, UnitAmount: 10000, Amount: 10000, Currency: "ARS"},
+		{Kind: models.LineTicket, Description: "Admisión general - Campo", Quantity: 2, UnitAmount: 5000, Amount: 10000, Currency: "ARS"},
+		{Kind: models.LineDiscount, Description: "Descuento FANS - Asiento 1 - PLATEA", Quantity: 1, Amount: -1000, Currency: "ARS"},
+		{Kind: models.LineServiceFee, Description: "Cargo por servicio", Quantity: 1, UnitAmount: 1000, Amount: 1000, Currency: "ARS"},
+		{Kind: models.LineServiceFee, Description: "Cargo por servicio", Quantity: 2, UnitAmount: 500, Amount: 1000, Currency: "ARS"},
+		{Kind: models.LineFacilityFee, Description: "Cargo del recinto", Quantity: 3, UnitAmount: 100, Amount: 300, Currency: "ARS"},
+		{Kind: models.LineTax, Description: "IVA 21%", Quantity: 1, UnitAmount: 4683, Amount: 4683, Currency: "ARS"},
+	}
+
+	totals := models.TotalsOf(lines)
+	assert.Equal(t, models.OrderTotals{FaceValue: 20000, Discount: -1000, Fees: 2300, Taxes: 4683, Total: 25983, Currency: "ARS"}, totals)
+
+	rows := models.Breakdown(lines)
+	if assert.Len(t, rows, 5) {
+		assert.Equal(t, "Entradas", rows[0].Description)
+		assert.Equal(t, 3, rows[0].Quantity)
+		assert.Equal(t, int64(20000), rows[0].Amount)
+		assert.Equal(t, "Descuentos", rows[1].Description)
+		assert.Equal(t, int64(2000), rows[2].Amount)
+		assert.Equal(t, models.LineTax, rows[4].Kind)
+	}
+	assert.Equal(t, "Asiento 1 - PLATEA", lines[0].Description)
+}
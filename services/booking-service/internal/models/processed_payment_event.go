@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ProcessedPaymentEvent registra los eventos de pago ya aplicados (idempotencia
+// frente a reentregas de SQS y reintentos de Stripe)
+type ProcessedPaymentEvent struct {
+	EventID     string    `gorm:"primaryKey;type:varchar(255)" json:"eventId"`
+	EventType   string    `gorm:"type:varchar(100)" json:"eventType"`
+	OrderID     string    `gorm:"index" json:"orderId"`
+	ProcessedAt time.Time `gorm:"not null" json:"processedAt"`
+}
+
+func (ProcessedPaymentEvent) TableName() string {
+	return "processed_payment_events"
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"booking-service/pkg/money"
+	"strings"
+	"time"
+)
+
+// DiscountType indica cómo descuenta un código promocional
+type DiscountType string
+
+const (
+	DiscountPercent DiscountType = "PERCENT" // Porcentaje sobre cada entrada alcanzada
+	DiscountFixed   DiscountType = "FIXED"   // Importe fijo sobre el total de las entradas alcanzadas
+)
+
+// AdjustmentPromo es el tipo de ajuste de una línea descontada por un código promocional
+const AdjustmentPromo = "PROMO"
+
+// PromoCode es un código de descuento para el checkout. Sin EventID vale para todos los eventos;
+// sin Sections, para todas las secciones. MaxUses y MaxUsesPerUser en 0 no tienen tope
+type PromoCode struct {
+	BaseModel
+
+	Code    string       `gorm:"not null;uniqueIndex" json:"code"` // Se guarda en mayúsculas
+	EventID *string      `gorm:"type:uuid;index" json:"eventId,omitempty"`
+	Type    DiscountType `gorm:"type:varchar(10);not null" json:"type"`
+	// PERCENT: 1 a 100. FIXED: importe en la moneda del evento (columnas amount_off_*)
+	PercentOff int         `gorm:"not null;default:0" json:"percentOff,omitempty"`
+	AmountOff  money.Money `gorm:"embedded;embeddedPrefix:amount_off_" json:"amountOff"`
+
+	Sections    []string `gorm:"serializer:json" json:"sections,omitempty"`
+	MinQuantity int      `gorm:"not null;default:0" json:"minQuantity"` // Entradas alcanzadas mínimas
+
+	MaxUses        int `gorm:"not null;default:0" json:"maxUses"`
+	MaxUsesPerUser int `gorm:"not null;default:0" json:"maxUsesPerUser"`
+	Uses           int `gorm:"not null;default:0" json:"uses"` // Órdenes pendientes o pagadas que lo usan
+
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
+}
+
+func (PromoCode) TableName() string {
+	return "promo_codes"
+}
+
+// ActiveAt indica si el código está dentro de su período de validez
+func (p PromoCode) ActiveAt(now time.Time) bool {
+	if p.ValidFrom != nil && now.Before(*p.ValidFrom) {
+		return false
+	}
+	return p.ValidUntil == nil || now.Before(*p.ValidUntil)
+}
+
+// AppliesToSection indica si el código descuenta las entradas de la sección (sin distinguir mayúsculas)
+func (p PromoCode) AppliesToSection(section string) bool {
+	if len(p.Sections) == 0 {
+		return true
+	}
+	for _, s := range p.Sections {
+		if strings.EqualFold(strings.TrimSpace(s), strings.TrimSpace(section)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PromoRedemption registra el uso de un código en una orden. Si la orden falla, vence o se
+// cancela el registro se borra y el código recupera el uso
+type PromoRedemption struct {
+	BaseModel
+
+	PromoCodeID string `gorm:"type:uuid;not null;index" json:"promoCodeId"`
+	OrderID     string `gorm:"type:uuid;not null;uniqueIndex" json:"orderId"`
+	UserID      string `gorm:"not null;index" json:"userId"`
+	Amount      int64  `gorm:"not null;default:0" json:"amount"` // Descuento en centavos
+}
+
+func (PromoRedemption) TableName() string {
+	return "promo_redemptions"
+}
+
+// OrderAdjustment es un ajuste sobre una línea de la orden (asiento o sector de admisión
+// general). Amount es negativo para los descuentos y ya está restado del total de la orden
+type OrderAdjustment struct {
+	Type        string `json:"type"`
+	Code        string `json:"code,omitempty"`
+	ItemID      string `json:"itemId"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	Amount      int64  `json:"amount"` // En centavos, por todas las entradas de la línea
+}
+
+// Discount devuelve el total descontado en la orden, en centavos (positivo)
+func (o BookingOrder) Discount() int64 {
+	var total int64
+	for _, adj := range o.Adjustments {
+		total -= adj.Amount
+	}
+	return total
+}
+
+// DiscountFor devuelve lo descontado en un asiento (o en todo un sector de admisión general),
+// en centavos (positivo)
+func (o BookingOrder) DiscountFor(itemID string) int64 {
+	var total int64
+	for _, adj := range o.Adjustments {
+		if adj.ItemID == itemID {
+			total -= adj.Amount
+		}
+	}
+	return total
+}
@@ -0,0 +1,44 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoCodeActiveAt(t *testing.T) {
+	at := func(h int) *time.Time {
+		v := time.Date(2026, 5, 1, h, 0, 0, 0, time.UTC)
+		return &v
+	}
+	promo := models.PromoCode{ValidFrom: at(10), ValidUntil: at(20)}
+
+	assert.False(t, promo.ActiveAt(*at(9)))
+	assert.True(t, promo.ActiveAt(*at(10)))
+	assert.False(t, promo.ActiveAt(*at(20)))
+	assert.True(t, models.PromoCode{}.ActiveAt(*at(9)))
+}
+
+func TestPromoCodeAppliesToSection(t *testing.T) {
+	assert.True(t, models.PromoCode{}.AppliesToSection("PALCO"))
+
+	promo := models.PromoCode{Sections: []string{"Platea", "Campo"}}
+	assert.True(t, promo.AppliesToSection("PLATEA"))
+	assert.True(t, promo.AppliesToSection(" campo "))
+	assert.False(t, promo.AppliesToSection("PALCO"))
+}
+
+func TestBookingOrderDiscount(t *testing.T) {
+	order := models.BookingOrder{Adjustments: []models.OrderAdjustment{
+		{ItemID: "s1", Quantity: 1, Amount: -500},
+		{ItemID: "ga1", Quantity: 2, Amount: -666},
+		{ItemID: "ga1", Quantity: 1, Amount: -334},
+	}}
+
+	assert.Equal(t, int64(1500), order.Discount())
+	assert.Equal(t, int64(500), order.DiscountFor("s1"))
+	assert.Equal(t, int64(1000), order.DiscountFor("ga1"))
+	assert.Equal(t, int64(0), order.DiscountFor("s2"))
+}
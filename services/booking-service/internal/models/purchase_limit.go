@@ -0,0 +1,14 @@
+package models
+
+import "strings"
+
+// Buyer identifica al comprador para los límites de compra por evento
+type Buyer struct {
+	UserID string
+	Email  string
+}
+
+// NormalizeEmail deja el email en minúsculas y sin espacios para comparar compras
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,52 @@
+package models
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "PENDING"
+	RefundSucceeded RefundStatus = "SUCCEEDED"
+	RefundFailed    RefundStatus = "FAILED"
+)
+
+// Refund registra cada reembolso (total o parcial) emitido sobre una orden
+type Refund struct {
+	BaseModel
+
+	OrderID string   `gorm:"not null;index" json:"orderId"`
+	SeatIDs []string `gorm:"serializer:json" json:"seatIds"`
+	// Reembolso total: también devuelve las entradas de admisión general
+	IncludeGA bool `gorm:"not null;default:false" json:"includeGa"`
+
+	Amount   int64        `gorm:"not null" json:"amount"`
+	Currency string       `gorm:"type:varchar(10)" json:"currency"`
+	Status   RefundStatus `gorm:"type:varchar(20);default:'PENDING'" json:"status"`
+
+	// ID del reembolso en la pasarela de pago
+	ProviderRefundID string `gorm:"type:text" json:"providerRefundId,omitempty"`
+
+	Reason      string `gorm:"type:text" json:"reason,omitempty"`
+	RequestedBy string `gorm:"type:text" json:"requestedBy"`
+	Error       string `gorm:"type:text" json:"error,omitempty"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// ActiveSeatIDs devuelve los asientos de la orden que no fueron reembolsados
+func (o *BookingOrder) ActiveSeatIDs() []string {
+	if len(o.RefundedSeatIDs) == 0 {
+		return o.SeatIDs
+	}
+	refunded := make(map[string]struct{}, len(o.RefundedSeatIDs))
+	for _, id := range o.RefundedSeatIDs {
+		refunded[id] = struct{}{}
+	}
+	active := make([]string, 0, len(o.SeatIDs))
+	for _, id := range o.SeatIDs {
+		if _, ok := refunded[id]; !ok {
+			active = append(active, id)
+		}
+	}
+	return active
+}
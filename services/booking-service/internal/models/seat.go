@@ -1,6 +1,7 @@
 package models
 
 import (
+	"booking-service/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -21,6 +22,11 @@ const (
 	PaymentPending   PaymentStatus = "PENDING"
 	PaymentCompleted PaymentStatus = "COMPLETED"
 	PaymentFailed    PaymentStatus = "FAILED"
+	PaymentCancelled PaymentStatus = "CANCELLED"
+	PaymentExpired   PaymentStatus = "EXPIRED"
+
+	PaymentRefunded          PaymentStatus = "REFUNDED"
+	PaymentPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
 )
 
 type Availability string
@@ -60,14 +66,30 @@ type Event struct {
 	Description  string       `json:"description,omitempty"` // Opcional en JSON
 	Location     string       `json:"location"`
 	Date         time.Time    `json:"date"`
-	Price        int64        `gorm:"not null" json:"price"` // Precio base por entrada
+	Price        money.Money  `gorm:"embedded;embeddedPrefix:price_" json:"price"` // Precio base por entrada; su moneda es la del evento
 	PosterURL    string       `json:"posterUrl"`
 	Gender       string       `gorm:"type:varchar(20);default:'VARIOS'" json:"gender"`
 	// Disponibilidad del evento
 	// enums: HIGH, MEDIUM, LOW, SOLD_OUT
 	Availability Availability `gorm:"type:varchar(20);default:'HIGH'" json:"availability"`
-
-	Seats []Seat `gorm:"foreignKey:EventID" json:"seats,omitempty"`
+	// Recinto del que se generaron los asientos (opcional)
+	VenueID *string `gorm:"type:uuid;index" json:"venueId,omitempty"`
+	// Rechaza selecciones que dejan butacas sueltas en una fila (regla "orphan")
+	PreventOrphanSeats bool `gorm:"default:false" json:"preventOrphanSeats"`
+	// Admisiones por minuto a la selección de asientos; 0 desactiva la sala de espera
+	WaitingRoomRate int `gorm:"default:0" json:"waitingRoomRate"`
+	// Ventana de venta: sin SalesStart se vende desde que hay asientos, sin SalesEnd hasta el evento
+	SalesStart *time.Time `json:"salesStart,omitempty"`
+	SalesEnd   *time.Time `json:"salesEnd,omitempty"`
+	// Preventa: desde PresaleStart hasta SalesStart compran solo los usuarios con código de preventa
+	PresaleStart *time.Time `json:"presaleStart,omitempty"`
+	// Límites de compra por evento entre órdenes PENDING y COMPLETED; 0 es sin límite
+	MaxTicketsPerUser  int `gorm:"default:0" json:"maxTicketsPerUser"`
+	MaxTicketsPerEmail int `gorm:"default:0" json:"maxTicketsPerEmail"`
+	MaxTicketsPerCard  int `gorm:"default:0" json:"maxTicketsPerCard"`
+
+	Seats []Seat      `gorm:"foreignKey:EventID" json:"seats,omitempty"`
+	Tiers []PriceTier `gorm:"foreignKey:EventID" json:"tiers,omitempty"`
 }
 
 // Seat representa un asiento específico en un evento
@@ -78,15 +100,32 @@ type Seat struct {
 	Section string `json:"section"`                // "VIP", "Platea", "General"
 	Number  string `gorm:"not null" json:"number"` // "10", "A1", etc.
 
-	Price float64 `json:"price"` 
+	// Categoría de precio (opcional); si está, Section y Price salen de ella
+	TierID *string `gorm:"type:uuid;index" json:"tierId,omitempty"`
+
+	// Ubicación en el plano (se copia del Venue al generar los asientos)
+	Row        string  `json:"row,omitempty"`
+	Position   int     `gorm:"default:0" json:"position,omitempty"` // Orden dentro de la fila
+	X          float64 `json:"x,omitempty"`
+	Y          float64 `json:"y,omitempty"`
+	AisleLeft  bool    `gorm:"default:false" json:"aisleLeft,omitempty"`
+	AisleRight bool    `gorm:"default:false" json:"aisleRight,omitempty"`
+
+	// Precio en unidades menores, en la moneda del evento
+	Price money.Money `gorm:"embedded;embeddedPrefix:price_" json:"price"`
 
 	// Estado actual del asiento
 	// enums: AVAILABLE, RESERVED, SOLD, BLOCKED
 	Status SeatStatus `gorm:"type:varchar(20);default:'AVAILABLE'" json:"status"`
 
 	// Control de Bloqueo Temporal
-	LockedBy *string    `gorm:"type:text" json:"lockedBy"` // Quien lo bloquea (uuid)
-	LockedAt *time.Time `json:"lockedAt"`                  // Cuando se desbloquea
+	LockedBy      *string    `gorm:"type:text" json:"lockedBy"`    // Quien lo bloquea (uuid)
+	LockedAt      *time.Time `json:"lockedAt"`                     // Cuando se bloqueó
+	LockExpiresAt *time.Time `gorm:"index" json:"lockExpiresAt"` // Cuando vence el bloqueo
+
+	// Hold: agrupa los asientos bloqueados juntos (para extender o liberar)
+	HoldID         *string `gorm:"type:uuid;index" json:"holdId,omitempty"`
+	HoldExtensions int     `gorm:"default:0" json:"holdExtensions"`
 
 	// Relaciones
 	EventID  string  `gorm:"not null" json:"eventId"`
@@ -100,15 +139,48 @@ type Seat struct {
 type BookingOrder struct {
 	BaseModel
 
-	UserID string        `gorm:"not null" json:"userId"`
-	Amount int64         `gorm:"not null" json:"amount"`
+	UserID string `gorm:"not null" json:"userId"`
+	// Total de la orden (columnas amount y currency)
+	Total  money.Money   `gorm:"embedded" json:"total"`
 	Status PaymentStatus `gorm:"default:'PENDING'" json:"status"`
+	// Versión para control de concurrencia optimista: se incrementa en cada cambio de estado
+	Version int `gorm:"not null;default:1" json:"version"`
 
 	// Asientos involucrados en esta orden
 	//SeatIDs []string `gorm:"type:text[]" json:"seatIds"`
 	SeatIDs []string `gorm:"serializer:json" json:"seatIds"`
 	Items   []Seat   `gorm:"-" json:"items,omitempty"`
-
+	// Entradas de admisión general (sectores con cupo, sin asiento asignado)
+	GAItems []GAItem `gorm:"serializer:json" json:"gaItems,omitempty"`
+
+	// Evento y cantidad de entradas de la orden, para los límites de compra
+	EventID  *string `gorm:"type:uuid;index" json:"eventId,omitempty"`
+	Quantity int     `gorm:"not null;default:0" json:"quantity"`
+	// Email del comprador (del JWT al crear la orden, o el del pagador) y huella de la tarjeta
+	CustomerEmail   string `gorm:"index" json:"customerEmail,omitempty"`
+	CardFingerprint string `gorm:"index" json:"-"`
+
+	// Código promocional aplicado y descuentos por línea (ya restados de Total)
+	PromoCodeID *string           `gorm:"type:uuid;index" json:"promoCodeId,omitempty"`
+	PromoCode   string            `json:"promoCode,omitempty"`
+	Adjustments []OrderAdjustment `gorm:"serializer:json" json:"adjustments,omitempty"`
+
+	// Detalle de la orden: entradas, descuentos, cargos e impuestos que suman Total
+	Lines   []OrderLine  `gorm:"foreignKey:OrderID" json:"lines,omitempty"`
+	Summary *OrderTotals `gorm:"-" json:"summary,omitempty"`
+
+	// Asientos e importe ya reembolsados (reembolsos parciales o totales)
+	RefundedSeatIDs []string `gorm:"serializer:json" json:"refundedSeatIds,omitempty"`
+	RefundedAmount  int64    `gorm:"default:0" json:"refundedAmount"` // En la moneda de Total
+
+	// Hold con el que se bloquearon los asientos de la orden
+	HoldID *string `gorm:"type:uuid;index" json:"holdId,omitempty"`
+
+	// Pasarela que maneja el pago de la orden (STRIPE, MERCADOPAGO, ...)
+	PaymentProvider string `gorm:"type:varchar(50)" json:"paymentProvider,omitempty"`
+	// Sesión de pago abierta en la pasarela (checkout session o preferencia), para cerrarla
+	// si la orden se cancela antes de pagarse
+	PaymentSessionID string `gorm:"type:varchar(255)" json:"paymentSessionId,omitempty"`
 	// Token o ID de transacción de la pasarela de pago (Stripe/MercadoPago)
 	PaymentProviderID string `json:"paymentProviderId,omitempty"`
 	EventName         string `gorm:"-" json:"eventName,omitempty"`
@@ -126,8 +198,8 @@ type Checkout struct {
 	PaymentProvider string `gorm:"type:varchar(50);default:'STRIPE'" json:"paymentProvider"`
 	PaymentIntentID string `gorm:"type:text;not null" json:"paymentIntentId"`
 
-	Currency string `gorm:"type:varchar(10);not null" json:"currency"`
-	Amount   int64  `gorm:"not null" json:"amount"`
+	// Importe cobrado (columnas amount y currency)
+	Total money.Money `gorm:"embedded" json:"total"`
 
 	CustomerEmail string `gorm:"type:text;not null" json:"customerEmail"`
 	CustomerName  string `gorm:"type:text;not null" json:"customerName"`
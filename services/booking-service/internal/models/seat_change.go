@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// SeatChange es un cambio de estado de un asiento ya confirmado en la base. Se publica en el
+// bus interno para actualizar los mapas de asientos en tiempo real (no se persiste)
+type SeatChange struct {
+	Seq           uint64     `json:"seq"` // Secuencia por evento, la asigna el bus
+	EventID       string     `json:"eventId"`
+	SeatID        string     `json:"seatId"`
+	Status        SeatStatus `json:"status"`
+	LockExpiresAt *time.Time `json:"lockExpiresAt,omitempty"`
+	At            time.Time  `json:"at"`
+}
+
+// SeatChangesFor arma los cambios de los asientos indicados al nuevo estado
+func SeatChangesFor(seats []Seat, status SeatStatus, lockExpiresAt *time.Time) []SeatChange {
+	now := time.Now()
+	changes := make([]SeatChange, 0, len(seats))
+	for _, seat := range seats {
+		changes = append(changes, SeatChange{
+			EventID:       seat.EventID,
+			SeatID:        seat.ID,
+			Status:        status,
+			LockExpiresAt: lockExpiresAt,
+			At:            now,
+		})
+	}
+	return changes
+}
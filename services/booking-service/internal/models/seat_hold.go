@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SeatHold es la vista de un bloqueo temporal de uno o más asientos (no se persiste,
+// se deriva de las columnas hold_* de seats y de los ga_holds con el mismo ID)
+type SeatHold struct {
+	ID         string    `json:"holdId"`
+	UserID     string    `json:"userId"`
+	SeatIDs    []string  `json:"seatIds"`
+	GAItems    []GAItem  `json:"gaItems,omitempty"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Extensions int       `json:"extensions"`
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"booking-service/internal/models"
+	"booking-service/pkg/money"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,14 +24,14 @@ func TestSeatStructMapping(t *testing.T) {
 	seat := models.Seat{
 		Section: "VIP",
 		Number:  "A1",
-		Price:   100.5,
+		Price:   money.New(10050, "ARS"),
 		Status:  models.StatusAvailable,
 		EventID: "event-123",
 	}
 
 	assert.Equal(t, "VIP", seat.Section)
 	assert.Equal(t, "A1", seat.Number)
-	assert.Equal(t, 100.5, seat.Price)
+	assert.Equal(t, int64(10050), seat.Price.Amount)
 	assert.Equal(t, models.StatusAvailable, seat.Status)
 	assert.Equal(t, "event-123", seat.EventID)
 }
@@ -20,7 +20,10 @@ type TicketPDF struct {
 	EventName string `gorm:"-" json:"eventName,omitempty"`
 	EventHour string `gorm:"-" json:"eventHour,omitempty"`
 
-	Items []Seat `gorm:"-" json:"items,omitempty"`
+	Items   []Seat   `gorm:"-" json:"items,omitempty"`
+	GAItems []GAItem `gorm:"-" json:"gaItems,omitempty"`
+	// Detalle de la orden (entradas, descuentos, cargos e impuestos) para el comprobante
+	Lines []OrderLine `gorm:"-" json:"lines,omitempty"`
 
 	PDFData        []byte     `gorm:"type:bytea" json:"-"`
 	PDFGeneratedAt *time.Time `gorm:"type:timestamp" json:"pdfGeneratedAt,omitempty"`
@@ -0,0 +1,52 @@
+package models
+
+// Venue es un recinto reutilizable con su plano de asientos
+type Venue struct {
+	BaseModel
+
+	Name    string      `gorm:"not null" json:"name"`
+	Address string      `json:"address,omitempty"`
+	Seats   []VenueSeat `gorm:"foreignKey:VenueID" json:"seats,omitempty"`
+}
+
+// VenueSeat es una butaca del plano: sección, fila, número y coordenadas para dibujar el mapa
+type VenueSeat struct {
+	BaseModel
+
+	VenueID  string `gorm:"type:uuid;not null;uniqueIndex:idx_venue_seat" json:"venueId"`
+	Section  string `gorm:"not null;uniqueIndex:idx_venue_seat" json:"section"`
+	Row      string `gorm:"uniqueIndex:idx_venue_seat" json:"row"`
+	Number   string `gorm:"not null;uniqueIndex:idx_venue_seat" json:"number"`
+	Position int    `gorm:"not null;default:0" json:"position"` // Orden dentro de la fila (1..n)
+
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	// Pasillo a la izquierda/derecha de la butaca
+	AisleLeft  bool `gorm:"default:false" json:"aisleLeft,omitempty"`
+	AisleRight bool `gorm:"default:false" json:"aisleRight,omitempty"`
+}
+
+// VenueLayout es el formato JSON de importación: secciones > filas > butacas (en orden)
+type VenueLayout struct {
+	Name     string               `json:"name"`
+	Address  string               `json:"address,omitempty"`
+	Sections []VenueLayoutSection `json:"sections"`
+}
+
+type VenueLayoutSection struct {
+	Name string           `json:"name"`
+	Rows []VenueLayoutRow `json:"rows"`
+}
+
+type VenueLayoutRow struct {
+	Label string            `json:"label"`
+	Seats []VenueLayoutSeat `json:"seats"`
+}
+
+type VenueLayoutSeat struct {
+	Number     string  `json:"number"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	AisleLeft  bool    `json:"aisleLeft,omitempty"`
+	AisleRight bool    `json:"aisleRight,omitempty"`
+}
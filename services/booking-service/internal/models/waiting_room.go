@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// WaitingRoomEntry es el lugar de un usuario en la sala de espera de un evento. AdmitAt se
+// calcula al entrar (goteo a WaitingRoomRate por minuto) y la admisión vale hasta ExpiresAt
+type WaitingRoomEntry struct {
+	BaseModel
+
+	EventID   string    `gorm:"type:uuid;not null;uniqueIndex:idx_waiting_room_event_user;index:idx_waiting_room_event_admit,priority:1" json:"eventId"`
+	UserID    string    `gorm:"not null;uniqueIndex:idx_waiting_room_event_user" json:"userId"`
+	Position  int64     `gorm:"not null" json:"position"` // Orden de llegada dentro del evento
+	AdmitAt   time.Time `gorm:"not null;index:idx_waiting_room_event_admit,priority:2" json:"admitAt"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
+}
+
+func (WaitingRoomEntry) TableName() string {
+	return "waiting_room_entries"
+}
+
+// QueuePass son los datos firmados en el token de la sala de espera
+type QueuePass struct {
+	EntryID   string
+	EventID   string
+	UserID    string
+	Position  int64
+	AdmitAt   time.Time
+	ExpiresAt time.Time
+}
+
+// Admitted indica si el pase ya habilita la selección de asientos
+func (p QueuePass) Admitted(now time.Time) bool {
+	return !now.Before(p.AdmitAt) && now.Before(p.ExpiresAt)
+}
+
+// QueueStatus es lo que ve el usuario de su lugar en la fila
+type QueueStatus struct {
+	Token    string `json:"token,omitempty"`
+	Admitted bool   `json:"admitted"`
+	// Personas delante más uno mientras espera; 0 una vez admitido
+	Position             int64      `json:"position"`
+	AdmitAt              *time.Time `json:"admitAt,omitempty"`
+	ExpiresAt            *time.Time `json:"expiresAt,omitempty"`
+	EstimatedWaitSeconds int64      `json:"estimatedWaitSeconds"`
+}
@@ -0,0 +1,76 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+var ErrBroadcasterClosed = errors.New("broadcaster closed")
+
+// Tipos de RoomMessage que viajan entre réplicas
+const (
+	RoomPresence = "presence" // Asientos que mira una conexión (reemplaza los anteriores)
+	RoomLeave    = "leave"    // La conexión salió de la sala
+	RoomSeats    = "seats"    // Cambios de estado de asientos
+)
+
+// RoomMessage es lo que una réplica le avisa a todas las demás (y a sí misma) sobre la sala de
+// un evento. ConnID identifica la conexión WebSocket que originó el mensaje de presencia
+type RoomMessage struct {
+	Type    string              `json:"type"`
+	EventID string              `json:"eventId"`
+	ConnID  string              `json:"connId,omitempty"`
+	SeatIDs []string            `json:"seatIds,omitempty"`
+	Changes []models.SeatChange `json:"changes,omitempty"`
+	At      time.Time           `json:"at"`
+}
+
+// Broadcaster reparte los mensajes de las salas entre todas las réplicas del servicio.
+// Publish también entrega el mensaje a los handlers de la réplica que lo publica
+type Broadcaster interface {
+	Publish(ctx context.Context, msg RoomMessage) error
+	Subscribe(handler func(RoomMessage))
+	Close() error
+}
+
+// MemoryBroadcaster entrega los mensajes dentro del mismo proceso; sirve para tests y para
+// correr una sola réplica
+type MemoryBroadcaster struct {
+	mu       sync.RWMutex
+	handlers []func(RoomMessage)
+	closed   bool
+}
+
+// Constructor
+func NewMemoryBroadcaster() *MemoryBroadcaster {
+	return &MemoryBroadcaster{}
+}
+
+func (b *MemoryBroadcaster) Publish(_ context.Context, msg RoomMessage) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrBroadcasterClosed
+	}
+	for _, handler := range b.handlers {
+		handler(msg)
+	}
+	return nil
+}
+
+func (b *MemoryBroadcaster) Subscribe(handler func(RoomMessage)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *MemoryBroadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
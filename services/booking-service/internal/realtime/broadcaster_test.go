@@ -0,0 +1,38 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMemoryBroadcaster_DeliversToAllHandlers(t *testing.T) {
+	b := NewMemoryBroadcaster()
+	var got []string
+	b.Subscribe(func(msg RoomMessage) { got = append(got, "a:"+msg.EventID) })
+	b.Subscribe(func(msg RoomMessage) { got = append(got, "b:"+msg.EventID) })
+
+	if err := b.Publish(context.Background(), RoomMessage{Type: RoomSeats, EventID: "e1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected both handlers called, got %v", got)
+	}
+
+	_ = b.Close()
+	if err := b.Publish(context.Background(), RoomMessage{}); !errors.Is(err, ErrBroadcasterClosed) {
+		t.Fatalf("expected closed error, got %v", err)
+	}
+}
+
+func TestPostgresBroadcaster_RejectsOversizedPayload(t *testing.T) {
+	b := NewPostgresBroadcaster(nil, "", "")
+	if b.channel != DefaultNotifyChannel {
+		t.Fatalf("expected default channel, got %q", b.channel)
+	}
+	msg := RoomMessage{Type: RoomPresence, EventID: "e1", SeatIDs: []string{strings.Repeat("x", maxNotifyPayload)}}
+	if err := b.Publish(context.Background(), msg); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("expected too large error, got %v", err)
+	}
+}
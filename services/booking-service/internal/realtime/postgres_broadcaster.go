@@ -0,0 +1,139 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// Canal de LISTEN/NOTIFY por defecto para las salas de asientos
+const DefaultNotifyChannel = "seat_rooms"
+
+// Postgres rechaza payloads de NOTIFY de 8000 bytes o más
+const maxNotifyPayload = 7900
+
+var ErrMessageTooLarge = errors.New("room message exceeds notify payload limit")
+
+// PostgresBroadcaster reparte los mensajes entre réplicas con LISTEN/NOTIFY. Publica con el
+// pool de gorm y escucha con una conexión dedicada que se reconecta si se corta
+type PostgresBroadcaster struct {
+	db      *gorm.DB
+	dsn     string
+	channel string
+
+	mu       sync.RWMutex
+	handlers []func(RoomMessage)
+	closed   bool
+}
+
+// Constructor
+func NewPostgresBroadcaster(db *gorm.DB, dsn, channel string) *PostgresBroadcaster {
+	if channel == "" {
+		channel = DefaultNotifyChannel
+	}
+	return &PostgresBroadcaster{db: db, dsn: dsn, channel: channel}
+}
+
+func (b *PostgresBroadcaster) Publish(ctx context.Context, msg RoomMessage) error {
+	b.mu.RLock()
+	closed := b.closed
+	b.mu.RUnlock()
+	if closed {
+		return ErrBroadcasterClosed
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		return ErrMessageTooLarge
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+func (b *PostgresBroadcaster) Subscribe(handler func(RoomMessage)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *PostgresBroadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// Start lanza el listener en segundo plano. El canal devuelto se cierra cuando el listener
+// terminó luego de cancelar el contexto
+func (b *PostgresBroadcaster) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		backoff := time.Second
+		for ctx.Err() == nil {
+			err := b.listen(ctx)
+			if ctx.Err() != nil {
+				break
+			}
+			log.Printf("⚠️ Listener de salas desconectado, reintentando en %v: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+		log.Println("🛑 Listener de salas detenido")
+	}()
+
+	return done
+}
+
+// listen abre la conexión, hace LISTEN y entrega las notificaciones hasta que falle
+func (b *PostgresBroadcaster) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var msg RoomMessage
+		if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			log.Printf("⚠️ Mensaje de sala inválido: %v", err)
+			continue
+		}
+		b.deliver(msg)
+	}
+}
+
+func (b *PostgresBroadcaster) deliver(msg RoomMessage) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, handler := range b.handlers {
+		handler(msg)
+	}
+}
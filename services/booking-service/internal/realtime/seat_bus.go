@@ -0,0 +1,279 @@
+package realtime
+
+import (
+	"booking-service/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrBusClosed = errors.New("seat bus closed")
+
+const (
+	DefaultHistorySize = 1000
+	DefaultBufferSize  = 256
+	// Tiempo sin suscriptores ni cambios tras el cual se descarta el historial de un evento
+	DefaultStreamIdle = 10 * time.Minute
+)
+
+// SeatBus reparte en memoria los cambios de asientos entre los suscriptores de cada evento.
+// Guarda los últimos cambios de cada evento para que un cliente que se reconecta pueda
+// retomar desde su Last-Event-ID sin volver a pedir el mapa completo. Los cambios llegan por
+// el Broadcaster, así que cada réplica ve también los confirmados por las demás
+type SeatBus struct {
+	mu      sync.Mutex
+	epoch   string // Distingue los IDs de esta instancia de los de un proceso anterior
+	history int
+	buffer  int
+	idle    time.Duration
+	now     func() time.Time
+	streams map[string]*eventStream
+	closed  bool
+}
+
+type eventStream struct {
+	seq      uint64
+	history  []models.SeatChange
+	subs     map[*Subscription]struct{}
+	activeAt time.Time // Último cambio, suscripción o desconexión
+}
+
+// Subscription es un cliente escuchando los cambios de un evento. El canal se cierra si el
+// cliente no consume a tiempo (Lagged), si se cierra el bus o al llamar a Close
+type Subscription struct {
+	EventID string
+
+	bus    *SeatBus
+	ch     chan models.SeatChange
+	lagged bool
+	done   bool
+}
+
+// NewSeatBus crea el bus. history es la cantidad de cambios que se guardan por evento para
+// retomar y buffer la cantidad de cambios pendientes que se toleran por cliente
+func NewSeatBus(history, buffer int) *SeatBus {
+	if history <= 0 {
+		history = DefaultHistorySize
+	}
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+	return &SeatBus{
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		history: history,
+		buffer:  buffer,
+		idle:    DefaultStreamIdle,
+		now:     time.Now,
+		streams: make(map[string]*eventStream),
+	}
+}
+
+// Listen suscribe el bus a los cambios de asientos que reparte el broadcaster, el mismo que
+// usan las salas WebSocket. Con el broadcaster de Postgres el stream SSE de una réplica
+// recibe los cambios confirmados en cualquier otra
+func (b *SeatBus) Listen(broadcaster Broadcaster) {
+	broadcaster.Subscribe(func(msg RoomMessage) {
+		if msg.Type == RoomSeats {
+			b.PublishSeatChanges(msg.Changes)
+		}
+	})
+}
+
+// Start lanza la limpieza de los eventos sin suscriptores ni cambios recientes. El canal
+// devuelto se cierra cuando el worker terminó luego de cancelar el contexto
+func (b *SeatBus) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(b.idle / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.prune()
+			}
+		}
+	}()
+
+	return done
+}
+
+// PublishSeatChanges asigna la secuencia de cada cambio y lo reparte a los suscriptores del
+// evento. Nunca bloquea: el cliente que tiene el buffer lleno se desconecta para que
+// se reconecte y retome desde el historial
+func (b *SeatBus) PublishSeatChanges(changes []models.SeatChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	for _, change := range changes {
+		if change.EventID == "" {
+			continue
+		}
+		stream := b.stream(change.EventID)
+		stream.seq++
+		stream.activeAt = b.now()
+		change.Seq = stream.seq
+
+		stream.history = append(stream.history, change)
+		if len(stream.history) > b.history {
+			stream.history = stream.history[len(stream.history)-b.history:]
+		}
+
+		for sub := range stream.subs {
+			select {
+			case sub.ch <- change:
+			default:
+				sub.lagged = true
+				b.drop(stream, sub)
+			}
+		}
+	}
+}
+
+// Subscribe registra un suscriptor del evento. Si lastEventID viene de esta instancia y sigue
+// en el historial devuelve los cambios posteriores para reenviar; si no se puede retomar
+// resumed es false y el cliente debe volver a pedir el mapa completo
+func (b *SeatBus) Subscribe(eventID, lastEventID string) (sub *Subscription, backlog []models.SeatChange, resumed bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil, false, ErrBusClosed
+	}
+
+	stream := b.stream(eventID)
+	stream.activeAt = b.now()
+	resumed = true
+	if lastEventID != "" {
+		backlog, resumed = b.backlog(stream, lastEventID)
+	}
+
+	sub = &Subscription{
+		EventID: eventID,
+		bus:     b,
+		ch:      make(chan models.SeatChange, b.buffer),
+	}
+	stream.subs[sub] = struct{}{}
+	return sub, backlog, resumed, nil
+}
+
+// EventIDFor es el ID SSE de un cambio publicado por este bus
+func (b *SeatBus) EventIDFor(change models.SeatChange) string {
+	return fmt.Sprintf("%s-%d", b.epoch, change.Seq)
+}
+
+// Subscribers devuelve la cantidad de clientes conectados a un evento
+func (b *SeatBus) Subscribers(eventID string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if stream, ok := b.streams[eventID]; ok {
+		return len(stream.subs)
+	}
+	return 0
+}
+
+// Close desconecta a todos los suscriptores; se usa al apagar el servidor para que las
+// conexiones SSE no demoren el shutdown
+func (b *SeatBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, stream := range b.streams {
+		for sub := range stream.subs {
+			b.drop(stream, sub)
+		}
+	}
+}
+
+// C es el canal con los cambios del evento
+func (s *Subscription) C() <-chan models.SeatChange {
+	return s.ch
+}
+
+// Lagged indica si el bus desconectó al cliente por no consumir a tiempo
+func (s *Subscription) Lagged() bool {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.lagged
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if stream, ok := s.bus.streams[s.EventID]; ok {
+		s.bus.drop(stream, s)
+	}
+}
+
+func (b *SeatBus) stream(eventID string) *eventStream {
+	stream, ok := b.streams[eventID]
+	if !ok {
+		stream = &eventStream{subs: make(map[*Subscription]struct{})}
+		b.streams[eventID] = stream
+	}
+	return stream
+}
+
+// drop saca al suscriptor y cierra su canal; se llama con el lock tomado
+func (b *SeatBus) drop(stream *eventStream, sub *Subscription) {
+	if sub.done {
+		return
+	}
+	sub.done = true
+	delete(stream.subs, sub)
+	stream.activeAt = b.now()
+	close(sub.ch)
+}
+
+// prune descarta los eventos sin suscriptores ni actividad dentro de idle. Un cliente que
+// retome un evento descartado recibe reset porque su secuencia ya no existe
+func (b *SeatBus) prune() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cutoff := b.now().Add(-b.idle)
+	for eventID, stream := range b.streams {
+		if len(stream.subs) == 0 && stream.activeAt.Before(cutoff) {
+			delete(b.streams, eventID)
+		}
+	}
+}
+
+// backlog devuelve los cambios posteriores a lastEventID; se llama con el lock tomado
+func (b *SeatBus) backlog(stream *eventStream, lastEventID string) ([]models.SeatChange, bool) {
+	epoch, rawSeq, ok := strings.Cut(lastEventID, "-")
+	if !ok || epoch != b.epoch {
+		return nil, false
+	}
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if err != nil || seq > stream.seq {
+		return nil, false
+	}
+	if seq == stream.seq {
+		return nil, true
+	}
+	// El cambio siguiente al último recibido tiene que seguir en el historial
+	if len(stream.history) == 0 || stream.history[0].Seq > seq+1 {
+		return nil, false
+	}
+
+	start := int(seq + 1 - stream.history[0].Seq)
+	backlog := make([]models.SeatChange, len(stream.history)-start)
+	copy(backlog, stream.history[start:])
+	return backlog, true
+}
@@ -0,0 +1,160 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+func change(eventID, seatID string) models.SeatChange {
+	return models.SeatChange{EventID: eventID, SeatID: seatID, Status: models.StatusLocked}
+}
+
+func TestSeatBus_FanOutPerEvent(t *testing.T) {
+	bus := NewSeatBus(10, 10)
+	a, _, _, _ := bus.Subscribe("e1", "")
+	b, _, _, _ := bus.Subscribe("e2", "")
+
+	bus.PublishSeatChanges([]models.SeatChange{change("e1", "s1"), change("e2", "s2"), change("e1", "s3")})
+
+	if got := <-a.C(); got.SeatID != "s1" || got.Seq != 1 {
+		t.Fatalf("unexpected change: %+v", got)
+	}
+	if got := <-a.C(); got.SeatID != "s3" || got.Seq != 2 {
+		t.Fatalf("unexpected change: %+v", got)
+	}
+	if got := <-b.C(); got.SeatID != "s2" || got.Seq != 1 {
+		t.Fatalf("unexpected change: %+v", got)
+	}
+	if len(b.C()) != 0 {
+		t.Fatalf("e2 subscriber received changes of e1")
+	}
+}
+
+func TestSeatBus_ResumeFromLastEventID(t *testing.T) {
+	bus := NewSeatBus(3, 10)
+	var published []models.SeatChange
+	for _, id := range []string{"s1", "s2", "s3", "s4"} {
+		bus.PublishSeatChanges([]models.SeatChange{change("e1", id)})
+		published = append(published, models.SeatChange{Seq: uint64(len(published) + 1)})
+	}
+
+	// Retoma desde el 2: el 3 y el 4 siguen en el historial
+	_, backlog, resumed, err := bus.Subscribe("e1", bus.EventIDFor(published[1]))
+	if err != nil || !resumed || len(backlog) != 2 || backlog[0].SeatID != "s3" || backlog[1].SeatID != "s4" {
+		t.Fatalf("unexpected resume: resumed=%v backlog=%+v err=%v", resumed, backlog, err)
+	}
+
+	// Al día: nada para reenviar
+	_, backlog, resumed, _ = bus.Subscribe("e1", bus.EventIDFor(published[3]))
+	if !resumed || len(backlog) != 0 {
+		t.Fatalf("expected up to date resume, got resumed=%v backlog=%+v", resumed, backlog)
+	}
+
+	cases := map[string]string{
+		"too old":       bus.EventIDFor(models.SeatChange{Seq: 0}),
+		"other process": "otro-2",
+		"future seq":    bus.EventIDFor(models.SeatChange{Seq: 9}),
+		"malformed":     "abc",
+	}
+	for name, lastID := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, backlog, resumed, _ := bus.Subscribe("e1", lastID)
+			if resumed || len(backlog) != 0 {
+				t.Fatalf("expected reset, got resumed=%v backlog=%+v", resumed, backlog)
+			}
+		})
+	}
+}
+
+func TestSeatBus_DropsLaggedSubscriber(t *testing.T) {
+	bus := NewSeatBus(10, 1)
+	slow, _, _, _ := bus.Subscribe("e1", "")
+	fast, _, _, _ := bus.Subscribe("e1", "")
+
+	bus.PublishSeatChanges([]models.SeatChange{change("e1", "s1")})
+	<-fast.C()
+	bus.PublishSeatChanges([]models.SeatChange{change("e1", "s2")})
+
+	<-slow.C()
+	if _, open := <-slow.C(); open || !slow.Lagged() {
+		t.Fatalf("expected slow subscriber dropped as lagged")
+	}
+	if got := <-fast.C(); got.SeatID != "s2" || fast.Lagged() {
+		t.Fatalf("unexpected change for fast subscriber: %+v", got)
+	}
+	if n := bus.Subscribers("e1"); n != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", n)
+	}
+}
+
+func TestSeatBus_Close(t *testing.T) {
+	bus := NewSeatBus(0, 0)
+	sub, _, _, _ := bus.Subscribe("e1", "")
+	sub2, _, _, _ := bus.Subscribe("e1", "")
+	sub2.Close()
+	sub2.Close()
+
+	bus.Close()
+	if _, open := <-sub.C(); open || sub.Lagged() {
+		t.Fatalf("expected channel closed without lag")
+	}
+	sub.Close()
+	if _, _, _, err := bus.Subscribe("e1", ""); !errors.Is(err, ErrBusClosed) {
+		t.Fatalf("expected bus closed, got %v", err)
+	}
+	bus.PublishSeatChanges([]models.SeatChange{change("e1", "s1")})
+}
+
+func TestSeatBus_PrunesIdleStreams(t *testing.T) {
+	bus := NewSeatBus(10, 10)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	bus.now = func() time.Time { return now }
+
+	bus.PublishSeatChanges([]models.SeatChange{change("idle", "s1"), change("watched", "s2")})
+	sub, _, _, _ := bus.Subscribe("watched", "")
+	lastID := bus.EventIDFor(models.SeatChange{Seq: 1})
+
+	now = now.Add(bus.idle / 2)
+	bus.prune()
+	if len(bus.streams) != 2 {
+		t.Fatalf("expected recent streams kept, got %d", len(bus.streams))
+	}
+
+	now = now.Add(bus.idle)
+	bus.prune()
+	if _, ok := bus.streams["idle"]; ok {
+		t.Fatalf("expected idle stream without subscribers dropped")
+	}
+	if _, ok := bus.streams["watched"]; !ok {
+		t.Fatalf("expected stream with subscribers kept")
+	}
+
+	// El historial descartado no se puede retomar
+	if _, _, resumed, _ := bus.Subscribe("idle", lastID); resumed {
+		t.Fatalf("expected reset after prune")
+	}
+	sub.Close()
+}
+
+func TestSeatBus_ListensToBroadcaster(t *testing.T) {
+	broadcaster := NewMemoryBroadcaster()
+	bus := NewSeatBus(10, 10)
+	bus.Listen(broadcaster)
+	sub, _, _, _ := bus.Subscribe("e1", "")
+
+	// Otra réplica publica por el broadcaster; la presencia no llega al stream
+	hub := NewSeatHub(broadcaster, time.Minute, 10)
+	hub.PublishSeatChanges([]models.SeatChange{change("e1", "s1")})
+	_ = broadcaster.Publish(context.Background(), RoomMessage{Type: RoomPresence, EventID: "e1", ConnID: "c1", SeatIDs: []string{"s2"}})
+
+	if got := <-sub.C(); got.SeatID != "s1" || got.Seq != 1 {
+		t.Fatalf("unexpected change: %+v", got)
+	}
+	if len(sub.C()) != 0 {
+		t.Fatalf("presence messages must not reach the seat stream")
+	}
+}
@@ -0,0 +1,367 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"booking-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrHubClosed = errors.New("seat hub closed")
+
+const (
+	DefaultPresenceTTL = 30 * time.Second
+	// Asientos que una conexión puede marcar como "mirando" a la vez
+	MaxHoverSeats = 20
+	// Cambios de asientos por mensaje, para no pasar el límite de payload de NOTIFY
+	seatChangesPerMessage = 40
+)
+
+// SeatHub administra las salas WebSocket de cada evento: quién está conectado en esta
+// réplica y qué asientos está mirando cada conexión de cualquier réplica. Todo pasa por el
+// Broadcaster, así que cada réplica arma la misma vista de presencia. Como una réplica que
+// se cae no avisa, cada una reenvía periódicamente la presencia de sus conexiones y las
+// entradas que no se refrescan dentro de presenceTTL se descartan
+type SeatHub struct {
+	broadcaster Broadcaster
+	presenceTTL time.Duration
+	buffer      int
+	now         func() time.Time
+
+	mu     sync.Mutex
+	rooms  map[string]*room
+	closed bool
+}
+
+type room struct {
+	clients  map[string]*Client      // Conexiones de esta réplica
+	presence map[string]presenceSeen // Presencia de todas las réplicas por connId
+}
+
+type presenceSeen struct {
+	seatIDs []string
+	seenAt  time.Time
+}
+
+// Client es una conexión WebSocket en la sala de un evento. Los mensajes para el cliente
+// salen por C(); el canal se cierra si el cliente no consume a tiempo, al salir de la sala
+// o al cerrar el hub
+type Client struct {
+	ID      string
+	EventID string
+	UserID  string
+
+	hub    *SeatHub
+	send   chan any
+	hover  []string
+	lagged bool
+	left   bool
+	closed bool // Canal send cerrado
+}
+
+// NewSeatHub crea el hub y lo suscribe al broadcaster. buffer es la cantidad de mensajes
+// pendientes que se toleran por conexión antes de desconectarla
+func NewSeatHub(broadcaster Broadcaster, presenceTTL time.Duration, buffer int) *SeatHub {
+	if presenceTTL <= 0 {
+		presenceTTL = DefaultPresenceTTL
+	}
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+	h := &SeatHub{
+		broadcaster: broadcaster,
+		presenceTTL: presenceTTL,
+		buffer:      buffer,
+		now:         time.Now,
+		rooms:       make(map[string]*room),
+	}
+	broadcaster.Subscribe(h.deliver)
+	return h
+}
+
+// Start lanza el refresco de presencia en segundo plano. El canal devuelto se cierra cuando
+// el worker terminó luego de cancelar el contexto
+func (h *SeatHub) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(h.presenceTTL / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.refresh(ctx)
+			}
+		}
+	}()
+
+	return done
+}
+
+// Join agrega una conexión a la sala del evento y devuelve los asientos que están mirando
+// las demás conexiones (connId -> seatIds)
+func (h *SeatHub) Join(eventID, userID string) (*Client, map[string][]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, nil, ErrHubClosed
+	}
+
+	client := &Client{
+		ID:      uuid.NewString(),
+		EventID: eventID,
+		UserID:  userID,
+		hub:     h,
+		send:    make(chan any, h.buffer),
+	}
+	r := h.room(eventID)
+	r.clients[client.ID] = client
+
+	cutoff := h.now().Add(-h.presenceTTL)
+	snapshot := make(map[string][]string, len(r.presence))
+	for connID, seen := range r.presence {
+		if seen.seenAt.After(cutoff) {
+			snapshot[connID] = append([]string(nil), seen.seatIDs...)
+		}
+	}
+	return client, snapshot, nil
+}
+
+// Leave saca la conexión de la sala y avisa a las demás réplicas
+func (h *SeatHub) Leave(c *Client) {
+	h.mu.Lock()
+	if c.left {
+		h.mu.Unlock()
+		return
+	}
+	c.left = true
+	if r, ok := h.rooms[c.EventID]; ok {
+		delete(r.clients, c.ID)
+		delete(r.presence, c.ID)
+		h.dropIfEmpty(c.EventID, r)
+	}
+	h.disconnect(c)
+	h.mu.Unlock()
+
+	h.publish(RoomMessage{Type: RoomLeave, EventID: c.EventID, ConnID: c.ID})
+}
+
+// Hover reemplaza los asientos que la conexión está mirando; una lista vacía los limpia
+func (h *SeatHub) Hover(c *Client, seatIDs []string) {
+	seen := make(map[string]struct{}, len(seatIDs))
+	hover := make([]string, 0, len(seatIDs))
+	for _, id := range seatIDs {
+		if _, dup := seen[id]; dup || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		hover = append(hover, id)
+		if len(hover) == MaxHoverSeats {
+			break
+		}
+	}
+
+	h.mu.Lock()
+	if c.left {
+		h.mu.Unlock()
+		return
+	}
+	c.hover = hover
+	h.mu.Unlock()
+
+	h.publish(RoomMessage{Type: RoomPresence, EventID: c.EventID, ConnID: c.ID, SeatIDs: hover})
+}
+
+// PublishSeatChanges reenvía a las salas de todas las réplicas los cambios de asientos que
+// publican los repositorios
+func (h *SeatHub) PublishSeatChanges(changes []models.SeatChange) {
+	byEvent := make(map[string][]models.SeatChange)
+	var order []string
+	for _, change := range changes {
+		if _, ok := byEvent[change.EventID]; !ok {
+			order = append(order, change.EventID)
+		}
+		byEvent[change.EventID] = append(byEvent[change.EventID], change)
+	}
+
+	for _, eventID := range order {
+		pending := byEvent[eventID]
+		for len(pending) > 0 {
+			n := min(len(pending), seatChangesPerMessage)
+			h.publish(RoomMessage{Type: RoomSeats, EventID: eventID, Changes: pending[:n]})
+			pending = pending[n:]
+		}
+	}
+}
+
+// Clients devuelve la cantidad de conexiones de esta réplica en la sala del evento
+func (h *SeatHub) Clients(eventID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if r, ok := h.rooms[eventID]; ok {
+		return len(r.clients)
+	}
+	return 0
+}
+
+// Close desconecta a todas las conexiones de esta réplica
+func (h *SeatHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, r := range h.rooms {
+		for _, c := range r.clients {
+			h.disconnect(c)
+		}
+	}
+}
+
+// C es el canal con los mensajes para el cliente
+func (c *Client) C() <-chan any {
+	return c.send
+}
+
+// Send encola un mensaje para el cliente; si tiene el buffer lleno se lo desconecta
+func (c *Client) Send(msg any) bool {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	return c.hub.sendLocked(c, msg)
+}
+
+// Lagged indica si el hub desconectó al cliente por no consumir a tiempo
+func (c *Client) Lagged() bool {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	return c.lagged
+}
+
+func (h *SeatHub) publish(msg RoomMessage) {
+	msg.At = h.now()
+	if err := h.broadcaster.Publish(context.Background(), msg); err != nil && !errors.Is(err, ErrBroadcasterClosed) {
+		log.Printf("⚠️ No se pudo publicar el mensaje %s de la sala %s: %v", msg.Type, msg.EventID, err)
+	}
+}
+
+// deliver recibe los mensajes de todas las réplicas, actualiza la presencia y los reparte
+// a las conexiones locales de la sala
+func (h *SeatHub) deliver(msg RoomMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	r, ok := h.rooms[msg.EventID]
+	switch msg.Type {
+	case RoomPresence:
+		if len(msg.SeatIDs) == 0 {
+			if ok {
+				delete(r.presence, msg.ConnID)
+			}
+			break
+		}
+		if !ok {
+			r = h.room(msg.EventID)
+		}
+		r.presence[msg.ConnID] = presenceSeen{seatIDs: msg.SeatIDs, seenAt: h.now()}
+		ok = true
+	case RoomLeave:
+		if ok {
+			delete(r.presence, msg.ConnID)
+		}
+	}
+	if !ok {
+		return
+	}
+
+	for id, c := range r.clients {
+		if id == msg.ConnID {
+			continue // La propia conexión ya sabe lo que mira
+		}
+		h.sendLocked(c, msg)
+	}
+	h.dropIfEmpty(msg.EventID, r)
+}
+
+// refresh reenvía la presencia de las conexiones locales y descarta la de conexiones que
+// dejaron de refrescarse (réplica caída)
+func (h *SeatHub) refresh(ctx context.Context) {
+	var outgoing []RoomMessage
+
+	h.mu.Lock()
+	cutoff := h.now().Add(-h.presenceTTL)
+	for eventID, r := range h.rooms {
+		for connID, seen := range r.presence {
+			if _, local := r.clients[connID]; local || seen.seenAt.After(cutoff) {
+				continue
+			}
+			delete(r.presence, connID)
+			for _, c := range r.clients {
+				h.sendLocked(c, RoomMessage{Type: RoomLeave, EventID: eventID, ConnID: connID, At: h.now()})
+			}
+		}
+		for _, c := range r.clients {
+			if len(c.hover) > 0 {
+				outgoing = append(outgoing, RoomMessage{Type: RoomPresence, EventID: eventID, ConnID: c.ID, SeatIDs: c.hover})
+			}
+		}
+		h.dropIfEmpty(eventID, r)
+	}
+	h.mu.Unlock()
+
+	for _, msg := range outgoing {
+		if ctx.Err() != nil {
+			return
+		}
+		h.publish(msg)
+	}
+}
+
+// Las funciones siguientes se llaman con el lock tomado
+
+func (h *SeatHub) room(eventID string) *room {
+	r, ok := h.rooms[eventID]
+	if !ok {
+		r = &room{clients: make(map[string]*Client), presence: make(map[string]presenceSeen)}
+		h.rooms[eventID] = r
+	}
+	return r
+}
+
+func (h *SeatHub) dropIfEmpty(eventID string, r *room) {
+	if len(r.clients) == 0 && len(r.presence) == 0 {
+		delete(h.rooms, eventID)
+	}
+}
+
+func (h *SeatHub) sendLocked(c *Client, msg any) bool {
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		c.lagged = true
+		h.disconnect(c)
+		return false
+	}
+}
+
+// disconnect cierra el canal del cliente una sola vez
+func (h *SeatHub) disconnect(c *Client) {
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
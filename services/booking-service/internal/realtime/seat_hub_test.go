@@ -0,0 +1,141 @@
+package realtime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// receive devuelve el próximo mensaje del cliente o falla si no llega
+func receive(t *testing.T, c *Client) RoomMessage {
+	t.Helper()
+	select {
+	case msg, open := <-c.C():
+		if !open {
+			t.Fatalf("client channel closed")
+		}
+		return msg.(RoomMessage)
+	case <-time.After(time.Second):
+		t.Fatalf("no message received")
+	}
+	return RoomMessage{}
+}
+
+func TestSeatHub_PresenceAcrossReplicas(t *testing.T) {
+	broadcaster := NewMemoryBroadcaster()
+	replica1 := NewSeatHub(broadcaster, time.Minute, 10)
+	replica2 := NewSeatHub(broadcaster, time.Minute, 10)
+
+	a, _, _ := replica1.Join("e1", "u1")
+	b, _, _ := replica2.Join("e1", "u2")
+	other, _, _ := replica2.Join("e2", "u3")
+
+	replica1.Hover(a, []string{"s1", "s2", "s1", ""})
+	if got := receive(t, b); got.Type != RoomPresence || got.ConnID != a.ID || len(got.SeatIDs) != 2 {
+		t.Fatalf("unexpected presence: %+v", got)
+	}
+	if len(a.C()) != 0 || len(other.C()) != 0 {
+		t.Fatalf("presence delivered to the sender or to another event")
+	}
+
+	// Una conexión nueva en cualquier réplica recibe la presencia actual
+	c, snapshot, _ := replica2.Join("e1", "u4")
+	if seats := snapshot[a.ID]; len(seats) != 2 || seats[0] != "s1" {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	replica1.Leave(a)
+	replica1.Leave(a)
+	for _, client := range []*Client{b, c} {
+		if got := receive(t, client); got.Type != RoomLeave || got.ConnID != a.ID {
+			t.Fatalf("unexpected leave: %+v", got)
+		}
+	}
+	if _, open := <-a.C(); open {
+		t.Fatalf("expected channel closed after leave")
+	}
+	if _, snapshot, _ := replica2.Join("e1", "u5"); len(snapshot) != 0 {
+		t.Fatalf("expected empty presence after leave, got %+v", snapshot)
+	}
+}
+
+func TestSeatHub_SeatChangesAreChunked(t *testing.T) {
+	broadcaster := NewMemoryBroadcaster()
+	replica1 := NewSeatHub(broadcaster, time.Minute, 10)
+	replica2 := NewSeatHub(broadcaster, time.Minute, 10)
+	b, _, _ := replica2.Join("e1", "u2")
+
+	changes := make([]models.SeatChange, 0, seatChangesPerMessage+5)
+	for i := 0; i < seatChangesPerMessage+5; i++ {
+		changes = append(changes, models.SeatChange{EventID: "e1", SeatID: fmt.Sprintf("s%d", i), Status: models.StatusLocked})
+	}
+	changes = append(changes, models.SeatChange{EventID: "e2", SeatID: "x", Status: models.StatusSold})
+	replica1.PublishSeatChanges(changes)
+
+	first, second := receive(t, b), receive(t, b)
+	if first.Type != RoomSeats || len(first.Changes) != seatChangesPerMessage || len(second.Changes) != 5 {
+		t.Fatalf("unexpected chunks: %d and %d", len(first.Changes), len(second.Changes))
+	}
+	if len(b.C()) != 0 {
+		t.Fatalf("received changes of another event")
+	}
+}
+
+func TestSeatHub_RefreshPrunesStalePresence(t *testing.T) {
+	broadcaster := NewMemoryBroadcaster()
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	alive := NewSeatHub(broadcaster, 30*time.Second, 10)
+	alive.now = clock
+	// Conexión de una réplica caída: publicó una vez y nunca más refresca
+	const ghost = "ghost"
+	alive.deliver(RoomMessage{Type: RoomPresence, EventID: "e1", ConnID: ghost, SeatIDs: []string{"s9"}})
+
+	a, snapshot, _ := alive.Join("e1", "u1")
+	if len(snapshot) != 1 {
+		t.Fatalf("expected ghost presence, got %+v", snapshot)
+	}
+	alive.Hover(a, []string{"s1"})
+
+	now = now.Add(31 * time.Second)
+	alive.refresh(t.Context())
+
+	if got := receive(t, a); got.Type != RoomLeave || got.ConnID != ghost {
+		t.Fatalf("expected leave for stale presence, got %+v", got)
+	}
+	_, snapshot, _ = alive.Join("e1", "u2")
+	if len(snapshot) != 1 || len(snapshot[a.ID]) != 1 {
+		t.Fatalf("expected only refreshed local presence, got %+v", snapshot)
+	}
+}
+
+func TestSeatHub_DropsLaggedClientAndClose(t *testing.T) {
+	hub := NewSeatHub(NewMemoryBroadcaster(), time.Minute, 1)
+	slow, _, _ := hub.Join("e1", "u1")
+	fast, _, _ := hub.Join("e1", "u2")
+
+	hub.PublishSeatChanges([]models.SeatChange{{EventID: "e1", SeatID: "s1"}})
+	receive(t, fast)
+	hub.PublishSeatChanges([]models.SeatChange{{EventID: "e1", SeatID: "s2"}})
+
+	<-slow.C()
+	if _, open := <-slow.C(); open || !slow.Lagged() {
+		t.Fatalf("expected slow client dropped as lagged")
+	}
+	if slow.Send("late") {
+		t.Fatalf("expected send to a dropped client to fail")
+	}
+	receive(t, fast)
+
+	hub.Close()
+	if _, open := <-fast.C(); open || fast.Lagged() {
+		t.Fatalf("expected channel closed without lag")
+	}
+	hub.Leave(fast)
+	if _, _, err := hub.Join("e1", "u3"); err != ErrHubClosed {
+		t.Fatalf("expected hub closed, got %v", err)
+	}
+}
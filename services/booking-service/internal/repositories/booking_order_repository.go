@@ -2,18 +2,39 @@ package repositories
 
 import (
 	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// StatusChange describe un cambio de estado de una orden validado por el servicio.
+// Solo se aplica si la orden sigue en From con la versión Version
+type StatusChange struct {
+	OrderID           string
+	From              models.PaymentStatus
+	To                models.PaymentStatus
+	Version           int
+	PaymentProviderID string
+	ChangedBy         string
+	Reason            string
+}
+
 type BookingOrderRepository interface {
 	Create(booking *models.BookingOrder) error
 	FindAll() ([]models.BookingOrder, error)
 	FindByID(id string) (*models.BookingOrder, error)
-	UpdateStatus(id string, status models.PaymentStatus) error
-	Update(id string, status models.PaymentStatus, paymentProviderID string) error
+	ChangeStatus(change *StatusChange) error
+	FindStatusHistory(orderID string) ([]models.BookingOrderStatusHistory, error)
 
 	FindAllOrdersByUserID(userID string) ([]models.BookingOrder, error)
+	FindPendingByUserIDs(userIDs []string) ([]models.BookingOrder, error)
+	// CancelPending cancela las órdenes que siguen PENDING y devuelve las canceladas, con su
+	// pasarela y sesión de pago para cerrarla
+	CancelPending(ids []string, changedBy, reason string) ([]models.BookingOrder, error)
+	// AttachPaymentSession guarda la sesión de pago abierta para una orden PENDING
+	AttachPaymentSession(orderID, sessionID string) error
 }
 
 type bookingOrderRepository struct {
@@ -24,8 +45,25 @@ func NewBookingOrderRepository(db *gorm.DB) BookingOrderRepository {
 	return &bookingOrderRepository{db: db}
 }
 
+// Create guarda la orden. Los límites de compra del evento se vuelven a contar en la misma
+// transacción y, si trae código promocional, su uso se registra ahí: con el límite superado o
+// sin usos disponibles la orden no se crea
 func (t *bookingOrderRepository) Create(bookingOrder *models.BookingOrder) error {
-	return t.db.Create(bookingOrder).Error
+	if bookingOrder.PromoCodeID == nil && bookingOrder.EventID == nil {
+		return t.db.Create(bookingOrder).Error
+	}
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		if err := enforceOrderLimits(tx, bookingOrder); err != nil {
+			return err
+		}
+		if err := tx.Create(bookingOrder).Error; err != nil {
+			return err
+		}
+		if bookingOrder.PromoCodeID == nil {
+			return nil
+		}
+		return redeemPromoCode(tx, bookingOrder)
+	})
 }
 
 func (t *bookingOrderRepository) FindAll() ([]models.BookingOrder, error) {
@@ -36,10 +74,16 @@ func (t *bookingOrderRepository) FindAll() ([]models.BookingOrder, error) {
 
 func (t *bookingOrderRepository) FindByID(id string) (*models.BookingOrder, error) {
 	var booking models.BookingOrder
-	err := t.db.First(&booking, "id = ?", id).Error
+	err := t.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).First(&booking, "id = ?", id).Error
 	if err != nil {
 		return &booking, err
 	}
+	if len(booking.Lines) > 0 {
+		summary := models.TotalsOf(booking.Lines)
+		booking.Summary = &summary
+	}
 
 	if len(booking.SeatIDs) == 0 {
 		return &booking, nil
@@ -66,16 +110,64 @@ func (t *bookingOrderRepository) FindByID(id string) (*models.BookingOrder, erro
 	return &booking, nil
 }
 
-func (t *bookingOrderRepository) UpdateStatus(id string, status models.PaymentStatus) error {
-	return t.db.Model(&models.BookingOrder{}).Where("id = ?", id).Update("status", status).Error
+// ChangeStatus aplica el cambio con control optimista (estado y versión esperados)
+// y registra el historial en la misma transacción
+func (t *bookingOrderRepository) ChangeStatus(change *StatusChange) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return applyStatusChange(tx, change)
+	})
 }
 
-func (t *bookingOrderRepository) Update(id string, status models.PaymentStatus, paymentProviderID string) error {
+func (t *bookingOrderRepository) FindStatusHistory(orderID string) ([]models.BookingOrderStatusHistory, error) {
+	var history []models.BookingOrderStatusHistory
+	err := t.db.Where("order_id = ?", orderID).Order("version ASC, created_at ASC").Find(&history).Error
+	return history, err
+}
+
+// applyStatusChange se comparte con otras transacciones que mueven el estado de una orden
+func applyStatusChange(tx *gorm.DB, change *StatusChange) error {
 	updates := map[string]any{
-		"status":              status,
-		"payment_provider_id": paymentProviderID,
+		"status":  change.To,
+		"version": gorm.Expr("version + 1"),
+	}
+	if change.PaymentProviderID != "" {
+		updates["payment_provider_id"] = change.PaymentProviderID
+	}
+
+	result := tx.Model(&models.BookingOrder{}).
+		Where("id = ? AND status = ? AND version = ?", change.OrderID, change.From, change.Version).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update order status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrOrderVersionConflict
+	}
+
+	// Una orden que no se va a pagar devuelve el uso de su código promocional
+	switch change.To {
+	case models.PaymentFailed, models.PaymentExpired, models.PaymentCancelled:
+		if err := releasePromoRedemption(tx, change.OrderID); err != nil {
+			return err
+		}
+	}
+
+	return recordStatusHistory(tx, change.OrderID, change.From, change.To, change.Version+1, change.ChangedBy, change.Reason)
+}
+
+func recordStatusHistory(tx *gorm.DB, orderID string, from, to models.PaymentStatus, version int, changedBy, reason string) error {
+	entry := &models.BookingOrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		Version:    version,
+		ChangedBy:  changedBy,
+		Reason:     reason,
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to record status history: %w", err)
 	}
-	return t.db.Model(&models.BookingOrder{}).Where("id = ?", id).Updates(updates).Error
+	return nil
 }
 
 func (t *bookingOrderRepository) FindAllOrdersByUserID(userID string) ([]models.BookingOrder, error) {
@@ -83,3 +175,58 @@ func (t *bookingOrderRepository) FindAllOrdersByUserID(userID string) ([]models.
 	err := t.db.Where("user_id = ?", userID).Find(&bookings).Error
 	return bookings, err
 }
+
+func (t *bookingOrderRepository) FindPendingByUserIDs(userIDs []string) ([]models.BookingOrder, error) {
+	if len(userIDs) == 0 {
+		return []models.BookingOrder{}, nil
+	}
+
+	var bookings []models.BookingOrder
+	err := t.db.Where("user_id IN ? AND status = ?", userIDs, models.PaymentPending).Find(&bookings).Error
+	return bookings, err
+}
+
+// Cancela solo las órdenes que siguen en PENDING (no pisa una orden que ya se pagó)
+func (t *bookingOrderRepository) CancelPending(ids []string, changedBy, reason string) ([]models.BookingOrder, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var cancelled []models.BookingOrder
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		var orders []models.BookingOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("id", "status", "version", "payment_provider", "payment_session_id").
+			Where("id IN ? AND status = ?", ids, models.PaymentPending).
+			Find(&orders).Error; err != nil {
+			return err
+		}
+
+		for _, o := range orders {
+			err := applyStatusChange(tx, &StatusChange{
+				OrderID:   o.ID,
+				From:      o.Status,
+				To:        models.PaymentCancelled,
+				Version:   o.Version,
+				ChangedBy: changedBy,
+				Reason:    reason,
+			})
+			if err != nil {
+				return err
+			}
+			o.Status = models.PaymentCancelled
+			cancelled = append(cancelled, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+func (t *bookingOrderRepository) AttachPaymentSession(orderID, sessionID string) error {
+	return t.db.Model(&models.BookingOrder{}).
+		Where("id = ? AND status = ?", orderID, models.PaymentPending).
+		Update("payment_session_id", sessionID).Error
+}
@@ -2,6 +2,9 @@ package repositories
 
 import (
 	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -11,7 +14,7 @@ func TestBookingOrderRepository_Integration_FindAndUpdate(t *testing.T) {
 	db := openIntegrationDB(t)
 	repo := NewBookingOrderRepository(db)
 	eventRepo := NewEventRepository(db)
-	seatRepo := NewSeatRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
 
 	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
 	eventID := "44444444-4444-4444-4444-" + suffix[len(suffix)-12:]
@@ -20,14 +23,14 @@ func TestBookingOrderRepository_Integration_FindAndUpdate(t *testing.T) {
 	orderID := "77777777-7777-7777-7777-" + suffix[len(suffix)-12:]
 	userID := "88888888-8888-8888-8888-" + suffix[len(suffix)-12:]
 
-	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Repo Test Event", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: 1000})
-	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seat1ID}, EventID: eventID, Section: "A", Number: "1", Price: 10, Status: models.StatusAvailable})
-	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seat2ID}, EventID: eventID, Section: "A", Number: "2", Price: 20, Status: models.StatusAvailable})
+	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Repo Test Event", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seat1ID}, EventID: eventID, Section: "A", Number: "1", Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seat2ID}, EventID: eventID, Section: "A", Number: "2", Price: money.New(2000, "ARS"), Status: models.StatusAvailable})
 
 	order := &models.BookingOrder{
 		BaseModel: models.BaseModel{ID: orderID},
 		UserID:    userID,
-		Amount:    3000,
+		Total:     money.New(3000, "ARS"),
 		Status:    models.PaymentPending,
 		SeatIDs:   []string{seat2ID, seat1ID},
 	}
@@ -43,11 +46,23 @@ func TestBookingOrderRepository_Integration_FindAndUpdate(t *testing.T) {
 		t.Fatalf("expected ordered items from seat IDs, got %+v", got.Items)
 	}
 
-	if err := repo.UpdateStatus(orderID, models.PaymentFailed); err != nil {
-		t.Fatalf("update status failed: %v", err)
+	change := &StatusChange{OrderID: orderID, From: models.PaymentPending, To: models.PaymentCompleted, Version: got.Version, PaymentProviderID: "pi_123", ChangedBy: userID}
+	if err := repo.ChangeStatus(change); err != nil {
+		t.Fatalf("change status failed: %v", err)
 	}
-	if err := repo.Update(orderID, models.PaymentCompleted, "pi_123"); err != nil {
-		t.Fatalf("update payment provider failed: %v", err)
+	// La misma versión ya no es válida: otro escritor concurrente pierde
+	if err := repo.ChangeStatus(change); !errors.Is(err, utils.ErrOrderVersionConflict) {
+		t.Fatalf("expected version conflict, got %v", err)
+	}
+
+	updated, _ := repo.FindByID(orderID)
+	if updated.Status != models.PaymentCompleted || updated.PaymentProviderID != "pi_123" || updated.Version != got.Version+1 {
+		t.Fatalf("unexpected order after change: %+v", updated)
+	}
+
+	history, err := repo.FindStatusHistory(orderID)
+	if err != nil || len(history) != 1 || history[0].ToStatus != models.PaymentCompleted || history[0].ChangedBy != userID {
+		t.Fatalf("expected one history entry, err=%v history=%+v", err, history)
 	}
 
 	byUser, err := repo.FindAllOrdersByUserID(userID)
@@ -55,3 +70,67 @@ func TestBookingOrderRepository_Integration_FindAndUpdate(t *testing.T) {
 		t.Fatalf("expected orders by user, err=%v len=%d", err, len(byUser))
 	}
 }
+
+func TestBookingOrderRepository_Integration_CancelPendingForExpiredLocks(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewBookingOrderRepository(db)
+	eventRepo := NewEventRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "bbbbbbbb-1111-1111-1111-" + suffix[len(suffix)-12:]
+	seatID := "bbbbbbbb-2222-2222-2222-" + suffix[len(suffix)-12:]
+	orderID := "bbbbbbbb-3333-3333-3333-" + suffix[len(suffix)-12:]
+	userID := "bbbbbbbb-4444-4444-4444-" + suffix[len(suffix)-12:]
+
+	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Reaper Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seatID}, EventID: eventID, Section: "A", Number: "1", Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	_ = repo.Create(&models.BookingOrder{BaseModel: models.BaseModel{ID: orderID}, UserID: userID, Total: money.New(1000, "ARS"), Status: models.PaymentPending, SeatIDs: []string{seatID}})
+
+	if err := seatRepo.LockSeats([]string{seatID}, userID, "bbbbbbbb-5555-5555-5555-"+suffix[len(suffix)-12:], time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("lock seats failed: %v", err)
+	}
+
+	now := time.Now()
+	expired, err := seatRepo.FindExpiredLocks(now, 0)
+	if err != nil {
+		t.Fatalf("find expired failed: %v", err)
+	}
+	ids := make([]string, 0, len(expired))
+	for _, s := range expired {
+		ids = append(ids, s.ID)
+	}
+	released, err := seatRepo.ReleaseExpiredLocks(ids, now)
+	if err != nil {
+		t.Fatalf("release expired failed: %v", err)
+	}
+	found := false
+	for _, s := range released {
+		if s.ID == seatID && s.LockedBy != nil && *s.LockedBy == userID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected seat %s in released list", seatID)
+	}
+
+	pending, err := repo.FindPendingByUserIDs([]string{userID})
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected 1 pending order, err=%v len=%d", err, len(pending))
+	}
+
+	cancelled, err := repo.CancelPending([]string{orderID}, "lock-reaper", "seat hold expired")
+	if err != nil || len(cancelled) != 1 || cancelled[0].ID != orderID {
+		t.Fatalf("expected 1 cancelled order, err=%v got=%+v", err, cancelled)
+	}
+
+	got, err := repo.FindByID(orderID)
+	if err != nil || got.Status != models.PaymentCancelled {
+		t.Fatalf("expected CANCELLED order, err=%v status=%s", err, got.Status)
+	}
+
+	history, _ := repo.FindStatusHistory(orderID)
+	if len(history) != 1 || history[0].ChangedBy != "lock-reaper" {
+		t.Fatalf("expected cancellation in history, got %+v", history)
+	}
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"booking-service/internal/models"
+	"booking-service/pkg/money"
 	"fmt"
 	"testing"
 	"time"
@@ -12,7 +13,7 @@ func TestCheckoutRepository_Integration_FindByOrderIDAndUpdate(t *testing.T) {
 	checkoutRepo := NewCheckoutRepository(db)
 	orderRepo := NewBookingOrderRepository(db)
 	eventRepo := NewEventRepository(db)
-	seatRepo := NewSeatRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
 
 	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
 	eventID := "99999999-9999-9999-9999-" + suffix[len(suffix)-12:]
@@ -20,17 +21,16 @@ func TestCheckoutRepository_Integration_FindByOrderIDAndUpdate(t *testing.T) {
 	orderID := "bbbbbbbb-bbbb-bbbb-bbbb-" + suffix[len(suffix)-12:]
 	checkoutID := "cccccccc-cccc-cccc-cccc-" + suffix[len(suffix)-12:]
 
-	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Checkout Event", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: 1200})
-	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seatID}, EventID: eventID, Section: "B", Number: "10", Price: 12, Status: models.StatusAvailable})
-	_ = orderRepo.Create(&models.BookingOrder{BaseModel: models.BaseModel{ID: orderID}, UserID: "u1", Amount: 1200, Status: models.PaymentPending, SeatIDs: []string{seatID}})
+	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Checkout Event", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1200, "ARS")})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seatID}, EventID: eventID, Section: "B", Number: "10", Price: money.New(1200, "ARS"), Status: models.StatusAvailable})
+	_ = orderRepo.Create(&models.BookingOrder{BaseModel: models.BaseModel{ID: orderID}, UserID: "u1", Total: money.New(1200, "ARS"), Status: models.PaymentPending, SeatIDs: []string{seatID}})
 
 	checkout := &models.Checkout{
 		BaseModel:       models.BaseModel{ID: checkoutID},
 		OrderID:         orderID,
 		PaymentProvider: "STRIPE",
 		PaymentIntentID: "pi_test",
-		Currency:        "usd",
-		Amount:          1200,
+		Total:           money.New(1200, "USD"),
 		CustomerEmail:   "buyer@test.com",
 		CustomerName:    "Buyer",
 	}
@@ -46,7 +46,7 @@ func TestCheckoutRepository_Integration_FindByOrderIDAndUpdate(t *testing.T) {
 		t.Fatalf("expected preloaded order with seats, got %+v", got.Order)
 	}
 
-	got.Currency = "ars"
+	got.Total.Currency = "ARS"
 	if err := checkoutRepo.Update(got); err != nil {
 		t.Fatalf("update checkout failed: %v", err)
 	}
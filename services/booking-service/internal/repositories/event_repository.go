@@ -31,7 +31,7 @@ func (r *eventRepository) Create(event *models.Event) error {
 
 func (r *eventRepository) FindByID(id string) (*models.Event, error) {
 	var event models.Event
-	err := r.db.Preload("Seats").First(&event, "id = ?", id).Error
+	err := r.db.Preload("Seats").Preload("Tiers").First(&event, "id = ?", id).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
@@ -77,10 +77,24 @@ func (r *eventRepository) UpdateAvailability(eventID string) error {
 		return err
 	}
 
-	if err := r.db.Model(&models.Seat{}).Where("event_id", eventID).Count(&availableSeats).Error; err != nil {
+	if err := r.db.Model(&models.Seat{}).Where("event_id = ? AND status = ?", eventID, models.StatusAvailable).Count(&availableSeats).Error; err != nil {
 		return err
 	}
 
+	// Los sectores de admisión general suman su cupo y lo que queda libre
+	var ga struct {
+		Capacity  int64
+		Available int64
+	}
+	if err := r.db.Model(&models.GASection{}).
+		Select("COALESCE(SUM(capacity), 0) AS capacity, COALESCE(SUM(GREATEST(capacity - held - sold, 0)), 0) AS available").
+		Where("event_id = ?", eventID).
+		Scan(&ga).Error; err != nil {
+		return err
+	}
+	totalSeats += ga.Capacity
+	availableSeats += ga.Available
+
 	if totalSeats == 0 {
 		return nil
 	}
@@ -0,0 +1,403 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GeneralAdmissionRepository maneja los sectores de admisión general y sus holds.
+// El cupo se descuenta con contadores (held/sold) en vez de bloquear filas de seats
+type GeneralAdmissionRepository interface {
+	CreateSection(section *models.GASection) error
+	FindSectionByID(id string) (*models.GASection, error)
+	FindSectionsByEventID(eventID string) ([]models.GASection, error)
+	DeleteSection(id string) error
+
+	Hold(holdID, userID string, items []models.GAItem, expiresAt time.Time) ([]models.GAHold, error)
+	FindActiveHolds(holdID string) ([]models.GAHold, error)
+	ExtendHold(holdID, userID string, expiresAt time.Time, maxExtensions int, now time.Time) (int64, error)
+	ReleaseHold(holdID, userID string) (int64, error)
+	FindExpiredHolds(now time.Time, limit int) ([]models.GAHold, error)
+	ReleaseExpiredHolds(ids []string, now time.Time) ([]models.GAHold, error)
+	CountActiveByUser(eventID, userID string, now time.Time) (int64, error)
+}
+
+type generalAdmissionRepository struct {
+	db *gorm.DB
+}
+
+func NewGeneralAdmissionRepository(db *gorm.DB) GeneralAdmissionRepository {
+	return &generalAdmissionRepository{db: db}
+}
+
+func (r *generalAdmissionRepository) CreateSection(section *models.GASection) error {
+	return r.db.Create(section).Error
+}
+
+func (r *generalAdmissionRepository) FindSectionByID(id string) (*models.GASection, error) {
+	var section models.GASection
+	err := r.db.First(&section, "id = ?", id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &section, err
+}
+
+func (r *generalAdmissionRepository) FindSectionsByEventID(eventID string) ([]models.GASection, error) {
+	var sections []models.GASection
+	err := r.db.Where("event_id = ?", eventID).Order("price_amount DESC, name").Find(&sections).Error
+	return sections, err
+}
+
+// Borra un sector que todavía no tiene entradas bloqueadas ni vendidas
+func (r *generalAdmissionRepository) DeleteSection(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var section models.GASection
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&section, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrGASectionNotFound
+			}
+			return err
+		}
+		if section.Held > 0 || section.Sold > 0 {
+			return utils.ErrGASectionInUse
+		}
+		if err := tx.Delete(&section).Error; err != nil {
+			return err
+		}
+		return (&eventRepository{db: tx}).UpdateAvailability(section.EventID)
+	})
+}
+
+// Hold descuenta el cupo de todos los sectores en una sola transacción (todo o nada).
+// Si algún sector no alcanza se hace rollback y se devuelve *utils.GASoldOutError
+func (r *generalAdmissionRepository) Hold(holdID, userID string, items []models.GAItem, expiresAt time.Time) ([]models.GAHold, error) {
+	items = models.MergeGAItems(items)
+	if len(items) == 0 {
+		return nil, errors.New("no general admission tickets to hold")
+	}
+
+	var holds []models.GAHold
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.SectionID)
+		}
+
+		// Se bloquean en orden de ID para que dos holds cruzados no se traben
+		var sections []models.GASection
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Order("id").
+			Find(&sections).Error; err != nil {
+			return err
+		}
+		byID := make(map[string]models.GASection, len(sections))
+		for _, s := range sections {
+			byID[s.ID] = s
+		}
+
+		var failed []string
+		for _, item := range items {
+			section, ok := byID[item.SectionID]
+			if !ok || section.Available() < item.Quantity {
+				failed = append(failed, item.SectionID)
+			}
+		}
+		if len(failed) > 0 {
+			return &utils.GASoldOutError{SectionIDs: failed}
+		}
+
+		for _, item := range items {
+			res := tx.Model(&models.GASection{}).
+				Where("id = ? AND capacity - held - sold >= ?", item.SectionID, item.Quantity).
+				Update("held", gorm.Expr("held + ?", item.Quantity))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return &utils.GASoldOutError{SectionIDs: []string{item.SectionID}}
+			}
+
+			holds = append(holds, models.GAHold{
+				HoldID:    holdID,
+				SectionID: item.SectionID,
+				EventID:   byID[item.SectionID].EventID,
+				UserID:    userID,
+				Quantity:  item.Quantity,
+				Status:    models.GAHoldActive,
+				ExpiresAt: expiresAt,
+			})
+		}
+		if err := tx.Create(&holds).Error; err != nil {
+			return err
+		}
+
+		return updateGAAvailability(tx, holds)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// Entradas que siguen bloqueadas bajo un hold
+func (r *generalAdmissionRepository) FindActiveHolds(holdID string) ([]models.GAHold, error) {
+	var holds []models.GAHold
+	err := r.db.Where("hold_id = ? AND status = ?", holdID, models.GAHoldActive).Find(&holds).Error
+	return holds, err
+}
+
+// Extiende un hold vigente del usuario si no superó el máximo de extensiones
+func (r *generalAdmissionRepository) ExtendHold(holdID, userID string, expiresAt time.Time, maxExtensions int, now time.Time) (int64, error) {
+	result := r.db.Model(&models.GAHold{}).
+		Where("hold_id = ? AND user_id = ? AND status = ? AND expires_at > ? AND extensions < ?",
+			holdID, userID, models.GAHoldActive, now, maxExtensions).
+		Updates(map[string]interface{}{
+			"expires_at": expiresAt,
+			"extensions": gorm.Expr("extensions + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// Devuelve al cupo las entradas de un hold del usuario. Devuelve la cantidad de entradas liberadas
+func (r *generalAdmissionRepository) ReleaseHold(holdID, userID string) (int64, error) {
+	var released int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var holds []models.GAHold
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hold_id = ? AND user_id = ? AND status = ?", holdID, userID, models.GAHoldActive).
+			Find(&holds).Error; err != nil {
+			return err
+		}
+
+		var err error
+		released, err = releaseGAHolds(tx, holds)
+		return err
+	})
+	return released, err
+}
+
+// Busca los holds vencidos sin liberarlos, para que el caller cancele primero las órdenes
+// pendientes de esos holds
+func (r *generalAdmissionRepository) FindExpiredHolds(now time.Time, limit int) ([]models.GAHold, error) {
+	var holds []models.GAHold
+	query := r.db.Where("status = ? AND expires_at <= ?", models.GAHoldActive, now)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&holds).Error; err != nil {
+		return nil, fmt.Errorf("failed to find expired general admission holds: %w", err)
+	}
+	return holds, nil
+}
+
+// Libera en bloque los holds indicados que sigan activos y vencidos. Devuelve los holds liberados
+func (r *generalAdmissionRepository) ReleaseExpiredHolds(ids []string, now time.Time) ([]models.GAHold, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var released []models.GAHold
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status = ? AND expires_at <= ?", ids, models.GAHoldActive, now).
+			Find(&released).Error; err != nil {
+			return err
+		}
+
+		_, err := releaseGAHolds(tx, released)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to release expired general admission holds: %w", err)
+	}
+
+	return released, nil
+}
+
+// Cuenta las entradas que el usuario tiene bloqueadas (sin vencer) en un evento
+func (r *generalAdmissionRepository) CountActiveByUser(eventID, userID string, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.GAHold{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("event_id = ? AND user_id = ? AND status = ? AND expires_at > ?", eventID, userID, models.GAHoldActive, now).
+		Scan(&count).Error
+	return count, err
+}
+
+// releaseGAHolds devuelve al cupo las entradas de holds ya bloqueados en la transacción
+func releaseGAHolds(tx *gorm.DB, holds []models.GAHold) (int64, error) {
+	if len(holds) == 0 {
+		return 0, nil
+	}
+
+	var released int64
+	ids := make([]string, 0, len(holds))
+	for _, h := range holds {
+		if err := tx.Model(&models.GASection{}).Where("id = ?", h.SectionID).
+			Update("held", gorm.Expr("GREATEST(held - ?, 0)", h.Quantity)).Error; err != nil {
+			return 0, fmt.Errorf("failed to release general admission capacity: %w", err)
+		}
+		ids = append(ids, h.ID)
+		released += int64(h.Quantity)
+	}
+
+	if err := tx.Model(&models.GAHold{}).Where("id IN ?", ids).
+		Update("status", models.GAHoldReleased).Error; err != nil {
+		return 0, err
+	}
+	return released, updateGAAvailability(tx, holds)
+}
+
+// releaseOrderGAHolds libera las entradas de la orden que siguen bloqueadas por su hold
+func releaseOrderGAHolds(tx *gorm.DB, order *models.BookingOrder) (int64, error) {
+	if len(order.GAItems) == 0 || order.HoldID == nil {
+		return 0, nil
+	}
+
+	var holds []models.GAHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("hold_id = ? AND status = ?", *order.HoldID, models.GAHoldActive).
+		Find(&holds).Error; err != nil {
+		return 0, err
+	}
+	return releaseGAHolds(tx, holds)
+}
+
+// sellGAItems pasa las entradas de admisión general de la orden de held a sold. Si el hold
+// venció y el cupo se liberó, se vuelve a tomar; sin cupo devuelve ErrSeatsTaken
+func sellGAItems(tx *gorm.DB, order *models.BookingOrder, ticketID string, now time.Time) error {
+	if len(order.GAItems) == 0 {
+		return nil
+	}
+
+	holdID := order.ID
+	if order.HoldID != nil {
+		holdID = *order.HoldID
+	}
+
+	var sold []models.GAHold
+	for _, item := range models.MergeGAItems(order.GAItems) {
+		var hold models.GAHold
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hold_id = ? AND section_id = ? AND status = ?", holdID, item.SectionID, models.GAHoldActive).
+			First(&hold).Error
+
+		switch {
+		case err == nil && hold.Quantity == item.Quantity:
+			if err := tx.Model(&models.GASection{}).Where("id = ?", item.SectionID).Updates(map[string]any{
+				"held": gorm.Expr("GREATEST(held - ?, 0)", item.Quantity),
+				"sold": gorm.Expr("sold + ?", item.Quantity),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to sell general admission tickets: %w", err)
+			}
+			if err := tx.Model(&models.GAHold{}).Where("id = ?", hold.ID).Updates(map[string]any{
+				"status":    models.GAHoldSold,
+				"ticket_id": ticketID,
+			}).Error; err != nil {
+				return err
+			}
+		case err == nil || errors.Is(err, gorm.ErrRecordNotFound):
+			if err == nil {
+				if _, err := releaseGAHolds(tx, []models.GAHold{hold}); err != nil {
+					return err
+				}
+			}
+			// El hold ya no está: se intenta tomar el cupo directamente como vendido
+			res := tx.Model(&models.GASection{}).
+				Where("id = ? AND capacity - held - sold >= ?", item.SectionID, item.Quantity).
+				Update("sold", gorm.Expr("sold + ?", item.Quantity))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("%w: general admission %s", ErrSeatsTaken, item.SectionID)
+			}
+
+			var section models.GASection
+			if err := tx.Select("id", "event_id").First(&section, "id = ?", item.SectionID).Error; err != nil {
+				return err
+			}
+			hold = models.GAHold{
+				HoldID:    holdID,
+				SectionID: item.SectionID,
+				EventID:   section.EventID,
+				UserID:    order.UserID,
+				Quantity:  item.Quantity,
+				Status:    models.GAHoldSold,
+				ExpiresAt: now,
+				TicketID:  &ticketID,
+			}
+			if err := tx.Create(&hold).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+		sold = append(sold, hold)
+	}
+
+	return updateGAAvailability(tx, sold)
+}
+
+// returnSoldGAItems devuelve al cupo las entradas vendidas de la orden (reembolso total)
+func returnSoldGAItems(tx *gorm.DB, order *models.BookingOrder) error {
+	if len(order.GAItems) == 0 {
+		return nil
+	}
+
+	holdID := order.ID
+	if order.HoldID != nil {
+		holdID = *order.HoldID
+	}
+
+	var holds []models.GAHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("hold_id = ? AND status = ?", holdID, models.GAHoldSold).
+		Find(&holds).Error; err != nil {
+		return err
+	}
+	if len(holds) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(holds))
+	for _, h := range holds {
+		if err := tx.Model(&models.GASection{}).Where("id = ?", h.SectionID).
+			Update("sold", gorm.Expr("GREATEST(sold - ?, 0)", h.Quantity)).Error; err != nil {
+			return fmt.Errorf("failed to return general admission tickets: %w", err)
+		}
+		ids = append(ids, h.ID)
+	}
+	if err := tx.Model(&models.GAHold{}).Where("id IN ?", ids).Updates(map[string]any{
+		"status":    models.GAHoldReleased,
+		"ticket_id": nil,
+	}).Error; err != nil {
+		return err
+	}
+	return updateGAAvailability(tx, holds)
+}
+
+// updateGAAvailability recalcula la disponibilidad de los eventos de los holds
+func updateGAAvailability(tx *gorm.DB, holds []models.GAHold) error {
+	events := make(map[string]struct{})
+	for _, h := range holds {
+		events[h.EventID] = struct{}{}
+	}
+	eventRepo := &eventRepository{db: tx}
+	for eventID := range events {
+		if err := eventRepo.UpdateAvailability(eventID); err != nil {
+			return fmt.Errorf("failed to update availability: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGeneralAdmissionRepository_Integration_HoldAndRelease(t *testing.T) {
+	db := openIntegrationDB(t)
+	eventRepo := NewEventRepository(db)
+	gaRepo := NewGeneralAdmissionRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	suffix = suffix[len(suffix)-12:]
+	eventID := "cccccccc-1111-1111-1111-" + suffix
+	sectionID := "cccccccc-2222-2222-2222-" + suffix
+	holdA := "cccccccc-3333-3333-3333-" + suffix
+	holdB := "cccccccc-4444-4444-4444-" + suffix
+
+	if err := eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "GA Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")}); err != nil {
+		t.Fatalf("create event failed: %v", err)
+	}
+	if err := gaRepo.CreateSection(&models.GASection{BaseModel: models.BaseModel{ID: sectionID}, EventID: eventID, Name: "CAMPO", Price: money.New(5000, "ARS"), Capacity: 3}); err != nil {
+		t.Fatalf("create section failed: %v", err)
+	}
+
+	items := []models.GAItem{{SectionID: sectionID, Quantity: 2}}
+	if _, err := gaRepo.Hold(holdA, "u1", items, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("hold failed: %v", err)
+	}
+	var soldOut *utils.GASoldOutError
+	if _, err := gaRepo.Hold(holdB, "u2", items, time.Now().Add(time.Minute)); !errors.As(err, &soldOut) {
+		t.Fatalf("expected sold out, got %v", err)
+	}
+	if n, err := gaRepo.CountActiveByUser(eventID, "u1", time.Now()); err != nil || n != 2 {
+		t.Fatalf("expected 2 active tickets, got %d err=%v", n, err)
+	}
+	if err := gaRepo.DeleteSection(sectionID); !errors.Is(err, utils.ErrGASectionInUse) {
+		t.Fatalf("expected section in use, got %v", err)
+	}
+
+	released, err := gaRepo.ReleaseHold(holdA, "u1")
+	if err != nil || released != 2 {
+		t.Fatalf("expected 2 released, got %d err=%v", released, err)
+	}
+	section, _ := gaRepo.FindSectionByID(sectionID)
+	if section.Held != 0 || section.Available() != 3 {
+		t.Fatalf("expected capacity restored, got %+v", section)
+	}
+
+	// Un hold vencido lo libera el reaper
+	if _, err := gaRepo.Hold(holdB, "u2", items, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("hold failed: %v", err)
+	}
+	now := time.Now()
+	expired, err := gaRepo.FindExpiredHolds(now, 0)
+	if err != nil || len(expired) == 0 {
+		t.Fatalf("expected expired holds, got %v err=%v", expired, err)
+	}
+	if section, _ := gaRepo.FindSectionByID(sectionID); section.Held == 0 {
+		t.Fatalf("expected tickets still held until released")
+	}
+	ids := make([]string, 0, len(expired))
+	for _, h := range expired {
+		ids = append(ids, h.ID)
+	}
+	expired, err = gaRepo.ReleaseExpiredHolds(ids, now)
+	if err != nil || len(expired) == 0 {
+		t.Fatalf("expected expired holds released, got %v err=%v", expired, err)
+	}
+	if section, _ := gaRepo.FindSectionByID(sectionID); section.Held != 0 {
+		t.Fatalf("expected no held tickets, got %d", section.Held)
+	}
+
+	if err := gaRepo.DeleteSection(sectionID); err != nil {
+		t.Fatalf("delete section failed: %v", err)
+	}
+	_ = eventRepo.Delete(eventID)
+}
@@ -0,0 +1,387 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOrderNotFound      = utils.ErrOrderNotFound
+	ErrOrderSeatsMismatch = errors.New("payment seats do not match booking order")
+	ErrSeatsTaken         = errors.New("some seats are sold or held by another user")
+	// ErrPaymentEventProcessed indica que el evento ya se aplicó: reprocesarlo es un no-op
+	ErrPaymentEventProcessed = errors.New("payment event already processed")
+	// ErrOrderAlreadyPaid indica que la orden ya se confirmó con este mismo pago (otro evento
+	// de la pasarela por el mismo cobro): no hay nada que aplicar ni que reembolsar
+	ErrOrderAlreadyPaid = errors.New("order already paid with this payment")
+)
+
+// PaymentCompletion son los datos necesarios para confirmar un pago
+type PaymentCompletion struct {
+	EventID           string // ID del evento de Stripe (clave de idempotencia)
+	EventType         string
+	OrderID           string
+	SeatIDs           []string
+	SeatsFromOrder    bool            // Los IDs no entraban en la metadata de la pasarela: valen los de la orden
+	GAItems           []models.GAItem // Entradas de admisión general informadas por la pasarela
+	PaymentProvider   string
+	PaymentProviderID string
+	Currency          string
+	Amount            int64
+
+	CustomerEmail string
+	CustomerName  string
+	CustomerID    *string
+	// Huella de la tarjeta, se guarda en la orden para el límite de compra por tarjeta
+	CardFingerprint string
+}
+
+// PaymentFailure son los datos de un pago que no se va a completar (sesión vencida,
+// pago rechazado o cancelado)
+type PaymentFailure struct {
+	EventID           string
+	EventType         string
+	OrderID           string
+	Status            models.PaymentStatus // FAILED o EXPIRED
+	PaymentProviderID string
+}
+
+// PaymentRepository aplica las transiciones de pago de forma atómica
+type PaymentRepository interface {
+	CompletePayment(p *PaymentCompletion) (*models.Checkout, *models.TicketPDF, error)
+	FailPayment(f *PaymentFailure) (int64, error)
+}
+
+type paymentRepository struct {
+	db        *gorm.DB
+	publisher SeatChangePublisher // Opcional
+}
+
+func NewPaymentRepository(db *gorm.DB, publisher SeatChangePublisher) PaymentRepository {
+	return &paymentRepository{db: db, publisher: publisher}
+}
+
+// CompletePayment marca la orden COMPLETED, los asientos SOLD (y las entradas de admisión
+// general como vendidas), crea el checkout y el ticket y recalcula la disponibilidad del evento, todo en una sola transacción
+func (r *paymentRepository) CompletePayment(p *PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+	if p == nil || p.OrderID == "" {
+		return nil, nil, errors.New("order ID is required")
+	}
+	if p.EventID == "" {
+		return nil, nil, errors.New("event ID is required")
+	}
+
+	var checkout *models.Checkout
+	var ticket *models.TicketPDF
+	var sold []models.Seat
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Primero se registra el evento: si ya existía no se toca nada más
+		recorded, err := NewProcessedPaymentEventRepository(tx).MarkProcessed(p.EventID, p.EventType, p.OrderID)
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			return ErrPaymentEventProcessed
+		}
+
+		var order models.BookingOrder
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", p.OrderID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrOrderNotFound, p.OrderID)
+		}
+		if err != nil {
+			return err
+		}
+		if order.PaymentProviderID != "" && order.PaymentProviderID == p.PaymentProviderID {
+			switch order.Status {
+			case models.PaymentCompleted, models.PaymentRefunded, models.PaymentPartiallyRefunded:
+				return ErrOrderAlreadyPaid
+			}
+		}
+
+		seatIDs := order.SeatIDs
+		if len(seatIDs) == 0 && len(order.GAItems) == 0 {
+			seatIDs = p.SeatIDs
+		}
+		if len(seatIDs) == 0 && len(order.GAItems) == 0 {
+			return ErrOrderSeatsMismatch
+		}
+		if (!p.SeatsFromOrder && !sameIDs(seatIDs, p.SeatIDs)) || !models.SameGAItems(order.GAItems, p.GAItems) {
+			return ErrOrderSeatsMismatch
+		}
+
+		var seats []models.Seat
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", seatIDs).
+			Order("id").
+			Find(&seats).Error; err != nil {
+			return err
+		}
+		if len(seats) != len(seatIDs) {
+			return ErrOrderSeatsMismatch
+		}
+
+		// Un asiento vendido o bloqueado por otro usuario no se puede pisar
+		for _, s := range seats {
+			takenByOther := s.Status == models.StatusLocked && (s.LockedBy == nil || *s.LockedBy != order.UserID)
+			if s.Status == models.StatusSold || takenByOther {
+				return fmt.Errorf("%w: %s", ErrSeatsTaken, s.ID)
+			}
+		}
+
+		if !order.Status.CanTransitionTo(models.PaymentCompleted) {
+			return &utils.InvalidTransitionError{From: string(order.Status), To: string(models.PaymentCompleted)}
+		}
+		// La tarjeta recién se conoce con el pago: el límite por tarjeta se controla acá
+		if err := enforceCardLimit(tx, &order, p.CardFingerprint); err != nil {
+			return err
+		}
+		if err := applyStatusChange(tx, &StatusChange{
+			OrderID:           order.ID,
+			From:              order.Status,
+			To:                models.PaymentCompleted,
+			Version:           order.Version,
+			PaymentProviderID: p.PaymentProviderID,
+			ChangedBy:         "payment-consumer",
+			Reason:            p.EventType + " " + p.EventID,
+		}); err != nil {
+			return err
+		}
+
+		// Lo cobrado según la pasarela; si no lo informa se toma el total de la orden
+		total := money.New(p.Amount, p.Currency)
+		if total.Currency == "" {
+			total.Currency = order.Total.Currency
+		}
+		if total.Amount == 0 {
+			total.Amount = order.Total.Amount
+		}
+
+		// La pasarela con la que se abrió la orden manda sobre la que informa el mensaje
+		provider := order.PaymentProvider
+		if provider == "" {
+			provider = p.PaymentProvider
+		}
+
+		checkout = &models.Checkout{
+			OrderID:         order.ID,
+			PaymentProvider: provider,
+			PaymentIntentID: p.PaymentProviderID,
+			Total:           total,
+			CustomerEmail:   p.CustomerEmail,
+			CustomerName:    p.CustomerName,
+			CustomerID:      p.CustomerID,
+		}
+		if err := tx.Omit("Order").Create(checkout).Error; err != nil {
+			return fmt.Errorf("failed to create checkout: %w", err)
+		}
+
+		// Tarjeta y email del pagador quedan en la orden para los límites de compra del evento
+		buyer := map[string]any{}
+		if p.CardFingerprint != "" {
+			buyer["card_fingerprint"] = p.CardFingerprint
+		}
+		if order.CustomerEmail == "" && p.CustomerEmail != "" {
+			buyer["customer_email"] = models.NormalizeEmail(p.CustomerEmail)
+		}
+		if len(buyer) > 0 {
+			if err := tx.Model(&models.BookingOrder{}).Where("id = ?", order.ID).Updates(buyer).Error; err != nil {
+				return fmt.Errorf("failed to record buyer: %w", err)
+			}
+		}
+
+		ticket = &models.TicketPDF{
+			PaymentProvider: checkout.PaymentProvider,
+			PaymentIntentID: checkout.PaymentIntentID,
+			Currency:        checkout.Total.Currency,
+			Amount:          checkout.Total.Amount,
+			Name:            checkout.CustomerName,
+			Email:           checkout.CustomerEmail,
+			CustomerID:      checkout.CustomerID,
+			OrderID:         order.ID,
+			PDFVersion:      1,
+		}
+		if err := tx.Create(ticket).Error; err != nil {
+			return fmt.Errorf("failed to create ticket: %w", err)
+		}
+		if err := tx.Where("order_id = ?", order.ID).Order("position").Find(&ticket.Lines).Error; err != nil {
+			return fmt.Errorf("failed to load order lines: %w", err)
+		}
+
+		if err := sellGAItems(tx, &order, ticket.ID, time.Now()); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Seat{}).Where("id IN ?", seatIDs).Updates(map[string]any{
+			"status":          models.StatusSold,
+			"locked_by":       nil,
+			"locked_at":       nil,
+			"lock_expires_at": nil,
+			"hold_id":         nil,
+			"hold_extensions": 0,
+			"ticket_id":       ticket.ID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to mark seats sold: %w", err)
+		}
+
+		events := make(map[string]struct{})
+		for _, s := range seats {
+			events[s.EventID] = struct{}{}
+		}
+		eventRepo := &eventRepository{db: tx}
+		for eventID := range events {
+			if err := eventRepo.UpdateAvailability(eventID); err != nil {
+				return fmt.Errorf("failed to update availability: %w", err)
+			}
+		}
+
+		sold = seats
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publishSeatChanges(r.publisher, models.SeatChangesFor(sold, models.StatusSold, nil))
+	return checkout, ticket, nil
+}
+
+// FailPayment pasa la orden pendiente a FAILED/EXPIRED y libera los asientos (y las entradas de
+// admisión general) de la orden que sigan bloqueados por ella. Devuelve la cantidad liberada
+func (r *paymentRepository) FailPayment(f *PaymentFailure) (int64, error) {
+	if f == nil || f.OrderID == "" {
+		return 0, errors.New("order ID is required")
+	}
+	if f.EventID == "" {
+		return 0, errors.New("event ID is required")
+	}
+
+	var released int64
+	var releasedSeats []models.Seat
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		recorded, err := NewProcessedPaymentEventRepository(tx).MarkProcessed(f.EventID, f.EventType, f.OrderID)
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			return ErrPaymentEventProcessed
+		}
+
+		var order models.BookingOrder
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", f.OrderID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrOrderNotFound, f.OrderID)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch order.Status {
+		case models.PaymentPending:
+			if err := applyStatusChange(tx, &StatusChange{
+				OrderID:           order.ID,
+				From:              order.Status,
+				To:                f.Status,
+				Version:           order.Version,
+				PaymentProviderID: f.PaymentProviderID,
+				ChangedBy:         "payment-consumer",
+				Reason:            f.EventType + " " + f.EventID,
+			}); err != nil {
+				return err
+			}
+		case models.PaymentFailed, models.PaymentExpired, models.PaymentCancelled:
+			// La orden ya se cerró (otro evento o el lock reaper): solo se liberan los asientos que queden
+		default:
+			return &utils.InvalidTransitionError{From: string(order.Status), To: string(f.Status)}
+		}
+
+		gaReleased, err := releaseOrderGAHolds(tx, &order)
+		if err != nil {
+			return err
+		}
+		released = gaReleased
+
+		if len(order.SeatIDs) == 0 {
+			return nil
+		}
+
+		// Solo se liberan los asientos que siguen bloqueados por esta orden: si el hold venció
+		// y otro usuario (o el mismo, con otra orden) los tomó, no se tocan
+		held := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status = ?", order.SeatIDs, models.StatusLocked)
+		if order.HoldID != nil {
+			held = held.Where("hold_id = ?", *order.HoldID)
+		} else {
+			held = held.Where("locked_by = ?", order.UserID)
+		}
+
+		var seats []models.Seat
+		if err := held.Find(&seats).Error; err != nil {
+			return err
+		}
+		if len(seats) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(seats))
+		events := make(map[string]struct{})
+		for _, s := range seats {
+			ids = append(ids, s.ID)
+			events[s.EventID] = struct{}{}
+		}
+
+		result := tx.Model(&models.Seat{}).Where("id IN ?", ids).Updates(map[string]any{
+			"status":          models.StatusAvailable,
+			"locked_by":       nil,
+			"locked_at":       nil,
+			"lock_expires_at": nil,
+			"hold_id":         nil,
+			"hold_extensions": 0,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to release seats: %w", result.Error)
+		}
+		released += result.RowsAffected
+		releasedSeats = seats
+
+		eventRepo := &eventRepository{db: tx}
+		for eventID := range events {
+			if err := eventRepo.UpdateAvailability(eventID); err != nil {
+				return fmt.Errorf("failed to update availability: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	publishSeatChanges(r.publisher, models.SeatChangesFor(releasedSeats, models.StatusAvailable, nil))
+	return released, nil
+}
+
+// sameIDs compara dos listas de IDs sin importar el orden
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]int, len(a))
+	for _, id := range a {
+		set[id]++
+	}
+	for _, id := range b {
+		if set[id] == 0 {
+			return false
+		}
+		set[id]--
+	}
+	return true
+}
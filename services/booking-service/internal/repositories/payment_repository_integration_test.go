@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPaymentRepository_Integration_CompletePayment(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewPaymentRepository(db, nil)
+	orderRepo := NewBookingOrderRepository(db)
+	eventRepo := NewEventRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "cccccccc-1111-1111-1111-" + suffix[len(suffix)-12:]
+	seatID := "cccccccc-2222-2222-2222-" + suffix[len(suffix)-12:]
+	orderID := "cccccccc-3333-3333-3333-" + suffix[len(suffix)-12:]
+	userID := "cccccccc-4444-4444-4444-" + suffix[len(suffix)-12:]
+
+	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Payment Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seatID}, EventID: eventID, Section: "A", Number: "1", Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	_ = orderRepo.Create(&models.BookingOrder{BaseModel: models.BaseModel{ID: orderID}, UserID: userID, Total: money.New(1000, "ARS"), Status: models.PaymentPending, SeatIDs: []string{seatID}})
+
+	if err := seatRepo.LockSeats([]string{seatID}, userID, "cccccccc-5555-5555-5555-"+suffix[len(suffix)-12:], time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatalf("lock seats failed: %v", err)
+	}
+
+	completion := &PaymentCompletion{
+		EventID:           "evt_" + suffix,
+		EventType:         "checkout.session.completed",
+		OrderID:           orderID,
+		SeatIDs:           []string{seatID},
+		PaymentProvider:   "STRIPE",
+		PaymentProviderID: "pi_" + suffix,
+		Currency:          "usd",
+		Amount:            1000,
+		CustomerEmail:     "buyer@example.com",
+		CustomerName:      "Buyer",
+	}
+
+	checkout, ticket, err := repo.CompletePayment(completion)
+	if err != nil {
+		t.Fatalf("complete payment failed: %v", err)
+	}
+	if checkout.ID == "" || ticket.ID == "" {
+		t.Fatalf("expected checkout and ticket to be created")
+	}
+
+	order, _ := orderRepo.FindByID(orderID)
+	if order.Status != models.PaymentCompleted || order.PaymentProviderID != completion.PaymentProviderID {
+		t.Fatalf("expected completed order, got %+v", order)
+	}
+
+	seat, _ := seatRepo.FindByID(seatID)
+	if seat.Status != models.StatusSold || seat.LockedBy != nil || seat.TicketID == nil || *seat.TicketID != ticket.ID {
+		t.Fatalf("expected sold seat linked to ticket, got %+v", seat)
+	}
+
+	event, _ := eventRepo.FindByID(eventID)
+	if event.Availability != models.AvailabilitySoldOut {
+		t.Fatalf("expected SOLD_OUT availability, got %s", event.Availability)
+	}
+
+	// Reprocesar el mismo evento es un no-op
+	if _, _, err := repo.CompletePayment(completion); !errors.Is(err, ErrPaymentEventProcessed) {
+		t.Fatalf("expected ErrPaymentEventProcessed on replay, got %v", err)
+	}
+
+	// Otro evento sobre asientos ya vendidos se revierte entero (tampoco queda registrado)
+	other := *completion
+	other.EventID = "evt_other_" + suffix
+	if _, _, err := repo.CompletePayment(&other); !errors.Is(err, ErrSeatsTaken) {
+		t.Fatalf("expected ErrSeatsTaken for a different event, got %v", err)
+	}
+	if processed, _ := NewProcessedPaymentEventRepository(db).IsProcessed(other.EventID); processed {
+		t.Fatalf("expected rolled back event not to be recorded")
+	}
+
+	var checkouts, tickets int64
+	db.Model(&models.Checkout{}).Where("order_id = ?", orderID).Count(&checkouts)
+	db.Model(&models.TicketPDF{}).Where("order_id = ?", orderID).Count(&tickets)
+	if checkouts != 1 || tickets != 1 {
+		t.Fatalf("expected a single checkout and ticket, got %d/%d", checkouts, tickets)
+	}
+}
+
+// Con muchos asientos Stripe no recibe los IDs en la metadata: la orden es la fuente de verdad
+func TestPaymentRepository_Integration_CompletePaymentSeatsFromOrder(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewPaymentRepository(db, nil)
+	orderRepo := NewBookingOrderRepository(db)
+	eventRepo := NewEventRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "c0c0c0c0-1111-1111-1111-" + suffix[len(suffix)-12:]
+	orderID := "c0c0c0c0-3333-3333-3333-" + suffix[len(suffix)-12:]
+	userID := "c0c0c0c0-4444-4444-4444-" + suffix[len(suffix)-12:]
+
+	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Many Seats Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")})
+	seatIDs := make([]string, 20)
+	for i := range seatIDs {
+		seatIDs[i] = fmt.Sprintf("c0c0c0c0-2222-2222-%04d-%s", i, suffix[len(suffix)-12:])
+		_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seatIDs[i]}, EventID: eventID, Section: "A", Number: fmt.Sprintf("%d", i+1), Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	}
+	_ = orderRepo.Create(&models.BookingOrder{BaseModel: models.BaseModel{ID: orderID}, UserID: userID, Total: money.New(20000, "ARS"), Status: models.PaymentPending, SeatIDs: seatIDs})
+
+	if err := seatRepo.LockSeats(seatIDs, userID, "c0c0c0c0-5555-5555-5555-"+suffix[len(suffix)-12:], time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatalf("lock seats failed: %v", err)
+	}
+
+	_, ticket, err := repo.CompletePayment(&PaymentCompletion{
+		EventID:           "evt_many_" + suffix,
+		EventType:         "checkout.session.completed",
+		OrderID:           orderID,
+		SeatsFromOrder:    true,
+		PaymentProvider:   "STRIPE",
+		PaymentProviderID: "pi_many_" + suffix,
+		Amount:            20000,
+		CustomerEmail:     "buyer@example.com",
+		CustomerName:      "Buyer",
+	})
+	if err != nil {
+		t.Fatalf("complete payment failed: %v", err)
+	}
+
+	seats, _ := seatRepo.FindByIDs(seatIDs)
+	if len(seats) != 20 {
+		t.Fatalf("expected 20 seats, got %d", len(seats))
+	}
+	for _, s := range seats {
+		if s.Status != models.StatusSold || s.TicketID == nil || *s.TicketID != ticket.ID {
+			t.Fatalf("expected every seat sold with the ticket, got %+v", s)
+		}
+	}
+	if order, _ := orderRepo.FindByID(orderID); order.Status != models.PaymentCompleted {
+		t.Fatalf("expected completed order, got %s", order.Status)
+	}
+}
+
+func TestPaymentRepository_Integration_FailPaymentReleasesOrderSeats(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewPaymentRepository(db, nil)
+	orderRepo := NewBookingOrderRepository(db)
+	eventRepo := NewEventRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "eeeeeeee-1111-1111-1111-" + suffix[len(suffix)-12:]
+	heldSeat := "eeeeeeee-2222-2222-2222-" + suffix[len(suffix)-12:]
+	retakenSeat := "eeeeeeee-2222-2222-3333-" + suffix[len(suffix)-12:]
+	orderID := "eeeeeeee-3333-3333-3333-" + suffix[len(suffix)-12:]
+	userID := "eeeeeeee-4444-4444-4444-" + suffix[len(suffix)-12:]
+	holdID := "eeeeeeee-5555-5555-5555-" + suffix[len(suffix)-12:]
+	otherHold := "eeeeeeee-6666-6666-6666-" + suffix[len(suffix)-12:]
+
+	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Expired Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: heldSeat}, EventID: eventID, Section: "A", Number: "1", Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: retakenSeat}, EventID: eventID, Section: "A", Number: "2", Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	_ = orderRepo.Create(&models.BookingOrder{BaseModel: models.BaseModel{ID: orderID}, UserID: userID, Total: money.New(2000, "ARS"), Status: models.PaymentPending, SeatIDs: []string{heldSeat, retakenSeat}, HoldID: &holdID})
+
+	if err := seatRepo.LockSeats([]string{heldSeat}, userID, holdID, time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatalf("lock seats failed: %v", err)
+	}
+	// El segundo asiento quedó en otro hold (del mismo usuario): no pertenece a esta orden
+	if err := seatRepo.LockSeats([]string{retakenSeat}, userID, otherHold, time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatalf("lock seats failed: %v", err)
+	}
+
+	failure := &PaymentFailure{EventID: "evt_exp_" + suffix, EventType: "checkout.session.expired", OrderID: orderID, Status: models.PaymentExpired}
+	released, err := repo.FailPayment(failure)
+	if err != nil {
+		t.Fatalf("fail payment failed: %v", err)
+	}
+	if released != 1 {
+		t.Fatalf("expected 1 released seat, got %d", released)
+	}
+
+	order, _ := orderRepo.FindByID(orderID)
+	if order.Status != models.PaymentExpired {
+		t.Fatalf("expected expired order, got %s", order.Status)
+	}
+	if seat, _ := seatRepo.FindByID(heldSeat); seat.Status != models.StatusAvailable || seat.HoldID != nil {
+		t.Fatalf("expected held seat released, got %+v", seat)
+	}
+	if seat, _ := seatRepo.FindByID(retakenSeat); seat.Status != models.StatusLocked {
+		t.Fatalf("expected seat in another hold to stay locked, got %s", seat.Status)
+	}
+
+	if _, err := repo.FailPayment(failure); !errors.Is(err, ErrPaymentEventProcessed) {
+		t.Fatalf("expected ErrPaymentEventProcessed on replay, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PresaleRepository maneja la ventana de venta de los eventos y los códigos de preventa
+type PresaleRepository interface {
+	// FindSalesWindow devuelve el evento solo con las columnas de la ventana de venta; nil si no existe
+	FindSalesWindow(eventID string) (*models.Event, error)
+	HasAccess(eventID, userID string) (bool, error)
+
+	CreateCode(code *models.PresaleCode) error
+	FindCodeByID(id string) (*models.PresaleCode, error)
+	FindCodesByEventID(eventID string) ([]models.PresaleCode, error)
+	DeleteCode(id string) error
+	Redeem(eventID, userID, code string) (*models.PresaleAccess, error)
+}
+
+type presaleRepository struct {
+	db *gorm.DB
+}
+
+func NewPresaleRepository(db *gorm.DB) PresaleRepository {
+	return &presaleRepository{db: db}
+}
+
+func (r *presaleRepository) FindSalesWindow(eventID string) (*models.Event, error) {
+	var event models.Event
+	err := r.db.Select("id", "sales_start", "sales_end", "presale_start").First(&event, "id = ?", eventID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *presaleRepository) HasAccess(eventID, userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.PresaleAccess{}).
+		Where("event_id = ? AND user_id = ?", eventID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *presaleRepository) CreateCode(code *models.PresaleCode) error {
+	return r.db.Create(code).Error
+}
+
+func (r *presaleRepository) FindCodeByID(id string) (*models.PresaleCode, error) {
+	var code models.PresaleCode
+	err := r.db.First(&code, "id = ?", id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &code, err
+}
+
+func (r *presaleRepository) FindCodesByEventID(eventID string) ([]models.PresaleCode, error) {
+	var codes []models.PresaleCode
+	err := r.db.Where("event_id = ?", eventID).Order("created_at").Find(&codes).Error
+	return codes, err
+}
+
+// DeleteCode borra el código de verdad para poder volver a crearlo con el mismo texto.
+// Los usuarios que ya lo canjearon conservan el acceso
+func (r *presaleRepository) DeleteCode(id string) error {
+	res := r.db.Unscoped().Delete(&models.PresaleCode{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return utils.ErrPresaleCodeNotFound
+	}
+	return nil
+}
+
+// Redeem canjea el código para el usuario. Si el usuario ya tiene acceso a la preventa del
+// evento lo devuelve sin gastar otro uso. El código se bloquea con FOR UPDATE para que dos
+// canjes simultáneos no superen MaxUses
+func (r *presaleRepository) Redeem(eventID, userID, code string) (*models.PresaleAccess, error) {
+	var access *models.PresaleAccess
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var presale models.PresaleCode
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("event_id = ? AND code = ?", eventID, code).
+			Take(&presale).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrPresaleCodeNotFound
+		}
+		if err != nil {
+			return err
+		}
+		// Un código personal no se revela a otros usuarios
+		if presale.UserID != nil && *presale.UserID != userID {
+			return utils.ErrPresaleCodeNotFound
+		}
+
+		var existing models.PresaleAccess
+		err = tx.Where("event_id = ? AND user_id = ?", eventID, userID).Take(&existing).Error
+		if err == nil {
+			access = &existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if presale.Uses >= presale.MaxUses {
+			return utils.ErrPresaleCodeExhausted
+		}
+		if err := tx.Model(&presale).UpdateColumn("uses", gorm.Expr("uses + 1")).Error; err != nil {
+			return err
+		}
+
+		access = &models.PresaleAccess{EventID: eventID, UserID: userID, CodeID: presale.ID}
+		return tx.Create(access).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return access, nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPresaleRepository_Integration_Redeem(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewPresaleRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "66666666-6666-6666-6666-" + suffix[len(suffix)-12:]
+	salesStart := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	presaleStart := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	if err := NewEventRepository(db).Create(&models.Event{
+		BaseModel:    models.BaseModel{ID: eventID},
+		Name:         "IT Presale " + suffix,
+		Date:         time.Now().Add(24 * time.Hour),
+		Price:        money.New(5000, "ARS"),
+		SalesStart:   &salesStart,
+		PresaleStart: &presaleStart,
+	}); err != nil {
+		t.Fatalf("create event failed: %v", err)
+	}
+
+	window, err := repo.FindSalesWindow(eventID)
+	if err != nil || window == nil || window.SalesStart == nil || !window.SalesStart.Equal(salesStart) {
+		t.Fatalf("unexpected sales window: %+v err=%v", window, err)
+	}
+
+	single := &models.PresaleCode{EventID: eventID, Code: "FANS-" + suffix[len(suffix)-6:], MaxUses: 1}
+	if err := repo.CreateCode(single); err != nil {
+		t.Fatalf("create code failed: %v", err)
+	}
+	owner := "u-owner"
+	personal := &models.PresaleCode{EventID: eventID, Code: "VIP-" + suffix[len(suffix)-6:], MaxUses: 1, UserID: &owner}
+	if err := repo.CreateCode(personal); err != nil {
+		t.Fatalf("create code failed: %v", err)
+	}
+
+	access, err := repo.Redeem(eventID, "u1", single.Code)
+	if err != nil || access.CodeID != single.ID {
+		t.Fatalf("redeem failed: %+v err=%v", access, err)
+	}
+	// Canjear de nuevo no gasta otro uso
+	if again, err := repo.Redeem(eventID, "u1", single.Code); err != nil || again.ID != access.ID {
+		t.Fatalf("expected same access on second redeem, got %+v err=%v", again, err)
+	}
+	if _, err := repo.Redeem(eventID, "u2", single.Code); !errors.Is(err, utils.ErrPresaleCodeExhausted) {
+		t.Fatalf("expected exhausted code, got %v", err)
+	}
+	if _, err := repo.Redeem(eventID, "u2", personal.Code); !errors.Is(err, utils.ErrPresaleCodeNotFound) {
+		t.Fatalf("expected personal code hidden from other users, got %v", err)
+	}
+	if _, err := repo.Redeem(eventID, owner, personal.Code); err != nil {
+		t.Fatalf("owner redeem failed: %v", err)
+	}
+
+	if ok, err := repo.HasAccess(eventID, "u1"); err != nil || !ok {
+		t.Fatalf("expected u1 access, got %v err=%v", ok, err)
+	}
+	if ok, err := repo.HasAccess(eventID, "u2"); err != nil || ok {
+		t.Fatalf("expected no access for u2, got %v err=%v", ok, err)
+	}
+
+	stored, err := repo.FindCodeByID(single.ID)
+	if err != nil || stored.Uses != 1 {
+		t.Fatalf("expected 1 use, got %+v err=%v", stored, err)
+	}
+
+	if err := repo.DeleteCode(single.ID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := repo.DeleteCode(single.ID); !errors.Is(err, utils.ErrPresaleCodeNotFound) {
+		t.Fatalf("expected not found on second delete, got %v", err)
+	}
+	// El acceso canjeado sobrevive al borrado del código
+	if ok, _ := repo.HasAccess(eventID, "u1"); !ok {
+		t.Fatalf("expected access to survive code deletion")
+	}
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PriceTierRepository interface {
+	Create(tier *models.PriceTier) error
+	FindByID(id string) (*models.PriceTier, error)
+	FindByEventID(eventID string) ([]models.PriceTier, error)
+	Update(tier *models.PriceTier) error
+	Delete(id string) error
+
+	CountSeats(tierID string) (int64, error)
+	Reprice(tierID string, price money.Money) (RepriceResult, error)
+}
+
+// RepriceResult cuenta los asientos de la categoría alcanzados por un cambio de precio
+type RepriceResult struct {
+	Repriced int64 // Asientos AVAILABLE que tomaron el nuevo precio
+	Skipped  int64 // Asientos LOCKED: conservan el precio del checkout en curso
+}
+
+type priceTierRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceTierRepository(db *gorm.DB) PriceTierRepository {
+	return &priceTierRepository{db: db}
+}
+
+func (r *priceTierRepository) Create(tier *models.PriceTier) error {
+	return r.db.Create(tier).Error
+}
+
+func (r *priceTierRepository) FindByID(id string) (*models.PriceTier, error) {
+	var tier models.PriceTier
+	err := r.db.First(&tier, "id = ?", id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &tier, err
+}
+
+func (r *priceTierRepository) FindByEventID(eventID string) ([]models.PriceTier, error) {
+	var tiers []models.PriceTier
+	err := r.db.Where("event_id = ?", eventID).Order("price_amount DESC, name").Find(&tiers).Error
+	return tiers, err
+}
+
+// Actualiza los datos de la categoría. Los asientos cuya sección era el nombre anterior
+// se renombran; los generados desde un recinto conservan la sección del plano
+func (r *priceTierRepository) Update(tier *models.PriceTier) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var previous models.PriceTier
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&previous, "id = ?", tier.ID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(tier).
+			Select("name", "color", "capacity", "description").
+			Updates(tier).Error; err != nil {
+			return err
+		}
+
+		if previous.Name == tier.Name {
+			return nil
+		}
+		return tx.Model(&models.Seat{}).
+			Where("tier_id = ? AND section = ?", tier.ID, previous.Name).
+			Update("section", tier.Name).Error
+	})
+}
+
+// Borra la categoría solo si ya no tiene asientos
+func (r *priceTierRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var seats int64
+		if err := tx.Model(&models.Seat{}).Where("tier_id = ?", id).Count(&seats).Error; err != nil {
+			return err
+		}
+		if seats > 0 {
+			return utils.ErrTierInUse
+		}
+
+		return tx.Delete(&models.PriceTier{}, "id = ?", id).Error
+	})
+}
+
+func (r *priceTierRepository) CountSeats(tierID string) (int64, error) {
+	var seats int64
+	err := r.db.Model(&models.Seat{}).Where("tier_id = ?", tierID).Count(&seats).Error
+	return seats, err
+}
+
+// Cambia el precio de la categoría y de sus asientos disponibles en una sola transacción.
+// Los asientos SOLD conservan el precio con el que se cobraron y los LOCKED el del checkout que
+// los bloqueó (se informan como salteados). Devuelve los asientos actualizados y salteados
+func (r *priceTierRepository) Reprice(tierID string, price money.Money) (RepriceResult, error) {
+	var result RepriceResult
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var tier models.PriceTier
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tier, "id = ?", tierID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrTierNotFound
+			}
+			return err
+		}
+
+		if err := tx.Model(&tier).Update("price_amount", price.Amount).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&models.Seat{}).
+			Where("tier_id = ? AND status = ?", tierID, models.StatusAvailable).
+			Update("price_amount", price.Amount)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Repriced = res.RowsAffected
+
+		return tx.Model(&models.Seat{}).
+			Where("tier_id = ? AND status = ?", tierID, models.StatusLocked).
+			Count(&result.Skipped).Error
+	})
+	if err != nil {
+		return RepriceResult{}, err
+	}
+
+	return result, nil
+}
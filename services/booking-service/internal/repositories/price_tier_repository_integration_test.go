@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPriceTierRepository_Integration_RepriceSkipsSoldAndLockedSeats(t *testing.T) {
+	db := openIntegrationDB(t)
+	eventRepo := NewEventRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
+	tierRepo := NewPriceTierRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	suffix = suffix[len(suffix)-12:]
+	eventID := "bbbbbbbb-1111-1111-1111-" + suffix
+	tierID := "bbbbbbbb-2222-2222-2222-" + suffix
+	freeID := "bbbbbbbb-3333-3333-3333-" + suffix
+	soldID := "bbbbbbbb-4444-4444-4444-" + suffix
+	lockedID := "bbbbbbbb-6666-6666-6666-" + suffix
+
+	if err := eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Tier Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")}); err != nil {
+		t.Fatalf("create event failed: %v", err)
+	}
+	if err := tierRepo.Create(&models.PriceTier{BaseModel: models.BaseModel{ID: tierID}, EventID: eventID, Name: "VIP", Price: money.New(5000, "ARS"), Capacity: 10}); err != nil {
+		t.Fatalf("create tier failed: %v", err)
+	}
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: freeID}, EventID: eventID, TierID: &tierID, Section: "VIP", Number: "1", Price: money.New(5000, "ARS"), Status: models.StatusAvailable})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: soldID}, EventID: eventID, TierID: &tierID, Section: "VIP", Number: "2", Price: money.New(5000, "ARS"), Status: models.StatusSold})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: lockedID}, EventID: eventID, TierID: &tierID, Section: "VIP", Number: "3", Price: money.New(5000, "ARS"), Status: models.StatusAvailable})
+	if err := seatRepo.LockSeats([]string{lockedID}, "u1", "bbbbbbbb-7777-7777-7777-"+suffix, time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatalf("lock seats failed: %v", err)
+	}
+
+	result, err := tierRepo.Reprice(tierID, money.New(7000, "ARS"))
+	if err != nil || result.Repriced != 1 || result.Skipped != 1 {
+		t.Fatalf("reprice failed: result=%+v err=%v", result, err)
+	}
+
+	free, _ := seatRepo.FindByID(freeID)
+	sold, _ := seatRepo.FindByID(soldID)
+	locked, _ := seatRepo.FindByID(lockedID)
+	if free.Price.Amount != 7000 || sold.Price.Amount != 5000 || locked.Price.Amount != 5000 {
+		t.Fatalf("expected free=7000 sold=5000 locked=5000, got free=%d sold=%d locked=%d", free.Price.Amount, sold.Price.Amount, locked.Price.Amount)
+	}
+	tier, err := tierRepo.FindByID(tierID)
+	if err != nil || tier.Price.Amount != 7000 {
+		t.Fatalf("expected tier repriced, got %v err=%v", tier, err)
+	}
+
+	tier.Name = "Palco"
+	if err := tierRepo.Update(tier); err != nil {
+		t.Fatalf("update tier failed: %v", err)
+	}
+	if free, _ := seatRepo.FindByID(freeID); free.Section != "Palco" {
+		t.Fatalf("expected seat section renamed, got %s", free.Section)
+	}
+
+	if err := tierRepo.Delete(tierID); !errors.Is(err, utils.ErrTierInUse) {
+		t.Fatalf("expected tier in use, got %v", err)
+	}
+	if _, err := tierRepo.Reprice("bbbbbbbb-5555-5555-5555-"+suffix, money.New(1, "ARS")); !errors.Is(err, utils.ErrTierNotFound) {
+		t.Fatalf("expected tier not found, got %v", err)
+	}
+
+	_ = eventRepo.Delete(eventID)
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// PricingRepository maneja las reglas de cargos y los impuestos de eventos y recintos
+type PricingRepository interface {
+	CreateFeeRule(rule *models.FeeRule) error
+	// FindFeeRules lista las reglas; eventID y venueID vacíos no filtran
+	FindFeeRules(eventID, venueID string) ([]models.FeeRule, error)
+	DeleteFeeRule(id string) error
+
+	CreateTaxRate(rate *models.TaxRate) error
+	FindTaxRates(eventID, venueID string) ([]models.TaxRate, error)
+	DeleteTaxRate(id string) error
+
+	// FindRulesForEvent devuelve los cargos e impuestos que se cobran en el evento: los propios
+	// del evento o, si no tiene, los de su recinto
+	FindRulesForEvent(eventID string) ([]models.FeeRule, []models.TaxRate, error)
+}
+
+type pricingRepository struct {
+	db *gorm.DB
+}
+
+func NewPricingRepository(db *gorm.DB) PricingRepository {
+	return &pricingRepository{db: db}
+}
+
+func (r *pricingRepository) CreateFeeRule(rule *models.FeeRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *pricingRepository) FindFeeRules(eventID, venueID string) ([]models.FeeRule, error) {
+	var rules []models.FeeRule
+	err := scopeRules(r.db, eventID, venueID).Order("created_at").Find(&rules).Error
+	return rules, err
+}
+
+func (r *pricingRepository) DeleteFeeRule(id string) error {
+	res := r.db.Delete(&models.FeeRule{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return utils.ErrFeeRuleNotFound
+	}
+	return nil
+}
+
+func (r *pricingRepository) CreateTaxRate(rate *models.TaxRate) error {
+	return r.db.Create(rate).Error
+}
+
+func (r *pricingRepository) FindTaxRates(eventID, venueID string) ([]models.TaxRate, error) {
+	var rates []models.TaxRate
+	err := scopeRules(r.db, eventID, venueID).Order("created_at").Find(&rates).Error
+	return rates, err
+}
+
+func (r *pricingRepository) DeleteTaxRate(id string) error {
+	res := r.db.Delete(&models.TaxRate{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return utils.ErrTaxRateNotFound
+	}
+	return nil
+}
+
+func (r *pricingRepository) FindRulesForEvent(eventID string) ([]models.FeeRule, []models.TaxRate, error) {
+	var event models.Event
+	err := r.db.Select("id", "venue_id").First(&event, "id = ?", eventID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, utils.ErrEventNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fees, err := r.FindFeeRules(eventID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	taxes, err := r.FindTaxRates(eventID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if event.VenueID == nil || *event.VenueID == "" {
+		return fees, taxes, nil
+	}
+	if len(fees) == 0 {
+		if fees, err = r.FindFeeRules("", *event.VenueID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(taxes) == 0 {
+		if taxes, err = r.FindTaxRates("", *event.VenueID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return fees, taxes, nil
+}
+
+// scopeRules filtra por evento y recinto. Las reglas de un recinto no tienen evento
+func scopeRules(db *gorm.DB, eventID, venueID string) *gorm.DB {
+	if eventID != "" {
+		db = db.Where("event_id = ?", eventID)
+	}
+	if venueID != "" {
+		db = db.Where("venue_id = ? AND event_id IS NULL", venueID)
+	}
+	return db
+}
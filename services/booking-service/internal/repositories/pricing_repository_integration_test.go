@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPricingRepository_Integration_EventRulesOverrideVenue(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewPricingRepository(db)
+	eventRepo := NewEventRepository(db)
+	venueRepo := NewVenueRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	suffix = suffix[len(suffix)-12:]
+	eventID := "dddddddd-3333-3333-3333-" + suffix
+
+	venue := &models.Venue{Name: "Pricing Venue " + suffix}
+	if err := venueRepo.Create(venue); err != nil {
+		t.Fatalf("create venue failed: %v", err)
+	}
+	if err := eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Pricing Event", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS"), VenueID: &venue.ID}); err != nil {
+		t.Fatalf("create event failed: %v", err)
+	}
+
+	venueFee := &models.FeeRule{VenueID: &venue.ID, Type: models.FeeFacility, Name: "Cargo del recinto", Amount: money.New(100, "ARS")}
+	venueTax := &models.TaxRate{VenueID: &venue.ID, Name: "IVA 21%", RateBps: 2100, AppliesTo: []models.OrderLineKind{models.LineServiceFee}}
+	if err := repo.CreateFeeRule(venueFee); err != nil {
+		t.Fatalf("create fee rule failed: %v", err)
+	}
+	if err := repo.CreateTaxRate(venueTax); err != nil {
+		t.Fatalf("create tax rate failed: %v", err)
+	}
+
+	// Sin reglas propias el evento usa las de su recinto
+	fees, taxes, err := repo.FindRulesForEvent(eventID)
+	if err != nil || len(fees) != 1 || fees[0].ID != venueFee.ID || len(taxes) != 1 || taxes[0].AppliesTo[0] != models.LineServiceFee {
+		t.Fatalf("expected venue rules, got %+v %+v err=%v", fees, taxes, err)
+	}
+
+	eventFee := &models.FeeRule{EventID: &eventID, Type: models.FeeService, Name: "Cargo por servicio", RateBps: 1000}
+	if err := repo.CreateFeeRule(eventFee); err != nil {
+		t.Fatalf("create fee rule failed: %v", err)
+	}
+	fees, taxes, err = repo.FindRulesForEvent(eventID)
+	if err != nil || len(fees) != 1 || fees[0].ID != eventFee.ID || len(taxes) != 1 {
+		t.Fatalf("expected event fees and venue taxes, got %+v %+v err=%v", fees, taxes, err)
+	}
+
+	if listed, err := repo.FindFeeRules("", venue.ID); err != nil || len(listed) != 1 {
+		t.Fatalf("expected one venue fee rule, got %+v err=%v", listed, err)
+	}
+	if _, _, err := repo.FindRulesForEvent("dddddddd-3333-3333-3333-000000000000"); !errors.Is(err, utils.ErrEventNotFound) {
+		t.Fatalf("expected ErrEventNotFound, got %v", err)
+	}
+
+	if err := repo.DeleteFeeRule(eventFee.ID); err != nil {
+		t.Fatalf("delete fee rule failed: %v", err)
+	}
+	if err := repo.DeleteFeeRule(eventFee.ID); !errors.Is(err, utils.ErrFeeRuleNotFound) {
+		t.Fatalf("expected ErrFeeRuleNotFound, got %v", err)
+	}
+	_ = repo.DeleteFeeRule(venueFee.ID)
+	_ = repo.DeleteTaxRate(venueTax.ID)
+}
+
+func TestBookingOrderRepository_Integration_Lines(t *testing.T) {
+	db := openIntegrationDB(t)
+	orders := NewBookingOrderRepository(db)
+
+	order := &models.BookingOrder{
+		UserID: "lines-user",
+		Total:  money.New(12100, "ARS"),
+		Status: models.PaymentPending,
+		Lines: []models.OrderLine{
+			{Position: 1, Kind: models.LineServiceFee, Description: "Cargo por servicio", Quantity: 1, UnitAmount: 1000, Amount: 1000, Currency: "ARS"},
+			{Position: 0, Kind: models.LineTicket, ItemID: "s1", Description: "Asiento 1", Quantity: 1, UnitAmount: 10000, Amount: 10000, Currency: "ARS"},
+			{Position: 2, Kind: models.LineTax, Description: "IVA 10%", Quantity: 1, UnitAmount: 1100, Amount: 1100, Currency: "ARS"},
+		},
+	}
+	if err := orders.Create(order); err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+
+	stored, err := orders.FindByID(order.ID)
+	if err != nil || len(stored.Lines) != 3 || stored.Lines[0].Kind != models.LineTicket {
+		t.Fatalf("expected lines ordered by position, got %+v err=%v", stored.Lines, err)
+	}
+	if stored.Summary == nil || stored.Summary.Total != 12100 || stored.Summary.Fees != 1000 || stored.Summary.Taxes != 1100 {
+		t.Fatalf("unexpected summary: %+v", stored.Summary)
+	}
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessedPaymentEventRepository registra qué eventos de pago ya se aplicaron.
+// Para que sea idempotente se debe usar con la misma transacción que aplica el pago
+type ProcessedPaymentEventRepository interface {
+	IsProcessed(eventID string) (bool, error)
+	MarkProcessed(eventID, eventType, orderID string) (bool, error)
+}
+
+type processedPaymentEventRepository struct {
+	db *gorm.DB
+}
+
+func NewProcessedPaymentEventRepository(db *gorm.DB) ProcessedPaymentEventRepository {
+	return &processedPaymentEventRepository{db: db}
+}
+
+func (r *processedPaymentEventRepository) IsProcessed(eventID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ProcessedPaymentEvent{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count > 0, err
+}
+
+// MarkProcessed inserta el evento y devuelve false si ya estaba registrado.
+// Dos transacciones concurrentes con el mismo ID se serializan en la clave primaria
+func (r *processedPaymentEventRepository) MarkProcessed(eventID, eventType, orderID string) (bool, error) {
+	if eventID == "" {
+		return false, errors.New("event ID cannot be empty")
+	}
+
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ProcessedPaymentEvent{
+		EventID:     eventID,
+		EventType:   eventType,
+		OrderID:     orderID,
+		ProcessedAt: time.Now(),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record payment event: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromoCodeRepository maneja los códigos promocionales. El uso de un código se registra al
+// crear la orden (BookingOrderRepository.Create) y se devuelve cuando la orden no se paga
+type PromoCodeRepository interface {
+	Create(promo *models.PromoCode) error
+	FindByID(id string) (*models.PromoCode, error)
+	// FindByCode devuelve el código (en mayúsculas); nil si no existe
+	FindByCode(code string) (*models.PromoCode, error)
+	// FindAll lista los códigos del evento y los globales; sin evento, todos
+	FindAll(eventID string) ([]models.PromoCode, error)
+	Delete(id string) error
+	CountRedemptionsByUser(promoID, userID string) (int64, error)
+}
+
+type promoCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewPromoCodeRepository(db *gorm.DB) PromoCodeRepository {
+	return &promoCodeRepository{db: db}
+}
+
+func (r *promoCodeRepository) Create(promo *models.PromoCode) error {
+	return r.db.Create(promo).Error
+}
+
+func (r *promoCodeRepository) FindByID(id string) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	err := r.db.First(&promo, "id = ?", id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &promo, err
+}
+
+func (r *promoCodeRepository) FindByCode(code string) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	err := r.db.Where("code = ?", code).Take(&promo).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &promo, err
+}
+
+func (r *promoCodeRepository) FindAll(eventID string) ([]models.PromoCode, error) {
+	var promos []models.PromoCode
+	query := r.db.Order("created_at")
+	if eventID != "" {
+		query = query.Where("event_id = ? OR event_id IS NULL", eventID)
+	}
+	err := query.Find(&promos).Error
+	return promos, err
+}
+
+// Delete borra el código de verdad para poder volver a crearlo con el mismo texto.
+// Las órdenes que ya lo usaron conservan su descuento
+func (r *promoCodeRepository) Delete(id string) error {
+	res := r.db.Unscoped().Delete(&models.PromoCode{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return utils.ErrPromoCodeNotFound
+	}
+	return nil
+}
+
+func (r *promoCodeRepository) CountRedemptionsByUser(promoID, userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PromoRedemption{}).
+		Where("promo_code_id = ? AND user_id = ?", promoID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// redeemPromoCode registra el uso del código de la orden dentro de la transacción que la crea.
+// El código se bloquea con FOR UPDATE para que dos checkouts simultáneos no superen los topes
+func redeemPromoCode(tx *gorm.DB, order *models.BookingOrder) error {
+	if order.PromoCodeID == nil {
+		return nil
+	}
+
+	var promo models.PromoCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promo, "id = ?", *order.PromoCodeID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if promo.MaxUses > 0 && promo.Uses >= promo.MaxUses {
+		return utils.ErrPromoCodeExhausted
+	}
+	if promo.MaxUsesPerUser > 0 {
+		var used int64
+		if err := tx.Model(&models.PromoRedemption{}).
+			Where("promo_code_id = ? AND user_id = ?", promo.ID, order.UserID).
+			Count(&used).Error; err != nil {
+			return err
+		}
+		if used >= int64(promo.MaxUsesPerUser) {
+			return utils.ErrPromoCodeExhausted
+		}
+	}
+
+	if err := tx.Model(&promo).UpdateColumn("uses", gorm.Expr("uses + 1")).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.PromoRedemption{
+		PromoCodeID: promo.ID,
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		Amount:      order.Discount(),
+	}).Error
+}
+
+// releasePromoRedemption devuelve el uso del código de una orden que no se va a pagar.
+// Sin uso registrado (o si ya se devolvió) no hace nada
+func releasePromoRedemption(tx *gorm.DB, orderID string) error {
+	var redemption models.PromoRedemption
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).Take(&redemption).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Delete(&redemption).Error; err != nil {
+		return fmt.Errorf("failed to release promo code: %w", err)
+	}
+	return tx.Model(&models.PromoCode{}).
+		Where("id = ? AND uses > 0", redemption.PromoCodeID).
+		UpdateColumn("uses", gorm.Expr("uses - 1")).Error
+}
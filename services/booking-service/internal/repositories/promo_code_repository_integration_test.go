@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPromoCodeRepository_Integration_RedeemAndRelease(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewPromoCodeRepository(db)
+	orders := NewBookingOrderRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	promo := &models.PromoCode{Code: "IT-" + suffix[len(suffix)-10:], Type: models.DiscountPercent, PercentOff: 10, MaxUses: 1}
+	if err := repo.Create(promo); err != nil {
+		t.Fatalf("create promo failed: %v", err)
+	}
+	if found, err := repo.FindByCode(promo.Code); err != nil || found == nil || found.ID != promo.ID {
+		t.Fatalf("find by code failed: %+v err=%v", found, err)
+	}
+
+	newOrder := func(userID string) *models.BookingOrder {
+		return &models.BookingOrder{
+			UserID:      userID,
+			Total:       money.New(900, "ARS"),
+			Status:      models.PaymentPending,
+			PromoCodeID: &promo.ID,
+			PromoCode:   promo.Code,
+			Adjustments: []models.OrderAdjustment{{Type: models.AdjustmentPromo, Code: promo.Code, ItemID: "s1", Quantity: 1, Amount: -100}},
+		}
+	}
+
+	first := newOrder("u1-" + suffix)
+	if err := orders.Create(first); err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	if n, err := repo.CountRedemptionsByUser(promo.ID, first.UserID); err != nil || n != 1 {
+		t.Fatalf("expected 1 redemption, got %d err=%v", n, err)
+	}
+
+	// Sin usos disponibles la orden no se crea
+	second := newOrder("u2-" + suffix)
+	if err := orders.Create(second); !errors.Is(err, utils.ErrPromoCodeExhausted) {
+		t.Fatalf("expected exhausted promo, got %v", err)
+	}
+	var count int64
+	db.Model(&models.BookingOrder{}).Where("user_id = ?", second.UserID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected no order for second user, got %d", count)
+	}
+
+	// La orden vence: el código recupera el uso
+	stored, _ := orders.FindByID(first.ID)
+	if err := orders.ChangeStatus(&StatusChange{OrderID: first.ID, From: models.PaymentPending, To: models.PaymentExpired, Version: stored.Version, ChangedBy: "test"}); err != nil {
+		t.Fatalf("change status failed: %v", err)
+	}
+	after, err := repo.FindByID(promo.ID)
+	if err != nil || after.Uses != 0 {
+		t.Fatalf("expected use released, got %+v err=%v", after, err)
+	}
+	if n, _ := repo.CountRedemptionsByUser(promo.ID, first.UserID); n != 0 {
+		t.Fatalf("expected redemption removed, got %d", n)
+	}
+
+	third := newOrder("u3-" + suffix)
+	if err := orders.Create(third); err != nil {
+		t.Fatalf("expected promo usable again, got %v", err)
+	}
+
+	if err := repo.Delete(promo.ID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := repo.Delete(promo.ID); !errors.Is(err, utils.ErrPromoCodeNotFound) {
+		t.Fatalf("expected not found on second delete, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Estados de orden que cuentan para los límites de compra: pagadas o con el pago en curso
+var purchaseLimitStatuses = []models.PaymentStatus{models.PaymentPending, models.PaymentCompleted}
+
+// PurchaseLimitRepository suma las entradas ya compradas de un evento por usuario, email y tarjeta
+type PurchaseLimitRepository interface {
+	// FindLimits devuelve el evento solo con las columnas de los límites; nil si no existe
+	FindLimits(eventID string) (*models.Event, error)
+	CountByUser(eventID, userID string) (int64, error)
+	CountByEmail(eventID, email string) (int64, error)
+	CountByCards(eventID string, fingerprints []string) (int64, error)
+	// FindCardFingerprints devuelve las tarjetas con las que pagó el usuario (en cualquier evento)
+	FindCardFingerprints(userID string) ([]string, error)
+}
+
+type purchaseLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseLimitRepository(db *gorm.DB) PurchaseLimitRepository {
+	return &purchaseLimitRepository{db: db}
+}
+
+func (r *purchaseLimitRepository) FindLimits(eventID string) (*models.Event, error) {
+	var event models.Event
+	err := r.db.Select("id", "max_tickets_per_user", "max_tickets_per_email", "max_tickets_per_card").
+		First(&event, "id = ?", eventID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *purchaseLimitRepository) CountByUser(eventID, userID string) (int64, error) {
+	return r.sumTickets(eventID, "user_id = ?", userID)
+}
+
+func (r *purchaseLimitRepository) CountByEmail(eventID, email string) (int64, error) {
+	return r.sumTickets(eventID, "customer_email = ?", email)
+}
+
+func (r *purchaseLimitRepository) CountByCards(eventID string, fingerprints []string) (int64, error) {
+	if len(fingerprints) == 0 {
+		return 0, nil
+	}
+	return r.sumTickets(eventID, "card_fingerprint IN ?", fingerprints)
+}
+
+func (r *purchaseLimitRepository) FindCardFingerprints(userID string) ([]string, error) {
+	var fingerprints []string
+	err := r.db.Model(&models.BookingOrder{}).
+		Distinct("card_fingerprint").
+		Where("user_id = ? AND card_fingerprint <> ''", userID).
+		Pluck("card_fingerprint", &fingerprints).Error
+	return fingerprints, err
+}
+
+// CheckPurchaseLimits valida que el comprador pueda sumar requested entradas del evento con
+// los límites ya cargados. El límite por tarjeta usa las tarjetas con las que el usuario ya pagó
+func CheckPurchaseLimits(repo PurchaseLimitRepository, limits *models.Event, buyer models.Buyer, requested int) error {
+	eventID := limits.ID
+
+	if limits.MaxTicketsPerUser > 0 && buyer.UserID != "" {
+		purchased, err := repo.CountByUser(eventID, buyer.UserID)
+		if err != nil {
+			return err
+		}
+		if err := checkLimit("user", limits.MaxTicketsPerUser, purchased, requested); err != nil {
+			return err
+		}
+	}
+
+	if email := models.NormalizeEmail(buyer.Email); limits.MaxTicketsPerEmail > 0 && email != "" {
+		purchased, err := repo.CountByEmail(eventID, email)
+		if err != nil {
+			return err
+		}
+		if err := checkLimit("email", limits.MaxTicketsPerEmail, purchased, requested); err != nil {
+			return err
+		}
+	}
+
+	if limits.MaxTicketsPerCard > 0 && buyer.UserID != "" {
+		fingerprints, err := repo.FindCardFingerprints(buyer.UserID)
+		if err != nil {
+			return err
+		}
+		purchased, err := repo.CountByCards(eventID, fingerprints)
+		if err != nil {
+			return err
+		}
+		if err := checkLimit("card", limits.MaxTicketsPerCard, purchased, requested); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkLimit(limit string, max int, purchased int64, requested int) error {
+	if purchased+int64(requested) <= int64(max) {
+		return nil
+	}
+	return &utils.PurchaseLimitError{Limit: limit, Max: max, Purchased: purchased, Requested: requested}
+}
+
+// lockPurchaseLimits devuelve los límites del evento y bloquea su fila hasta el fin de la
+// transacción, así dos compras simultáneas no pasan las dos el conteo. Los eventos sin límites
+// devuelven nil y no se bloquean
+func lockPurchaseLimits(tx *gorm.DB, eventID string) (*models.Event, error) {
+	limits, err := (&purchaseLimitRepository{db: tx}).FindLimits(eventID)
+	if err != nil || limits == nil {
+		return nil, err
+	}
+	if limits.MaxTicketsPerUser <= 0 && limits.MaxTicketsPerEmail <= 0 && limits.MaxTicketsPerCard <= 0 {
+		return nil, nil
+	}
+
+	var locked models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "max_tickets_per_user", "max_tickets_per_email", "max_tickets_per_card").
+		First(&locked, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+	return &locked, nil
+}
+
+// enforceOrderLimits controla los límites de compra de una orden nueva dentro de la
+// transacción que la crea
+func enforceOrderLimits(tx *gorm.DB, order *models.BookingOrder) error {
+	if order.EventID == nil || order.Quantity <= 0 {
+		return nil
+	}
+	limits, err := lockPurchaseLimits(tx, *order.EventID)
+	if err != nil || limits == nil {
+		return err
+	}
+	buyer := models.Buyer{UserID: order.UserID, Email: order.CustomerEmail}
+	return CheckPurchaseLimits(&purchaseLimitRepository{db: tx}, limits, buyer, order.Quantity)
+}
+
+// enforceCardLimit controla el límite por tarjeta al confirmar el pago, que es cuando se conoce
+// la tarjeta. La orden que se confirma no se cuenta dos veces
+func enforceCardLimit(tx *gorm.DB, order *models.BookingOrder, fingerprint string) error {
+	if order.EventID == nil || fingerprint == "" {
+		return nil
+	}
+	limits, err := lockPurchaseLimits(tx, *order.EventID)
+	if err != nil || limits == nil || limits.MaxTicketsPerCard <= 0 {
+		return err
+	}
+	purchased, err := (&purchaseLimitRepository{db: tx}).sumTickets(*order.EventID, "card_fingerprint = ? AND id <> ?", fingerprint, order.ID)
+	if err != nil {
+		return err
+	}
+	return checkLimit("card", limits.MaxTicketsPerCard, purchased, order.Quantity)
+}
+
+// sumTickets suma las entradas de las órdenes PENDING y COMPLETED del evento que cumplen el filtro
+func (r *purchaseLimitRepository) sumTickets(eventID, query string, args ...interface{}) (int64, error) {
+	var total int64
+	err := r.db.Model(&models.BookingOrder{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("event_id = ? AND status IN ?", eventID, purchaseLimitStatuses).
+		Where(query, args...).
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestPurchaseLimitRepository_Integration_Counts(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewPurchaseLimitRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "77777777-7777-7777-7777-" + suffix[len(suffix)-12:]
+	if err := NewEventRepository(db).Create(&models.Event{
+		BaseModel:         models.BaseModel{ID: eventID},
+		Name:              "IT Limits " + suffix,
+		Date:              time.Now().Add(24 * time.Hour),
+		Price:             money.New(5000, "ARS"),
+		MaxTicketsPerUser: 4,
+		MaxTicketsPerCard: 6,
+	}); err != nil {
+		t.Fatalf("create event failed: %v", err)
+	}
+
+	userID := "u-limits-" + suffix
+	email := "fan-" + suffix + "@mail.com"
+	fingerprint := "fp-" + suffix
+	orders := []models.BookingOrder{
+		{UserID: userID, EventID: &eventID, Quantity: 2, Status: models.PaymentCompleted, CustomerEmail: email, CardFingerprint: fingerprint},
+		{UserID: userID, EventID: &eventID, Quantity: 1, Status: models.PaymentPending, CustomerEmail: email},
+		// Las órdenes fallidas no cuentan
+		{UserID: userID, EventID: &eventID, Quantity: 5, Status: models.PaymentFailed, CustomerEmail: email, CardFingerprint: fingerprint},
+		// Otro usuario que pagó con la misma tarjeta
+		{UserID: "other-" + suffix, EventID: &eventID, Quantity: 3, Status: models.PaymentCompleted, CardFingerprint: fingerprint},
+	}
+	for i := range orders {
+		orders[i].Total = money.New(1000, "ARS")
+		if err := db.Create(&orders[i]).Error; err != nil {
+			t.Fatalf("create order failed: %v", err)
+		}
+	}
+
+	limits, err := repo.FindLimits(eventID)
+	if err != nil || limits == nil || limits.MaxTicketsPerUser != 4 || limits.MaxTicketsPerCard != 6 {
+		t.Fatalf("unexpected limits: %+v err=%v", limits, err)
+	}
+	if missing, err := repo.FindLimits("00000000-0000-0000-0000-000000000000"); err != nil || missing != nil {
+		t.Fatalf("expected nil for missing event, got %+v err=%v", missing, err)
+	}
+
+	if n, err := repo.CountByUser(eventID, userID); err != nil || n != 3 {
+		t.Fatalf("expected 3 tickets by user, got %d err=%v", n, err)
+	}
+	if n, err := repo.CountByEmail(eventID, email); err != nil || n != 3 {
+		t.Fatalf("expected 3 tickets by email, got %d err=%v", n, err)
+	}
+
+	fingerprints, err := repo.FindCardFingerprints(userID)
+	if err != nil || len(fingerprints) != 1 || fingerprints[0] != fingerprint {
+		t.Fatalf("unexpected fingerprints: %v err=%v", fingerprints, err)
+	}
+	if n, err := repo.CountByCards(eventID, fingerprints); err != nil || n != 5 {
+		t.Fatalf("expected 5 tickets by card, got %d err=%v", n, err)
+	}
+	if n, err := repo.CountByCards(eventID, nil); err != nil || n != 0 {
+		t.Fatalf("expected 0 without cards, got %d err=%v", n, err)
+	}
+}
+
+func TestPurchaseLimits_Integration_EnforcedOnCreateAndPayment(t *testing.T) {
+	db := openIntegrationDB(t)
+	orders := NewBookingOrderRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "78787878-7878-7878-7878-" + suffix[len(suffix)-12:]
+	if err := NewEventRepository(db).Create(&models.Event{
+		BaseModel:         models.BaseModel{ID: eventID},
+		Name:              "IT Limits Enforced " + suffix,
+		Date:              time.Now().Add(24 * time.Hour),
+		Price:             money.New(5000, "ARS"),
+		MaxTicketsPerUser: 3,
+		MaxTicketsPerCard: 3,
+	}); err != nil {
+		t.Fatalf("create event failed: %v", err)
+	}
+
+	userID := "u-enforced-" + suffix
+	newOrder := func(user string, quantity int) *models.BookingOrder {
+		return &models.BookingOrder{UserID: user, EventID: &eventID, Quantity: quantity, Status: models.PaymentPending, Total: money.New(1000, "ARS")}
+	}
+
+	if err := orders.Create(newOrder(userID, 2)); err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	var exceeded *utils.PurchaseLimitError
+	if err := orders.Create(newOrder(userID, 2)); !errors.As(err, &exceeded) || exceeded.Limit != "user" {
+		t.Fatalf("expected user limit on create, got %v", err)
+	}
+
+	// Otro usuario con la misma tarjeta: el límite por tarjeta se controla al confirmar el pago
+	fingerprint := "fp-enforced-" + suffix
+	paid := newOrder("other-"+suffix, 2)
+	paid.Status = models.PaymentCompleted
+	paid.CardFingerprint = fingerprint
+	if err := db.Create(paid).Error; err != nil {
+		t.Fatalf("create paid order failed: %v", err)
+	}
+	pending := newOrder("third-"+suffix, 2)
+	if err := orders.Create(pending); err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return enforceCardLimit(tx, pending, fingerprint)
+	})
+	if !errors.As(err, &exceeded) || exceeded.Limit != "card" || exceeded.Purchased != 2 {
+		t.Fatalf("expected card limit on payment, got %v", err)
+	}
+}
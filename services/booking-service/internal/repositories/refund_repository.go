@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefundCompletion son los datos de un reembolso ya confirmado por la pasarela
+type RefundCompletion struct {
+	RefundID         string
+	ProviderRefundID string
+	OrderID          string
+	SeatIDs          []string
+	Amount           int64
+	ChangedBy        string
+	// Reembolso total: también se devuelven al cupo las entradas de admisión general
+	IncludeGA bool
+}
+
+type RefundRepository interface {
+	FindByOrderID(orderID string) ([]models.Refund, error)
+	CreatePending(refund *models.Refund) error
+	MarkFailed(id, reason string) error
+	// RecordProviderRefund guarda el ID del reembolso en la pasarela apenas se emite, para poder
+	// completarlo después si CompleteRefund falla
+	RecordProviderRefund(id, providerRefundID string) error
+	// FindStalePending devuelve los reembolsos PENDING creados antes de before
+	FindStalePending(before time.Time, limit int) ([]models.Refund, error)
+	CompleteRefund(c *RefundCompletion) (*models.BookingOrder, error)
+}
+
+type refundRepository struct {
+	db        *gorm.DB
+	publisher SeatChangePublisher // Opcional
+}
+
+func NewRefundRepository(db *gorm.DB, publisher SeatChangePublisher) RefundRepository {
+	return &refundRepository{db: db, publisher: publisher}
+}
+
+func (r *refundRepository) FindByOrderID(orderID string) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.db.Where("order_id = ?", orderID).Order("created_at ASC").Find(&refunds).Error
+	return refunds, err
+}
+
+// CreatePending registra el reembolso antes de llamar a la pasarela. Con la orden bloqueada
+// se asegura que no haya otro reembolso en curso para la misma orden
+func (r *refundRepository) CreatePending(refund *models.Refund) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.BookingOrder
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&order, "id = ?", refund.OrderID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var inProgress int64
+		if err := tx.Model(&models.Refund{}).
+			Where("order_id = ? AND status = ?", refund.OrderID, models.RefundPending).
+			Count(&inProgress).Error; err != nil {
+			return err
+		}
+		if inProgress > 0 {
+			return utils.ErrRefundInProgress
+		}
+
+		refund.Status = models.RefundPending
+		return tx.Create(refund).Error
+	})
+}
+
+func (r *refundRepository) MarkFailed(id, reason string) error {
+	return r.db.Model(&models.Refund{}).
+		Where("id = ? AND status = ?", id, models.RefundPending).
+		Updates(map[string]any{"status": models.RefundFailed, "error": reason}).Error
+}
+
+func (r *refundRepository) RecordProviderRefund(id, providerRefundID string) error {
+	return r.db.Model(&models.Refund{}).
+		Where("id = ? AND status = ?", id, models.RefundPending).
+		Update("provider_refund_id", providerRefundID).Error
+}
+
+func (r *refundRepository) FindStalePending(before time.Time, limit int) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.db.Where("status = ? AND created_at < ?", models.RefundPending, before).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&refunds).Error
+	return refunds, err
+}
+
+// CompleteRefund aplica un reembolso confirmado en una sola transacción: marca el reembolso,
+// mueve la orden a REFUNDED/PARTIALLY_REFUNDED, devuelve los asientos al inventario,
+// anula o reemite el ticket y recalcula la disponibilidad
+func (r *refundRepository) CompleteRefund(c *RefundCompletion) (*models.BookingOrder, error) {
+	var updated models.BookingOrder
+	var returned []models.Seat
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.BookingOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", c.OrderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrOrderNotFound
+			}
+			return err
+		}
+
+		refundedSeats := append(append([]string{}, order.RefundedSeatIDs...), c.SeatIDs...)
+		refundedAmount := order.RefundedAmount + c.Amount
+
+		to := models.PaymentPartiallyRefunded
+		allSeats := len(refundedSeats) >= len(order.SeatIDs) && len(order.GAItems) == 0
+		if c.IncludeGA || allSeats || refundedAmount >= order.Total.Amount {
+			to = models.PaymentRefunded
+		}
+		if !order.Status.CanTransitionTo(to) {
+			return &utils.InvalidTransitionError{From: string(order.Status), To: string(to)}
+		}
+
+		result := tx.Model(&models.Refund{}).
+			Where("id = ? AND status = ?", c.RefundID, models.RefundPending).
+			Updates(map[string]any{"status": models.RefundSucceeded, "provider_refund_id": c.ProviderRefundID})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update refund: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("refund %s is not pending", c.RefundID)
+		}
+
+		// Con struct + Select para que se aplique el serializer json de RefundedSeatIDs
+		if err := tx.Model(&models.BookingOrder{BaseModel: models.BaseModel{ID: order.ID}}).
+			Select("refunded_seat_ids", "refunded_amount").
+			Updates(&models.BookingOrder{RefundedSeatIDs: refundedSeats, RefundedAmount: refundedAmount}).Error; err != nil {
+			return fmt.Errorf("failed to update refunded seats: %w", err)
+		}
+
+		if err := applyStatusChange(tx, &StatusChange{
+			OrderID:   order.ID,
+			From:      order.Status,
+			To:        to,
+			Version:   order.Version,
+			ChangedBy: c.ChangedBy,
+			Reason:    "refund " + c.RefundID,
+		}); err != nil {
+			return err
+		}
+
+		var seats []models.Seat
+		if err := tx.Where("id IN ? AND status = ?", c.SeatIDs, models.StatusSold).Find(&seats).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Seat{}).
+			Where("id IN ? AND status = ?", c.SeatIDs, models.StatusSold).
+			Updates(map[string]any{
+				"status":          models.StatusAvailable,
+				"ticket_id":       nil,
+				"locked_by":       nil,
+				"locked_at":       nil,
+				"lock_expires_at": nil,
+				"hold_id":         nil,
+				"hold_extensions": 0,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to release seats: %w", err)
+		}
+
+		if c.IncludeGA {
+			if err := returnSoldGAItems(tx, &order); err != nil {
+				return err
+			}
+		}
+
+		if err := voidOrReissueTicket(tx, &order, to, refundedAmount); err != nil {
+			return err
+		}
+
+		events := make(map[string]struct{})
+		for _, s := range seats {
+			events[s.EventID] = struct{}{}
+		}
+		eventRepo := &eventRepository{db: tx}
+		for eventID := range events {
+			if err := eventRepo.UpdateAvailability(eventID); err != nil {
+				return fmt.Errorf("failed to update availability: %w", err)
+			}
+		}
+
+		returned = seats
+		return tx.First(&updated, "id = ?", order.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publishSeatChanges(r.publisher, models.SeatChangesFor(returned, models.StatusAvailable, nil))
+	return &updated, nil
+}
+
+// voidOrReissueTicket anula el ticket si la orden se reembolsó por completo; si el reembolso
+// fue parcial actualiza el importe y descarta el PDF para que se regenere sin los asientos devueltos
+func voidOrReissueTicket(tx *gorm.DB, order *models.BookingOrder, status models.PaymentStatus, refundedAmount int64) error {
+	if status == models.PaymentRefunded {
+		if err := tx.Where("order_id = ?", order.ID).Delete(&models.TicketPDF{}).Error; err != nil {
+			return fmt.Errorf("failed to void ticket: %w", err)
+		}
+		return nil
+	}
+
+	var ticket models.TicketPDF
+	err := tx.Where("order_id = ?", order.ID).First(&ticket).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	amount := ticket.Amount - (refundedAmount - order.RefundedAmount)
+	if err := tx.Model(&models.TicketPDF{}).Where("id = ?", ticket.ID).Updates(map[string]any{
+		"amount":           max(amount, 0),
+		"pdf_data":         nil,
+		"pdf_generated_at": nil,
+		"pdf_version":      gorm.Expr("pdf_version + 1"),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to reissue ticket: %w", err)
+	}
+	return nil
+}
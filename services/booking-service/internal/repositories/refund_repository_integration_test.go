@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRefundRepository_Integration_PartialThenFullRefund(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewRefundRepository(db, nil)
+	paymentRepo := NewPaymentRepository(db, nil)
+	orderRepo := NewBookingOrderRepository(db)
+	eventRepo := NewEventRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "dddddddd-1111-1111-1111-" + suffix[len(suffix)-12:]
+	seat1 := "dddddddd-2222-2222-2222-" + suffix[len(suffix)-12:]
+	seat2 := "dddddddd-2222-2222-3333-" + suffix[len(suffix)-12:]
+	orderID := "dddddddd-3333-3333-3333-" + suffix[len(suffix)-12:]
+	userID := "dddddddd-4444-4444-4444-" + suffix[len(suffix)-12:]
+
+	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Refund Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seat1}, EventID: eventID, Section: "A", Number: "1", Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: seat2}, EventID: eventID, Section: "A", Number: "2", Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	_ = orderRepo.Create(&models.BookingOrder{BaseModel: models.BaseModel{ID: orderID}, UserID: userID, Total: money.New(2000, "ARS"), Status: models.PaymentPending, SeatIDs: []string{seat1, seat2}})
+
+	if _, _, err := paymentRepo.CompletePayment(&PaymentCompletion{
+		EventID: "evt_refund_" + suffix, EventType: "checkout.session.completed", OrderID: orderID,
+		SeatIDs: []string{seat1, seat2}, PaymentProvider: "STRIPE", PaymentProviderID: "pi_" + suffix,
+		Currency: "usd", Amount: 2000, CustomerEmail: "buyer@example.com", CustomerName: "Buyer",
+	}); err != nil {
+		t.Fatalf("complete payment failed: %v", err)
+	}
+
+	partial := &models.Refund{OrderID: orderID, SeatIDs: []string{seat1}, Amount: 1000, Currency: "usd", RequestedBy: userID}
+	if err := repo.CreatePending(partial); err != nil {
+		t.Fatalf("create pending failed: %v", err)
+	}
+	if err := repo.CreatePending(&models.Refund{OrderID: orderID, Amount: 1000, RequestedBy: userID}); !errors.Is(err, utils.ErrRefundInProgress) {
+		t.Fatalf("expected ErrRefundInProgress, got %v", err)
+	}
+
+	// El ID de la pasarela queda guardado en el reembolso PENDING para poder completarlo después
+	if err := repo.RecordProviderRefund(partial.ID, "re_1"); err != nil {
+		t.Fatalf("record provider refund failed: %v", err)
+	}
+	stale, err := repo.FindStalePending(time.Now().Add(time.Minute), 100)
+	if err != nil {
+		t.Fatalf("find stale pending failed: %v", err)
+	}
+	found := false
+	for _, r := range stale {
+		found = found || (r.ID == partial.ID && r.ProviderRefundID == "re_1")
+	}
+	if !found {
+		t.Fatalf("expected pending refund with provider id, got %+v", stale)
+	}
+
+	order, err := repo.CompleteRefund(&RefundCompletion{RefundID: partial.ID, ProviderRefundID: "re_1", OrderID: orderID, SeatIDs: []string{seat1}, Amount: 1000, ChangedBy: userID})
+	if err != nil {
+		t.Fatalf("complete partial refund failed: %v", err)
+	}
+	if order.Status != models.PaymentPartiallyRefunded || order.RefundedAmount != 1000 || len(order.RefundedSeatIDs) != 1 {
+		t.Fatalf("expected partially refunded order, got %+v", order)
+	}
+
+	seat, _ := seatRepo.FindByID(seat1)
+	if seat.Status != models.StatusAvailable || seat.TicketID != nil {
+		t.Fatalf("expected refunded seat back in inventory, got %+v", seat)
+	}
+	var ticket models.TicketPDF
+	db.Where("order_id = ?", orderID).First(&ticket)
+	if ticket.Amount != 1000 || ticket.PDFVersion != 2 {
+		t.Fatalf("expected reissued ticket, got amount %d version %d", ticket.Amount, ticket.PDFVersion)
+	}
+
+	full := &models.Refund{OrderID: orderID, SeatIDs: []string{seat2}, Amount: 1000, Currency: "usd", RequestedBy: userID}
+	if err := repo.CreatePending(full); err != nil {
+		t.Fatalf("create pending failed: %v", err)
+	}
+	order, err = repo.CompleteRefund(&RefundCompletion{RefundID: full.ID, ProviderRefundID: "re_2", OrderID: orderID, SeatIDs: []string{seat2}, Amount: 1000, ChangedBy: userID})
+	if err != nil {
+		t.Fatalf("complete full refund failed: %v", err)
+	}
+	if order.Status != models.PaymentRefunded {
+		t.Fatalf("expected refunded order, got %s", order.Status)
+	}
+
+	var tickets int64
+	db.Model(&models.TicketPDF{}).Where("order_id = ?", orderID).Count(&tickets)
+	if tickets != 0 {
+		t.Fatalf("expected ticket voided, got %d", tickets)
+	}
+
+	event, _ := eventRepo.FindByID(eventID)
+	if event.Availability == models.AvailabilitySoldOut {
+		t.Fatalf("expected availability recalculated after refund")
+	}
+}
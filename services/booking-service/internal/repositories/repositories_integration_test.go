@@ -2,6 +2,9 @@ package repositories
 
 import (
 	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -21,7 +24,7 @@ func openIntegrationDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to open db: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Event{}, &models.Seat{}, &models.BookingOrder{}, &models.Checkout{}, &models.TicketPDF{}); err != nil {
+	if err := db.AutoMigrate(&models.Event{}, &models.PriceTier{}, &models.Venue{}, &models.VenueSeat{}, &models.Seat{}, &models.BookingOrder{}, &models.Checkout{}, &models.TicketPDF{}, &models.ProcessedPaymentEvent{}, &models.BookingOrderStatusHistory{}, &models.Refund{}, &models.GASection{}, &models.GAHold{}, &models.WaitingRoomEntry{}, &models.PresaleCode{}, &models.PresaleAccess{}, &models.PromoCode{}, &models.PromoRedemption{}, &models.OrderLine{}, &models.FeeRule{}, &models.TaxRate{}); err != nil {
 		t.Fatalf("failed automigrate: %v", err)
 	}
 	return db
@@ -30,7 +33,7 @@ func openIntegrationDB(t *testing.T) *gorm.DB {
 func TestEventAndSeatRepository_Integration_CRUDAndLock(t *testing.T) {
 	db := openIntegrationDB(t)
 	eventRepo := NewEventRepository(db)
-	seatRepo := NewSeatRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
 
 	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
 	eventID := "11111111-1111-1111-1111-" + suffix[len(suffix)-12:]
@@ -42,7 +45,7 @@ func TestEventAndSeatRepository_Integration_CRUDAndLock(t *testing.T) {
 		Name:      "IT Concert " + suffix,
 		Location:  "Arena",
 		Date:      time.Now().Add(24 * time.Hour),
-		Price:     5000,
+		Price:     money.New(5000, "ARS"),
 	}
 	if err := eventRepo.Create(event); err != nil {
 		t.Fatalf("create event failed: %v", err)
@@ -53,14 +56,14 @@ func TestEventAndSeatRepository_Integration_CRUDAndLock(t *testing.T) {
 		EventID:   eventID,
 		Section:   "VIP",
 		Number:    "A1",
-		Price:     100,
+		Price:     money.New(10000, "ARS"),
 		Status:    models.StatusAvailable,
 	}
 	if err := seatRepo.Create(seat); err != nil {
 		t.Fatalf("create seat failed: %v", err)
 	}
 
-	if err := seatRepo.LockSeat(seatID, userID, time.Now().Add(-1*time.Minute)); err != nil {
+	if err := seatRepo.LockSeat(seatID, userID, "22222222-3333-3333-3333-"+suffix[len(suffix)-12:], time.Now().Add(-1*time.Minute)); err != nil {
 		t.Fatalf("lock seat failed: %v", err)
 	}
 	if err := seatRepo.UnlockIfExpired(seatID, time.Now()); err != nil {
@@ -89,3 +92,53 @@ func TestEventAndSeatRepository_Integration_CRUDAndLock(t *testing.T) {
 	_ = seatRepo.UpdateStatus(seatID, models.StatusSold)
 	_ = eventRepo.Delete(eventID)
 }
+
+func TestSeatRepository_Integration_LockSeatsAllOrNothing(t *testing.T) {
+	db := openIntegrationDB(t)
+	eventRepo := NewEventRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "aaaaaaaa-1111-1111-1111-" + suffix[len(suffix)-12:]
+	freeID := "aaaaaaaa-2222-2222-2222-" + suffix[len(suffix)-12:]
+	soldID := "aaaaaaaa-3333-3333-3333-" + suffix[len(suffix)-12:]
+	userID := "aaaaaaaa-4444-4444-4444-" + suffix[len(suffix)-12:]
+
+	_ = eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Lock Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: freeID}, EventID: eventID, Section: "A", Number: "1", Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	_ = seatRepo.Create(&models.Seat{BaseModel: models.BaseModel{ID: soldID}, EventID: eventID, Section: "A", Number: "2", Price: money.New(1000, "ARS"), Status: models.StatusSold})
+
+	err := seatRepo.LockSeats([]string{freeID, soldID}, userID, "aaaaaaaa-5555-5555-5555-"+suffix[len(suffix)-12:], time.Now().Add(10*time.Minute))
+	var unavailable *utils.SeatsUnavailableError
+	if !errors.As(err, &unavailable) || len(unavailable.SeatIDs) != 1 || unavailable.SeatIDs[0] != soldID {
+		t.Fatalf("expected only sold seat reported, got %v", err)
+	}
+
+	free, err := seatRepo.FindByID(freeID)
+	if err != nil {
+		t.Fatalf("find seat failed: %v", err)
+	}
+	if free.Status != models.StatusAvailable {
+		t.Fatalf("expected rollback to keep seat AVAILABLE, got %s", free.Status)
+	}
+
+	holdID := "aaaaaaaa-6666-6666-6666-" + suffix[len(suffix)-12:]
+	if err := seatRepo.LockSeats([]string{freeID}, userID, holdID, time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatalf("lock seats failed: %v", err)
+	}
+	n, err := seatRepo.ExtendHold(holdID, userID, time.Now().Add(20*time.Minute), 1, time.Now())
+	if err != nil || n != 1 {
+		t.Fatalf("extend hold failed: n=%d err=%v", n, err)
+	}
+	if n, _ := seatRepo.ExtendHold(holdID, userID, time.Now().Add(30*time.Minute), 1, time.Now()); n != 0 {
+		t.Fatalf("expected second extension to be rejected, got %d rows", n)
+	}
+	if n, err := seatRepo.ReleaseHold(holdID, "someone-else"); err != nil || n != 0 {
+		t.Fatalf("expected release by non-owner to be a no-op, n=%d err=%v", n, err)
+	}
+	if n, err := seatRepo.ReleaseHold(holdID, userID); err != nil || n != 1 {
+		t.Fatalf("release hold failed: n=%d err=%v", n, err)
+	}
+
+	_ = eventRepo.Delete(eventID)
+}
@@ -2,11 +2,13 @@ package repositories
 
 import (
 	"booking-service/internal/models"
+	"booking-service/pkg/utils"
 	"errors"
 	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SeatRepository interface {
@@ -14,20 +16,36 @@ type SeatRepository interface {
 	FindAlls() ([]models.Seat, error)
 	FindByID(id string) (*models.Seat, error)
 	UpdateStatus(id string, status models.SeatStatus) error
-	LockSeat(id string, userId string, expiresAt time.Time) error
+	LockSeat(id string, userId string, holdID string, expiresAt time.Time) error
+	LockSeats(ids []string, userId string, holdID string, expiresAt time.Time) error
+	FindByHoldID(holdID string) ([]models.Seat, error)
+	ExtendHold(holdID string, userId string, expiresAt time.Time, maxExtensions int, now time.Time) (int64, error)
+	ReleaseHold(holdID string, userId string) (int64, error)
 	UnlockIfExpired(id string, now time.Time) error
+	FindExpiredLocks(now time.Time, limit int) ([]models.Seat, error)
+	ReleaseExpiredLocks(ids []string, now time.Time) ([]models.Seat, error)
+	CountActiveLocksByUser(eventID, userId string, now time.Time) (int64, error)
 
 	FindSeatByEventId(id string) ([]models.Seat, error)
 
 	FindByIDs(ids []string) ([]models.Seat, error)
+
+	CreateForEvent(eventID, venueID string, seats []models.Seat) error
+}
+
+// SeatChangePublisher recibe los cambios de estado de asientos una vez confirmados en la base
+// (p. ej. el bus que alimenta el mapa de asientos en tiempo real)
+type SeatChangePublisher interface {
+	PublishSeatChanges(changes []models.SeatChange)
 }
 
 type seatRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	publisher SeatChangePublisher // Opcional
 }
 
-func NewSeatRepository(db *gorm.DB) SeatRepository {
-	return &seatRepository{db: db}
+func NewSeatRepository(db *gorm.DB, publisher SeatChangePublisher) SeatRepository {
+	return &seatRepository{db: db, publisher: publisher}
 }
 
 func (r *seatRepository) Create(seat *models.Seat) error {
@@ -47,7 +65,8 @@ func (r *seatRepository) FindByID(id string) (*models.Seat, error) {
 }
 
 func (r *seatRepository) UpdateStatus(id string, status models.SeatStatus) error {
-	result := r.db.Model(&models.Seat{}).Where("id = ?", id).Update("status", status)
+	var changed []models.Seat
+	result := r.db.Model(&changed).Clauses(returningSeatIDs).Where("id = ?", id).Update("status", status)
 
 	if result.Error != nil {
 		return result.Error
@@ -57,18 +76,16 @@ func (r *seatRepository) UpdateStatus(id string, status models.SeatStatus) error
 		return gorm.ErrRecordNotFound
 	}
 
+	publishSeatChanges(r.publisher, models.SeatChangesFor(changed, status, nil))
 	return nil
 }
 
-// Bloquear asiento por 15 minutos
-func (r *seatRepository) LockSeat(id, userId string, expiresAt time.Time) error {
-	tx := r.db.Model(&models.Seat{}).
+// Bloquea el asiento hasta expiresAt
+func (r *seatRepository) LockSeat(id, userId, holdID string, expiresAt time.Time) error {
+	var changed []models.Seat
+	tx := r.db.Model(&changed).Clauses(returningSeatIDs).
 		Where("id = ? AND status = ?", id, models.StatusAvailable).
-		Updates(map[string]interface{}{
-			"status":    models.StatusLocked,
-			"locked_by": userId,
-			"locked_at": expiresAt,
-		})
+		Updates(lockUpdates(userId, holdID, expiresAt))
 
 	if tx.Error != nil {
 		return tx.Error
@@ -76,20 +93,164 @@ func (r *seatRepository) LockSeat(id, userId string, expiresAt time.Time) error
 	if tx.RowsAffected == 0 {
 		return errors.New("seat not available or not found")
 	}
+
+	publishSeatChanges(r.publisher, models.SeatChangesFor(changed, models.StatusLocked, &expiresAt))
 	return nil
 }
 
-// Worker que se encarga de verificar si ya paso el tiempo de bloqueo de un asiento
-func (r *seatRepository) UnlockIfExpired(id string, now time.Time) error {
-	tx := r.db.Model(&models.Seat{}).
-		Where("id = ? AND status = ? AND locked_at IS NOT NULL AND locked_at <= ?", id, models.StatusLocked, now).
+// Bloquea todos los asientos en una sola transacción (todo o nada).
+// Si alguno no está AVAILABLE se hace rollback y se devuelve *utils.SeatsUnavailableError
+func (r *seatRepository) LockSeats(ids []string, userId, holdID string, expiresAt time.Time) error {
+	if len(ids) == 0 {
+		return errors.New("no seats to lock")
+	}
+
+	var seats []models.Seat
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Find(&seats).Error; err != nil {
+			return err
+		}
+
+		seatByID := make(map[string]models.Seat, len(seats))
+		for _, s := range seats {
+			seatByID[s.ID] = s
+		}
+
+		var failed []string
+		for _, id := range ids {
+			seat, ok := seatByID[id]
+			if !ok || seat.Status != models.StatusAvailable {
+				failed = append(failed, id)
+			}
+		}
+		if len(failed) > 0 {
+			return &utils.SeatsUnavailableError{SeatIDs: failed}
+		}
+
+		res := tx.Model(&models.Seat{}).
+			Where("id IN ? AND status = ?", ids, models.StatusAvailable).
+			Updates(lockUpdates(userId, holdID, expiresAt))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != int64(len(ids)) {
+			return &utils.SeatsUnavailableError{SeatIDs: ids}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	publishSeatChanges(r.publisher, models.SeatChangesFor(seats, models.StatusLocked, &expiresAt))
+	return nil
+}
+
+// Asientos que siguen bloqueados bajo un hold
+func (r *seatRepository) FindByHoldID(holdID string) ([]models.Seat, error) {
+	var seats []models.Seat
+	err := r.db.Where("hold_id = ? AND status = ?", holdID, models.StatusLocked).Find(&seats).Error
+	return seats, err
+}
+
+// Extiende un hold vigente del usuario si no superó el máximo de extensiones
+func (r *seatRepository) ExtendHold(holdID, userId string, expiresAt time.Time, maxExtensions int, now time.Time) (int64, error) {
+	result := r.db.Model(&models.Seat{}).
+		Where("hold_id = ? AND locked_by = ? AND status = ? AND lock_expires_at > ? AND hold_extensions < ?",
+			holdID, userId, models.StatusLocked, now, maxExtensions).
 		Updates(map[string]interface{}{
-			"status":    models.StatusAvailable,
-			"locked_by": nil,
-			"locked_at": nil,
+			"lock_expires_at": expiresAt,
+			"hold_extensions": gorm.Expr("hold_extensions + 1"),
 		})
+	return result.RowsAffected, result.Error
+}
+
+// Libera todos los asientos de un hold del usuario
+func (r *seatRepository) ReleaseHold(holdID, userId string) (int64, error) {
+	var changed []models.Seat
+	result := r.db.Model(&changed).Clauses(returningSeatIDs).
+		Where("hold_id = ? AND locked_by = ? AND status = ?", holdID, userId, models.StatusLocked).
+		Updates(unlockUpdates())
+	if result.Error == nil {
+		publishSeatChanges(r.publisher, models.SeatChangesFor(changed, models.StatusAvailable, nil))
+	}
+	return result.RowsAffected, result.Error
+}
+
+// Worker que se encarga de verificar si ya paso el tiempo de bloqueo de un asiento
+func (r *seatRepository) UnlockIfExpired(id string, now time.Time) error {
+	var changed []models.Seat
+	tx := r.db.Model(&changed).Clauses(returningSeatIDs).
+		Where("id = ? AND status = ? AND lock_expires_at IS NOT NULL AND lock_expires_at <= ?", id, models.StatusLocked, now).
+		Updates(unlockUpdates())
+	if tx.Error != nil {
+		return tx.Error
+	}
 
-	return tx.Error
+	publishSeatChanges(r.publisher, models.SeatChangesFor(changed, models.StatusAvailable, nil))
+	return nil
+}
+
+// Busca los asientos con bloqueo vencido sin liberarlos, para que el caller cancele primero
+// las órdenes pendientes que los contienen
+func (r *seatRepository) FindExpiredLocks(now time.Time, limit int) ([]models.Seat, error) {
+	var seats []models.Seat
+	query := r.db.Where("status = ? AND lock_expires_at IS NOT NULL AND lock_expires_at <= ?", models.StatusLocked, now)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&seats).Error; err != nil {
+		return nil, fmt.Errorf("failed to find expired locks: %w", err)
+	}
+	return seats, nil
+}
+
+// Libera en bloque los asientos indicados que sigan con el bloqueo vencido (los que se
+// volvieron a bloquear mientras tanto quedan como están). Devuelve los asientos liberados
+// (con el LockedBy previo) para que el caller pueda recalcular disponibilidad
+func (r *seatRepository) ReleaseExpiredLocks(ids []string, now time.Time) ([]models.Seat, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var released []models.Seat
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status = ? AND lock_expires_at IS NOT NULL AND lock_expires_at <= ?", ids, models.StatusLocked, now).
+			Find(&released).Error; err != nil {
+			return err
+		}
+
+		if len(released) == 0 {
+			return nil
+		}
+
+		lockedIDs := make([]string, 0, len(released))
+		for _, s := range released {
+			lockedIDs = append(lockedIDs, s.ID)
+		}
+
+		return tx.Model(&models.Seat{}).
+			Where("id IN ?", lockedIDs).
+			Updates(unlockUpdates()).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to release expired locks: %w", err)
+	}
+
+	publishSeatChanges(r.publisher, models.SeatChangesFor(released, models.StatusAvailable, nil))
+	return released, nil
+}
+
+// Cuenta los asientos que el usuario tiene bloqueados (sin vencer) en un evento
+func (r *seatRepository) CountActiveLocksByUser(eventID, userId string, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Seat{}).
+		Where("event_id = ? AND locked_by = ? AND status = ? AND lock_expires_at > ?", eventID, userId, models.StatusLocked, now).
+		Count(&count).Error
+	return count, err
 }
 
 func (r *seatRepository) FindSeatByEventId(eventId string) ([]models.Seat, error) {
@@ -117,3 +278,69 @@ func (r *seatRepository) FindByIDs(ids []string) ([]models.Seat, error) {
 
 	return seats, nil
 }
+
+// Crea todo el inventario de un evento en una sola transacción (todo o nada).
+// Falla con utils.ErrEventHasSeats si el evento ya tiene asientos
+func (r *seatRepository) CreateForEvent(eventID, venueID string, seats []models.Seat) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Bloquea el evento para que dos generaciones simultáneas no dupliquen asientos
+		var event models.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", eventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrEventNotFound
+			}
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.Seat{}).Where("event_id = ?", eventID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return utils.ErrEventHasSeats
+		}
+
+		if err := tx.CreateInBatches(&seats, 1000).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&event).Update("venue_id", venueID).Error; err != nil {
+			return err
+		}
+
+		return (&eventRepository{db: tx}).UpdateAvailability(eventID)
+	})
+}
+
+// returningSeatIDs devuelve los asientos modificados por un UPDATE para publicar sus cambios
+var returningSeatIDs = clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "event_id"}}}
+
+// publishSeatChanges publica los cambios si hay un publisher configurado. Se llama solo
+// después de confirmar la transacción, para no anunciar cambios que terminan en rollback
+func publishSeatChanges(publisher SeatChangePublisher, changes []models.SeatChange) {
+	if publisher == nil || len(changes) == 0 {
+		return
+	}
+	publisher.PublishSeatChanges(changes)
+}
+
+func lockUpdates(userId, holdID string, expiresAt time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"status":          models.StatusLocked,
+		"locked_by":       userId,
+		"locked_at":       time.Now(),
+		"lock_expires_at": expiresAt,
+		"hold_id":         holdID,
+		"hold_extensions": 0,
+	}
+}
+
+func unlockUpdates() map[string]interface{} {
+	return map[string]interface{}{
+		"status":          models.StatusAvailable,
+		"locked_by":       nil,
+		"locked_at":       nil,
+		"lock_expires_at": nil,
+		"hold_id":         nil,
+		"hold_extensions": 0,
+	}
+}
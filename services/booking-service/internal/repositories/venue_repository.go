@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type VenueRepository interface {
+	Create(venue *models.Venue) error
+	FindByID(id string) (*models.Venue, error)
+	FindAll() ([]models.Venue, error)
+	Delete(id string) error
+}
+
+type venueRepository struct {
+	db *gorm.DB
+}
+
+func NewVenueRepository(db *gorm.DB) VenueRepository {
+	return &venueRepository{db: db}
+}
+
+// Crea el recinto con todas sus butacas en una transacción
+func (r *venueRepository) Create(venue *models.Venue) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		seats := venue.Seats
+		if err := tx.Omit("Seats").Create(venue).Error; err != nil {
+			return err
+		}
+		for i := range seats {
+			seats[i].VenueID = venue.ID
+		}
+		if len(seats) > 0 {
+			if err := tx.CreateInBatches(&seats, 1000).Error; err != nil {
+				return err
+			}
+		}
+		venue.Seats = seats
+		return nil
+	})
+}
+
+// Devuelve el recinto con sus butacas ordenadas por sección, fila y posición
+func (r *venueRepository) FindByID(id string) (*models.Venue, error) {
+	var venue models.Venue
+	err := r.db.Preload("Seats", func(db *gorm.DB) *gorm.DB {
+		return db.Order("section, row, position")
+	}).First(&venue, "id = ?", id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &venue, err
+}
+
+func (r *venueRepository) FindAll() ([]models.Venue, error) {
+	var venues []models.Venue
+	err := r.db.Order("name").Find(&venues).Error
+	return venues, err
+}
+
+func (r *venueRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("venue_id = ?", id).Delete(&models.VenueSeat{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Venue{}, "id = ?", id).Error
+	})
+}
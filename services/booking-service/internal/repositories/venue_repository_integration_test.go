@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestVenueRepository_Integration_GenerateSeatsForEvent(t *testing.T) {
+	db := openIntegrationDB(t)
+	eventRepo := NewEventRepository(db)
+	seatRepo := NewSeatRepository(db, nil)
+	venueRepo := NewVenueRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	suffix = suffix[len(suffix)-12:]
+	eventID := "cccccccc-1111-1111-1111-" + suffix
+
+	venue := &models.Venue{
+		Name: "IT Venue " + suffix,
+		Seats: []models.VenueSeat{
+			{Section: "PLATEA", Row: "B", Number: "1", Position: 1},
+			{Section: "PLATEA", Row: "A", Number: "2", Position: 2, AisleRight: true},
+			{Section: "PLATEA", Row: "A", Number: "1", Position: 1, AisleLeft: true},
+		},
+	}
+	if err := venueRepo.Create(venue); err != nil {
+		t.Fatalf("create venue failed: %v", err)
+	}
+	got, err := venueRepo.FindByID(venue.ID)
+	if err != nil || got == nil || len(got.Seats) != 3 {
+		t.Fatalf("find venue failed: %v %v", got, err)
+	}
+	if got.Seats[0].Row != "A" || got.Seats[0].Position != 1 || got.Seats[2].Row != "B" {
+		t.Fatalf("expected seats ordered by row and position, got %+v", got.Seats)
+	}
+
+	if err := eventRepo.Create(&models.Event{BaseModel: models.BaseModel{ID: eventID}, Name: "Venue Test", Location: "Arena", Date: time.Now().Add(24 * time.Hour), Price: money.New(1000, "ARS")}); err != nil {
+		t.Fatalf("create event failed: %v", err)
+	}
+
+	seats := make([]models.Seat, 0, len(got.Seats))
+	for _, vs := range got.Seats {
+		seats = append(seats, models.Seat{EventID: eventID, Section: vs.Section, Row: vs.Row, Number: vs.Number, Position: vs.Position, Price: money.New(1000, "ARS"), Status: models.StatusAvailable})
+	}
+	if err := seatRepo.CreateForEvent(eventID, venue.ID, seats); err != nil {
+		t.Fatalf("generate seats failed: %v", err)
+	}
+	created, _ := seatRepo.FindSeatByEventId(eventID)
+	if len(created) != 3 {
+		t.Fatalf("expected 3 seats, got %d", len(created))
+	}
+	event, _ := eventRepo.FindByID(eventID)
+	if event.VenueID == nil || *event.VenueID != venue.ID {
+		t.Fatalf("expected event linked to venue, got %v", event.VenueID)
+	}
+
+	if err := seatRepo.CreateForEvent(eventID, venue.ID, seats[:1]); !errors.Is(err, utils.ErrEventHasSeats) {
+		t.Fatalf("expected event has seats, got %v", err)
+	}
+
+	_ = eventRepo.Delete(eventID)
+	_ = venueRepo.Delete(venue.ID)
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WaitingRoomRepository interface {
+	// FindRate devuelve las admisiones por minuto del evento; nil si el evento no existe
+	FindRate(eventID string) (*int, error)
+	Enqueue(eventID, userID string, interval, window time.Duration, now time.Time) (*models.WaitingRoomEntry, error)
+	CountAhead(eventID string, admitAt, now time.Time) (int64, error)
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+type waitingRoomRepository struct {
+	db *gorm.DB
+}
+
+func NewWaitingRoomRepository(db *gorm.DB) WaitingRoomRepository {
+	return &waitingRoomRepository{db: db}
+}
+
+func (r *waitingRoomRepository) FindRate(eventID string) (*int, error) {
+	var event models.Event
+	err := r.db.Select("id", "waiting_room_rate").First(&event, "id = ?", eventID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event.WaitingRoomRate, nil
+}
+
+// Enqueue pone al usuario al final de la fila del evento. Cada entrada se admite interval
+// después de la anterior (o ya, si la fila está vacía), así entran a lo sumo 1/interval
+// usuarios por unidad de tiempo en todas las réplicas. Si el usuario ya tiene un lugar
+// vigente se devuelve ese mismo
+func (r *waitingRoomRepository) Enqueue(eventID, userID string, interval, window time.Duration, now time.Time) (*models.WaitingRoomEntry, error) {
+	var entry *models.WaitingRoomEntry
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Serializa las altas del mismo evento sin bloquear la fila de events
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "waiting_room:"+eventID).Error; err != nil {
+			return err
+		}
+
+		var existing models.WaitingRoomEntry
+		err := tx.Where("event_id = ? AND user_id = ?", eventID, userID).Take(&existing).Error
+		switch {
+		case err == nil && existing.ExpiresAt.After(now):
+			entry = &existing
+			return nil
+		case err == nil:
+			// La admisión anterior venció: vuelve a la fila desde el final
+			if err := tx.Unscoped().Delete(&existing).Error; err != nil {
+				return err
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		var last struct {
+			AdmitAt  *time.Time
+			Position *int64
+		}
+		if err := tx.Model(&models.WaitingRoomEntry{}).
+			Select("MAX(admit_at) AS admit_at, MAX(position) AS position").
+			Where("event_id = ?", eventID).
+			Scan(&last).Error; err != nil {
+			return err
+		}
+
+		admitAt := now
+		if last.AdmitAt != nil && last.AdmitAt.Add(interval).After(admitAt) {
+			admitAt = last.AdmitAt.Add(interval)
+		}
+		var position int64 = 1
+		if last.Position != nil {
+			position = *last.Position + 1
+		}
+
+		entry = &models.WaitingRoomEntry{
+			EventID:   eventID,
+			UserID:    userID,
+			Position:  position,
+			AdmitAt:   admitAt,
+			ExpiresAt: admitAt.Add(window),
+		}
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// CountAhead cuenta las entradas del evento que todavía esperan y entran antes que admitAt
+func (r *waitingRoomRepository) CountAhead(eventID string, admitAt, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WaitingRoomEntry{}).
+		Where("event_id = ? AND admit_at > ? AND admit_at < ?", eventID, now, admitAt).
+		Count(&count).Error
+	return count, err
+}
+
+// PurgeExpired borra las entradas cuya admisión ya venció. Las que esperan nunca vencen
+// antes de ser admitidas, así que el goteo de la fila no cambia
+func (r *waitingRoomRepository) PurgeExpired(now time.Time) (int64, error) {
+	res := r.db.Unscoped().Where("expires_at <= ?", now).Delete(&models.WaitingRoomEntry{})
+	return res.RowsAffected, res.Error
+}
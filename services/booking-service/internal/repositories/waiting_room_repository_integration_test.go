@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWaitingRoomRepository_Integration_EnqueueDrip(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewWaitingRoomRepository(db)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	eventID := "44444444-4444-4444-4444-" + suffix[len(suffix)-12:]
+	if err := NewEventRepository(db).Create(&models.Event{
+		BaseModel:       models.BaseModel{ID: eventID},
+		Name:            "IT Queue " + suffix,
+		Date:            time.Now().Add(24 * time.Hour),
+		Price:           money.New(5000, "ARS"),
+		WaitingRoomRate: 60,
+	}); err != nil {
+		t.Fatalf("create event failed: %v", err)
+	}
+
+	rate, err := repo.FindRate(eventID)
+	if err != nil || rate == nil || *rate != 60 {
+		t.Fatalf("unexpected rate: %v err=%v", rate, err)
+	}
+	if rate, err := repo.FindRate("55555555-5555-5555-5555-" + suffix[len(suffix)-12:]); err != nil || rate != nil {
+		t.Fatalf("expected nil rate for missing event, got %v err=%v", rate, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	first, err := repo.Enqueue(eventID, "u1", time.Second, time.Minute, now)
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	second, _ := repo.Enqueue(eventID, "u2", time.Second, time.Minute, now)
+	third, _ := repo.Enqueue(eventID, "u3", time.Second, time.Minute, now)
+
+	if !first.AdmitAt.Equal(now) || !second.AdmitAt.Equal(now.Add(time.Second)) || !third.AdmitAt.Equal(now.Add(2*time.Second)) {
+		t.Fatalf("unexpected drip: %v %v %v", first.AdmitAt, second.AdmitAt, third.AdmitAt)
+	}
+	if first.Position != 1 || third.Position != 3 {
+		t.Fatalf("unexpected positions: %d %d", first.Position, third.Position)
+	}
+
+	// Entrar de nuevo con el pase vigente devuelve el mismo lugar
+	again, _ := repo.Enqueue(eventID, "u2", time.Second, time.Minute, now)
+	if again.ID != second.ID {
+		t.Fatalf("expected same entry on rejoin, got %s and %s", again.ID, second.ID)
+	}
+
+	if ahead, err := repo.CountAhead(eventID, third.AdmitAt, now); err != nil || ahead != 1 {
+		t.Fatalf("expected 1 waiting ahead, got %d err=%v", ahead, err)
+	}
+
+	// Con la admisión vencida vuelve al final de la fila
+	later := now.Add(2 * time.Minute)
+	rejoined, _ := repo.Enqueue(eventID, "u1", time.Second, time.Minute, later)
+	if rejoined.ID == first.ID || !rejoined.AdmitAt.Equal(later) || rejoined.Position != 4 {
+		t.Fatalf("unexpected rejoin: %+v", rejoined)
+	}
+
+	if purged, err := repo.PurgeExpired(later); err != nil || purged != 2 {
+		t.Fatalf("expected 2 purged entries, got %d err=%v", purged, err)
+	}
+}
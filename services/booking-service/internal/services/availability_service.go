@@ -21,6 +21,19 @@ func UpdateEventAvailability(db *gorm.DB, eventID string) error {
 		return err
 	}
 
+	var ga struct {
+		Capacity  int64
+		Available int64
+	}
+	if err := db.Model(&models.GASection{}).
+		Select("COALESCE(SUM(capacity), 0) AS capacity, COALESCE(SUM(GREATEST(capacity - held - sold, 0)), 0) AS available").
+		Where("event_id = ?", eventID).
+		Scan(&ga).Error; err != nil {
+		return err
+	}
+	totalSeats += ga.Capacity
+	availableSeats += ga.Available
+
 	if totalSeats == 0 {
 		return nil
 	}
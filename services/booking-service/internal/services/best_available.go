@@ -0,0 +1,215 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Reintentos cuando otro comprador bloquea alguno de los asientos elegidos entre la lectura y el hold
+const bestAvailableAttempts = 3
+
+// seatRow es una fila de una sección con sus asientos ordenados por posición
+type seatRow struct {
+	label  string
+	seats  []models.Seat
+	center float64
+}
+
+// FindBestAvailable elige los mejores asientos disponibles de una sección y los bloquea
+// para el usuario. Prefiere filas delanteras y bloques centrados; con contiguous=false,
+// si no hay un bloque de asientos juntos, completa con los mejores asientos sueltos
+func (s *SeatService) FindBestAvailable(eventID, section string, quantity int, contiguous bool, userId string) (*models.SeatHold, []models.Seat, error) {
+	if quantity <= 0 {
+		return nil, nil, fmt.Errorf("%w: quantity must be positive", utils.ErrInvalidSeatRequest)
+	}
+	section = strings.TrimSpace(section)
+	if section == "" {
+		return nil, nil, fmt.Errorf("%w: section is required", utils.ErrInvalidSeatRequest)
+	}
+
+	rules, err := s.enabledRules(eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	excluded := make(map[string]bool)
+	for attempt := 0; attempt < bestAvailableAttempts; attempt++ {
+		seats, err := s.repo.FindSeatByEventId(eventID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rows := sectionRows(seats, section)
+		chosen, err := selectBestSeats(rows, quantity, contiguous, excluded, rulesFilter(rules, rows))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ids := make([]string, 0, len(chosen))
+		for _, seat := range chosen {
+			ids = append(ids, seat.ID)
+		}
+		hold, err := s.LockSeats(ids, userId)
+		if err == nil {
+			return hold, chosen, nil
+		}
+
+		// Otro comprador ganó alguno de los asientos: se descartan y se vuelve a elegir
+		var unavailable *utils.SeatsUnavailableError
+		if !errors.As(err, &unavailable) {
+			return nil, nil, err
+		}
+		for _, id := range unavailable.SeatIDs {
+			excluded[id] = true
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: seats were taken while selecting, try again", utils.ErrSeatsUnavailable)
+}
+
+// sectionRows agrupa los asientos de la sección por fila, en orden de filas (A antes que B)
+// y de posición. Los asientos sin posición (creados a mano) se ordenan por número
+func sectionRows(seats []models.Seat, section string) []seatRow {
+	byRow := make(map[string][]models.Seat)
+	for _, seat := range seats {
+		if strings.EqualFold(seat.Section, section) {
+			byRow[seat.Row] = append(byRow[seat.Row], seat)
+		}
+	}
+
+	rows := make([]seatRow, 0, len(byRow))
+	for label, rowSeats := range byRow {
+		sort.SliceStable(rowSeats, func(i, j int) bool {
+			if rowSeats[i].Position != rowSeats[j].Position {
+				return rowSeats[i].Position < rowSeats[j].Position
+			}
+			return naturalLess(rowSeats[i].Number, rowSeats[j].Number)
+		})
+		if rowSeats[0].Position == 0 {
+			for i := range rowSeats {
+				rowSeats[i].Position = i + 1
+			}
+		}
+		first, last := rowSeats[0].Position, rowSeats[len(rowSeats)-1].Position
+		rows = append(rows, seatRow{label: label, seats: rowSeats, center: float64(first+last) / 2})
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return naturalLess(rows[i].label, rows[j].label) })
+	return rows
+}
+
+// selectBestSeats devuelve el mejor bloque de asientos juntos (fila más adelante y más
+// centrado). Prefiere los bloques que acepta accept (reglas del evento) y solo si no hay
+// ninguno usa los demás. Sin bloque posible y con contiguous=false, los mejores asientos sueltos
+func selectBestSeats(rows []seatRow, quantity int, contiguous bool, excluded map[string]bool, accept func([]models.Seat) bool) ([]models.Seat, error) {
+	free := func(seat models.Seat) bool {
+		return seat.Status == models.StatusAvailable && !excluded[seat.ID]
+	}
+
+	if accept != nil {
+		if block := bestBlock(rows, quantity, free, accept); block != nil {
+			return block, nil
+		}
+	}
+	if block := bestBlock(rows, quantity, free, nil); block != nil {
+		return block, nil
+	}
+
+	if contiguous {
+		return nil, fmt.Errorf("%w: no %d adjacent seats available", utils.ErrNoAdjacentSeats, quantity)
+	}
+
+	type candidate struct {
+		seat     models.Seat
+		row      int
+		distance float64
+	}
+	var candidates []candidate
+	for i, row := range rows {
+		for _, seat := range row.seats {
+			if !free(seat) {
+				continue
+			}
+			distance := float64(seat.Position) - row.center
+			if distance < 0 {
+				distance = -distance
+			}
+			candidates = append(candidates, candidate{seat: seat, row: i, distance: distance})
+		}
+	}
+	if len(candidates) < quantity {
+		return nil, fmt.Errorf("%w: only %d seats left in section", utils.ErrSeatsUnavailable, len(candidates))
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].row != candidates[j].row {
+			return candidates[i].row < candidates[j].row
+		}
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	chosen := make([]models.Seat, 0, quantity)
+	for _, c := range candidates[:quantity] {
+		chosen = append(chosen, c.seat)
+	}
+	return chosen, nil
+}
+
+// bestBlock busca en orden de filas el bloque libre más centrado de la primera fila que tenga uno
+func bestBlock(rows []seatRow, quantity int, free func(models.Seat) bool, accept func([]models.Seat) bool) []models.Seat {
+	for _, row := range rows {
+		var best []models.Seat
+		bestDistance := 0.0
+		for start := 0; start+quantity <= len(row.seats); start++ {
+			block := row.seats[start : start+quantity]
+			if !blockAvailable(block, free) || (accept != nil && !accept(block)) {
+				continue
+			}
+			center := float64(block[0].Position+block[len(block)-1].Position) / 2
+			distance := center - row.center
+			if distance < 0 {
+				distance = -distance
+			}
+			if best == nil || distance < bestDistance {
+				best, bestDistance = block, distance
+			}
+		}
+		if best != nil {
+			return append([]models.Seat(nil), best...)
+		}
+	}
+	return nil
+}
+
+// Un bloque sirve si todos están libres, son posiciones consecutivas y no lo corta un pasillo
+func blockAvailable(block []models.Seat, free func(models.Seat) bool) bool {
+	for i, seat := range block {
+		if !free(seat) {
+			return false
+		}
+		if i == 0 {
+			continue
+		}
+		if !seatsAdjacent(block[i-1], seat) {
+			return false
+		}
+	}
+	return true
+}
+
+// Dos asientos de la misma fila están juntos si sus posiciones son consecutivas y no hay pasillo
+func seatsAdjacent(left, right models.Seat) bool {
+	return right.Position == left.Position+1 && !left.AisleRight && !right.AisleLeft
+}
+
+// naturalLess ordena etiquetas como filas o números de asiento: "B" < "AA" y "9" < "10"
+func naturalLess(a, b string) bool {
+	a, b = strings.ToUpper(a), strings.ToUpper(b)
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"booking-service/internal/config"
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// row arma una fila de asientos; status por posición: '.' libre, 'x' vendido, 'l' bloqueado, '|' pasillo a la derecha del anterior
+func row(section, label, layout string) []models.Seat {
+	var seats []models.Seat
+	pos := 0
+	for _, ch := range layout {
+		if ch == '|' {
+			seats[len(seats)-1].AisleRight = true
+			continue
+		}
+		pos++
+		status := models.StatusAvailable
+		switch ch {
+		case 'x':
+			status = models.StatusSold
+		case 'l':
+			status = models.StatusLocked
+		}
+		seats = append(seats, models.Seat{
+			BaseModel: models.BaseModel{ID: fmt.Sprintf("%s-%s%d", section, label, pos)},
+			EventID:   "e1",
+			Section:   section,
+			Row:       label,
+			Number:    fmt.Sprintf("%d", pos),
+			Position:  pos,
+			Status:    status,
+		})
+	}
+	return seats
+}
+
+func seatIDs(seats []models.Seat) []string {
+	ids := make([]string, 0, len(seats))
+	for _, s := range seats {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestSelectBestSeats(t *testing.T) {
+	cases := []struct {
+		name       string
+		seats      [][]models.Seat
+		quantity   int
+		contiguous bool
+		want       []string
+		wantErr    error
+	}{
+		{
+			name:     "centered block in first row",
+			seats:    [][]models.Seat{row("PLATEA", "A", "......"), row("PLATEA", "B", "......")},
+			quantity: 2, contiguous: true,
+			want: []string{"PLATEA-A3", "PLATEA-A4"},
+		},
+		{
+			name:     "fragmented row picks the gap that fits",
+			seats:    [][]models.Seat{row("PLATEA", "A", "..x...x")},
+			quantity: 3, contiguous: true,
+			want: []string{"PLATEA-A4", "PLATEA-A5", "PLATEA-A6"},
+		},
+		{
+			name:     "skips to next row when front row is too fragmented",
+			seats:    [][]models.Seat{row("PLATEA", "A", ".x.x.x."), row("PLATEA", "B", "x....x")},
+			quantity: 4, contiguous: true,
+			want: []string{"PLATEA-B2", "PLATEA-B3", "PLATEA-B4", "PLATEA-B5"},
+		},
+		{
+			name:     "locked seats are not free",
+			seats:    [][]models.Seat{row("PLATEA", "A", "ll..ll")},
+			quantity: 2, contiguous: true,
+			want: []string{"PLATEA-A3", "PLATEA-A4"},
+		},
+		{
+			name:     "block does not cross an aisle",
+			seats:    [][]models.Seat{row("PLATEA", "A", "...|..."), row("PLATEA", "B", "....|..")},
+			quantity: 4, contiguous: true,
+			want: []string{"PLATEA-B1", "PLATEA-B2", "PLATEA-B3", "PLATEA-B4"},
+		},
+		{
+			name:     "rows sort naturally",
+			seats:    [][]models.Seat{row("PLATEA", "AA", ".."), row("PLATEA", "B", "x."), row("PLATEA", "C", "..")},
+			quantity: 2, contiguous: true,
+			want: []string{"PLATEA-C1", "PLATEA-C2"},
+		},
+		{
+			name:     "only other sections",
+			seats:    [][]models.Seat{row("VIP", "A", "....")},
+			quantity: 1, contiguous: true,
+			wantErr: utils.ErrNoAdjacentSeats,
+		},
+		{
+			name:     "contiguous fails on fragmentation",
+			seats:    [][]models.Seat{row("PLATEA", "A", ".x.x."), row("PLATEA", "B", "x.x")},
+			quantity: 2, contiguous: true,
+			wantErr: utils.ErrNoAdjacentSeats,
+		},
+		{
+			name:     "split seats when not contiguous",
+			seats:    [][]models.Seat{row("PLATEA", "A", ".x.x."), row("PLATEA", "B", "x.x")},
+			quantity: 4, contiguous: false,
+			want: []string{"PLATEA-A3", "PLATEA-A1", "PLATEA-A5", "PLATEA-B2"},
+		},
+		{
+			name:     "not enough seats at all",
+			seats:    [][]models.Seat{row("PLATEA", "A", ".x.")},
+			quantity: 3, contiguous: false,
+			wantErr: utils.ErrSeatsUnavailable,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var all []models.Seat
+			for _, r := range tc.seats {
+				all = append(all, r...)
+			}
+			got, err := selectBestSeats(sectionRows(all, "platea"), tc.quantity, tc.contiguous, nil, nil)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v (%v)", tc.wantErr, err, seatIDs(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprint(seatIDs(got)) != fmt.Sprint(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, seatIDs(got))
+			}
+		})
+	}
+}
+
+func TestSectionRows_OrdersSeatsWithoutPosition(t *testing.T) {
+	seats := []models.Seat{
+		{BaseModel: models.BaseModel{ID: "10"}, Section: "GENERAL", Number: "G10", Status: models.StatusAvailable},
+		{BaseModel: models.BaseModel{ID: "2"}, Section: "GENERAL", Number: "G2", Status: models.StatusAvailable},
+		{BaseModel: models.BaseModel{ID: "1"}, Section: "GENERAL", Number: "G1", Status: models.StatusAvailable},
+	}
+	rows := sectionRows(seats, "GENERAL")
+	if len(rows) != 1 || fmt.Sprint(seatIDs(rows[0].seats)) != "[1 2 10]" || rows[0].seats[2].Position != 3 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+}
+
+// seatInventory es un repositorio en memoria con bloqueo atómico, para probar compras simultáneas
+type seatInventory struct {
+	mu    sync.Mutex
+	seats map[string]*models.Seat
+	// Se ejecuta antes de cada LockSeats (p. ej. para simular otro comprador)
+	beforeLock func()
+}
+
+func newSeatInventory(rows ...[]models.Seat) *seatInventory {
+	inv := &seatInventory{seats: make(map[string]*models.Seat)}
+	for _, r := range rows {
+		for i := range r {
+			seat := r[i]
+			inv.seats[seat.ID] = &seat
+		}
+	}
+	return inv
+}
+
+func (inv *seatInventory) repo() *mockSeatRepo {
+	return &mockSeatRepo{
+		findByEventIDFn: func(string) ([]models.Seat, error) {
+			inv.mu.Lock()
+			defer inv.mu.Unlock()
+			out := make([]models.Seat, 0, len(inv.seats))
+			for _, s := range inv.seats {
+				out = append(out, *s)
+			}
+			return out, nil
+		},
+		findByIDsFn: func(ids []string) ([]models.Seat, error) {
+			inv.mu.Lock()
+			defer inv.mu.Unlock()
+			out := make([]models.Seat, 0, len(ids))
+			for _, id := range ids {
+				out = append(out, *inv.seats[id])
+			}
+			return out, nil
+		},
+		lockSeatsFn: func(ids []string, userId, holdID string, _ time.Time) error {
+			if inv.beforeLock != nil {
+				inv.beforeLock()
+			}
+			inv.mu.Lock()
+			defer inv.mu.Unlock()
+			var failed []string
+			for _, id := range ids {
+				if inv.seats[id].Status != models.StatusAvailable {
+					failed = append(failed, id)
+				}
+			}
+			if len(failed) > 0 {
+				return &utils.SeatsUnavailableError{SeatIDs: failed}
+			}
+			for _, id := range ids {
+				inv.seats[id].Status = models.StatusLocked
+				inv.seats[id].LockedBy = &userId
+			}
+			return nil
+		},
+	}
+}
+
+func TestSeatService_FindBestAvailable_RetriesWhenSeatsAreTaken(t *testing.T) {
+	inv := newSeatInventory(row("PLATEA", "A", "......"), row("PLATEA", "B", "......"))
+	// Otro comprador se lleva el centro de la fila A justo antes del primer intento
+	attempts := 0
+	inv.beforeLock = func() {
+		attempts++
+		if attempts == 1 {
+			inv.mu.Lock()
+			inv.seats["PLATEA-A4"].Status = models.StatusLocked
+			inv.mu.Unlock()
+		}
+	}
+	svc := NewSeatService(inv.repo(), &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, config.HoldPolicy{})
+
+	hold, seats, err := svc.FindBestAvailable("e1", "PLATEA", 3, true, "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected a retry, got %d attempts", attempts)
+	}
+	if fmt.Sprint(seatIDs(seats)) != "[PLATEA-A1 PLATEA-A2 PLATEA-A3]" || fmt.Sprint(hold.SeatIDs) != fmt.Sprint(seatIDs(seats)) {
+		t.Fatalf("unexpected selection after retry: %v hold=%v", seatIDs(seats), hold.SeatIDs)
+	}
+}
+
+func TestSeatService_FindBestAvailable_GivesUpAfterRepeatedConflicts(t *testing.T) {
+	inv := newSeatInventory(row("PLATEA", "A", "........"))
+	// Siempre gana otro comprador: en cada intento se ocupa el asiento central elegido
+	inv.beforeLock = func() {
+		inv.mu.Lock()
+		defer inv.mu.Unlock()
+		for _, id := range []string{"PLATEA-A4", "PLATEA-A5", "PLATEA-A3", "PLATEA-A6", "PLATEA-A2", "PLATEA-A7"} {
+			if inv.seats[id].Status == models.StatusAvailable {
+				inv.seats[id].Status = models.StatusLocked
+				return
+			}
+		}
+	}
+	svc := NewSeatService(inv.repo(), &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, config.HoldPolicy{})
+
+	if _, _, err := svc.FindBestAvailable("e1", "PLATEA", 2, true, "u1"); !errors.Is(err, utils.ErrSeatsUnavailable) {
+		t.Fatalf("expected seats unavailable, got %v", err)
+	}
+}
+
+func TestSeatService_FindBestAvailable_ConcurrentBuyersNeverShareSeats(t *testing.T) {
+	inv := newSeatInventory(row("PLATEA", "A", "........"), row("PLATEA", "B", "........"), row("PLATEA", "C", "........"))
+	svc := NewSeatService(inv.repo(), &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, config.HoldPolicy{})
+
+	const buyers = 12
+	var wg sync.WaitGroup
+	results := make([][]models.Seat, buyers)
+	errs := make([]error, buyers)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i], errs[i] = svc.FindBestAvailable("e1", "PLATEA", 2, true, fmt.Sprintf("u%d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	owner := make(map[string]int)
+	held := 0
+	for i, seats := range results {
+		if errs[i] != nil {
+			if !errors.Is(errs[i], utils.ErrSeatsUnavailable) && !errors.Is(errs[i], utils.ErrNoAdjacentSeats) {
+				t.Fatalf("buyer %d: unexpected error %v", i, errs[i])
+			}
+			continue
+		}
+		if len(seats) != 2 || seats[0].Row != seats[1].Row || seats[1].Position != seats[0].Position+1 {
+			t.Fatalf("buyer %d: expected two adjacent seats, got %v", i, seatIDs(seats))
+		}
+		for _, s := range seats {
+			if prev, ok := owner[s.ID]; ok {
+				t.Fatalf("seat %s held by buyers %d and %d", s.ID, prev, i)
+			}
+			owner[s.ID] = i
+			held++
+		}
+	}
+
+	locked := 0
+	for _, s := range inv.seats {
+		if s.Status == models.StatusLocked {
+			locked++
+		}
+	}
+	if locked != held {
+		t.Fatalf("inventory has %d locked seats but buyers hold %d", locked, held)
+	}
+	if held == 0 {
+		t.Fatalf("expected some buyers to get seats")
+	}
+}
+
+func TestSeatService_FindBestAvailable_Validation(t *testing.T) {
+	svc := NewSeatService(&mockSeatRepo{}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, config.HoldPolicy{})
+	if _, _, err := svc.FindBestAvailable("e1", "PLATEA", 0, true, "u1"); !errors.Is(err, utils.ErrInvalidSeatRequest) {
+		t.Fatalf("expected invalid request, got %v", err)
+	}
+	if _, _, err := svc.FindBestAvailable("e1", " ", 2, true, "u1"); !errors.Is(err, utils.ErrInvalidSeatRequest) {
+		t.Fatalf("expected invalid request, got %v", err)
+	}
+}
@@ -3,22 +3,51 @@ package services
 import (
 	"booking-service/internal/models"
 	"booking-service/internal/repositories"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
 )
 
+// OrderStatusChange es un pedido de cambio de estado de una orden
+type OrderStatusChange struct {
+	To                models.PaymentStatus
+	PaymentProviderID string
+	// ExpectedVersion (opcional) es la versión que el cliente leyó; si cambió se rechaza
+	ExpectedVersion *int
+	ChangedBy       string
+	Reason          string
+}
+
+// HoldReleaser devuelve al inventario el hold de un usuario
+type HoldReleaser interface {
+	ReleaseHold(holdID, userId string) error
+}
+
 type BookingOrderService struct {
 	repo       repositories.BookingOrderRepository
 	repoSeats  repositories.SeatRepository
 	repoEvents repositories.EventRepository
+	// Hold y sesión de pago que se liberan cuando el usuario cancela la orden; opcionales
+	holds    HoldReleaser
+	sessions SessionExpirer
 }
 
-func NewBookingOrderService(repo repositories.BookingOrderRepository, repoSeats repositories.SeatRepository, repoEvents repositories.EventRepository) *BookingOrderService {
-	return &BookingOrderService{repo: repo, repoSeats: repoSeats, repoEvents: repoEvents}
+func NewBookingOrderService(repo repositories.BookingOrderRepository, repoSeats repositories.SeatRepository, repoEvents repositories.EventRepository, holds HoldReleaser, sessions SessionExpirer) *BookingOrderService {
+	return &BookingOrderService{repo: repo, repoSeats: repoSeats, repoEvents: repoEvents, holds: holds, sessions: sessions}
 }
 
 func (s *BookingOrderService) CreateBookingOrder(bookingOrder *models.BookingOrder) error {
 	return s.repo.Create(bookingOrder)
 }
 
+// AttachPaymentSession guarda la sesión de pago de la orden para poder cerrarla si se cancela
+func (s *BookingOrderService) AttachPaymentSession(orderID, sessionID string) error {
+	return s.repo.AttachPaymentSession(orderID, sessionID)
+}
+
 func (s *BookingOrderService) FindAllBookingOrders() ([]models.BookingOrder, error) {
 	return s.repo.FindAll()
 }
@@ -55,15 +84,91 @@ func (s *BookingOrderService) FindBookingOrderById(id string) (*models.BookingOr
 	return bookingOrder, nil
 }
 
+// UpdateBookingOrderStatus cambia el estado de una orden por una acción interna del sistema
 func (s *BookingOrderService) UpdateBookingOrderStatus(id string, status models.PaymentStatus) error {
-	return s.repo.UpdateStatus(id, status)
+	return s.ChangeBookingOrderStatus(id, OrderStatusChange{To: status, ChangedBy: "system"})
+}
+
+// ChangeBookingOrderStatus aplica un cambio de estado respetando la máquina de estados.
+// Devuelve *utils.InvalidTransitionError si el cambio no está permitido y
+// utils.ErrOrderVersionConflict si la orden cambió mientras tanto
+func (s *BookingOrderService) ChangeBookingOrderStatus(id string, change OrderStatusChange) error {
+	if !change.To.IsValid() {
+		return &utils.InvalidTransitionError{From: "", To: string(change.To)}
+	}
+
+	order, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if change.ExpectedVersion != nil && *change.ExpectedVersion != order.Version {
+		return utils.ErrOrderVersionConflict
+	}
+	if !order.Status.CanTransitionTo(change.To) {
+		return &utils.InvalidTransitionError{From: string(order.Status), To: string(change.To)}
+	}
+
+	return s.repo.ChangeStatus(&repositories.StatusChange{
+		OrderID:           order.ID,
+		From:              order.Status,
+		To:                change.To,
+		Version:           order.Version,
+		PaymentProviderID: change.PaymentProviderID,
+		ChangedBy:         change.ChangedBy,
+		Reason:            change.Reason,
+	})
 }
 
-func (s *BookingOrderService) UpdateBookingOrder(id string, status models.PaymentStatus, paymentProviderID string) error {
-	if paymentProviderID == "" {
-		return s.repo.UpdateStatus(id, status)
+// CancelOrder cancela una orden PENDING a pedido de su dueño: cierra la sesión de pago y
+// devuelve al inventario los asientos y entradas del hold. Es el único cambio de estado que
+// puede pedir un usuario; el resto lo hacen el consumer de pagos, el lock reaper y los reembolsos
+func (s *BookingOrderService) CancelOrder(id, userID string, expectedVersion *int, reason string) error {
+	order, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if order.UserID != userID {
+		return utils.ErrOrderForbidden
+	}
+	if expectedVersion != nil && *expectedVersion != order.Version {
+		return utils.ErrOrderVersionConflict
+	}
+	if order.Status != models.PaymentPending {
+		return &utils.InvalidTransitionError{From: string(order.Status), To: string(models.PaymentCancelled)}
+	}
+
+	if reason == "" {
+		reason = "cancelled by user"
 	}
-	return s.repo.Update(id, status, paymentProviderID)
+	cancelled, err := s.repo.CancelPending([]string{order.ID}, userID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+	// La orden se pagó, venció o se canceló entre la lectura y el cambio
+	if len(cancelled) == 0 {
+		return utils.ErrOrderVersionConflict
+	}
+	expireSessions(s.sessions, cancelled)
+
+	// La orden ya está cancelada: si el hold no se puede liberar lo hará el lock reaper al vencer
+	if order.HoldID != nil && s.holds != nil {
+		if err := s.holds.ReleaseHold(*order.HoldID, userID); err != nil && !errors.Is(err, utils.ErrHoldNotFound) {
+			log.Printf("⚠️ No se pudo liberar el hold %s de la orden %s: %v", *order.HoldID, order.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *BookingOrderService) FindStatusHistory(id string) ([]models.BookingOrderStatusHistory, error) {
+	return s.repo.FindStatusHistory(id)
 }
 
 func (s *BookingOrderService) FindAllOrdersByUserID(userID string) ([]models.BookingOrder, error) {
@@ -2,18 +2,25 @@ package services
 
 import (
 	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/utils"
 	"errors"
 	"testing"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type mockBookingOrderRepo struct {
 	createFn                func(*models.BookingOrder) error
 	findAllFn               func() ([]models.BookingOrder, error)
 	findByIDFn              func(string) (*models.BookingOrder, error)
-	updateStatusFn          func(string, models.PaymentStatus) error
-	updateFn                func(string, models.PaymentStatus, string) error
+	changeStatusFn          func(*repositories.StatusChange) error
+	findStatusHistoryFn     func(string) ([]models.BookingOrderStatusHistory, error)
 	findAllOrdersByUserIDFn func(string) ([]models.BookingOrder, error)
+	findPendingByUserIDsFn  func([]string) ([]models.BookingOrder, error)
+	cancelPendingFn         func([]string, string, string) ([]models.BookingOrder, error)
+	attachPaymentSessionFn  func(string, string) error
 }
 
 func (m *mockBookingOrderRepo) Create(booking *models.BookingOrder) error { return m.createFn(booking) }
@@ -21,16 +28,26 @@ func (m *mockBookingOrderRepo) FindAll() ([]models.BookingOrder, error)   { retu
 func (m *mockBookingOrderRepo) FindByID(id string) (*models.BookingOrder, error) {
 	return m.findByIDFn(id)
 }
-func (m *mockBookingOrderRepo) UpdateStatus(id string, status models.PaymentStatus) error {
-	return m.updateStatusFn(id, status)
+func (m *mockBookingOrderRepo) ChangeStatus(change *repositories.StatusChange) error {
+	return m.changeStatusFn(change)
 }
-func (m *mockBookingOrderRepo) Update(id string, status models.PaymentStatus, paymentProviderID string) error {
-	return m.updateFn(id, status, paymentProviderID)
+func (m *mockBookingOrderRepo) FindStatusHistory(orderID string) ([]models.BookingOrderStatusHistory, error) {
+	return m.findStatusHistoryFn(orderID)
 }
 func (m *mockBookingOrderRepo) FindAllOrdersByUserID(userID string) ([]models.BookingOrder, error) {
 	return m.findAllOrdersByUserIDFn(userID)
 }
 
+func (m *mockBookingOrderRepo) FindPendingByUserIDs(userIDs []string) ([]models.BookingOrder, error) {
+	return m.findPendingByUserIDsFn(userIDs)
+}
+func (m *mockBookingOrderRepo) CancelPending(ids []string, changedBy, reason string) ([]models.BookingOrder, error) {
+	return m.cancelPendingFn(ids, changedBy, reason)
+}
+func (m *mockBookingOrderRepo) AttachPaymentSession(orderID, sessionID string) error {
+	return m.attachPaymentSessionFn(orderID, sessionID)
+}
+
 type mockSeatRepoForBooking struct {
 	findByIDFn func(string) (*models.Seat, error)
 }
@@ -39,10 +56,20 @@ func (m *mockSeatRepoForBooking) Create(*models.Seat) error { panic("not used")
 func (m *mockSeatRepoForBooking) FindAlls() ([]models.Seat, error) { panic("not used") }
 func (m *mockSeatRepoForBooking) FindByID(id string) (*models.Seat, error) { return m.findByIDFn(id) }
 func (m *mockSeatRepoForBooking) UpdateStatus(string, models.SeatStatus) error { panic("not used") }
-func (m *mockSeatRepoForBooking) LockSeat(string, string, time.Time) error { panic("not used") }
+func (m *mockSeatRepoForBooking) LockSeat(string, string, string, time.Time) error { panic("not used") }
+func (m *mockSeatRepoForBooking) LockSeats([]string, string, string, time.Time) error { panic("not used") }
+func (m *mockSeatRepoForBooking) FindByHoldID(string) ([]models.Seat, error) { panic("not used") }
+func (m *mockSeatRepoForBooking) ExtendHold(string, string, time.Time, int, time.Time) (int64, error) {
+	panic("not used")
+}
+func (m *mockSeatRepoForBooking) ReleaseHold(string, string) (int64, error) { panic("not used") }
 func (m *mockSeatRepoForBooking) UnlockIfExpired(string, time.Time) error { panic("not used") }
+func (m *mockSeatRepoForBooking) FindExpiredLocks(time.Time, int) ([]models.Seat, error) { panic("not used") }
+func (m *mockSeatRepoForBooking) ReleaseExpiredLocks([]string, time.Time) ([]models.Seat, error) { panic("not used") }
+func (m *mockSeatRepoForBooking) CountActiveLocksByUser(string, string, time.Time) (int64, error) { panic("not used") }
 func (m *mockSeatRepoForBooking) FindSeatByEventId(string) ([]models.Seat, error) { panic("not used") }
 func (m *mockSeatRepoForBooking) FindByIDs([]string) ([]models.Seat, error) { panic("not used") }
+func (m *mockSeatRepoForBooking) CreateForEvent(string, string, []models.Seat) error { panic("not used") }
 
 type mockEventRepoForBooking struct {
 	findByIDFn func(string) (*models.Event, error)
@@ -55,38 +82,170 @@ func (m *mockEventRepoForBooking) Update(*models.Event) error { panic("not used"
 func (m *mockEventRepoForBooking) Delete(string) error { panic("not used") }
 func (m *mockEventRepoForBooking) UpdateAvailability(string) error { panic("not used") }
 
-func TestBookingOrderService_UpdateBookingOrder_UsesCorrectRepoMethod(t *testing.T) {
-	statusCalled := 0
-	updateCalled := 0
-
-	svc := NewBookingOrderService(
-		&mockBookingOrderRepo{
-			updateStatusFn: func(string, models.PaymentStatus) error {
-				statusCalled++
-				return nil
+func TestBookingOrderService_ChangeBookingOrderStatus(t *testing.T) {
+	newSvc := func(status models.PaymentStatus, version int, changes *[]repositories.StatusChange) *BookingOrderService {
+		return NewBookingOrderService(
+			&mockBookingOrderRepo{
+				findByIDFn: func(id string) (*models.BookingOrder, error) {
+					return &models.BookingOrder{BaseModel: models.BaseModel{ID: id}, Status: status, Version: version}, nil
+				},
+				changeStatusFn: func(c *repositories.StatusChange) error {
+					*changes = append(*changes, *c)
+					return nil
+				},
 			},
-			updateFn: func(string, models.PaymentStatus, string) error {
-				updateCalled++
-				return nil
-			},
-		},
-		&mockSeatRepoForBooking{},
-		&mockEventRepoForBooking{},
-	)
-
-	if err := svc.UpdateBookingOrder("o1", models.PaymentCompleted, ""); err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if statusCalled != 1 || updateCalled != 0 {
-		t.Fatalf("expected UpdateStatus once, got status=%d update=%d", statusCalled, updateCalled)
+			&mockSeatRepoForBooking{},
+			&mockEventRepoForBooking{},
+			nil,
+			nil,
+		)
 	}
 
-	if err := svc.UpdateBookingOrder("o1", models.PaymentCompleted, "pi_123"); err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if statusCalled != 1 || updateCalled != 1 {
-		t.Fatalf("expected Update once on second call, got status=%d update=%d", statusCalled, updateCalled)
+	t.Run("allowed transition is applied with current version", func(t *testing.T) {
+		var changes []repositories.StatusChange
+		svc := newSvc(models.PaymentPending, 3, &changes)
+
+		err := svc.ChangeBookingOrderStatus("o1", OrderStatusChange{To: models.PaymentCompleted, PaymentProviderID: "pi_123", ChangedBy: "u1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(changes) != 1 {
+			t.Fatalf("expected one status change, got %d", len(changes))
+		}
+		c := changes[0]
+		if c.From != models.PaymentPending || c.To != models.PaymentCompleted || c.Version != 3 || c.PaymentProviderID != "pi_123" || c.ChangedBy != "u1" {
+			t.Fatalf("unexpected change: %+v", c)
+		}
+	})
+
+	t.Run("illegal transitions are rejected", func(t *testing.T) {
+		cases := []struct{ from, to models.PaymentStatus }{
+			{models.PaymentCompleted, models.PaymentPending},
+			{models.PaymentCompleted, models.PaymentFailed},
+			{models.PaymentFailed, models.PaymentCompleted},
+			{models.PaymentCancelled, models.PaymentPending},
+			{models.PaymentRefunded, models.PaymentCompleted},
+		}
+		for _, tc := range cases {
+			var changes []repositories.StatusChange
+			svc := newSvc(tc.from, 1, &changes)
+
+			err := svc.ChangeBookingOrderStatus("o1", OrderStatusChange{To: tc.to})
+			var transitionErr *utils.InvalidTransitionError
+			if !errors.As(err, &transitionErr) || !errors.Is(err, utils.ErrInvalidOrderTransition) {
+				t.Fatalf("%s -> %s: expected InvalidTransitionError, got %v", tc.from, tc.to, err)
+			}
+			if len(changes) != 0 {
+				t.Fatalf("%s -> %s: repo must not be called", tc.from, tc.to)
+			}
+		}
+	})
+
+	t.Run("stale expected version is a conflict", func(t *testing.T) {
+		var changes []repositories.StatusChange
+		svc := newSvc(models.PaymentPending, 2, &changes)
+		stale := 1
+
+		err := svc.ChangeBookingOrderStatus("o1", OrderStatusChange{To: models.PaymentFailed, ExpectedVersion: &stale})
+		if !errors.Is(err, utils.ErrOrderVersionConflict) {
+			t.Fatalf("expected ErrOrderVersionConflict, got %v", err)
+		}
+	})
+
+	t.Run("unknown status is rejected", func(t *testing.T) {
+		var changes []repositories.StatusChange
+		svc := newSvc(models.PaymentPending, 1, &changes)
+		if err := svc.ChangeBookingOrderStatus("o1", OrderStatusChange{To: "BAD"}); !errors.Is(err, utils.ErrInvalidOrderTransition) {
+			t.Fatalf("expected ErrInvalidOrderTransition, got %v", err)
+		}
+	})
+}
+
+type fakeHoldReleaser struct {
+	released []string
+}
+
+func (f *fakeHoldReleaser) ReleaseHold(holdID, userId string) error {
+	f.released = append(f.released, holdID+"/"+userId)
+	return nil
+}
+
+func TestBookingOrderService_CancelOrder(t *testing.T) {
+	h1 := "h1"
+	newSvc := func(order models.BookingOrder, cancelled *[]string) (*BookingOrderService, *fakeHoldReleaser, *FakePaymentProvider) {
+		holds := &fakeHoldReleaser{}
+		fake := NewFakePaymentProvider()
+		svc := NewBookingOrderService(
+			&mockBookingOrderRepo{
+				findByIDFn: func(id string) (*models.BookingOrder, error) {
+					if id != order.ID {
+						return nil, gorm.ErrRecordNotFound
+					}
+					o := order
+					return &o, nil
+				},
+				cancelPendingFn: func(ids []string, changedBy, reason string) ([]models.BookingOrder, error) {
+					*cancelled = append(*cancelled, ids...)
+					return []models.BookingOrder{{BaseModel: order.BaseModel, PaymentProvider: FakeProviderName, PaymentSessionID: "cs_1"}}, nil
+				},
+			},
+			&mockSeatRepoForBooking{},
+			&mockEventRepoForBooking{},
+			holds,
+			NewPaymentProviders(fake),
+		)
+		return svc, holds, fake
 	}
+	pending := models.BookingOrder{BaseModel: models.BaseModel{ID: "o1"}, UserID: "u1", Status: models.PaymentPending, Version: 2, HoldID: &h1}
+
+	t.Run("owner cancels pending order and releases the hold", func(t *testing.T) {
+		var cancelled []string
+		svc, holds, fake := newSvc(pending, &cancelled)
+
+		if err := svc.CancelOrder("o1", "u1", nil, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(cancelled) != 1 || cancelled[0] != "o1" {
+			t.Fatalf("expected order cancelled, got %v", cancelled)
+		}
+		if len(fake.Expired) != 1 || fake.Expired[0] != "cs_1" {
+			t.Fatalf("expected payment session expired, got %v", fake.Expired)
+		}
+		if len(holds.released) != 1 || holds.released[0] != "h1/u1" {
+			t.Fatalf("expected hold released, got %v", holds.released)
+		}
+	})
+
+	t.Run("rejections", func(t *testing.T) {
+		stale := 1
+		completed := pending
+		completed.Status = models.PaymentCompleted
+		cases := []struct {
+			name    string
+			order   models.BookingOrder
+			id      string
+			user    string
+			version *int
+			want    error
+		}{
+			{"not found", pending, "missing", "u1", nil, utils.ErrOrderNotFound},
+			{"other user", pending, "o1", "u2", nil, utils.ErrOrderForbidden},
+			{"stale version", pending, "o1", "u1", &stale, utils.ErrOrderVersionConflict},
+			{"not pending", completed, "o1", "u1", nil, utils.ErrInvalidOrderTransition},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				var cancelled []string
+				svc, holds, _ := newSvc(tc.order, &cancelled)
+				if err := svc.CancelOrder(tc.id, tc.user, tc.version, ""); !errors.Is(err, tc.want) {
+					t.Fatalf("expected %v, got %v", tc.want, err)
+				}
+				if len(cancelled) != 0 || len(holds.released) != 0 {
+					t.Fatalf("nothing must change, got %v %v", cancelled, holds.released)
+				}
+			})
+		}
+	})
 }
 
 func TestBookingOrderService_FindBookingOrderById_EnrichesSeatsAndEvent(t *testing.T) {
@@ -105,6 +264,8 @@ func TestBookingOrderService_FindBookingOrderById_EnrichesSeatsAndEvent(t *testi
 		&mockEventRepoForBooking{findByIDFn: func(string) (*models.Event, error) {
 			return &models.Event{Name: "Show", Date: time.Date(2026, 3, 1, 21, 30, 0, 0, time.UTC)}, nil
 		}},
+		nil,
+		nil,
 	)
 
 	got, err := service.FindBookingOrderById("o1")
@@ -133,6 +294,8 @@ func TestBookingOrderService_FindAllOrdersByUserID_EnrichesOrders(t *testing.T)
 		&mockEventRepoForBooking{findByIDFn: func(string) (*models.Event, error) {
 			return &models.Event{Name: "Concert", Date: time.Date(2026, 5, 10, 20, 0, 0, 0, time.UTC)}, nil
 		}},
+		nil,
+		nil,
 	)
 
 	orders, err := svc.FindAllOrdersByUserID("u1")
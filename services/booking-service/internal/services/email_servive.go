@@ -1,10 +1,14 @@
 package services
 
 import (
+	"booking-service/internal/models"
 	"booking-service/internal/repositories"
 	"booking-service/pkg/domain"
+	"booking-service/pkg/money"
 	"context"
 	"fmt"
+	"html"
+	"strings"
 	"sync"
 	"time"
 )
@@ -14,7 +18,8 @@ type EmailService interface {
 	SendBulk(emails []*domain.Email)
 	Shutdown()
 
-	SendPurchaseEmail(ctx context.Context, to string, name string, orderId string, amount float64) error
+	SendPurchaseEmail(ctx context.Context, to string, name string, orderId string, amount money.Money, lines []models.OrderLine) error
+	SendRefundEmail(ctx context.Context, to string, name string, orderId string, amount money.Money, seats int) error
 }
 
 type emailService struct {
@@ -34,11 +39,45 @@ func NewEmailService(repo repositories.EmailRepository, workers int) EmailServic
 	return s
 }
 
-// SendSync envía inmediatamente (bloqueante)
-func (s *emailService) SendPurchaseEmail(ctx context.Context, to string, name string, orderId string, amount float64) error {
+// SendSync envía inmediatamente (bloqueante). Con las líneas de la orden agrega el detalle de
+// entradas, descuentos, cargos e impuestos. Los importes se muestran con los decimales de su moneda
+func (s *emailService) SendPurchaseEmail(ctx context.Context, to string, name string, orderId string, amount money.Money, lines []models.OrderLine) error {
 	subject := fmt.Sprintf("✅ Confirmación de Compra #%s", orderId[:8])
 
-	body := fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="UTF-8"><style>body{font-family:Arial;background:#f4f4f4;margin:0;padding:20px}.card{max-width:600px;margin:0 auto;background:#fff;padding:40px;border-radius:8px}.header{background:#667eea;color:#fff;padding:30px;text-align:center;border-radius:8px 8px 0 0;margin:-40px -40px 30px}.amount{font-size:32px;font-weight:bold;color:#667eea;margin:20px 0}.divider{height:1px;background:#e5e7eb;margin:30px 0}</style></head><body><div class="card"><div class="header"><h1>¡Compra Confirmada!</h1></div><p>Hola %s 👋</p><p>Tu compra se procesó exitosamente.</p><div class="amount">$%.2f USD</div><p><strong>Orden:</strong> %s</p><div class="divider"></div><p style="color:#666;font-size:14px;text-align:center">Ingresa a tu cuenta de SeatGuards para ver y descargar tu comprobante de pago</p><p style="color:#999;margin-top:30px;font-size:13px">Gracias por tu compra. Si tienes preguntas, contáctanos en soporte@seatguards.com</p></div></body></html>`, name, amount/100, orderId)
+	body := fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="UTF-8"><style>body{font-family:Arial;background:#f4f4f4;margin:0;padding:20px}.card{max-width:600px;margin:0 auto;background:#fff;padding:40px;border-radius:8px}.header{background:#667eea;color:#fff;padding:30px;text-align:center;border-radius:8px 8px 0 0;margin:-40px -40px 30px}.amount{font-size:32px;font-weight:bold;color:#667eea;margin:20px 0}.divider{height:1px;background:#e5e7eb;margin:30px 0}</style></head><body><div class="card"><div class="header"><h1>¡Compra Confirmada!</h1></div><p>Hola %s 👋</p><p>Tu compra se procesó exitosamente.</p><div class="amount">%s</div>%s<p><strong>Orden:</strong> %s</p><div class="divider"></div><p style="color:#666;font-size:14px;text-align:center">Ingresa a tu cuenta de SeatGuards para ver y descargar tu comprobante de pago</p><p style="color:#999;margin-top:30px;font-size:13px">Gracias por tu compra. Si tienes preguntas, contáctanos en soporte@seatguards.com</p></div></body></html>`, name, amount, breakdownTable(lines), orderId)
+
+	email := &domain.Email{
+		To:      []string{to},
+		Subject: subject,
+		Body:    body,
+	}
+
+	return s.repo.SendEmail(ctx, email)
+}
+
+// breakdownTable arma la tabla con el detalle de la orden; vacía si no hay líneas
+func breakdownTable(lines []models.OrderLine) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(`<table style="width:100%;border-collapse:collapse;font-size:14px;margin:10px 0 20px">`)
+	for _, line := range models.Breakdown(lines) {
+		label := html.EscapeString(line.Description)
+		if line.Kind == models.LineTicket {
+			label = fmt.Sprintf("%s x%d", label, line.Quantity)
+		}
+		fmt.Fprintf(&b, `<tr><td style="padding:6px 0;color:#444">%s</td><td style="padding:6px 0;text-align:right;color:#444">%s</td></tr>`, label, money.New(line.Amount, line.Currency))
+	}
+	fmt.Fprintf(&b, `<tr><td style="padding:8px 0;border-top:1px solid #e5e7eb"><strong>Total</strong></td><td style="padding:8px 0;border-top:1px solid #e5e7eb;text-align:right"><strong>%s</strong></td></tr></table>`, money.New(models.TotalsOf(lines).Total, lines[0].Currency))
+	return b.String()
+}
+
+// SendRefundEmail avisa al cliente del reembolso (bloqueante)
+func (s *emailService) SendRefundEmail(ctx context.Context, to string, name string, orderId string, amount money.Money, seats int) error {
+	subject := fmt.Sprintf("💸 Reembolso de la Orden #%s", orderId[:8])
+
+	body := fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="UTF-8"><style>body{font-family:Arial;background:#f4f4f4;margin:0;padding:20px}.card{max-width:600px;margin:0 auto;background:#fff;padding:40px;border-radius:8px}.header{background:#667eea;color:#fff;padding:30px;text-align:center;border-radius:8px 8px 0 0;margin:-40px -40px 30px}.amount{font-size:32px;font-weight:bold;color:#667eea;margin:20px 0}.divider{height:1px;background:#e5e7eb;margin:30px 0}</style></head><body><div class="card"><div class="header"><h1>Reembolso Procesado</h1></div><p>Hola %s 👋</p><p>Procesamos el reembolso de %d asiento(s) de tu orden.</p><div class="amount">%s</div><p><strong>Orden:</strong> %s</p><div class="divider"></div><p style="color:#666;font-size:14px;text-align:center">El importe se verá reflejado en tu medio de pago en los próximos días hábiles</p><p style="color:#999;margin-top:30px;font-size:13px">Si tienes preguntas, contáctanos en soporte@seatguards.com</p></div></body></html>`, name, seats, amount, orderId)
 
 	email := &domain.Email{
 		To:      []string{to},
@@ -3,7 +3,10 @@ package services
 import (
 	"booking-service/internal/models"
 	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -19,9 +22,23 @@ func (s *EventService) CreateEvent(event *models.Event) error {
 	if event.Name == "" {
 		return errors.New("event name cannot be empty")
 	}
-	if event.Price < 0 {
+	if event.Price.IsNegative() {
 		return errors.New("event price cannot be negative")
 	}
+	currency, err := money.NormalizeCurrency(event.Price.Currency)
+	if err != nil {
+		return err
+	}
+	event.Price.Currency = currency
+	if event.WaitingRoomRate < 0 {
+		return utils.ErrInvalidWaitingRoom
+	}
+	if err := validateSalesWindow(event); err != nil {
+		return err
+	}
+	if err := validatePurchaseLimits(event); err != nil {
+		return err
+	}
 	if event.Date.Before(time.Now()) {
 		return errors.New("event date cannot be in the past")
 	}
@@ -59,7 +76,31 @@ func (s *EventService) UpdateEvent(id string, updatedData *models.Event) error {
 	existingEvent.Description = updatedData.Description
 	existingEvent.Location = updatedData.Location
 	existingEvent.Date = updatedData.Date
-	existingEvent.Price = updatedData.Price
+	existingEvent.PreventOrphanSeats = updatedData.PreventOrphanSeats
+	if updatedData.WaitingRoomRate < 0 {
+		return utils.ErrInvalidWaitingRoom
+	}
+	existingEvent.WaitingRoomRate = updatedData.WaitingRoomRate
+	if err := validateSalesWindow(updatedData); err != nil {
+		return err
+	}
+	existingEvent.SalesStart = updatedData.SalesStart
+	existingEvent.SalesEnd = updatedData.SalesEnd
+	existingEvent.PresaleStart = updatedData.PresaleStart
+	if err := validatePurchaseLimits(updatedData); err != nil {
+		return err
+	}
+	existingEvent.MaxTicketsPerUser = updatedData.MaxTicketsPerUser
+	existingEvent.MaxTicketsPerEmail = updatedData.MaxTicketsPerEmail
+	existingEvent.MaxTicketsPerCard = updatedData.MaxTicketsPerCard
+	if updatedData.Price.IsNegative() {
+		return errors.New("event price cannot be negative")
+	}
+	// La moneda queda fija al crear el evento: los asientos y órdenes ya la usan
+	if updatedData.Price.Currency != "" && !strings.EqualFold(updatedData.Price.Currency, existingEvent.Price.Currency) {
+		return utils.ErrEventCurrencyChange
+	}
+	existingEvent.Price.Amount = updatedData.Price.Amount
 
 	return s.repo.Update(existingEvent)
 }
@@ -2,6 +2,9 @@ package services
 
 import (
 	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
 	"testing"
 	"time"
 )
@@ -26,9 +29,10 @@ func TestEventService_CreateEvent_Validations(t *testing.T) {
 	svc := NewEventService(&mockEventRepo{})
 
 	cases := []models.Event{
-		{Name: "", Price: 10, Date: time.Now().Add(time.Hour)},
-		{Name: "x", Price: -1, Date: time.Now().Add(time.Hour)},
-		{Name: "x", Price: 1, Date: time.Now().Add(-time.Hour)},
+		{Name: "", Price: money.New(10, "ARS"), Date: time.Now().Add(time.Hour)},
+		{Name: "x", Price: money.New(-1, "ARS"), Date: time.Now().Add(time.Hour)},
+		{Name: "x", Price: money.New(1, "ARS"), Date: time.Now().Add(-time.Hour)},
+		{Name: "x", Price: money.New(1, "XYZ"), Date: time.Now().Add(time.Hour)},
 	}
 	for _, c := range cases {
 		if err := svc.CreateEvent(&c); err == nil {
@@ -49,17 +53,68 @@ func TestEventService_UpdateEvent_NotFoundAndSuccess(t *testing.T) {
 		updated := &models.Event{}
 		svc := NewEventService(&mockEventRepo{
 			findByIDFn: func(string) (*models.Event, error) {
-				return &models.Event{Name: "old", Price: 1}, nil
+				return &models.Event{Name: "old", Price: money.New(1, "ARS")}, nil
 			},
 			updateFn: func(e *models.Event) error { *updated = *e; return nil },
 		})
 
-		err := svc.UpdateEvent("e1", &models.Event{Name: "new", Description: "d", Location: "loc", Date: time.Now().Add(time.Hour), Price: 2})
+		err := svc.UpdateEvent("e1", &models.Event{Name: "new", Description: "d", Location: "loc", Date: time.Now().Add(time.Hour), Price: money.New(2, "")})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if updated.Name != "new" || updated.Location != "loc" || updated.Price != 2 {
+		if updated.Name != "new" || updated.Location != "loc" || updated.Price != money.New(2, "ARS") {
 			t.Fatalf("expected updated fields copied, got %+v", updated)
 		}
 	})
+
+	t.Run("currency is fixed", func(t *testing.T) {
+		svc := NewEventService(&mockEventRepo{
+			findByIDFn: func(string) (*models.Event, error) {
+				return &models.Event{Name: "old", Price: money.New(1, "ARS")}, nil
+			},
+		})
+		err := svc.UpdateEvent("e1", &models.Event{Name: "new", Price: money.New(2, "USD")})
+		if !errors.Is(err, utils.ErrEventCurrencyChange) {
+			t.Fatalf("expected currency change error, got %v", err)
+		}
+	})
+
+	t.Run("negative waiting room rate", func(t *testing.T) {
+		svc := NewEventService(&mockEventRepo{
+			findByIDFn: func(string) (*models.Event, error) {
+				return &models.Event{Name: "old", Price: money.New(1, "ARS")}, nil
+			},
+		})
+		err := svc.UpdateEvent("e1", &models.Event{Name: "new", WaitingRoomRate: -1})
+		if !errors.Is(err, utils.ErrInvalidWaitingRoom) {
+			t.Fatalf("expected invalid waiting room error, got %v", err)
+		}
+	})
+
+	t.Run("negative purchase limit", func(t *testing.T) {
+		svc := NewEventService(&mockEventRepo{
+			findByIDFn: func(string) (*models.Event, error) {
+				return &models.Event{Name: "old", Price: money.New(1, "ARS")}, nil
+			},
+		})
+		err := svc.UpdateEvent("e1", &models.Event{Name: "new", MaxTicketsPerUser: -2})
+		if !errors.Is(err, utils.ErrInvalidPurchaseLimit) {
+			t.Fatalf("expected invalid purchase limit error, got %v", err)
+		}
+	})
+}
+
+func TestEventService_CreateEvent_DefaultsCurrency(t *testing.T) {
+	var created *models.Event
+	svc := NewEventService(&mockEventRepo{createFn: func(e *models.Event) error { created = e; return nil }})
+
+	if err := svc.CreateEvent(&models.Event{Name: "x", Price: money.New(150000, "ars"), Date: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Price.Currency != "ARS" {
+		t.Fatalf("expected normalized currency, got %q", created.Price.Currency)
+	}
+	if err := svc.CreateEvent(&models.Event{Name: "y", Date: time.Now().Add(time.Hour)}); err != nil || created.Price.Currency != money.DefaultCurrency {
+		t.Fatalf("expected default currency, got %q (%v)", created.Price.Currency, err)
+	}
 }
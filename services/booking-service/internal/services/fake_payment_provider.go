@@ -0,0 +1,93 @@
+package services
+
+import (
+	"booking-service/internal/messaging"
+	"booking-service/pkg/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const FakeProviderName = "FAKE"
+
+// FakePaymentProvider es una pasarela en memoria para tests y desarrollo local.
+// Los webhooks son el BookingMessage en JSON, sin firma
+type FakePaymentProvider struct {
+	mu sync.Mutex
+
+	Sessions []CheckoutSessionRequest
+	Expired  []string
+	Refunds  []RefundRequest
+	Payments map[string]*PaymentDetails
+
+	// Err, si no es nil, hace fallar todas las llamadas a la pasarela
+	Err error
+}
+
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{Payments: make(map[string]*PaymentDetails)}
+}
+
+func (p *FakePaymentProvider) Name() string { return FakeProviderName }
+
+func (p *FakePaymentProvider) CreateSession(_ context.Context, req CheckoutSessionRequest) (*CheckoutSession, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	p.Sessions = append(p.Sessions, req)
+
+	id := "fake_cs_" + uuid.NewString()
+	return &CheckoutSession{ID: id, URL: "https://fake.pay/" + id, ExpiresAt: req.ExpiresAt}, nil
+}
+
+func (p *FakePaymentProvider) ExpireSession(_ context.Context, sessionID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return p.Err
+	}
+	p.Expired = append(p.Expired, sessionID)
+	return nil
+}
+
+func (p *FakePaymentProvider) RetrievePayment(_ context.Context, paymentID string) (*PaymentDetails, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	payment, ok := p.Payments[paymentID]
+	if !ok {
+		return nil, fmt.Errorf("payment %s not found", paymentID)
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+func (p *FakePaymentProvider) Refund(_ context.Context, req RefundRequest) (*RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	p.Refunds = append(p.Refunds, req)
+	return &RefundResult{ID: fmt.Sprintf("fake_re_%d", len(p.Refunds)), Status: "succeeded"}, nil
+}
+
+func (p *FakePaymentProvider) ParseWebhook(_ context.Context, req WebhookRequest) (*messaging.BookingMessage, error) {
+	var msg messaging.BookingMessage
+	if err := json.Unmarshal(req.Payload, &msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidWebhookPayload, err)
+	}
+	msg.Provider = FakeProviderName
+	return &msg, nil
+}
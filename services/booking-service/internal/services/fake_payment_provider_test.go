@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakePaymentProvider(t *testing.T) {
+	var provider PaymentProvider = NewFakePaymentProvider()
+	fake := provider.(*FakePaymentProvider)
+
+	session, err := provider.CreateSession(context.Background(), CheckoutSessionRequest{OrderID: "o1"})
+	if err != nil || session.URL == "" || len(fake.Sessions) != 1 {
+		t.Fatalf("expected session recorded, got %+v (%v)", session, err)
+	}
+
+	fake.Payments["pi_1"] = &PaymentDetails{ID: "pi_1", Status: "succeeded", Customer: PaymentCustomer{Email: "a@a.com"}}
+	payment, err := provider.RetrievePayment(context.Background(), "pi_1")
+	if err != nil || payment.Customer.Email != "a@a.com" {
+		t.Fatalf("unexpected payment %+v (%v)", payment, err)
+	}
+
+	if _, err := provider.Refund(context.Background(), RefundRequest{PaymentID: "pi_1", Amount: 100}); err != nil || len(fake.Refunds) != 1 {
+		t.Fatalf("expected refund recorded, got %v", err)
+	}
+
+	msg, err := provider.ParseWebhook(context.Background(), WebhookRequest{Payload: []byte(`{"orderId":"o1","status":"paid"}`)})
+	if err != nil || msg.Provider != FakeProviderName || msg.OrderID != "o1" {
+		t.Fatalf("unexpected message %+v (%v)", msg, err)
+	}
+
+	fake.Err = errors.New("provider down")
+	if _, err := provider.CreateSession(context.Background(), CheckoutSessionRequest{}); err == nil {
+		t.Fatalf("expected forced error")
+	}
+}
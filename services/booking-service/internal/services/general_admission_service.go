@@ -0,0 +1,104 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/utils"
+	"fmt"
+	"strings"
+)
+
+type GeneralAdmissionService struct {
+	repo       repositories.GeneralAdmissionRepository
+	repoEvents repositories.EventRepository
+}
+
+func NewGeneralAdmissionService(repo repositories.GeneralAdmissionRepository, repoEvents repositories.EventRepository) *GeneralAdmissionService {
+	return &GeneralAdmissionService{repo: repo, repoEvents: repoEvents}
+}
+
+func (s *GeneralAdmissionService) CreateSection(eventID string, section *models.GASection) error {
+	event, err := s.repoEvents.FindByID(eventID)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return utils.ErrEventNotFound
+	}
+
+	section.Name = strings.TrimSpace(section.Name)
+	if section.Name == "" {
+		return fmt.Errorf("%w: name cannot be empty", utils.ErrInvalidGASection)
+	}
+	if section.Capacity <= 0 {
+		return fmt.Errorf("%w: capacity must be positive", utils.ErrInvalidGASection)
+	}
+	if section.Price.IsNegative() {
+		return fmt.Errorf("%w: price cannot be negative", utils.ErrInvalidGASection)
+	}
+	price, err := tierPrice(event, section.Price)
+	if err != nil {
+		return err
+	}
+
+	sections, err := s.repo.FindSectionsByEventID(eventID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range sections {
+		if strings.EqualFold(existing.Name, section.Name) {
+			return utils.ErrGASectionNameTaken
+		}
+	}
+
+	section.EventID = eventID
+	section.Price = price
+	section.Held = 0
+	section.Sold = 0
+	if err := s.repo.CreateSection(section); err != nil {
+		return err
+	}
+	return s.repoEvents.UpdateAvailability(eventID)
+}
+
+func (s *GeneralAdmissionService) GetSections(eventID string) ([]models.GASection, error) {
+	event, err := s.repoEvents.FindByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, utils.ErrEventNotFound
+	}
+	return s.repo.FindSectionsByEventID(eventID)
+}
+
+func (s *GeneralAdmissionService) GetSection(eventID, sectionID string) (*models.GASection, error) {
+	section, err := s.repo.FindSectionByID(sectionID)
+	if err != nil {
+		return nil, err
+	}
+	if section == nil || section.EventID != eventID {
+		return nil, utils.ErrGASectionNotFound
+	}
+	return section, nil
+}
+
+// DeleteSection solo borra sectores sin entradas bloqueadas ni vendidas
+func (s *GeneralAdmissionService) DeleteSection(eventID, sectionID string) error {
+	if _, err := s.GetSection(eventID, sectionID); err != nil {
+		return err
+	}
+	return s.repo.DeleteSection(sectionID)
+}
+
+// FindSection devuelve un sector por ID sin importar el evento (checkout)
+func (s *GeneralAdmissionService) FindSection(sectionID string) (*models.GASection, error) {
+	section, err := s.repo.FindSectionByID(sectionID)
+	if err != nil {
+		return nil, err
+	}
+	if section == nil {
+		return nil, utils.ErrGASectionNotFound
+	}
+	return section, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"booking-service/internal/config"
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockGARepo struct {
+	createSectionFn   func(*models.GASection) error
+	findSectionFn     func(string) (*models.GASection, error)
+	findSectionsFn    func(string) ([]models.GASection, error)
+	deleteSectionFn   func(string) error
+	holdFn            func(string, string, []models.GAItem, time.Time) ([]models.GAHold, error)
+	findActiveHoldsFn func(string) ([]models.GAHold, error)
+	extendHoldFn      func(string, string, time.Time, int, time.Time) (int64, error)
+	releaseHoldFn     func(string, string) (int64, error)
+	findExpiredFn     func(time.Time, int) ([]models.GAHold, error)
+	releaseExpiredFn  func([]string, time.Time) ([]models.GAHold, error)
+	countActiveFn     func(string, string, time.Time) (int64, error)
+}
+
+func (m *mockGARepo) CreateSection(section *models.GASection) error {
+	return m.createSectionFn(section)
+}
+func (m *mockGARepo) FindSectionByID(id string) (*models.GASection, error) {
+	return m.findSectionFn(id)
+}
+func (m *mockGARepo) FindSectionsByEventID(eventID string) ([]models.GASection, error) {
+	return m.findSectionsFn(eventID)
+}
+func (m *mockGARepo) DeleteSection(id string) error { return m.deleteSectionFn(id) }
+func (m *mockGARepo) Hold(holdID, userID string, items []models.GAItem, expiresAt time.Time) ([]models.GAHold, error) {
+	return m.holdFn(holdID, userID, items, expiresAt)
+}
+func (m *mockGARepo) FindActiveHolds(holdID string) ([]models.GAHold, error) {
+	return m.findActiveHoldsFn(holdID)
+}
+func (m *mockGARepo) ExtendHold(holdID, userID string, expiresAt time.Time, max int, now time.Time) (int64, error) {
+	return m.extendHoldFn(holdID, userID, expiresAt, max, now)
+}
+func (m *mockGARepo) ReleaseHold(holdID, userID string) (int64, error) {
+	return m.releaseHoldFn(holdID, userID)
+}
+func (m *mockGARepo) FindExpiredHolds(now time.Time, limit int) ([]models.GAHold, error) {
+	return m.findExpiredFn(now, limit)
+}
+func (m *mockGARepo) ReleaseExpiredHolds(ids []string, now time.Time) ([]models.GAHold, error) {
+	return m.releaseExpiredFn(ids, now)
+}
+func (m *mockGARepo) CountActiveByUser(eventID, userID string, now time.Time) (int64, error) {
+	return m.countActiveFn(eventID, userID, now)
+}
+
+func campoSection(id string) (*models.GASection, error) {
+	if id != "ga1" {
+		return nil, nil
+	}
+	return &models.GASection{BaseModel: models.BaseModel{ID: "ga1"}, EventID: "e1", Name: "CAMPO", Price: money.New(1300000, "ARS"), Capacity: 100}, nil
+}
+
+func TestGeneralAdmissionService_CreateSection(t *testing.T) {
+	var created *models.GASection
+	repo := &mockGARepo{
+		createSectionFn: func(s *models.GASection) error { created = s; return nil },
+		findSectionsFn: func(string) ([]models.GASection, error) {
+			return []models.GASection{{BaseModel: models.BaseModel{ID: "ga0"}, Name: "CAMPO VIP"}}, nil
+		},
+	}
+	events := &mockEventRepo{
+		findByIDFn:           eventsWithARS().findByIDFn,
+		updateAvailabilityFn: func(string) error { return nil },
+	}
+	svc := NewGeneralAdmissionService(repo, events)
+
+	if err := svc.CreateSection("e1", &models.GASection{Name: " Campo ", Price: money.New(1300000, ""), Capacity: 500, Sold: 10}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.EventID != "e1" || created.Name != "Campo" || created.Price != money.New(1300000, "ARS") || created.Sold != 0 {
+		t.Fatalf("unexpected section: %+v", created)
+	}
+
+	cases := []struct {
+		name    string
+		eventID string
+		section models.GASection
+		want    error
+	}{
+		{"event not found", "e2", models.GASection{Name: "Campo", Capacity: 1}, utils.ErrEventNotFound},
+		{"empty name", "e1", models.GASection{Capacity: 1}, utils.ErrInvalidGASection},
+		{"no capacity", "e1", models.GASection{Name: "Campo"}, utils.ErrInvalidGASection},
+		{"negative price", "e1", models.GASection{Name: "Campo", Capacity: 1, Price: money.New(-1, "ARS")}, utils.ErrInvalidGASection},
+		{"other currency", "e1", models.GASection{Name: "Campo", Capacity: 1, Price: money.New(100, "USD")}, utils.ErrCurrencyMismatch},
+		{"duplicated name", "e1", models.GASection{Name: "campo vip", Capacity: 1}, utils.ErrGASectionNameTaken},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := svc.CreateSection(tc.eventID, &tc.section); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestGeneralAdmissionService_GetSection_ChecksEvent(t *testing.T) {
+	svc := NewGeneralAdmissionService(&mockGARepo{findSectionFn: campoSection}, &mockEventRepo{})
+
+	if _, err := svc.GetSection("e1", "ga1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.GetSection("e2", "ga1"); !errors.Is(err, utils.ErrGASectionNotFound) {
+		t.Fatalf("expected section not found for other event, got %v", err)
+	}
+	if err := svc.DeleteSection("e1", "ga9"); !errors.Is(err, utils.ErrGASectionNotFound) {
+		t.Fatalf("expected section not found, got %v", err)
+	}
+}
+
+func TestSeatService_HoldCart_GeneralAdmissionOnly(t *testing.T) {
+	var held []models.GAItem
+	repoGA := &mockGARepo{
+		findSectionFn: campoSection,
+		countActiveFn: func(string, string, time.Time) (int64, error) { return 1, nil },
+		holdFn: func(holdID, userID string, items []models.GAItem, expiresAt time.Time) ([]models.GAHold, error) {
+			if holdID == "" || userID != "u1" || expiresAt.IsZero() {
+				t.Fatalf("unexpected hold args: %s %s %v", holdID, userID, expiresAt)
+			}
+			held = items
+			return nil, nil
+		},
+	}
+	seats := &mockSeatRepo{countLocksFn: func(string, string, time.Time) (int64, error) { return 0, nil }}
+	svc := NewSeatService(seats, &mockEventRepoForSeat{}, nil, repoGA, nil, nil, nil, config.HoldPolicy{MaxSeatsPerUser: 4})
+
+	hold, err := svc.HoldCart(nil, []models.GAItem{{SectionID: "ga1", Quantity: 2}, {SectionID: "ga1", Quantity: 1}}, "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(held) != 1 || held[0].Quantity != 3 || len(hold.GAItems) != 1 || hold.GAItems[0].Section != "CAMPO" {
+		t.Fatalf("unexpected hold: %+v held=%+v", hold, held)
+	}
+
+	// 1 entrada ya bloqueada + 4 pedidas supera el máximo de 4
+	if _, err := svc.HoldCart(nil, []models.GAItem{{SectionID: "ga1", Quantity: 4}}, "u1"); !errors.Is(err, utils.ErrHoldLimitExceeded) {
+		t.Fatalf("expected hold limit exceeded, got %v", err)
+	}
+	if _, err := svc.HoldCart(nil, []models.GAItem{{SectionID: "ga9", Quantity: 1}}, "u1"); !errors.Is(err, utils.ErrGASectionNotFound) {
+		t.Fatalf("expected section not found, got %v", err)
+	}
+	if _, err := svc.HoldCart(nil, []models.GAItem{{SectionID: "ga1", Quantity: 0}}, "u1"); !errors.Is(err, utils.ErrInvalidSeatRequest) {
+		t.Fatalf("expected invalid request, got %v", err)
+	}
+}
+
+func TestSeatService_HoldCart_ReleasesSeatsWhenSoldOut(t *testing.T) {
+	var releasedHold string
+	seats := &mockSeatRepo{
+		findByIDsFn: func(ids []string) ([]models.Seat, error) {
+			return []models.Seat{{BaseModel: models.BaseModel{ID: "s1"}, EventID: "e1", Section: "Platea"}}, nil
+		},
+		lockSeatsFn: func([]string, string, string, time.Time) error { return nil },
+		releaseHoldFn: func(holdID, userID string) (int64, error) {
+			releasedHold = holdID
+			return 1, nil
+		},
+	}
+	repoGA := &mockGARepo{
+		findSectionFn: campoSection,
+		holdFn: func(string, string, []models.GAItem, time.Time) ([]models.GAHold, error) {
+			return nil, &utils.GASoldOutError{SectionIDs: []string{"ga1"}}
+		},
+	}
+	svc := NewSeatService(seats, &mockEventRepoForSeat{}, nil, repoGA, nil, nil, nil, config.HoldPolicy{})
+
+	_, err := svc.HoldCart([]string{"s1"}, []models.GAItem{{SectionID: "ga1", Quantity: 2}}, "u1")
+	var soldOut *utils.GASoldOutError
+	if !errors.As(err, &soldOut) || soldOut.SectionIDs[0] != "ga1" {
+		t.Fatalf("expected sold out error, got %v", err)
+	}
+	if releasedHold == "" {
+		t.Fatalf("expected locked seats to be released")
+	}
+}
+
+func TestSeatService_ReleaseHold_GeneralAdmission(t *testing.T) {
+	released := false
+	seats := &mockSeatRepo{findByHoldIDFn: func(string) ([]models.Seat, error) { return nil, nil }}
+	repoGA := &mockGARepo{
+		findActiveHoldsFn: func(string) ([]models.GAHold, error) {
+			return []models.GAHold{{HoldID: "h1", SectionID: "ga1", UserID: "u1", Quantity: 2, ExpiresAt: time.Now().Add(time.Minute)}}, nil
+		},
+		releaseHoldFn: func(string, string) (int64, error) { released = true; return 2, nil },
+	}
+	svc := NewSeatService(seats, &mockEventRepoForSeat{}, nil, repoGA, nil, nil, nil, config.HoldPolicy{})
+
+	if err := svc.ReleaseHold("h1", "u2"); !errors.Is(err, utils.ErrHoldForbidden) {
+		t.Fatalf("expected forbidden, got %v", err)
+	}
+	if err := svc.ReleaseHold("h1", "u1"); err != nil || !released {
+		t.Fatalf("expected general admission hold released, err=%v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// LockReaper libera periódicamente los asientos y entradas de admisión general con bloqueo
+// vencido y recalcula la disponibilidad del evento. Como en SeatService.ReleaseHold, primero
+// cancela las órdenes PENDING asociadas (cerrando su sesión de pago) y recién después libera:
+// así ningún asiento vuelve a estar disponible mientras su orden anterior sigue pagable
+type LockReaper struct {
+	seatRepo  repositories.SeatRepository
+	orderRepo repositories.BookingOrderRepository
+	eventRepo repositories.EventRepository
+	gaRepo    repositories.GeneralAdmissionRepository // Opcional
+	sessions  SessionExpirer                          // Opcional
+
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+func NewLockReaper(
+	seatRepo repositories.SeatRepository,
+	orderRepo repositories.BookingOrderRepository,
+	eventRepo repositories.EventRepository,
+	gaRepo repositories.GeneralAdmissionRepository,
+	sessions SessionExpirer,
+	interval time.Duration,
+) *LockReaper {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &LockReaper{
+		seatRepo:  seatRepo,
+		orderRepo: orderRepo,
+		eventRepo: eventRepo,
+		gaRepo:    gaRepo,
+		sessions:  sessions,
+		interval:  interval,
+		batchSize: 500,
+		now:       time.Now,
+	}
+}
+
+// Start lanza el worker en segundo plano. El canal devuelto se cierra cuando el worker
+// terminó luego de cancelar el contexto
+func (r *LockReaper) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			r.safeRun()
+
+			select {
+			case <-ctx.Done():
+				log.Println("🛑 Lock reaper detenido")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return done
+}
+
+// safeRun evita que un panic en una iteración mate al worker
+func (r *LockReaper) safeRun() {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Printf("⚠️ Lock reaper panic: %v", rec)
+		}
+	}()
+
+	released, err := r.RunOnce()
+	if err != nil {
+		log.Printf("⚠️ Lock reaper error: %v", err)
+		return
+	}
+	if released > 0 {
+		log.Printf("🔓 Lock reaper liberó %d asientos/entradas", released)
+	}
+}
+
+// RunOnce ejecuta una pasada completa y devuelve la cantidad de asientos y entradas de
+// admisión general liberados
+func (r *LockReaper) RunOnce() (int, error) {
+	total, err := r.releaseSeats()
+	if err != nil {
+		return total, err
+	}
+
+	tickets, err := r.releaseGAHolds()
+	return total + tickets, err
+}
+
+func (r *LockReaper) releaseSeats() (int, error) {
+	total := 0
+
+	for {
+		now := r.now()
+		expired, err := r.seatRepo.FindExpiredLocks(now, r.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(expired) == 0 {
+			return total, nil
+		}
+
+		if err := r.cancelOrders(expired); err != nil {
+			return total, err
+		}
+
+		ids := make([]string, 0, len(expired))
+		for _, s := range expired {
+			ids = append(ids, s.ID)
+		}
+		seats, err := r.seatRepo.ReleaseExpiredLocks(ids, now)
+		if err != nil {
+			return total, err
+		}
+		total += len(seats)
+
+		eventIDs := make(map[string]struct{})
+		for _, s := range seats {
+			eventIDs[s.EventID] = struct{}{}
+		}
+		for eventID := range eventIDs {
+			if err := r.eventRepo.UpdateAvailability(eventID); err != nil {
+				log.Printf("⚠️ Lock reaper: no se pudo actualizar disponibilidad del evento %s: %v", eventID, err)
+			}
+		}
+
+		if r.batchSize <= 0 || len(expired) < r.batchSize {
+			return total, nil
+		}
+	}
+}
+
+// cancelOrders cancela las órdenes PENDING que contienen alguno de los asientos vencidos
+func (r *LockReaper) cancelOrders(seats []models.Seat) error {
+	expiredSeats := make(map[string]struct{}, len(seats))
+	userSet := make(map[string]struct{})
+	for _, s := range seats {
+		expiredSeats[s.ID] = struct{}{}
+		if s.LockedBy != nil && *s.LockedBy != "" {
+			userSet[*s.LockedBy] = struct{}{}
+		}
+	}
+
+	if len(userSet) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(userSet))
+	for id := range userSet {
+		userIDs = append(userIDs, id)
+	}
+
+	orders, err := r.orderRepo.FindPendingByUserIDs(userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to find pending orders: %w", err)
+	}
+
+	var toCancel []string
+	for _, o := range orders {
+		for _, seatID := range o.SeatIDs {
+			if _, ok := expiredSeats[seatID]; ok {
+				toCancel = append(toCancel, o.ID)
+				break
+			}
+		}
+	}
+
+	cancelled, err := r.orderRepo.CancelPending(toCancel, "lock-reaper", "seat hold expired")
+	if err != nil {
+		return fmt.Errorf("failed to cancel pending orders: %w", err)
+	}
+	expireSessions(r.sessions, cancelled)
+	return nil
+}
+
+// releaseGAHolds cancela las órdenes PENDING de los holds de admisión general vencidos y luego
+// libera esos holds (el repositorio ya recalcula la disponibilidad)
+func (r *LockReaper) releaseGAHolds() (int, error) {
+	if r.gaRepo == nil {
+		return 0, nil
+	}
+
+	total := 0
+	for {
+		now := r.now()
+		holds, err := r.gaRepo.FindExpiredHolds(now, r.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(holds) == 0 {
+			return total, nil
+		}
+
+		ids := make([]string, 0, len(holds))
+		holdIDs := make(map[string]struct{}, len(holds))
+		userSet := make(map[string]struct{})
+		for _, h := range holds {
+			ids = append(ids, h.ID)
+			holdIDs[h.HoldID] = struct{}{}
+			userSet[h.UserID] = struct{}{}
+		}
+
+		userIDs := make([]string, 0, len(userSet))
+		for id := range userSet {
+			userIDs = append(userIDs, id)
+		}
+		orders, err := r.orderRepo.FindPendingByUserIDs(userIDs)
+		if err != nil {
+			return total, fmt.Errorf("failed to find pending orders: %w", err)
+		}
+
+		var toCancel []string
+		for _, o := range orders {
+			if o.HoldID == nil {
+				continue
+			}
+			if _, ok := holdIDs[*o.HoldID]; ok {
+				toCancel = append(toCancel, o.ID)
+			}
+		}
+		cancelled, err := r.orderRepo.CancelPending(toCancel, "lock-reaper", "general admission hold expired")
+		if err != nil {
+			return total, fmt.Errorf("failed to cancel pending orders: %w", err)
+		}
+		expireSessions(r.sessions, cancelled)
+
+		released, err := r.gaRepo.ReleaseExpiredHolds(ids, now)
+		if err != nil {
+			return total, err
+		}
+		for _, h := range released {
+			total += h.Quantity
+		}
+
+		if r.batchSize <= 0 || len(holds) < r.batchSize {
+			return total, nil
+		}
+	}
+}
+
+// expireSessions cierra en la pasarela la sesión de pago de las órdenes canceladas. Stripe no
+// deja crear sesiones de menos de 30 minutos: sin esto la orden seguiría pagable después de
+// liberar sus asientos. Si el cierre falla, el pago que llegue igual se reembolsa
+func expireSessions(sessions SessionExpirer, orders []models.BookingOrder) {
+	if sessions == nil {
+		return
+	}
+	for _, o := range orders {
+		if o.PaymentSessionID == "" {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := sessions.ExpireSession(ctx, o.PaymentProvider, o.PaymentSessionID); err != nil {
+			log.Printf("⚠️ No se pudo cerrar la sesión de pago %s de la orden %s: %v", o.PaymentSessionID, o.ID, err)
+		}
+		cancel()
+	}
+}
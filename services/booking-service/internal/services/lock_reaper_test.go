@@ -0,0 +1,216 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLockReaper_RunOnce_ReleasesSeatsAndCancelsOrders(t *testing.T) {
+	u1 := "u1"
+	var cancelled, released []string
+	availabilityCalls := map[string]int{}
+	expired := []models.Seat{
+		{BaseModel: models.BaseModel{ID: "s1"}, EventID: "e1", LockedBy: &u1},
+		{BaseModel: models.BaseModel{ID: "s2"}, EventID: "e1", LockedBy: &u1},
+	}
+
+	reaper := NewLockReaper(
+		&mockSeatRepo{
+			findExpiredFn: func(time.Time, int) ([]models.Seat, error) { return expired, nil },
+			releaseExpiredFn: func(ids []string, _ time.Time) ([]models.Seat, error) {
+				// Como ReleaseHold: la orden ya tiene que estar cancelada al liberar
+				if len(cancelled) == 0 {
+					t.Fatalf("seats released before cancelling their orders")
+				}
+				released = ids
+				return expired, nil
+			},
+		},
+		&mockBookingOrderRepo{
+			findPendingByUserIDsFn: func(ids []string) ([]models.BookingOrder, error) {
+				if len(ids) != 1 || ids[0] != "u1" {
+					t.Fatalf("unexpected user ids: %v", ids)
+				}
+				return []models.BookingOrder{
+					{BaseModel: models.BaseModel{ID: "o1"}, SeatIDs: []string{"s1", "s2"}},
+					{BaseModel: models.BaseModel{ID: "o2"}, SeatIDs: []string{"s9"}},
+				}, nil
+			},
+			cancelPendingFn: func(ids []string, changedBy, reason string) ([]models.BookingOrder, error) {
+				cancelled = ids
+				return ordersWithIDs(ids), nil
+			},
+		},
+		&mockEventRepo{updateAvailabilityFn: func(id string) error {
+			availabilityCalls[id]++
+			return nil
+		}},
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	total, err := reaper.RunOnce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 2 || len(released) != 2 {
+		t.Fatalf("expected 2 released seats, got %d (%v)", total, released)
+	}
+	if len(cancelled) != 1 || cancelled[0] != "o1" {
+		t.Fatalf("expected only o1 cancelled, got %v", cancelled)
+	}
+	if availabilityCalls["e1"] != 1 {
+		t.Fatalf("expected availability recomputed once for e1, got %v", availabilityCalls)
+	}
+}
+
+func TestLockReaper_RunOnce_NothingToRelease(t *testing.T) {
+	reaper := NewLockReaper(
+		&mockSeatRepo{findExpiredFn: func(time.Time, int) ([]models.Seat, error) { return nil, nil }},
+		&mockBookingOrderRepo{},
+		&mockEventRepo{},
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	released, err := reaper.RunOnce()
+	if err != nil || released != 0 {
+		t.Fatalf("expected no-op, got released=%d err=%v", released, err)
+	}
+}
+
+func TestLockReaper_Start_StopsOnContextCancel(t *testing.T) {
+	calls := 0
+	reaper := NewLockReaper(
+		&mockSeatRepo{findExpiredFn: func(time.Time, int) ([]models.Seat, error) {
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+			return nil, errors.New("db down")
+		}},
+		&mockBookingOrderRepo{},
+		&mockEventRepo{},
+		nil,
+		nil,
+		5*time.Millisecond,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := reaper.Start(ctx)
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected reaper to stop after context cancel")
+	}
+	if calls < 2 {
+		t.Fatalf("expected reaper to survive panic and keep running, got %d calls", calls)
+	}
+}
+
+func TestLockReaper_RunOnce_ReleasesGeneralAdmissionHolds(t *testing.T) {
+	holdID := "h1"
+	var cancelled []string
+	expired := []models.GAHold{{BaseModel: models.BaseModel{ID: "gh1"}, HoldID: "h1", UserID: "u1", EventID: "e1", Quantity: 3}}
+
+	reaper := NewLockReaper(
+		&mockSeatRepo{findExpiredFn: func(time.Time, int) ([]models.Seat, error) { return nil, nil }},
+		&mockBookingOrderRepo{
+			findPendingByUserIDsFn: func(ids []string) ([]models.BookingOrder, error) {
+				other := "h2"
+				return []models.BookingOrder{
+					{BaseModel: models.BaseModel{ID: "o1"}, HoldID: &holdID},
+					{BaseModel: models.BaseModel{ID: "o2"}, HoldID: &other},
+				}, nil
+			},
+			cancelPendingFn: func(ids []string, changedBy, reason string) ([]models.BookingOrder, error) {
+				cancelled = ids
+				return ordersWithIDs(ids), nil
+			},
+		},
+		&mockEventRepo{},
+		&mockGARepo{
+			findExpiredFn: func(time.Time, int) ([]models.GAHold, error) { return expired, nil },
+			releaseExpiredFn: func(ids []string, _ time.Time) ([]models.GAHold, error) {
+				if len(cancelled) == 0 {
+					t.Fatalf("holds released before cancelling their orders")
+				}
+				if len(ids) != 1 || ids[0] != "gh1" {
+					t.Fatalf("unexpected hold ids: %v", ids)
+				}
+				return expired, nil
+			},
+		},
+		nil,
+		time.Minute,
+	)
+
+	released, err := reaper.RunOnce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if released != 3 {
+		t.Fatalf("expected 3 released tickets, got %d", released)
+	}
+	if len(cancelled) != 1 || cancelled[0] != "o1" {
+		t.Fatalf("expected only o1 cancelled, got %v", cancelled)
+	}
+}
+
+func TestLockReaper_RunOnce_ExpiresPaymentSessions(t *testing.T) {
+	u1 := "u1"
+	fake := NewFakePaymentProvider()
+
+	reaper := NewLockReaper(
+		&mockSeatRepo{
+			findExpiredFn: func(time.Time, int) ([]models.Seat, error) {
+				return []models.Seat{{BaseModel: models.BaseModel{ID: "s1"}, EventID: "e1", LockedBy: &u1}}, nil
+			},
+			releaseExpiredFn: func([]string, time.Time) ([]models.Seat, error) {
+				if len(fake.Expired) == 0 {
+					t.Fatalf("seats released before expiring the payment session")
+				}
+				return nil, nil
+			},
+		},
+		&mockBookingOrderRepo{
+			findPendingByUserIDsFn: func([]string) ([]models.BookingOrder, error) {
+				return []models.BookingOrder{
+					{BaseModel: models.BaseModel{ID: "o1"}, SeatIDs: []string{"s1"}},
+					{BaseModel: models.BaseModel{ID: "o2"}, SeatIDs: []string{"s1"}},
+				}, nil
+			},
+			// o2 se pagó mientras tanto: solo o1 queda cancelada
+			cancelPendingFn: func([]string, string, string) ([]models.BookingOrder, error) {
+				return []models.BookingOrder{{BaseModel: models.BaseModel{ID: "o1"}, PaymentProvider: FakeProviderName, PaymentSessionID: "cs_1"}}, nil
+			},
+		},
+		&mockEventRepo{updateAvailabilityFn: func(string) error { return nil }},
+		nil,
+		NewPaymentProviders(fake),
+		time.Minute,
+	)
+
+	if _, err := reaper.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.Expired) != 1 || fake.Expired[0] != "cs_1" {
+		t.Fatalf("expected the session of o1 expired, got %v", fake.Expired)
+	}
+}
+
+func ordersWithIDs(ids []string) []models.BookingOrder {
+	orders := make([]models.BookingOrder, 0, len(ids))
+	for _, id := range ids {
+		orders = append(orders, models.BookingOrder{BaseModel: models.BaseModel{ID: id}})
+	}
+	return orders
+}
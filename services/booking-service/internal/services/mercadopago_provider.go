@@ -0,0 +1,465 @@
+package services
+
+import (
+	"booking-service/internal/messaging"
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const MercadoPagoProviderName = "MERCADOPAGO"
+
+const mercadoPagoDefaultBaseURL = "https://api.mercadopago.com"
+
+// Formato de fechas que acepta la API de preferencias (ISO 8601 con milisegundos)
+const mercadoPagoTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// MercadoPagoConfig es la configuración de la pasarela MercadoPago (Checkout Pro)
+type MercadoPagoConfig struct {
+	AccessToken   string
+	WebhookSecret string
+	// BaseURL permite apuntar a un stand-in de la API en tests
+	BaseURL string
+	// NotificationURL es donde MercadoPago envía las notificaciones de pago (/sqs/messaging/mercadopago)
+	NotificationURL  string
+	WebhookTolerance time.Duration
+	HTTPClient       *http.Client
+}
+
+// MercadoPagoProvider implementa PaymentProvider sobre la API REST de MercadoPago
+type MercadoPagoProvider struct {
+	cfg    MercadoPagoConfig
+	client *http.Client
+}
+
+func NewMercadoPagoProvider(cfg MercadoPagoConfig) *MercadoPagoProvider {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = mercadoPagoDefaultBaseURL
+	}
+	if cfg.WebhookTolerance <= 0 {
+		cfg.WebhookTolerance = 5 * time.Minute
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &MercadoPagoProvider{cfg: cfg, client: client}
+}
+
+func (p *MercadoPagoProvider) Name() string { return MercadoPagoProviderName }
+
+type mpPreferenceItem struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Quantity   int64   `json:"quantity"`
+	UnitPrice  float64 `json:"unit_price"`
+	CurrencyID string  `json:"currency_id"`
+}
+
+type mpBackURLs struct {
+	Success string `json:"success"`
+	Failure string `json:"failure"`
+	Pending string `json:"pending"`
+}
+
+type mpPreferenceRequest struct {
+	Items              []mpPreferenceItem `json:"items"`
+	ExternalReference  string             `json:"external_reference"`
+	Metadata           map[string]string  `json:"metadata"`
+	BackURLs           mpBackURLs         `json:"back_urls"`
+	AutoReturn         string             `json:"auto_return,omitempty"`
+	NotificationURL    string             `json:"notification_url,omitempty"`
+	Expires            bool               `json:"expires"`
+	ExpirationDateFrom string             `json:"expiration_date_from,omitempty"`
+	ExpirationDateTo   string             `json:"expiration_date_to,omitempty"`
+}
+
+type mpPreferenceResponse struct {
+	ID               string `json:"id"`
+	InitPoint        string `json:"init_point"`
+	SandboxInitPoint string `json:"sandbox_init_point"`
+}
+
+type mpPayment struct {
+	ID                int64          `json:"id"`
+	Status            string         `json:"status"`
+	StatusDetail      string         `json:"status_detail"`
+	TransactionAmount float64        `json:"transaction_amount"`
+	CurrencyID        string         `json:"currency_id"`
+	ExternalReference string         `json:"external_reference"`
+	Metadata          map[string]any `json:"metadata"`
+	Payer             mpPayer        `json:"payer"`
+}
+
+// amount convierte transaction_amount (en unidades) a unidades menores
+func (p *mpPayment) amount() money.Money {
+	m, err := money.FromMajor(p.TransactionAmount, p.CurrencyID)
+	if err != nil {
+		return money.New(0, p.CurrencyID)
+	}
+	return m
+}
+
+type mpPayer struct {
+	ID        json.RawMessage `json:"id"`
+	Email     string          `json:"email"`
+	FirstName string          `json:"first_name"`
+	LastName  string          `json:"last_name"`
+}
+
+type mpRefundResponse struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// mpNotification es el body de los webhooks; las IPN viejas solo traen topic e id en la query
+type mpNotification struct {
+	ID     json.RawMessage `json:"id"`
+	Type   string          `json:"type"`
+	Topic  string          `json:"topic"`
+	Action string          `json:"action"`
+	Data   struct {
+		ID json.RawMessage `json:"id"`
+	} `json:"data"`
+}
+
+func (p *MercadoPagoProvider) CreateSession(ctx context.Context, req CheckoutSessionRequest) (*CheckoutSession, error) {
+	body := mercadoPagoPreference(req, p.cfg.NotificationURL, time.Now())
+
+	var pref mpPreferenceResponse
+	if err := p.do(ctx, http.MethodPost, "/checkout/preferences", "pref-"+req.OrderID, body, &pref); err != nil {
+		return nil, err
+	}
+
+	// Con credenciales de prueba el checkout tiene que abrirse en el sandbox
+	checkoutURL := pref.InitPoint
+	if strings.HasPrefix(p.cfg.AccessToken, "TEST-") && pref.SandboxInitPoint != "" {
+		checkoutURL = pref.SandboxInitPoint
+	}
+	return &CheckoutSession{ID: pref.ID, URL: checkoutURL, ExpiresAt: req.ExpiresAt}, nil
+}
+
+// mercadoPagoPreference arma la preferencia con la metadata que luego se lee del pago
+func mercadoPagoPreference(req CheckoutSessionRequest, notificationURL string, now time.Time) *mpPreferenceRequest {
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	items := make([]mpPreferenceItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		quantity := item.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+		items = append(items, mpPreferenceItem{
+			ID:         item.SeatID,
+			Title:      item.Name,
+			Quantity:   quantity,
+			UnitPrice:  money.New(item.Amount, currency).Major(), // MercadoPago trabaja en unidades, no en centavos
+			CurrencyID: currency,
+		})
+	}
+
+	pref := &mpPreferenceRequest{
+		Items:             items,
+		ExternalReference: req.OrderID,
+		Metadata: map[string]string{
+			"user_id":  req.UserID,
+			"seat_ids": strings.Join(req.SeatIDs, ","),
+			"ga_items": models.FormatGAItems(req.GAItems),
+			"event_id": req.EventID,
+			"order_id": req.OrderID,
+		},
+		BackURLs: mpBackURLs{
+			Success: req.SuccessURL,
+			Failure: req.CancelURL,
+			Pending: req.SuccessURL,
+		},
+		AutoReturn:      "approved",
+		NotificationURL: notificationURL,
+	}
+
+	if req.PromoCode != "" {
+		pref.Metadata["promo_code"] = req.PromoCode
+	}
+
+	// La preferencia vence con el hold: después los asientos ya no están reservados
+	if !req.ExpiresAt.IsZero() {
+		pref.Expires = true
+		pref.ExpirationDateFrom = now.Format(mercadoPagoTimeLayout)
+		pref.ExpirationDateTo = req.ExpiresAt.Format(mercadoPagoTimeLayout)
+	}
+	return pref
+}
+
+// ExpireSession vence la preferencia para que el checkout ya no acepte pagos
+func (p *MercadoPagoProvider) ExpireSession(ctx context.Context, sessionID string) error {
+	body := map[string]any{
+		"expires":            true,
+		"expiration_date_to": time.Now().Format(mercadoPagoTimeLayout),
+	}
+	return p.do(ctx, http.MethodPut, "/checkout/preferences/"+url.PathEscape(sessionID), "", body, nil)
+}
+
+func (p *MercadoPagoProvider) RetrievePayment(ctx context.Context, paymentID string) (*PaymentDetails, error) {
+	payment, err := p.fetchPayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &PaymentDetails{
+		ID:       strconv.FormatInt(payment.ID, 10),
+		Status:   payment.Status,
+		Amount:   payment.amount().Amount,
+		Currency: strings.ToLower(payment.CurrencyID),
+		Customer: PaymentCustomer{
+			Email: payment.Payer.Email,
+			Name:  strings.TrimSpace(payment.Payer.FirstName + " " + payment.Payer.LastName),
+		},
+	}
+	if id := rawID(payment.Payer.ID); id != "" {
+		details.Customer.CustomerID = &id
+	}
+	return details, nil
+}
+
+func (p *MercadoPagoProvider) fetchPayment(ctx context.Context, paymentID string) (*mpPayment, error) {
+	var payment mpPayment
+	if err := p.do(ctx, http.MethodGet, "/v1/payments/"+url.PathEscape(paymentID), "", nil, &payment); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (p *MercadoPagoProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	// Sin importe MercadoPago devuelve el pago completo
+	var body any
+	if req.Amount > 0 {
+		body = map[string]float64{"amount": money.New(req.Amount, req.Currency).Major()}
+	}
+
+	var re mpRefundResponse
+	if err := p.do(ctx, http.MethodPost, "/v1/payments/"+url.PathEscape(req.PaymentID)+"/refunds", req.IdempotencyKey, body, &re); err != nil {
+		return nil, err
+	}
+	return &RefundResult{ID: strconv.FormatInt(re.ID, 10), Status: re.Status}, nil
+}
+
+// ParseWebhook acepta tanto el webhook (JSON con data.id y firma x-signature) como la IPN
+// (topic e id en la query). El estado nunca se toma de la notificación: se consulta el pago en la API
+func (p *MercadoPagoProvider) ParseWebhook(ctx context.Context, req WebhookRequest) (*messaging.BookingMessage, error) {
+	var notification mpNotification
+	if len(bytes.TrimSpace(req.Payload)) > 0 {
+		if err := json.Unmarshal(req.Payload, &notification); err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrInvalidWebhookPayload, err)
+		}
+	}
+
+	topic := firstNonEmpty(notification.Type, notification.Topic, req.Query.Get("type"), req.Query.Get("topic"))
+	dataID := firstNonEmpty(req.Query.Get("data.id"), rawID(notification.Data.ID), req.Query.Get("id"))
+
+	if signature := req.Headers.Get("X-Signature"); signature != "" || (p.cfg.WebhookSecret != "" && !isIPN(req.Query)) {
+		if err := p.verifySignature(signature, req.Headers.Get("X-Request-Id"), dataID, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if topic != "payment" {
+		return nil, fmt.Errorf("%w: topic %q", utils.ErrWebhookIgnored, topic)
+	}
+	if dataID == "" {
+		return nil, fmt.Errorf("%w: missing payment id", utils.ErrInvalidWebhookPayload)
+	}
+
+	payment, err := p.fetchPayment(ctx, dataID)
+	if err != nil {
+		return nil, err
+	}
+	return mercadoPagoMessage(payment), nil
+}
+
+// isIPN indica si la notificación es una IPN: MercadoPago no las firma
+func isIPN(query url.Values) bool {
+	return query.Get("topic") != "" && query.Get("data.id") == ""
+}
+
+// verifySignature valida x-signature ("ts=...,v1=...") con HMAC-SHA256 del manifest que documenta MercadoPago
+func (p *MercadoPagoProvider) verifySignature(signature, requestID, dataID string, now time.Time) error {
+	if p.cfg.WebhookSecret == "" {
+		return utils.ErrWebhookNotConfigured
+	}
+
+	var ts, v1 string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "ts":
+			ts = value
+		case "v1":
+			v1 = value
+		}
+	}
+	if ts == "" || v1 == "" {
+		return utils.ErrInvalidWebhookSignature
+	}
+
+	var manifest strings.Builder
+	if dataID != "" {
+		manifest.WriteString("id:" + strings.ToLower(dataID) + ";")
+	}
+	if requestID != "" {
+		manifest.WriteString("request-id:" + requestID + ";")
+	}
+	manifest.WriteString("ts:" + ts + ";")
+
+	mac := hmac.New(sha256.New, []byte(p.cfg.WebhookSecret))
+	mac.Write([]byte(manifest.String()))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(v1))) {
+		return utils.ErrInvalidWebhookSignature
+	}
+
+	sent, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return utils.ErrInvalidWebhookSignature
+	}
+	// ts puede venir en segundos o en milisegundos
+	signedAt := time.Unix(sent, 0)
+	if sent > 1e12 {
+		signedAt = time.UnixMilli(sent)
+	}
+	if now.Sub(signedAt) > p.cfg.WebhookTolerance {
+		return utils.ErrWebhookSignatureExpired
+	}
+	return nil
+}
+
+// mercadoPagoMessage traduce el pago al mensaje interno con el que trabaja el consumer
+func mercadoPagoMessage(payment *mpPayment) *messaging.BookingMessage {
+	paymentID := strconv.FormatInt(payment.ID, 10)
+	status := strings.ToLower(strings.TrimSpace(payment.Status))
+
+	orderID := strings.TrimSpace(payment.ExternalReference)
+	if orderID == "" {
+		orderID = metadataString(payment.Metadata, "order_id")
+	}
+
+	// Un mismo pago notifica varias veces; cada cambio de estado es un evento distinto
+	eventID := "mp:" + paymentID + ":" + status
+	return &messaging.BookingMessage{
+		UserID:            metadataString(payment.Metadata, "user_id"),
+		Amount:            float64(payment.amount().Amount), // en centavos, como los de Stripe
+		Currency:          strings.ToLower(payment.CurrencyID),
+		Status:            status,
+		SeatIDs:           metadataString(payment.Metadata, "seat_ids"),
+		GAItems:           metadataString(payment.Metadata, "ga_items"),
+		EventID:           metadataString(payment.Metadata, "event_id"),
+		PaymentProviderID: paymentID,
+		OrderID:           orderID,
+		Nonce:             eventID,
+		StripeEventID:     eventID,
+		StripeEventType:   "payment." + status,
+		Provider:          MercadoPagoProviderName,
+	}
+}
+
+// do llama a la API de MercadoPago y decodifica la respuesta en out
+func (p *MercadoPagoProvider) do(ctx context.Context, method, path, idempotencyKey string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.cfg.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.cfg.AccessToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("X-Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("mercadopago %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("mercadopago %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+			Error   string `json:"error"`
+		}
+		_ = json.Unmarshal(raw, &apiErr)
+		return fmt.Errorf("mercadopago %s %s: status %d: %s", method, path, resp.StatusCode, firstNonEmpty(apiErr.Message, apiErr.Error, string(raw)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("mercadopago %s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
+
+// rawID normaliza un ID que MercadoPago manda a veces como número y a veces como string
+func rawID(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+func metadataString(metadata map[string]any, key string) string {
+	switch v := metadata[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"booking-service/internal/messaging"
+	"booking-service/pkg/utils"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMercadoPagoAPI es un stand-in de la API de MercadoPago con las rutas que usa el provider
+type fakeMercadoPagoAPI struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []map[string]any
+	payments map[string]string
+}
+
+func newFakeMercadoPagoAPI(t *testing.T) (*fakeMercadoPagoAPI, *httptest.Server) {
+	api := &fakeMercadoPagoAPI{payments: make(map[string]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /checkout/preferences", func(w http.ResponseWriter, r *http.Request) {
+		api.record(t, r)
+		fmt.Fprint(w, `{"id":"pref_1","init_point":"https://mp/checkout/pref_1","sandbox_init_point":"https://sandbox.mp/checkout/pref_1"}`)
+	})
+	mux.HandleFunc("GET /v1/payments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.record(t, r)
+		api.mu.Lock()
+		body, ok := api.payments[r.PathValue("id")]
+		api.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Payment not found","error":"not_found","status":404}`)
+			return
+		}
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("POST /v1/payments/{id}/refunds", func(w http.ResponseWriter, r *http.Request) {
+		api.record(t, r)
+		fmt.Fprint(w, `{"id":987,"status":"approved"}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return api, server
+}
+
+func (a *fakeMercadoPagoAPI) record(t *testing.T, r *http.Request) {
+	var body map[string]any
+	if raw, _ := io.ReadAll(r.Body); len(raw) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests = append(a.requests, r)
+	a.bodies = append(a.bodies, body)
+}
+
+func (a *fakeMercadoPagoAPI) last() (*http.Request, map[string]any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests[len(a.requests)-1], a.bodies[len(a.bodies)-1]
+}
+
+const mpApprovedPayment = `{"id":123,"status":"approved","transaction_amount":1500.5,"currency_id":"ARS","external_reference":"o1",
+	"metadata":{"user_id":"u1","seat_ids":"s1,s2","event_id":"e1","order_id":"o1"},
+	"payer":{"id":"99","email":"ana@mail.com","first_name":"Ana","last_name":"Pérez"}}`
+
+func mpSignature(secret, dataID, requestID string, ts int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "id:%s;request-id:%s;ts:%d;", dataID, requestID, ts)
+	return fmt.Sprintf("ts=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestMercadoPagoProvider_CreateSession(t *testing.T) {
+	api, server := newFakeMercadoPagoAPI(t)
+	provider := NewMercadoPagoProvider(MercadoPagoConfig{AccessToken: "TEST-token", BaseURL: server.URL, NotificationURL: "https://api/sqs/messaging/mercadopago"})
+
+	expiresAt := time.Now().Add(10 * time.Minute)
+	session, err := provider.CreateSession(context.Background(), CheckoutSessionRequest{
+		OrderID:    "o1",
+		UserID:     "u1",
+		EventID:    "e1",
+		SeatIDs:    []string{"s1", "s2"},
+		Currency:   "ars",
+		Items:      []CheckoutItem{{SeatID: "s1", Name: "Asiento 1 - A", Amount: 150050}, {SeatID: "s2", Name: "Asiento 2 - A", Amount: 1000}},
+		SuccessURL: "https://front/success?order_id=o1",
+		CancelURL:  "https://front/cancel",
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session.ID != "pref_1" || session.URL != "https://sandbox.mp/checkout/pref_1" {
+		t.Fatalf("expected sandbox checkout for test credentials, got %+v", session)
+	}
+
+	req, body := api.last()
+	if req.Header.Get("Authorization") != "Bearer TEST-token" || req.Header.Get("X-Idempotency-Key") != "pref-o1" {
+		t.Fatalf("unexpected headers: %v", req.Header)
+	}
+	items := body["items"].([]any)
+	first := items[0].(map[string]any)
+	if len(items) != 2 || first["unit_price"] != 1500.5 || first["currency_id"] != "ARS" || first["quantity"] != float64(1) {
+		t.Fatalf("unexpected items: %v", items)
+	}
+	metadata := body["metadata"].(map[string]any)
+	if body["external_reference"] != "o1" || metadata["seat_ids"] != "s1,s2" || body["notification_url"] != "https://api/sqs/messaging/mercadopago" {
+		t.Fatalf("unexpected preference: %v", body)
+	}
+	if body["expires"] != true || body["expiration_date_to"] != expiresAt.Format(mercadoPagoTimeLayout) {
+		t.Fatalf("expected preference to expire with the hold, got %v", body["expiration_date_to"])
+	}
+}
+
+func TestMercadoPagoProvider_RetrievePaymentAndRefund(t *testing.T) {
+	api, server := newFakeMercadoPagoAPI(t)
+	api.payments["123"] = mpApprovedPayment
+	provider := NewMercadoPagoProvider(MercadoPagoConfig{AccessToken: "APP_USR-token", BaseURL: server.URL})
+
+	payment, err := provider.RetrievePayment(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.Amount != 150050 || payment.Currency != "ars" || payment.Customer.Email != "ana@mail.com" || payment.Customer.Name != "Ana Pérez" || *payment.Customer.CustomerID != "99" {
+		t.Fatalf("unexpected payment %+v", payment)
+	}
+
+	if _, err := provider.RetrievePayment(context.Background(), "404"); err == nil {
+		t.Fatalf("expected error for unknown payment")
+	}
+
+	result, err := provider.Refund(context.Background(), RefundRequest{PaymentID: "123", Amount: 50025, IdempotencyKey: "refund-r1"})
+	if err != nil || result.ID != "987" || result.Status != "approved" {
+		t.Fatalf("unexpected refund %+v (%v)", result, err)
+	}
+	req, body := api.last()
+	if req.URL.Path != "/v1/payments/123/refunds" || req.Header.Get("X-Idempotency-Key") != "refund-r1" || body["amount"] != 500.25 {
+		t.Fatalf("unexpected refund request %s %v", req.URL.Path, body)
+	}
+}
+
+func TestMercadoPagoProvider_ParseWebhook(t *testing.T) {
+	const secret = "mp_secret"
+	api, server := newFakeMercadoPagoAPI(t)
+	api.payments["123"] = mpApprovedPayment
+	api.payments["124"] = `{"id":124,"status":"rejected","transaction_amount":10,"currency_id":"ARS","external_reference":"o2"}`
+	provider := NewMercadoPagoProvider(MercadoPagoConfig{AccessToken: "APP_USR-token", WebhookSecret: secret, BaseURL: server.URL, WebhookTolerance: time.Minute})
+
+	webhook := func(dataID, signature string) WebhookRequest {
+		h := http.Header{}
+		h.Set("X-Request-Id", "req-1")
+		if signature != "" {
+			h.Set("X-Signature", signature)
+		}
+		return WebhookRequest{
+			Payload: []byte(`{"action":"payment.updated","type":"payment","data":{"id":"` + dataID + `"}}`),
+			Headers: h,
+			Query:   url.Values{"data.id": {dataID}, "type": {"payment"}},
+		}
+	}
+	now := time.Now().Unix()
+
+	msg, err := provider.ParseWebhook(context.Background(), webhook("123", mpSignature(secret, "123", "req-1", now)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Provider != MercadoPagoProviderName || msg.OrderID != "o1" || msg.UserID != "u1" || msg.SeatIDs != "s1,s2" || msg.EventID != "e1" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if !msg.IsPaid() || msg.IsFailure() || msg.Amount != 150050 || msg.Currency != "ars" || msg.PaymentProviderID != "123" || msg.StripeEventID != "mp:123:approved" {
+		t.Fatalf("unexpected payment data %+v", msg)
+	}
+
+	msg, err = provider.ParseWebhook(context.Background(), webhook("124", mpSignature(secret, "124", "req-1", now)))
+	// El comprador puede reintentar en Checkout Pro: un rechazo no termina la orden
+	if err != nil || msg.IsFailure() || msg.IsPaid() || msg.StripeEventType != messaging.EventMercadoPagoRejected || msg.OrderID != "o2" {
+		t.Fatalf("expected rejected payment to keep the order open, got %+v (%v)", msg, err)
+	}
+
+	if _, err := provider.ParseWebhook(context.Background(), webhook("123", mpSignature("otro", "123", "req-1", now))); !errors.Is(err, utils.ErrInvalidWebhookSignature) {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+	if _, err := provider.ParseWebhook(context.Background(), webhook("123", "")); !errors.Is(err, utils.ErrInvalidWebhookSignature) {
+		t.Fatalf("expected unsigned webhook to be rejected, got %v", err)
+	}
+	old := time.Now().Add(-time.Hour).Unix()
+	if _, err := provider.ParseWebhook(context.Background(), webhook("123", mpSignature(secret, "123", "req-1", old))); !errors.Is(err, utils.ErrWebhookSignatureExpired) {
+		t.Fatalf("expected expired signature, got %v", err)
+	}
+	ms := time.Now().UnixMilli()
+	if _, err := provider.ParseWebhook(context.Background(), webhook("123", mpSignature(secret, "123", "req-1", ms))); err != nil {
+		t.Fatalf("expected millisecond timestamps to be accepted, got %v", err)
+	}
+}
+
+func TestMercadoPagoProvider_ParseWebhook_IPNAndIgnoredTopics(t *testing.T) {
+	api, server := newFakeMercadoPagoAPI(t)
+	api.payments["123"] = mpApprovedPayment
+	provider := NewMercadoPagoProvider(MercadoPagoConfig{AccessToken: "APP_USR-token", WebhookSecret: "mp_secret", BaseURL: server.URL})
+
+	// Las IPN no vienen firmadas: el estado sale igual de la API
+	msg, err := provider.ParseWebhook(context.Background(), WebhookRequest{Headers: http.Header{}, Query: url.Values{"topic": {"payment"}, "id": {"123"}}})
+	if err != nil || msg.OrderID != "o1" || !msg.IsPaid() {
+		t.Fatalf("unexpected IPN message %+v (%v)", msg, err)
+	}
+
+	_, err = provider.ParseWebhook(context.Background(), WebhookRequest{Headers: http.Header{}, Query: url.Values{"topic": {"merchant_order"}, "id": {"55"}}})
+	if !errors.Is(err, utils.ErrWebhookIgnored) {
+		t.Fatalf("expected merchant_order to be ignored, got %v", err)
+	}
+
+	_, err = provider.ParseWebhook(context.Background(), WebhookRequest{Payload: []byte("{"), Headers: http.Header{}, Query: url.Values{"topic": {"payment"}}})
+	if !errors.Is(err, utils.ErrInvalidWebhookPayload) {
+		t.Fatalf("expected invalid payload, got %v", err)
+	}
+
+	_, err = provider.ParseWebhook(context.Background(), WebhookRequest{Headers: http.Header{}, Query: url.Values{"topic": {"payment"}, "id": {"404"}}})
+	if err == nil || errors.Is(err, utils.ErrWebhookIgnored) {
+		t.Fatalf("expected API error for unknown payment, got %v", err)
+	}
+}
+
+func TestPaymentProviders(t *testing.T) {
+	stripeFake := NewFakePaymentProvider()
+	api, server := newFakeMercadoPagoAPI(t)
+	api.payments["123"] = mpApprovedPayment
+	providers := NewPaymentProviders(stripeFake, NewMercadoPagoProvider(MercadoPagoConfig{BaseURL: server.URL}))
+
+	if p, err := providers.Get(""); err != nil || p != stripeFake {
+		t.Fatalf("expected default provider, got %v (%v)", p, err)
+	}
+	if p, err := providers.Get(" mercadopago "); err != nil || p.Name() != MercadoPagoProviderName {
+		t.Fatalf("expected case-insensitive lookup, got %v (%v)", p, err)
+	}
+	if _, err := providers.Get("paypal"); !errors.Is(err, utils.ErrUnknownPaymentProvider) {
+		t.Fatalf("expected unknown provider, got %v", err)
+	}
+
+	if _, err := providers.Refund(context.Background(), RefundRequest{Provider: FakeProviderName, PaymentID: "pi_1", Amount: 100}); err != nil || len(stripeFake.Refunds) != 1 {
+		t.Fatalf("expected refund on default provider, got %v", err)
+	}
+
+	customer, err := providers.LookupCustomer(context.Background(), MercadoPagoProviderName, "123")
+	if err != nil || customer.Email != "ana@mail.com" {
+		t.Fatalf("unexpected customer %+v (%v)", customer, err)
+	}
+	if _, err := providers.LookupCustomer(context.Background(), "paypal", "1"); !errors.Is(err, utils.ErrUnknownPaymentProvider) {
+		t.Fatalf("expected unknown provider, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"booking-service/internal/messaging"
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CheckoutItem es una línea de la sesión de pago (un asiento o entradas de admisión general)
+type CheckoutItem struct {
+	SeatID   string
+	Name     string
+	Amount   int64 // en centavos
+	Quantity int64
+	// Sección del asiento o nombre del sector, para los códigos promocionales por sección
+	Section string
+}
+
+// CheckoutSessionRequest son los datos para abrir una sesión de pago en la pasarela
+type CheckoutSessionRequest struct {
+	OrderID string
+	UserID  string
+	EventID string
+	SeatIDs []string
+	GAItems []models.GAItem
+	// Email del comprador (del JWT); la pasarela lo fija en el pago si lo soporta
+	Email string
+	// Código promocional de la orden; los importes de Items ya tienen el descuento
+	PromoCode  string
+	Currency   string
+	Items      []CheckoutItem
+	SuccessURL string
+	CancelURL  string
+	// ExpiresAt es el vencimiento deseado; la pasarela puede extenderlo a su mínimo
+	ExpiresAt time.Time
+}
+
+// CheckoutSession es la sesión creada en la pasarela
+type CheckoutSession struct {
+	ID        string
+	URL       string
+	ExpiresAt time.Time
+}
+
+// PaymentDetails es el estado de un pago consultado en la pasarela
+type PaymentDetails struct {
+	ID       string
+	Status   string
+	Amount   int64
+	Currency string
+	Customer PaymentCustomer
+}
+
+// WebhookRequest es la notificación tal como llegó: body crudo, headers y query string
+// (las IPN de MercadoPago traen el ID del pago en la query)
+type WebhookRequest struct {
+	Payload []byte
+	Headers http.Header
+	Query   url.Values
+}
+
+// PaymentProvider abstrae la pasarela de pago: checkout, consulta, reembolso y webhooks
+type PaymentProvider interface {
+	// Name identifica la pasarela (se guarda en BookingOrder/Checkout.PaymentProvider)
+	Name() string
+	CreateSession(ctx context.Context, req CheckoutSessionRequest) (*CheckoutSession, error)
+	// ExpireSession cierra la sesión de pago para que ya no se pueda pagar la orden
+	ExpireSession(ctx context.Context, sessionID string) error
+	RetrievePayment(ctx context.Context, paymentID string) (*PaymentDetails, error)
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+	// ParseWebhook verifica la firma de la notificación y la traduce al mensaje interno
+	ParseWebhook(ctx context.Context, req WebhookRequest) (*messaging.BookingMessage, error)
+}
+
+// SessionExpirer cierra la sesión de pago de una orden que se cancela sin pagar
+type SessionExpirer interface {
+	ExpireSession(ctx context.Context, provider, sessionID string) error
+}
+
+// PaymentProviders agrupa las pasarelas configuradas y elige la que corresponde a cada orden
+type PaymentProviders struct {
+	byName      map[string]PaymentProvider
+	defaultName string
+}
+
+// NewPaymentProviders registra las pasarelas; la primera es la que se usa si no se indica ninguna
+func NewPaymentProviders(defaultProvider PaymentProvider, others ...PaymentProvider) *PaymentProviders {
+	p := &PaymentProviders{byName: make(map[string]PaymentProvider), defaultName: defaultProvider.Name()}
+	for _, provider := range append([]PaymentProvider{defaultProvider}, others...) {
+		p.byName[provider.Name()] = provider
+	}
+	return p
+}
+
+// Get devuelve la pasarela por nombre (sin distinguir mayúsculas); vacío es la de por defecto
+func (p *PaymentProviders) Get(name string) (PaymentProvider, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		name = p.defaultName
+	}
+	provider, ok := p.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", utils.ErrUnknownPaymentProvider, name)
+	}
+	return provider, nil
+}
+
+// Refund implementa PaymentRefunder enviando el reembolso a la pasarela que cobró la orden
+func (p *PaymentProviders) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	provider, err := p.Get(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Refund(ctx, req)
+}
+
+// ExpireSession implementa SessionExpirer cerrando la sesión en la pasarela de la orden
+func (p *PaymentProviders) ExpireSession(ctx context.Context, providerName, sessionID string) error {
+	provider, err := p.Get(providerName)
+	if err != nil {
+		return err
+	}
+	return provider.ExpireSession(ctx, sessionID)
+}
+
+// LookupCustomer implementa CustomerLookup consultando el pago en su pasarela
+func (p *PaymentProviders) LookupCustomer(ctx context.Context, providerName, paymentID string) (*PaymentCustomer, error) {
+	provider, err := p.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := provider.RetrievePayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return &payment.Customer, nil
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"booking-service/internal/messaging"
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// PaymentCustomer son los datos del pagador obtenidos de la pasarela
+type PaymentCustomer struct {
+	Email      string
+	Name       string
+	CustomerID *string
+	// Huella de la tarjeta con la que pagó (Stripe), para el límite de compra por tarjeta
+	CardFingerprint string
+}
+
+// CustomerLookup obtiene los datos del pagador a partir del ID del pago en la pasarela indicada
+type CustomerLookup interface {
+	LookupCustomer(ctx context.Context, provider, paymentID string) (*PaymentCustomer, error)
+}
+
+// PaymentService aplica en proceso los eventos de pago que llegan por la cola
+type PaymentService struct {
+	repo      repositories.PaymentRepository
+	events    repositories.ProcessedPaymentEventRepository
+	customers CustomerLookup
+	emails    EmailService
+	// refunder devuelve los pagos que llegan para órdenes que ya no se pueden cumplir
+	refunder PaymentRefunder
+}
+
+func NewPaymentService(
+	repo repositories.PaymentRepository,
+	events repositories.ProcessedPaymentEventRepository,
+	customers CustomerLookup,
+	emails EmailService,
+	refunder PaymentRefunder,
+) *PaymentService {
+	return &PaymentService{repo: repo, events: events, customers: customers, emails: emails, refunder: refunder}
+}
+
+// HandlePayment implementa messaging.PaymentHandler
+func (s *PaymentService) HandlePayment(ctx context.Context, msg messaging.BookingMessage) error {
+	if msg.IsFailure() {
+		return s.handleFailure(msg)
+	}
+
+	if !msg.IsPaid() {
+		log.Printf("ℹ️ Pago de la orden %s no completado (%s), ignorando", msg.OrderID, msg.Status)
+		return nil
+	}
+
+	seatIDs := msg.SeatIDList()
+	gaItems := models.ParseGAItems(msg.GAItems)
+	if strings.TrimSpace(msg.OrderID) == "" || (len(seatIDs) == 0 && len(gaItems) == 0 && !msg.SeatsInOrder()) {
+		return fmt.Errorf("%w: missing order or seats", messaging.ErrDiscardMessage)
+	}
+
+	eventID := paymentEventKey(msg)
+
+	// Chequeo rápido para no consultar a Stripe en una reentrega; la garantía real
+	// es el registro del evento dentro de la transacción de CompletePayment
+	if s.events != nil {
+		processed, err := s.events.IsProcessed(eventID)
+		if err != nil {
+			return err
+		}
+		if processed {
+			log.Printf("ℹ️ Evento de pago %s ya procesado, ignorando", eventID)
+			return nil
+		}
+	}
+
+	customer := s.lookupCustomer(ctx, providerName(msg), msg.PaymentProviderID)
+
+	completion := &repositories.PaymentCompletion{
+		EventID:           eventID,
+		EventType:         msg.StripeEventType,
+		OrderID:           msg.OrderID,
+		SeatIDs:           seatIDs,
+		SeatsFromOrder:    msg.SeatsInOrder(),
+		GAItems:           gaItems,
+		PaymentProvider:   providerName(msg),
+		PaymentProviderID: msg.PaymentProviderID,
+		Currency:          msg.Currency, // vacío: se usa la moneda de la orden
+		Amount:            int64(math.Round(msg.Amount)),
+		CustomerEmail:     customer.Email,
+		CustomerName:      customer.Name,
+		CustomerID:        customer.CustomerID,
+		CardFingerprint:   customer.CardFingerprint,
+	}
+
+	checkout, ticket, err := s.repo.CompletePayment(completion)
+	if errors.Is(err, repositories.ErrPaymentEventProcessed) {
+		log.Printf("ℹ️ Evento de pago %s ya procesado, ignorando", eventID)
+		return nil
+	}
+	if errors.Is(err, repositories.ErrOrderAlreadyPaid) {
+		log.Printf("ℹ️ La orden %s ya se confirmó con el pago %s, ignorando", msg.OrderID, msg.PaymentProviderID)
+		return nil
+	}
+	if err != nil {
+		// La orden existe pero ya no se puede cumplir (cancelada, vencida, con los asientos
+		// vendidos a otro o pagada con una tarjeta sin cupo): el cobro se devuelve antes de
+		// descartar el mensaje
+		if errors.Is(err, repositories.ErrOrderSeatsMismatch) ||
+			errors.Is(err, repositories.ErrSeatsTaken) ||
+			errors.Is(err, utils.ErrInvalidOrderTransition) ||
+			errors.Is(err, utils.ErrPurchaseLimitExceeded) {
+			if refundErr := s.refundUnfulfillable(ctx, msg, customer, err); refundErr != nil {
+				return refundErr
+			}
+			return fmt.Errorf("%w: %v (payment refunded)", messaging.ErrDiscardMessage, err)
+		}
+		if errors.Is(err, repositories.ErrOrderNotFound) {
+			return fmt.Errorf("%w: %v", messaging.ErrDiscardMessage, err)
+		}
+		return err
+	}
+
+	// El email no es crítico: la orden ya quedó confirmada
+	if s.emails != nil {
+		var lines []models.OrderLine
+		if ticket != nil {
+			lines = ticket.Lines
+		}
+		if err := s.emails.SendPurchaseEmail(ctx, checkout.CustomerEmail, checkout.CustomerName, msg.OrderID, checkout.Total, lines); err != nil {
+			log.Printf("⚠️ No se pudo enviar el email de compra de la orden %s: %v", msg.OrderID, err)
+		}
+	}
+
+	log.Printf("✅ Orden %s procesada completamente", msg.OrderID)
+	return nil
+}
+
+// refundUnfulfillable devuelve un pago que llegó para una orden que no se puede cumplir, por
+// ejemplo cuando el hold venció y la sesión de la pasarela seguía abierta. La clave de
+// idempotencia es el pago: una reentrega no lo reembolsa dos veces. Si la pasarela falla se
+// devuelve el error para que el mensaje se reintente
+func (s *PaymentService) refundUnfulfillable(ctx context.Context, msg messaging.BookingMessage, customer *PaymentCustomer, cause error) error {
+	if s.refunder == nil || msg.PaymentProviderID == "" {
+		log.Printf("⚠️ Pago %s de la orden %s sin cumplir y sin reembolso automático: %v", msg.PaymentProviderID, msg.OrderID, cause)
+		return nil
+	}
+
+	amount := int64(math.Round(msg.Amount))
+	if _, err := s.refunder.Refund(ctx, RefundRequest{
+		Provider:       providerName(msg),
+		PaymentID:      msg.PaymentProviderID,
+		Amount:         amount, // 0: la pasarela devuelve el pago completo
+		Currency:       msg.Currency,
+		IdempotencyKey: "unfulfilled-" + msg.PaymentProviderID,
+		Reason:         "order cannot be fulfilled: " + cause.Error(),
+	}); err != nil {
+		return fmt.Errorf("failed to refund unfulfilled payment %s: %w", msg.PaymentProviderID, err)
+	}
+	log.Printf("💸 Pago %s de la orden %s reembolsado: %v", msg.PaymentProviderID, msg.OrderID, cause)
+
+	// El evento queda procesado para no volver a consultar la pasarela en una reentrega
+	if s.events != nil {
+		if _, err := s.events.MarkProcessed(paymentEventKey(msg), msg.StripeEventType, msg.OrderID); err != nil {
+			log.Printf("⚠️ No se pudo registrar el evento de pago %s: %v", paymentEventKey(msg), err)
+		}
+	}
+
+	// El email no es crítico: el reembolso ya se emitió
+	if s.emails != nil && customer != nil {
+		tickets := len(msg.SeatIDList()) + models.GATicketCount(models.ParseGAItems(msg.GAItems))
+		if err := s.emails.SendRefundEmail(ctx, customer.Email, customer.Name, msg.OrderID, money.New(amount, msg.Currency), tickets); err != nil {
+			log.Printf("⚠️ No se pudo enviar el email de reembolso de la orden %s: %v", msg.OrderID, err)
+		}
+	}
+	return nil
+}
+
+// handleFailure cierra la orden de una sesión vencida o un pago fallido y devuelve sus asientos
+func (s *PaymentService) handleFailure(msg messaging.BookingMessage) error {
+	if strings.TrimSpace(msg.OrderID) == "" {
+		return fmt.Errorf("%w: missing order", messaging.ErrDiscardMessage)
+	}
+
+	status := models.PaymentFailed
+	if msg.StripeEventType == messaging.EventCheckoutSessionExpired {
+		status = models.PaymentExpired
+	}
+
+	released, err := s.repo.FailPayment(&repositories.PaymentFailure{
+		EventID:           paymentEventKey(msg),
+		EventType:         msg.StripeEventType,
+		OrderID:           msg.OrderID,
+		Status:            status,
+		PaymentProviderID: msg.PaymentProviderID,
+	})
+	if errors.Is(err, repositories.ErrPaymentEventProcessed) {
+		log.Printf("ℹ️ Evento de pago %s ya procesado, ignorando", paymentEventKey(msg))
+		return nil
+	}
+	if err != nil {
+		// Una orden inexistente o ya pagada no se puede marcar como fallida
+		if errors.Is(err, repositories.ErrOrderNotFound) || errors.Is(err, utils.ErrInvalidOrderTransition) {
+			return fmt.Errorf("%w: %v", messaging.ErrDiscardMessage, err)
+		}
+		return err
+	}
+
+	log.Printf("❌ Orden %s marcada como %s (%s), %d asientos liberados", msg.OrderID, status, msg.StripeEventType, released)
+	return nil
+}
+
+// providerName devuelve la pasarela que originó el mensaje (los mensajes viejos no la traen)
+func providerName(msg messaging.BookingMessage) string {
+	if name := strings.ToUpper(strings.TrimSpace(msg.Provider)); name != "" {
+		return name
+	}
+	return StripeProviderName
+}
+
+// paymentEventKey devuelve la clave de idempotencia del mensaje: el ID del evento de Stripe
+// o, si no viene, el nonce con el que se encoló
+func paymentEventKey(msg messaging.BookingMessage) string {
+	if id := strings.TrimSpace(msg.StripeEventID); id != "" {
+		return id
+	}
+	if nonce := strings.TrimSpace(msg.Nonce); nonce != "" {
+		return nonce
+	}
+	return "order:" + msg.OrderID + ":" + msg.PaymentProviderID
+}
+
+// lookupCustomer nunca falla: si la pasarela no responde usa valores por defecto
+func (s *PaymentService) lookupCustomer(ctx context.Context, provider, paymentID string) *PaymentCustomer {
+	fallback := &PaymentCustomer{Email: "noreply@booking.com", Name: "Cliente"}
+	if s.customers == nil || paymentID == "" {
+		return fallback
+	}
+
+	customer, err := s.customers.LookupCustomer(ctx, provider, paymentID)
+	if err != nil || customer == nil {
+		log.Printf("⚠️ No se pudo obtener el cliente del pago %s: %v", paymentID, err)
+		return fallback
+	}
+	if customer.Email == "" {
+		customer.Email = fallback.Email
+	}
+	if customer.Name == "" {
+		customer.Name = strings.NewReplacer(".", " ", "_", " ", "-", " ").Replace(strings.Split(customer.Email, "@")[0])
+	}
+	return customer
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"booking-service/internal/messaging"
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/domain"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type mockPaymentRepo struct {
+	completeFn func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error)
+	failFn     func(*repositories.PaymentFailure) (int64, error)
+}
+
+func (m *mockPaymentRepo) CompletePayment(p *repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+	return m.completeFn(p)
+}
+func (m *mockPaymentRepo) FailPayment(f *repositories.PaymentFailure) (int64, error) {
+	return m.failFn(f)
+}
+
+type mockProcessedEventRepo struct {
+	processed map[string]bool
+	marked    []string
+}
+
+func (m *mockProcessedEventRepo) IsProcessed(eventID string) (bool, error) {
+	return m.processed[eventID], nil
+}
+func (m *mockProcessedEventRepo) MarkProcessed(eventID, _, _ string) (bool, error) {
+	m.marked = append(m.marked, eventID)
+	return true, nil
+}
+
+type mockCustomerLookup struct {
+	customer *PaymentCustomer
+	err      error
+}
+
+func (m *mockCustomerLookup) LookupCustomer(context.Context, string, string) (*PaymentCustomer, error) {
+	return m.customer, m.err
+}
+
+type mockEmailServiceForPayment struct {
+	sentTo     []string
+	refundedTo []string
+}
+
+func (m *mockEmailServiceForPayment) SendAsync(*domain.Email) error { return nil }
+func (m *mockEmailServiceForPayment) SendBulk([]*domain.Email)      {}
+func (m *mockEmailServiceForPayment) Shutdown()                     {}
+func (m *mockEmailServiceForPayment) SendPurchaseEmail(_ context.Context, to, _, _ string, _ money.Money, _ []models.OrderLine) error {
+	m.sentTo = append(m.sentTo, to)
+	return nil
+}
+func (m *mockEmailServiceForPayment) SendRefundEmail(_ context.Context, to, _, _ string, _ money.Money, _ int) error {
+	m.refundedTo = append(m.refundedTo, to)
+	return nil
+}
+
+func paidMessage() messaging.BookingMessage {
+	return messaging.BookingMessage{
+		OrderID:           "11111111-1111-1111-1111-111111111111",
+		SeatIDs:           "s1, s2",
+		Status:            "paid",
+		Amount:            2500,
+		PaymentProviderID: "pi_1",
+		StripeEventID:     "evt_1",
+		Nonce:             "evt_1",
+	}
+}
+
+func TestPaymentService_HandlePayment(t *testing.T) {
+	t.Run("ignores unpaid events", func(t *testing.T) {
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			t.Fatalf("repo must not be called")
+			return nil, nil, nil
+		}}, nil, nil, nil, nil)
+		msg := paidMessage()
+		msg.Status = "unpaid"
+		if err := svc.HandlePayment(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("missing seats is discarded", func(t *testing.T) {
+		svc := NewPaymentService(&mockPaymentRepo{}, nil, nil, nil, nil)
+		msg := paidMessage()
+		msg.SeatIDs = " , "
+		if err := svc.HandlePayment(context.Background(), msg); !errors.Is(err, messaging.ErrDiscardMessage) {
+			t.Fatalf("expected ErrDiscardMessage, got %v", err)
+		}
+	})
+
+	t.Run("general admission only order is completed", func(t *testing.T) {
+		var got *repositories.PaymentCompletion
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(p *repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			got = p
+			return &models.Checkout{}, &models.TicketPDF{}, nil
+		}}, nil, nil, nil, nil)
+		msg := paidMessage()
+		msg.SeatIDs = ""
+		msg.GAItems = "ga1:2"
+		if err := svc.HandlePayment(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got == nil || len(got.SeatIDs) != 0 || len(got.GAItems) != 1 || got.GAItems[0].Quantity != 2 {
+			t.Fatalf("unexpected completion: %+v", got)
+		}
+	})
+
+	t.Run("seat list too long for the metadata is taken from the order", func(t *testing.T) {
+		var got *repositories.PaymentCompletion
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(p *repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			got = p
+			return &models.Checkout{}, &models.TicketPDF{}, nil
+		}}, nil, nil, nil, nil)
+
+		seatIDs := make([]string, 20)
+		for i := range seatIDs {
+			seatIDs[i] = fmt.Sprintf("11111111-1111-1111-1111-%012d", i)
+		}
+		msg := paidMessage()
+		msg.SeatIDs = stripeSessionParams(CheckoutSessionRequest{OrderID: msg.OrderID, SeatIDs: seatIDs}, time.Now()).Metadata["seat_ids"]
+		if err := svc.HandlePayment(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got == nil || !got.SeatsFromOrder || len(got.SeatIDs) != 0 {
+			t.Fatalf("expected seats taken from the order, got %+v", got)
+		}
+	})
+
+	t.Run("completes order and sends email", func(t *testing.T) {
+		var got *repositories.PaymentCompletion
+		emails := &mockEmailServiceForPayment{}
+		customerID := "cus_1"
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(p *repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			got = p
+			return &models.Checkout{CustomerEmail: p.CustomerEmail, CustomerName: p.CustomerName, Total: money.New(p.Amount, p.Currency)}, &models.TicketPDF{}, nil
+		}}, nil, &mockCustomerLookup{customer: &PaymentCustomer{Email: "jane.doe@example.com", CustomerID: &customerID}}, emails, nil)
+
+		if err := svc.HandlePayment(context.Background(), paidMessage()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got == nil || len(got.SeatIDs) != 2 || got.SeatIDs[1] != "s2" || got.Amount != 2500 || got.Currency != "" {
+			t.Fatalf("unexpected completion: %+v", got)
+		}
+		if got.PaymentProvider != StripeProviderName {
+			t.Fatalf("expected STRIPE as default provider, got %q", got.PaymentProvider)
+		}
+		if got.EventID != "evt_1" {
+			t.Fatalf("expected stripe event id as idempotency key, got %q", got.EventID)
+		}
+		if got.CustomerName != "jane doe" || got.CustomerID == nil {
+			t.Fatalf("expected customer data from lookup, got %+v", got)
+		}
+		if len(emails.sentTo) != 1 || emails.sentTo[0] != "jane.doe@example.com" {
+			t.Fatalf("expected purchase email, got %v", emails.sentTo)
+		}
+	})
+
+	t.Run("lookup failure falls back to defaults", func(t *testing.T) {
+		var got *repositories.PaymentCompletion
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(p *repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			got = p
+			return &models.Checkout{}, &models.TicketPDF{}, nil
+		}}, nil, &mockCustomerLookup{err: errors.New("stripe down")}, nil, nil)
+
+		if err := svc.HandlePayment(context.Background(), paidMessage()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.CustomerEmail != "noreply@booking.com" || got.CustomerName != "Cliente" {
+			t.Fatalf("expected fallback customer, got %+v", got)
+		}
+	})
+
+	t.Run("permanent repo errors are discarded", func(t *testing.T) {
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			return nil, nil, fmt.Errorf("%w: s1", repositories.ErrSeatsTaken)
+		}}, nil, nil, nil, nil)
+		if err := svc.HandlePayment(context.Background(), paidMessage()); !errors.Is(err, messaging.ErrDiscardMessage) {
+			t.Fatalf("expected ErrDiscardMessage, got %v", err)
+		}
+	})
+
+	t.Run("payment for a cancelled order is refunded", func(t *testing.T) {
+		refunder := &fakeRefunder{}
+		events := &mockProcessedEventRepo{}
+		emails := &mockEmailServiceForPayment{}
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			return nil, nil, fmt.Errorf("%w: CANCELLED -> COMPLETED", utils.ErrInvalidOrderTransition)
+		}}, events, &mockCustomerLookup{customer: &PaymentCustomer{Email: "ana@example.com"}}, emails, refunder)
+
+		if err := svc.HandlePayment(context.Background(), paidMessage()); !errors.Is(err, messaging.ErrDiscardMessage) {
+			t.Fatalf("expected ErrDiscardMessage, got %v", err)
+		}
+		if len(refunder.requests) != 1 {
+			t.Fatalf("expected one refund, got %+v", refunder.requests)
+		}
+		req := refunder.requests[0]
+		if req.Provider != StripeProviderName || req.PaymentID != "pi_1" || req.Amount != 2500 || req.IdempotencyKey != "unfulfilled-pi_1" {
+			t.Fatalf("unexpected refund request: %+v", req)
+		}
+		if len(events.marked) != 1 || events.marked[0] != "evt_1" {
+			t.Fatalf("expected event marked as processed, got %v", events.marked)
+		}
+		if len(emails.refundedTo) != 1 || len(emails.sentTo) != 0 {
+			t.Fatalf("expected only the refund email, got %+v", emails)
+		}
+	})
+
+	t.Run("payment over the card limit is refunded", func(t *testing.T) {
+		refunder := &fakeRefunder{}
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			return nil, nil, &utils.PurchaseLimitError{Limit: "card", Max: 4, Purchased: 4, Requested: 2}
+		}}, nil, nil, nil, refunder)
+		if err := svc.HandlePayment(context.Background(), paidMessage()); !errors.Is(err, messaging.ErrDiscardMessage) {
+			t.Fatalf("expected ErrDiscardMessage, got %v", err)
+		}
+		if len(refunder.requests) != 1 || refunder.requests[0].IdempotencyKey != "unfulfilled-pi_1" {
+			t.Fatalf("expected one refund, got %+v", refunder.requests)
+		}
+	})
+
+	t.Run("failed refund of an unfulfillable payment is retried", func(t *testing.T) {
+		refunder := &fakeRefunder{err: errors.New("stripe down")}
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			return nil, nil, fmt.Errorf("%w: s1", repositories.ErrSeatsTaken)
+		}}, nil, nil, nil, refunder)
+		err := svc.HandlePayment(context.Background(), paidMessage())
+		if err == nil || errors.Is(err, messaging.ErrDiscardMessage) {
+			t.Fatalf("expected retryable error, got %v", err)
+		}
+	})
+
+	t.Run("second payment event for a paid order is acked without refund", func(t *testing.T) {
+		refunder := &fakeRefunder{}
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			return nil, nil, repositories.ErrOrderAlreadyPaid
+		}}, nil, nil, nil, refunder)
+		if err := svc.HandlePayment(context.Background(), paidMessage()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(refunder.requests) != 0 {
+			t.Fatalf("expected no refund, got %+v", refunder.requests)
+		}
+	})
+
+	t.Run("transient repo errors are retried", func(t *testing.T) {
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			return nil, nil, errors.New("connection reset")
+		}}, nil, nil, nil, nil)
+		err := svc.HandlePayment(context.Background(), paidMessage())
+		if err == nil || errors.Is(err, messaging.ErrDiscardMessage) {
+			t.Fatalf("expected retryable error, got %v", err)
+		}
+	})
+
+	t.Run("already processed event is a no-op", func(t *testing.T) {
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			t.Fatalf("repo must not be called for a processed event")
+			return nil, nil, nil
+		}}, &mockProcessedEventRepo{processed: map[string]bool{"evt_1": true}}, nil, nil, nil)
+		if err := svc.HandlePayment(context.Background(), paidMessage()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("concurrent duplicate detected in transaction is a no-op", func(t *testing.T) {
+		emails := &mockEmailServiceForPayment{}
+		svc := NewPaymentService(&mockPaymentRepo{completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+			return nil, nil, repositories.ErrPaymentEventProcessed
+		}}, &mockProcessedEventRepo{}, nil, emails, nil)
+		if err := svc.HandlePayment(context.Background(), paidMessage()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(emails.sentTo) != 0 {
+			t.Fatalf("expected no email for duplicate event")
+		}
+	})
+}
+
+func TestPaymentService_HandlePaymentFailures(t *testing.T) {
+	cases := []struct {
+		eventType string
+		status    string
+		want      models.PaymentStatus
+	}{
+		{messaging.EventCheckoutSessionExpired, "expired", models.PaymentExpired},
+		{messaging.EventPaymentIntentCanceled, "canceled", models.PaymentFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.eventType, func(t *testing.T) {
+			var got *repositories.PaymentFailure
+			svc := NewPaymentService(&mockPaymentRepo{
+				completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+					t.Fatalf("payment must not be completed")
+					return nil, nil, nil
+				},
+				failFn: func(f *repositories.PaymentFailure) (int64, error) {
+					got = f
+					return 2, nil
+				},
+			}, nil, nil, nil, nil)
+
+			msg := paidMessage()
+			msg.SeatIDs = ""
+			msg.Status = tc.status
+			msg.StripeEventType = tc.eventType
+			if err := svc.HandlePayment(context.Background(), msg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got == nil || got.Status != tc.want || got.OrderID != msg.OrderID || got.EventID != "evt_1" {
+				t.Fatalf("unexpected failure: %+v", got)
+			}
+		})
+	}
+
+	t.Run("paid order is discarded", func(t *testing.T) {
+		svc := NewPaymentService(&mockPaymentRepo{failFn: func(*repositories.PaymentFailure) (int64, error) {
+			return 0, &utils.InvalidTransitionError{From: "COMPLETED", To: "FAILED"}
+		}}, nil, nil, nil, nil)
+		msg := paidMessage()
+		msg.StripeEventType = messaging.EventPaymentIntentCanceled
+		if err := svc.HandlePayment(context.Background(), msg); !errors.Is(err, messaging.ErrDiscardMessage) {
+			t.Fatalf("expected ErrDiscardMessage, got %v", err)
+		}
+	})
+
+	// El comprador puede reintentar: ni Stripe ni MercadoPago terminan la orden por un rechazo
+	retries := []struct{ eventType, status string }{
+		{messaging.EventPaymentIntentFailed, "requires_payment_method"},
+		{messaging.EventMercadoPagoRejected, "rejected"},
+	}
+	for _, tc := range retries {
+		t.Run(tc.eventType+" keeps the order pending", func(t *testing.T) {
+			svc := NewPaymentService(&mockPaymentRepo{
+				completeFn: func(*repositories.PaymentCompletion) (*models.Checkout, *models.TicketPDF, error) {
+					t.Fatalf("payment must not be completed")
+					return nil, nil, nil
+				},
+				failFn: func(*repositories.PaymentFailure) (int64, error) {
+					t.Fatalf("a failed attempt must not end the order")
+					return 0, nil
+				},
+			}, nil, nil, nil, nil)
+			msg := paidMessage()
+			msg.Status = tc.status
+			msg.StripeEventType = tc.eventType
+			if err := svc.HandlePayment(context.Background(), msg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	t.Run("replayed event is a no-op", func(t *testing.T) {
+		svc := NewPaymentService(&mockPaymentRepo{failFn: func(*repositories.PaymentFailure) (int64, error) {
+			return 0, repositories.ErrPaymentEventProcessed
+		}}, nil, nil, nil, nil)
+		msg := paidMessage()
+		msg.StripeEventType = messaging.EventCheckoutSessionExpired
+		if err := svc.HandlePayment(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestPaymentEventKey(t *testing.T) {
+	msg := paidMessage()
+	if got := paymentEventKey(msg); got != "evt_1" {
+		t.Fatalf("expected stripe event id, got %s", got)
+	}
+	msg.StripeEventID = ""
+	msg.Nonce = "nonce-1"
+	if got := paymentEventKey(msg); got != "nonce-1" {
+		t.Fatalf("expected nonce, got %s", got)
+	}
+	msg.Nonce = ""
+	if got := paymentEventKey(msg); got != "order:"+msg.OrderID+":pi_1" {
+		t.Fatalf("expected order fallback, got %s", got)
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"time"
 
 	"booking-service/internal/models"
+	"booking-service/pkg/money"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
+// Filas del detalle de la orden que entran en la página del ticket
+const maxBreakdownRows = 12
+
 type PDFService struct{}
 
 func NewPDFService() *PDFService {
@@ -28,6 +32,15 @@ func (s *PDFService) tr(text string) string {
 	return r.Replace(text)
 }
 
+// majorUnits pasa un importe en unidades menores a unidades enteras de su
+// moneda, según el exponente ISO 4217 (CLP no tiene decimales)
+func majorUnits(amount int64, currency string) int64 {
+	for i := 0; i < money.Exponent(currency); i++ {
+		amount /= 10
+	}
+	return amount
+}
+
 func formatMoney(amount int64) string {
 	s := fmt.Sprintf("%d", amount)
 	n := len(s)
@@ -136,14 +149,43 @@ func (s *PDFService) GenerateTicket(ticket *models.TicketPDF) ([]byte, error) {
 	pdf.SetTextColor(text[0], text[1], text[2])
 	pdf.Cell(0, 5, ticket.Email)
 
+	// ================= DETALLE =================
+	y := 155.0
+	if len(ticket.Lines) > 0 {
+		rows := models.Breakdown(ticket.Lines)
+		if len(rows) > maxBreakdownRows {
+			rows = rows[:maxBreakdownRows]
+		}
+		for _, row := range rows {
+			label := row.Description
+			if row.Kind == models.LineTicket {
+				label = fmt.Sprintf("%s x%d", label, row.Quantity)
+			}
+			sign := ""
+			amount := row.Amount
+			if amount < 0 {
+				sign, amount = "-", -amount
+			}
+
+			pdf.SetXY(108, y)
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.SetTextColor(muted[0], muted[1], muted[2])
+			pdf.Cell(55, 6, s.tr(label))
+			pdf.SetTextColor(text[0], text[1], text[2])
+			pdf.CellFormat(35, 6, fmt.Sprintf("%s$%s", sign, formatMoney(majorUnits(amount, ticket.Currency))), "", 0, "R", false, 0, "")
+			y += 6
+		}
+		y += 4
+	}
+
 	// ================= TOTAL =================
-	total := ticket.Amount / 100
-	pdf.SetY(155)
+	total := majorUnits(ticket.Amount, ticket.Currency)
+	pdf.SetY(y)
 
 	pdf.SetFillColor(primary[0], primary[1], primary[2])
-	pdf.Rect(108, 155, 90, 16, "F")
+	pdf.Rect(108, y, 90, 16, "F")
 
-	pdf.SetXY(112, 160)
+	pdf.SetXY(112, y+5)
 	pdf.SetFont("Helvetica", "B", 11)
 	pdf.SetTextColor(255, 255, 255)
 	pdf.Cell(30, 6, "TOTAL")
@@ -35,4 +35,22 @@ func TestPDFService_GenerateTicket(t *testing.T) {
 		t.Fatalf("expected PDF header, got prefix %q", string(pdf[:4]))
 	}
 
+	withLines, err := svc.GenerateTicket(&models.TicketPDF{
+		Currency:  "ARS",
+		Amount:    12100,
+		Name:      "Test User",
+		Email:     "test@example.com",
+		OrderID:   "12345678-1234-1234-1234-123456789012",
+		EventName: "Rock Fest",
+		Lines: []models.OrderLine{
+			{Kind: models.LineTicket, Description: "Asiento 1", Quantity: 1, UnitAmount: 10000, Amount: 10000, Currency: "ARS"},
+			{Kind: models.LineDiscount, Description: "Descuento FANS", Quantity: 1, Amount: -1000, Currency: "ARS"},
+			{Kind: models.LineServiceFee, Description: "Cargo por servicio", Quantity: 1, UnitAmount: 1000, Amount: 1000, Currency: "ARS"},
+			{Kind: models.LineTax, Description: "IVA 21%", Quantity: 1, UnitAmount: 2100, Amount: 2100, Currency: "ARS"},
+		},
+		PDFVersion: 1,
+	})
+	if err != nil || !bytes.HasPrefix(withLines, []byte("%PDF")) {
+		t.Fatalf("unexpected error generating PDF with lines: %v", err)
+	}
 }
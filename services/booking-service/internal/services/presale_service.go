@@ -0,0 +1,152 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/utils"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Códigos de preventa: letras, números, guiones y guiones bajos; se comparan en mayúsculas
+var presaleCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{4,32}$`)
+
+// PresaleService administra los códigos de preventa de los eventos y su canje
+type PresaleService struct {
+	repo repositories.PresaleRepository
+	now  func() time.Time
+}
+
+func NewPresaleService(repo repositories.PresaleRepository) *PresaleService {
+	return &PresaleService{repo: repo, now: time.Now}
+}
+
+// CreateCode crea un código de preventa. Sin maxUses el código es de un solo uso
+func (s *PresaleService) CreateCode(eventID string, code *models.PresaleCode) error {
+	if _, err := s.findWindow(eventID); err != nil {
+		return err
+	}
+
+	code.Code = normalizePresaleCode(code.Code)
+	if !presaleCodePattern.MatchString(code.Code) {
+		return fmt.Errorf("%w: code must have 4 to 32 letters, digits, '-' or '_'", utils.ErrInvalidPresaleCode)
+	}
+	if code.MaxUses == 0 {
+		code.MaxUses = 1
+	}
+	if code.MaxUses < 0 {
+		return fmt.Errorf("%w: maxUses must be positive", utils.ErrInvalidPresaleCode)
+	}
+	if code.UserID != nil && strings.TrimSpace(*code.UserID) == "" {
+		code.UserID = nil
+	}
+
+	codes, err := s.repo.FindCodesByEventID(eventID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range codes {
+		if existing.Code == code.Code {
+			return utils.ErrPresaleCodeTaken
+		}
+	}
+
+	code.EventID = eventID
+	code.Uses = 0
+	return s.repo.CreateCode(code)
+}
+
+func (s *PresaleService) GetCodes(eventID string) ([]models.PresaleCode, error) {
+	if _, err := s.findWindow(eventID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindCodesByEventID(eventID)
+}
+
+// DeleteCode borra el código; quienes ya lo canjearon siguen con acceso a la preventa
+func (s *PresaleService) DeleteCode(eventID, codeID string) error {
+	code, err := s.repo.FindCodeByID(codeID)
+	if err != nil {
+		return err
+	}
+	if code == nil || code.EventID != eventID {
+		return utils.ErrPresaleCodeNotFound
+	}
+	return s.repo.DeleteCode(codeID)
+}
+
+// Redeem canjea un código y habilita al usuario a comprar durante la preventa del evento
+func (s *PresaleService) Redeem(eventID, userID, code string) (*models.PresaleAccess, error) {
+	event, err := s.findWindow(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.SalesPhase(s.now()) == models.SalesEnded {
+		return nil, utils.ErrSalesEnded
+	}
+
+	code = normalizePresaleCode(code)
+	if code == "" {
+		return nil, fmt.Errorf("%w: code is required", utils.ErrInvalidPresaleCode)
+	}
+	return s.repo.Redeem(eventID, userID, code)
+}
+
+func (s *PresaleService) findWindow(eventID string) (*models.Event, error) {
+	event, err := s.repo.FindSalesWindow(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, utils.ErrEventNotFound
+	}
+	return event, nil
+}
+
+func normalizePresaleCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// checkSalesWindow valida que el evento esté a la venta para el usuario. Si el evento no existe
+// no controla nada: el bloqueo falla después con el error de siempre
+func checkSalesWindow(repo repositories.PresaleRepository, eventID, userID string, now time.Time) error {
+	event, err := repo.FindSalesWindow(eventID)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return nil
+	}
+
+	switch event.SalesPhase(now) {
+	case models.SalesOpen:
+		return nil
+	case models.SalesEnded:
+		return utils.ErrSalesEnded
+	case models.SalesPresale:
+		ok, err := repo.HasAccess(eventID, userID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return &utils.SalesNotStartedError{SalesStart: *event.SalesStart, PresaleStart: event.PresaleStart}
+}
+
+// validateSalesWindow controla que las fechas de venta del evento sean coherentes
+func validateSalesWindow(event *models.Event) error {
+	if event.PresaleStart != nil && event.SalesStart == nil {
+		return fmt.Errorf("%w: presaleStart requires salesStart", utils.ErrInvalidSalesWindow)
+	}
+	if event.PresaleStart != nil && !event.PresaleStart.Before(*event.SalesStart) {
+		return fmt.Errorf("%w: presaleStart must be before salesStart", utils.ErrInvalidSalesWindow)
+	}
+	if event.SalesEnd != nil && event.SalesStart != nil && !event.SalesEnd.After(*event.SalesStart) {
+		return fmt.Errorf("%w: salesEnd must be after salesStart", utils.ErrInvalidSalesWindow)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"booking-service/internal/config"
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockPresaleRepo struct {
+	findSalesWindowFn func(string) (*models.Event, error)
+	hasAccessFn       func(string, string) (bool, error)
+	createCodeFn      func(*models.PresaleCode) error
+	findCodeByIDFn    func(string) (*models.PresaleCode, error)
+	findCodesFn       func(string) ([]models.PresaleCode, error)
+	deleteCodeFn      func(string) error
+	redeemFn          func(string, string, string) (*models.PresaleAccess, error)
+}
+
+func (m *mockPresaleRepo) FindSalesWindow(eventID string) (*models.Event, error) {
+	return m.findSalesWindowFn(eventID)
+}
+func (m *mockPresaleRepo) HasAccess(eventID, userID string) (bool, error) {
+	return m.hasAccessFn(eventID, userID)
+}
+func (m *mockPresaleRepo) CreateCode(code *models.PresaleCode) error { return m.createCodeFn(code) }
+func (m *mockPresaleRepo) FindCodeByID(id string) (*models.PresaleCode, error) {
+	return m.findCodeByIDFn(id)
+}
+func (m *mockPresaleRepo) FindCodesByEventID(eventID string) ([]models.PresaleCode, error) {
+	return m.findCodesFn(eventID)
+}
+func (m *mockPresaleRepo) DeleteCode(id string) error { return m.deleteCodeFn(id) }
+func (m *mockPresaleRepo) Redeem(eventID, userID, code string) (*models.PresaleAccess, error) {
+	return m.redeemFn(eventID, userID, code)
+}
+
+func windowFor(events map[string]models.Event) func(string) (*models.Event, error) {
+	return func(eventID string) (*models.Event, error) {
+		event, ok := events[eventID]
+		if !ok {
+			return nil, nil
+		}
+		return &event, nil
+	}
+}
+
+func TestPresaleService_CreateCode(t *testing.T) {
+	var created *models.PresaleCode
+	svc := NewPresaleService(&mockPresaleRepo{
+		findSalesWindowFn: windowFor(map[string]models.Event{"e1": {}}),
+		findCodesFn: func(string) ([]models.PresaleCode, error) {
+			return []models.PresaleCode{{Code: "FANCLUB"}}, nil
+		},
+		createCodeFn: func(code *models.PresaleCode) error { created = code; return nil },
+	})
+
+	if err := svc.CreateCode("e1", &models.PresaleCode{Code: " early-birds ", Uses: 7}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Code != "EARLY-BIRDS" || created.MaxUses != 1 || created.Uses != 0 || created.EventID != "e1" {
+		t.Fatalf("unexpected code: %+v", created)
+	}
+
+	cases := []struct {
+		name    string
+		eventID string
+		code    models.PresaleCode
+		want    error
+	}{
+		{"missing event", "missing", models.PresaleCode{Code: "VALID1"}, utils.ErrEventNotFound},
+		{"too short", "e1", models.PresaleCode{Code: "AB"}, utils.ErrInvalidPresaleCode},
+		{"invalid chars", "e1", models.PresaleCode{Code: "NO SPACES"}, utils.ErrInvalidPresaleCode},
+		{"negative uses", "e1", models.PresaleCode{Code: "VALID1", MaxUses: -1}, utils.ErrInvalidPresaleCode},
+		{"taken", "e1", models.PresaleCode{Code: "fanclub"}, utils.ErrPresaleCodeTaken},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code := tc.code
+			if err := svc.CreateCode(tc.eventID, &code); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPresaleService_Redeem(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	ended := now.Add(-time.Hour)
+
+	var redeemed string
+	svc := NewPresaleService(&mockPresaleRepo{
+		findSalesWindowFn: windowFor(map[string]models.Event{"e1": {}, "over": {SalesEnd: &ended}}),
+		redeemFn: func(eventID, userID, code string) (*models.PresaleAccess, error) {
+			redeemed = code
+			return &models.PresaleAccess{EventID: eventID, UserID: userID}, nil
+		},
+	})
+	svc.now = func() time.Time { return now }
+
+	if access, err := svc.Redeem("e1", "u1", " fanclub "); err != nil || access.UserID != "u1" || redeemed != "FANCLUB" {
+		t.Fatalf("unexpected redeem: %+v code=%q err=%v", access, redeemed, err)
+	}
+	if _, err := svc.Redeem("over", "u1", "FANCLUB"); !errors.Is(err, utils.ErrSalesEnded) {
+		t.Fatalf("expected sales ended, got %v", err)
+	}
+	if _, err := svc.Redeem("missing", "u1", "FANCLUB"); !errors.Is(err, utils.ErrEventNotFound) {
+		t.Fatalf("expected event not found, got %v", err)
+	}
+	if _, err := svc.Redeem("e1", "u1", "  "); !errors.Is(err, utils.ErrInvalidPresaleCode) {
+		t.Fatalf("expected invalid code, got %v", err)
+	}
+}
+
+func TestSeatService_LockSeats_SalesWindow(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+	events := map[string]models.Event{
+		"open":      {},
+		"scheduled": {SalesStart: at(time.Hour)},
+		"presale":   {PresaleStart: at(-time.Hour), SalesStart: at(time.Hour)},
+		"ended":     {SalesEnd: at(-time.Minute)},
+	}
+	presale := &mockPresaleRepo{
+		findSalesWindowFn: windowFor(events),
+		hasAccessFn:       func(_, userID string) (bool, error) { return userID == "fan", nil },
+	}
+
+	lockFor := func(eventID, userID string) error {
+		var locked bool
+		svc := NewSeatService(&mockSeatRepo{
+			findByIDsFn: func(ids []string) ([]models.Seat, error) {
+				return []models.Seat{{BaseModel: models.BaseModel{ID: ids[0]}, EventID: eventID}}, nil
+			},
+			lockSeatsFn: func([]string, string, string, time.Time) error { locked = true; return nil },
+		}, &mockEventRepoForSeat{}, nil, nil, presale, nil, nil, config.HoldPolicy{})
+
+		_, err := svc.LockSeats([]string{"s1"}, userID)
+		if err != nil && locked {
+			t.Fatalf("%s: seats locked despite error %v", eventID, err)
+		}
+		return err
+	}
+
+	if err := lockFor("open", "u1"); err != nil {
+		t.Fatalf("expected open event to lock, got %v", err)
+	}
+	if err := lockFor("presale", "fan"); err != nil {
+		t.Fatalf("expected presale access to lock, got %v", err)
+	}
+
+	var notStarted *utils.SalesNotStartedError
+	if err := lockFor("presale", "u1"); !errors.As(err, &notStarted) || notStarted.PresaleStart == nil {
+		t.Fatalf("expected sales not started with presale, got %v", err)
+	}
+	// Sin preventa configurada el código no adelanta la compra
+	if err := lockFor("scheduled", "fan"); !errors.Is(err, utils.ErrSalesNotStarted) {
+		t.Fatalf("expected sales not started, got %v", err)
+	}
+	if err := lockFor("ended", "fan"); !errors.Is(err, utils.ErrSalesEnded) {
+		t.Fatalf("expected sales ended, got %v", err)
+	}
+}
+
+func TestSeatService_HoldCart_SalesWindowBeforeGAHold(t *testing.T) {
+	salesStart := time.Now().Add(time.Hour)
+	repoGA := &mockGARepo{
+		findSectionFn: func(id string) (*models.GASection, error) {
+			return &models.GASection{BaseModel: models.BaseModel{ID: id}, EventID: "e1", Name: "Campo", Capacity: 10}, nil
+		},
+	}
+	presale := &mockPresaleRepo{
+		findSalesWindowFn: windowFor(map[string]models.Event{"e1": {SalesStart: &salesStart}}),
+	}
+	svc := NewSeatService(&mockSeatRepo{}, &mockEventRepoForSeat{}, nil, repoGA, presale, nil, nil, config.HoldPolicy{})
+
+	_, err := svc.HoldCart(nil, []models.GAItem{{SectionID: "ga1", Quantity: 2}}, "u1")
+	if !errors.Is(err, utils.ErrSalesNotStarted) {
+		t.Fatalf("expected sales not started, got %v", err)
+	}
+}
+
+func TestValidateSalesWindow(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+	cases := []struct {
+		name  string
+		event models.Event
+		valid bool
+	}{
+		{"no window", models.Event{}, true},
+		{"full window", models.Event{PresaleStart: at(time.Hour), SalesStart: at(2 * time.Hour), SalesEnd: at(3 * time.Hour)}, true},
+		{"only end", models.Event{SalesEnd: at(time.Hour)}, true},
+		{"presale without start", models.Event{PresaleStart: at(time.Hour)}, false},
+		{"presale after start", models.Event{PresaleStart: at(2 * time.Hour), SalesStart: at(time.Hour)}, false},
+		{"end before start", models.Event{SalesStart: at(2 * time.Hour), SalesEnd: at(time.Hour)}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSalesWindow(&tc.event)
+			if tc.valid != (err == nil) {
+				t.Fatalf("valid=%v, got %v", tc.valid, err)
+			}
+			if err != nil && !errors.Is(err, utils.ErrInvalidSalesWindow) {
+				t.Fatalf("expected ErrInvalidSalesWindow, got %v", err)
+			}
+		})
+	}
+}
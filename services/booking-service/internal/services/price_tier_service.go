@@ -0,0 +1,173 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"fmt"
+	"strings"
+)
+
+type PriceTierService struct {
+	repo       repositories.PriceTierRepository
+	repoEvents repositories.EventRepository
+}
+
+func NewPriceTierService(repo repositories.PriceTierRepository, repoEvents repositories.EventRepository) *PriceTierService {
+	return &PriceTierService{repo: repo, repoEvents: repoEvents}
+}
+
+func (s *PriceTierService) CreateTier(eventID string, tier *models.PriceTier) error {
+	event, err := s.findEvent(eventID)
+	if err != nil {
+		return err
+	}
+
+	tier.Name = strings.TrimSpace(tier.Name)
+	if tier.Name == "" {
+		return fmt.Errorf("%w: name cannot be empty", utils.ErrInvalidTier)
+	}
+	if tier.Capacity < 0 {
+		return fmt.Errorf("%w: capacity cannot be negative", utils.ErrInvalidTier)
+	}
+	price, err := tierPrice(event, tier.Price)
+	if err != nil {
+		return err
+	}
+	if err := s.checkNameAvailable(eventID, "", tier.Name); err != nil {
+		return err
+	}
+
+	tier.EventID = eventID
+	tier.Price = price
+	return s.repo.Create(tier)
+}
+
+func (s *PriceTierService) GetTiers(eventID string) ([]models.PriceTier, error) {
+	if _, err := s.findEvent(eventID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByEventID(eventID)
+}
+
+func (s *PriceTierService) GetTier(eventID, tierID string) (*models.PriceTier, error) {
+	tier, err := s.repo.FindByID(tierID)
+	if err != nil {
+		return nil, err
+	}
+	if tier == nil || tier.EventID != eventID {
+		return nil, utils.ErrTierNotFound
+	}
+	return tier, nil
+}
+
+func (s *PriceTierService) UpdateTier(eventID, tierID string, changes models.PriceTierUpdate) (*models.PriceTier, error) {
+	tier, err := s.GetTier(eventID, tierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Name != nil {
+		name := strings.TrimSpace(*changes.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name cannot be empty", utils.ErrInvalidTier)
+		}
+		if err := s.checkNameAvailable(eventID, tierID, name); err != nil {
+			return nil, err
+		}
+		tier.Name = name
+	}
+	if changes.Capacity != nil {
+		if *changes.Capacity < 0 {
+			return nil, fmt.Errorf("%w: capacity cannot be negative", utils.ErrInvalidTier)
+		}
+		// No se puede achicar por debajo de los asientos ya asignados
+		if *changes.Capacity > 0 {
+			seats, err := s.repo.CountSeats(tierID)
+			if err != nil {
+				return nil, err
+			}
+			if int64(*changes.Capacity) < seats {
+				return nil, fmt.Errorf("%w: tier already has %d seats", utils.ErrTierFull, seats)
+			}
+		}
+		tier.Capacity = *changes.Capacity
+	}
+	if changes.Color != nil {
+		tier.Color = strings.TrimSpace(*changes.Color)
+	}
+	if changes.Description != nil {
+		tier.Description = *changes.Description
+	}
+
+	if err := s.repo.Update(tier); err != nil {
+		return nil, err
+	}
+	return tier, nil
+}
+
+func (s *PriceTierService) DeleteTier(eventID, tierID string) error {
+	if _, err := s.GetTier(eventID, tierID); err != nil {
+		return err
+	}
+	return s.repo.Delete(tierID)
+}
+
+// RepriceTier cambia el precio de la categoría y de sus asientos disponibles; los asientos
+// vendidos o bloqueados no se tocan. Las órdenes pendientes conservan el total calculado al
+// crear el checkout
+func (s *PriceTierService) RepriceTier(eventID, tierID string, price money.Money) (*models.PriceTier, repositories.RepriceResult, error) {
+	event, err := s.findEvent(eventID)
+	if err != nil {
+		return nil, repositories.RepriceResult{}, err
+	}
+	tier, err := s.GetTier(eventID, tierID)
+	if err != nil {
+		return nil, repositories.RepriceResult{}, err
+	}
+	if tier.Price, err = tierPrice(event, price); err != nil {
+		return nil, repositories.RepriceResult{}, err
+	}
+
+	result, err := s.repo.Reprice(tierID, tier.Price)
+	if err != nil {
+		return nil, repositories.RepriceResult{}, err
+	}
+	return tier, result, nil
+}
+
+func (s *PriceTierService) findEvent(eventID string) (*models.Event, error) {
+	event, err := s.repoEvents.FindByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, utils.ErrEventNotFound
+	}
+	return event, nil
+}
+
+func (s *PriceTierService) checkNameAvailable(eventID, tierID, name string) error {
+	tiers, err := s.repo.FindByEventID(eventID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tiers {
+		if t.ID != tierID && strings.EqualFold(t.Name, name) {
+			return utils.ErrTierNameTaken
+		}
+	}
+	return nil
+}
+
+// El precio de una categoría siempre va en la moneda del evento
+func tierPrice(event *models.Event, price money.Money) (money.Money, error) {
+	if price.IsNegative() {
+		return money.Money{}, fmt.Errorf("%w: price cannot be negative", utils.ErrInvalidTier)
+	}
+	if price.Currency != "" && !strings.EqualFold(price.Currency, event.Price.Currency) {
+		return money.Money{}, fmt.Errorf("%w: %s != %s", utils.ErrCurrencyMismatch, price.Currency, event.Price.Currency)
+	}
+	return money.New(price.Amount, event.Price.Currency), nil
+}
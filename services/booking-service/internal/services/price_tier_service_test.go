@@ -0,0 +1,170 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"testing"
+)
+
+type mockPriceTierRepo struct {
+	createFn        func(*models.PriceTier) error
+	findByIDFn      func(string) (*models.PriceTier, error)
+	findByEventIDFn func(string) ([]models.PriceTier, error)
+	updateFn        func(*models.PriceTier) error
+	deleteFn        func(string) error
+	countSeatsFn    func(string) (int64, error)
+	repriceFn       func(string, money.Money) (repositories.RepriceResult, error)
+}
+
+func (m *mockPriceTierRepo) Create(tier *models.PriceTier) error { return m.createFn(tier) }
+func (m *mockPriceTierRepo) FindByID(id string) (*models.PriceTier, error) {
+	return m.findByIDFn(id)
+}
+func (m *mockPriceTierRepo) FindByEventID(eventID string) ([]models.PriceTier, error) {
+	return m.findByEventIDFn(eventID)
+}
+func (m *mockPriceTierRepo) Update(tier *models.PriceTier) error     { return m.updateFn(tier) }
+func (m *mockPriceTierRepo) Delete(id string) error                  { return m.deleteFn(id) }
+func (m *mockPriceTierRepo) CountSeats(tierID string) (int64, error) { return m.countSeatsFn(tierID) }
+func (m *mockPriceTierRepo) Reprice(tierID string, price money.Money) (repositories.RepriceResult, error) {
+	return m.repriceFn(tierID, price)
+}
+
+func eventsWithARS() *mockEventRepoForSeat {
+	return &mockEventRepoForSeat{findByIDFn: func(id string) (*models.Event, error) {
+		if id != "e1" {
+			return nil, nil
+		}
+		return &models.Event{BaseModel: models.BaseModel{ID: "e1"}, Price: money.New(100000, "ARS")}, nil
+	}}
+}
+
+func TestPriceTierService_CreateTier(t *testing.T) {
+	var created *models.PriceTier
+	repo := &mockPriceTierRepo{
+		createFn: func(tier *models.PriceTier) error { created = tier; return nil },
+		findByEventIDFn: func(string) ([]models.PriceTier, error) {
+			return []models.PriceTier{{BaseModel: models.BaseModel{ID: "t0"}, Name: "VIP"}}, nil
+		},
+	}
+	svc := NewPriceTierService(repo, eventsWithARS())
+
+	if err := svc.CreateTier("e1", &models.PriceTier{Name: " Platea ", Price: money.New(2500000, ""), Capacity: 100}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.EventID != "e1" || created.Name != "Platea" || created.Price != money.New(2500000, "ARS") {
+		t.Fatalf("unexpected tier: %+v", created)
+	}
+
+	cases := []struct {
+		name    string
+		eventID string
+		tier    models.PriceTier
+		want    error
+	}{
+		{"missing event", "missing", models.PriceTier{Name: "A"}, utils.ErrEventNotFound},
+		{"empty name", "e1", models.PriceTier{Name: " "}, utils.ErrInvalidTier},
+		{"negative price", "e1", models.PriceTier{Name: "A", Price: money.New(-1, "ARS")}, utils.ErrInvalidTier},
+		{"negative capacity", "e1", models.PriceTier{Name: "A", Capacity: -1}, utils.ErrInvalidTier},
+		{"other currency", "e1", models.PriceTier{Name: "A", Price: money.New(100, "USD")}, utils.ErrCurrencyMismatch},
+		{"duplicated name", "e1", models.PriceTier{Name: "vip"}, utils.ErrTierNameTaken},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tier := tc.tier
+			if err := svc.CreateTier(tc.eventID, &tier); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPriceTierService_GetTier_ChecksEvent(t *testing.T) {
+	svc := NewPriceTierService(&mockPriceTierRepo{findByIDFn: func(id string) (*models.PriceTier, error) {
+		if id == "t1" {
+			return &models.PriceTier{BaseModel: models.BaseModel{ID: "t1"}, EventID: "e1"}, nil
+		}
+		return nil, nil
+	}}, eventsWithARS())
+
+	if _, err := svc.GetTier("e1", "t1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.GetTier("e2", "t1"); !errors.Is(err, utils.ErrTierNotFound) {
+		t.Fatalf("expected tier not found for other event, got %v", err)
+	}
+	if _, err := svc.GetTier("e1", "missing"); !errors.Is(err, utils.ErrTierNotFound) {
+		t.Fatalf("expected tier not found, got %v", err)
+	}
+}
+
+func TestPriceTierService_UpdateTier(t *testing.T) {
+	var updated *models.PriceTier
+	repo := &mockPriceTierRepo{
+		findByIDFn: func(string) (*models.PriceTier, error) {
+			return &models.PriceTier{BaseModel: models.BaseModel{ID: "t1"}, EventID: "e1", Name: "VIP", Capacity: 50}, nil
+		},
+		findByEventIDFn: func(string) ([]models.PriceTier, error) {
+			return []models.PriceTier{{BaseModel: models.BaseModel{ID: "t1"}, Name: "VIP"}, {BaseModel: models.BaseModel{ID: "t2"}, Name: "Campo"}}, nil
+		},
+		countSeatsFn: func(string) (int64, error) { return 30, nil },
+		updateFn:     func(tier *models.PriceTier) error { updated = tier; return nil },
+	}
+	svc := NewPriceTierService(repo, eventsWithARS())
+
+	name, color, capacity := "Super VIP", "#FFD700", 40
+	tier, err := svc.UpdateTier("e1", "t1", models.PriceTierUpdate{Name: &name, Color: &color, Capacity: &capacity})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tier != updated || updated.Name != name || updated.Color != color || updated.Capacity != capacity {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+
+	// Renombrar a un nombre propio no es un duplicado
+	same := "vip"
+	if _, err := svc.UpdateTier("e1", "t1", models.PriceTierUpdate{Name: &same}); err != nil {
+		t.Fatalf("unexpected error renaming to own name: %v", err)
+	}
+	taken := "campo"
+	if _, err := svc.UpdateTier("e1", "t1", models.PriceTierUpdate{Name: &taken}); !errors.Is(err, utils.ErrTierNameTaken) {
+		t.Fatalf("expected name taken, got %v", err)
+	}
+	small := 10
+	if _, err := svc.UpdateTier("e1", "t1", models.PriceTierUpdate{Capacity: &small}); !errors.Is(err, utils.ErrTierFull) {
+		t.Fatalf("expected capacity below seats error, got %v", err)
+	}
+}
+
+func TestPriceTierService_RepriceTier(t *testing.T) {
+	var gotID string
+	var gotPrice money.Money
+	repo := &mockPriceTierRepo{
+		findByIDFn: func(string) (*models.PriceTier, error) {
+			return &models.PriceTier{BaseModel: models.BaseModel{ID: "t1"}, EventID: "e1", Price: money.New(1000, "ARS")}, nil
+		},
+		repriceFn: func(id string, price money.Money) (repositories.RepriceResult, error) {
+			gotID, gotPrice = id, price
+			return repositories.RepriceResult{Repriced: 12, Skipped: 2}, nil
+		},
+	}
+	svc := NewPriceTierService(repo, eventsWithARS())
+
+	tier, repriced, err := svc.RepriceTier("e1", "t1", money.New(1500, "ars"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotID != "t1" || gotPrice != money.New(1500, "ARS") || repriced.Repriced != 12 || repriced.Skipped != 2 || tier.Price != gotPrice {
+		t.Fatalf("unexpected reprice: id=%s price=%v repriced=%d tier=%v", gotID, gotPrice, repriced, tier.Price)
+	}
+
+	if _, _, err := svc.RepriceTier("e1", "t1", money.New(1500, "USD")); !errors.Is(err, utils.ErrCurrencyMismatch) {
+		t.Fatalf("expected currency mismatch, got %v", err)
+	}
+	if _, _, err := svc.RepriceTier("e1", "t1", money.New(-5, "ARS")); !errors.Is(err, utils.ErrInvalidTier) {
+		t.Fatalf("expected invalid tier, got %v", err)
+	}
+}
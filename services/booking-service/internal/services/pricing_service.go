@@ -0,0 +1,275 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PricingService administra los cargos e impuestos y arma el detalle de la orden en el checkout
+type PricingService struct {
+	repo       repositories.PricingRepository
+	repoEvents repositories.EventRepository
+	repoVenues repositories.VenueRepository
+}
+
+func NewPricingService(repo repositories.PricingRepository, repoEvents repositories.EventRepository, repoVenues repositories.VenueRepository) *PricingService {
+	return &PricingService{repo: repo, repoEvents: repoEvents, repoVenues: repoVenues}
+}
+
+// PriceQuote es el detalle de la orden: las líneas que se guardan, los items que cobra la
+// pasarela (entradas con el descuento aplicado, cargos e impuestos) y el total
+type PriceQuote struct {
+	Lines []models.OrderLine
+	Items []CheckoutItem
+	Total money.Money
+}
+
+// Price arma las líneas de la orden a partir de las entradas a valor nominal y el código
+// promocional aplicado (puede ser nil). Los cargos se calculan por entrada sobre el valor
+// nominal y los impuestos sobre el neto de los tipos de línea que gravan
+func (s *PricingService) Price(eventID, currency string, faceItems []CheckoutItem, promo *PromoQuote) (*PriceQuote, error) {
+	fees, taxes, err := s.repo.FindRulesForEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &PriceQuote{Items: faceItems}
+	add := func(line models.OrderLine) {
+		line.Position = len(quote.Lines)
+		line.Currency = currency
+		quote.Lines = append(quote.Lines, line)
+	}
+
+	for _, item := range faceItems {
+		quantity := checkoutQuantity(item)
+		add(models.OrderLine{
+			Kind:        models.LineTicket,
+			ItemID:      item.SeatID,
+			Description: item.Name,
+			Quantity:    int(quantity),
+			UnitAmount:  item.Amount,
+			Amount:      item.Amount * quantity,
+		})
+	}
+
+	if promo != nil {
+		quote.Items = promo.Items
+		for _, adj := range promo.Adjustments {
+			quantity := max(adj.Quantity, 1)
+			add(models.OrderLine{
+				Kind:        models.LineDiscount,
+				ItemID:      adj.ItemID,
+				Description: fmt.Sprintf("Descuento %s - %s", adj.Code, adj.Description),
+				Quantity:    quantity,
+				UnitAmount:  adj.Amount / int64(quantity),
+				Amount:      adj.Amount,
+			})
+		}
+	}
+
+	for _, rule := range fees {
+		if rule.Amount.Amount > 0 && !strings.EqualFold(rule.Amount.Currency, currency) {
+			return nil, fmt.Errorf("%w: fee %q is in %s", utils.ErrCurrencyMismatch, rule.Name, rule.Amount.Currency)
+		}
+
+		// Entradas agrupadas por cargo, en el orden de las líneas
+		type group struct {
+			fee      int64
+			quantity int64
+		}
+		var groups []group
+		index := make(map[int64]int)
+		for _, item := range faceItems {
+			fee := rule.FeeFor(item.Amount)
+			if i, ok := index[fee]; ok {
+				groups[i].quantity += checkoutQuantity(item)
+				continue
+			}
+			index[fee] = len(groups)
+			groups = append(groups, group{fee: fee, quantity: checkoutQuantity(item)})
+		}
+
+		for _, g := range groups {
+			if g.fee <= 0 {
+				continue
+			}
+			add(models.OrderLine{
+				Kind:        rule.LineKind(),
+				ItemID:      rule.ID,
+				Description: rule.Name,
+				Quantity:    int(g.quantity),
+				UnitAmount:  g.fee,
+				Amount:      g.fee * g.quantity,
+			})
+		}
+	}
+
+	// Los impuestos gravan las entradas (netas de descuentos) y los cargos, no otros impuestos
+	taxable := len(quote.Lines)
+	for _, rate := range taxes {
+		var base int64
+		for _, line := range quote.Lines[:taxable] {
+			if rate.Applies(line.Kind) {
+				base += line.Amount
+			}
+		}
+		tax := rate.TaxOn(base)
+		if tax <= 0 {
+			continue
+		}
+		add(models.OrderLine{
+			Kind:        models.LineTax,
+			ItemID:      rate.ID,
+			Description: rate.Name,
+			Quantity:    1,
+			UnitAmount:  tax,
+			Amount:      tax,
+		})
+	}
+
+	// La pasarela cobra las entradas ya descontadas más los cargos e impuestos
+	quote.Items = append([]CheckoutItem(nil), quote.Items...)
+	for _, line := range quote.Lines {
+		switch line.Kind {
+		case models.LineServiceFee, models.LineFacilityFee, models.LineTax:
+			quote.Items = append(quote.Items, CheckoutItem{
+				SeatID:   line.ItemID,
+				Name:     line.Description,
+				Amount:   line.UnitAmount,
+				Quantity: int64(line.Quantity),
+			})
+		}
+	}
+
+	quote.Total = money.New(models.TotalsOf(quote.Lines).Total, currency)
+	if quote.Total.Amount <= 0 {
+		return nil, errors.New("order total must be positive")
+	}
+	return quote, nil
+}
+
+// CreateFeeRule valida y crea una regla de cargos de un evento o de un recinto
+func (s *PricingService) CreateFeeRule(rule *models.FeeRule) error {
+	eventCurrency, err := s.validateScope(&rule.EventID, &rule.VenueID, utils.ErrInvalidFeeRule)
+	if err != nil {
+		return err
+	}
+
+	rule.Type = models.FeeType(strings.ToUpper(strings.TrimSpace(string(rule.Type))))
+	if rule.Type == "" {
+		rule.Type = models.FeeService
+	}
+	if rule.Type != models.FeeService && rule.Type != models.FeeFacility {
+		return fmt.Errorf("%w: type must be SERVICE or FACILITY", utils.ErrInvalidFeeRule)
+	}
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name is required", utils.ErrInvalidFeeRule)
+	}
+	if rule.RateBps < 0 || rule.RateBps > 10000 || rule.Amount.Amount < 0 {
+		return fmt.Errorf("%w: rateBps must be between 0 and 10000 and amount cannot be negative", utils.ErrInvalidFeeRule)
+	}
+	if rule.RateBps == 0 && rule.Amount.Amount == 0 {
+		return fmt.Errorf("%w: rateBps or amount is required", utils.ErrInvalidFeeRule)
+	}
+
+	if rule.Amount.Amount == 0 {
+		rule.Amount = money.Money{}
+		return s.repo.CreateFeeRule(rule)
+	}
+	currency := rule.Amount.Currency
+	if currency == "" {
+		currency = eventCurrency
+	}
+	normalized, err := money.NormalizeCurrency(currency)
+	if err != nil || currency == "" {
+		return fmt.Errorf("%w: amount needs a valid currency", utils.ErrInvalidFeeRule)
+	}
+	if eventCurrency != "" && !strings.EqualFold(normalized, eventCurrency) {
+		return fmt.Errorf("%w: event is sold in %s", utils.ErrCurrencyMismatch, eventCurrency)
+	}
+	rule.Amount.Currency = normalized
+	return s.repo.CreateFeeRule(rule)
+}
+
+func (s *PricingService) GetFeeRules(eventID, venueID string) ([]models.FeeRule, error) {
+	return s.repo.FindFeeRules(eventID, venueID)
+}
+
+func (s *PricingService) DeleteFeeRule(id string) error {
+	return s.repo.DeleteFeeRule(id)
+}
+
+// CreateTaxRate valida y crea un impuesto de un evento o de un recinto
+func (s *PricingService) CreateTaxRate(rate *models.TaxRate) error {
+	if _, err := s.validateScope(&rate.EventID, &rate.VenueID, utils.ErrInvalidTaxRate); err != nil {
+		return err
+	}
+
+	rate.Name = strings.TrimSpace(rate.Name)
+	if rate.Name == "" {
+		return fmt.Errorf("%w: name is required", utils.ErrInvalidTaxRate)
+	}
+	if rate.RateBps < 1 || rate.RateBps > 10000 {
+		return fmt.Errorf("%w: rateBps must be between 1 and 10000", utils.ErrInvalidTaxRate)
+	}
+
+	kinds := make([]models.OrderLineKind, 0, len(rate.AppliesTo))
+	for _, kind := range rate.AppliesTo {
+		kind = models.OrderLineKind(strings.ToUpper(strings.TrimSpace(string(kind))))
+		switch kind {
+		case models.LineTicket, models.LineServiceFee, models.LineFacilityFee:
+			kinds = append(kinds, kind)
+		default:
+			return fmt.Errorf("%w: appliesTo accepts TICKET, SERVICE_FEE and FACILITY_FEE", utils.ErrInvalidTaxRate)
+		}
+	}
+	rate.AppliesTo = kinds
+	return s.repo.CreateTaxRate(rate)
+}
+
+func (s *PricingService) GetTaxRates(eventID, venueID string) ([]models.TaxRate, error) {
+	return s.repo.FindTaxRates(eventID, venueID)
+}
+
+func (s *PricingService) DeleteTaxRate(id string) error {
+	return s.repo.DeleteTaxRate(id)
+}
+
+// validateScope exige un evento o un recinto existente (no los dos) y devuelve la moneda del
+// evento, vacía para los recintos
+func (s *PricingService) validateScope(eventID, venueID **string, invalid error) (string, error) {
+	for _, id := range []**string{eventID, venueID} {
+		if *id != nil && strings.TrimSpace(**id) == "" {
+			*id = nil
+		}
+	}
+	if (*eventID == nil) == (*venueID == nil) {
+		return "", fmt.Errorf("%w: either eventId or venueId is required", invalid)
+	}
+
+	if *venueID != nil {
+		venue, err := s.repoVenues.FindByID(**venueID)
+		if err != nil {
+			return "", err
+		}
+		if venue == nil {
+			return "", utils.ErrVenueNotFound
+		}
+		return "", nil
+	}
+
+	event, err := s.repoEvents.FindByID(**eventID)
+	if err != nil {
+		return "", err
+	}
+	if event == nil {
+		return "", utils.ErrEventNotFound
+	}
+	return event.Price.Currency, nil
+}
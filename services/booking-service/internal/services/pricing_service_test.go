@@ -0,0 +1,199 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"testing"
+)
+
+type mockPricingRepo struct {
+	createFeeRuleFn     func(*models.FeeRule) error
+	findFeeRulesFn      func(string, string) ([]models.FeeRule, error)
+	deleteFeeRuleFn     func(string) error
+	createTaxRateFn     func(*models.TaxRate) error
+	findTaxRatesFn      func(string, string) ([]models.TaxRate, error)
+	deleteTaxRateFn     func(string) error
+	findRulesForEventFn func(string) ([]models.FeeRule, []models.TaxRate, error)
+}
+
+func (m *mockPricingRepo) CreateFeeRule(rule *models.FeeRule) error { return m.createFeeRuleFn(rule) }
+func (m *mockPricingRepo) FindFeeRules(eventID, venueID string) ([]models.FeeRule, error) {
+	return m.findFeeRulesFn(eventID, venueID)
+}
+func (m *mockPricingRepo) DeleteFeeRule(id string) error            { return m.deleteFeeRuleFn(id) }
+func (m *mockPricingRepo) CreateTaxRate(rate *models.TaxRate) error { return m.createTaxRateFn(rate) }
+func (m *mockPricingRepo) FindTaxRates(eventID, venueID string) ([]models.TaxRate, error) {
+	return m.findTaxRatesFn(eventID, venueID)
+}
+func (m *mockPricingRepo) DeleteTaxRate(id string) error { return m.deleteTaxRateFn(id) }
+func (m *mockPricingRepo) FindRulesForEvent(eventID string) ([]models.FeeRule, []models.TaxRate, error) {
+	return m.findRulesForEventFn(eventID)
+}
+
+func pricingWith(fees []models.FeeRule, taxes []models.TaxRate) *PricingService {
+	return NewPricingService(&mockPricingRepo{
+		findRulesForEventFn: func(string) ([]models.FeeRule, []models.TaxRate, error) { return fees, taxes, nil },
+	}, &mockEventRepo{}, &mockVenueRepo{})
+}
+
+func TestPricingService_Price_FeesTaxesAndPromo(t *testing.T) {
+	fees := []models.FeeRule{
+		{BaseModel: models.BaseModel{ID: "f1"}, Type: models.FeeService, Name: "Cargo por servicio", RateBps: 1000},
+		{BaseModel: models.BaseModel{ID: "f2"}, Type: models.FeeFacility, Name: "Cargo del recinto", Amount: money.New(100, "ARS")},
+	}
+	taxes := []models.TaxRate{
+		{BaseModel: models.BaseModel{ID: "t1"}, Name: "IVA 21%", RateBps: 2100},
+		{BaseModel: models.BaseModel{ID: "t2"}, Name: "Tasa municipal", RateBps: 100, AppliesTo: []models.OrderLineKind{models.LineTicket}},
+	}
+	items := []CheckoutItem{
+		{SeatID: "s1", Name: "Asiento 1 - PLATEA", Amount: 10000, Quantity: 1, Section: "PLATEA"},
+		{SeatID: "ga1", Name: "Admisión general - Campo", Amount: 5000, Quantity: 2, Section: "Campo"},
+	}
+	promo, err := applyPromo(&models.PromoCode{Code: "FANS10", Type: models.DiscountPercent, PercentOff: 10, Sections: []string{"PLATEA"}}, "ARS", items)
+	if err != nil {
+		t.Fatalf("unexpected promo error: %v", err)
+	}
+
+	quote, err := pricingWith(fees, taxes).Price("e1", "ARS", items, promo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Servicio 10%: 1000 + 2 x 500; recinto 3 x 100; IVA sobre 19000 + 2300; tasa sobre 19000
+	totals := models.TotalsOf(quote.Lines)
+	want := models.OrderTotals{FaceValue: 20000, Discount: -1000, Fees: 2300, Taxes: 4473 + 190, Total: 25963, Currency: "ARS"}
+	if totals != want {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+	if quote.Total != money.New(25963, "ARS") || sumItems(quote.Items) != quote.Total.Amount {
+		t.Fatalf("gateway items %d do not match total %+v", sumItems(quote.Items), quote.Total)
+	}
+
+	var kinds []models.OrderLineKind
+	for i, line := range quote.Lines {
+		if line.Position != i || line.Currency != "ARS" || line.Amount != line.UnitAmount*int64(line.Quantity) {
+			t.Fatalf("unexpected line %d: %+v", i, line)
+		}
+		kinds = append(kinds, line.Kind)
+	}
+	wantKinds := []models.OrderLineKind{models.LineTicket, models.LineTicket, models.LineDiscount, models.LineServiceFee, models.LineServiceFee, models.LineFacilityFee, models.LineTax, models.LineTax}
+	if len(kinds) != len(wantKinds) {
+		t.Fatalf("unexpected lines: %v", kinds)
+	}
+	for i := range kinds {
+		if kinds[i] != wantKinds[i] {
+			t.Fatalf("unexpected lines: %v", kinds)
+		}
+	}
+	if quote.Items[0].Amount != 9000 || quote.Items[len(quote.Items)-1].SeatID != "t2" {
+		t.Fatalf("unexpected gateway items: %+v", quote.Items)
+	}
+}
+
+func TestPricingService_Price_WithoutRules(t *testing.T) {
+	items := []CheckoutItem{{SeatID: "s1", Name: "Asiento 1", Amount: 10000, Quantity: 1}}
+
+	quote, err := pricingWith(nil, nil).Price("e1", "USD", items, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(quote.Lines) != 1 || len(quote.Items) != 1 || quote.Total != money.New(10000, "USD") {
+		t.Fatalf("unexpected quote %+v", quote)
+	}
+}
+
+func TestPricingService_Price_FixedFeeInOtherCurrency(t *testing.T) {
+	fees := []models.FeeRule{{Name: "Servicio", Amount: money.New(100, "USD")}}
+	items := []CheckoutItem{{SeatID: "s1", Amount: 10000, Quantity: 1}}
+
+	if _, err := pricingWith(fees, nil).Price("e1", "ARS", items, nil); !errors.Is(err, utils.ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestPricingService_CreateFeeRule(t *testing.T) {
+	var created *models.FeeRule
+	svc := NewPricingService(&mockPricingRepo{
+		createFeeRuleFn: func(rule *models.FeeRule) error { created = rule; return nil },
+	}, &mockEventRepo{
+		findByIDFn: func(id string) (*models.Event, error) {
+			if id != "e1" {
+				return nil, nil
+			}
+			return &models.Event{Price: money.New(1000, "ARS")}, nil
+		},
+	}, &mockVenueRepo{
+		findByIDFn: func(id string) (*models.Venue, error) {
+			if id != "v1" {
+				return nil, nil
+			}
+			return &models.Venue{}, nil
+		},
+	})
+
+	event := "e1"
+	if err := svc.CreateFeeRule(&models.FeeRule{EventID: &event, Type: "facility", Name: " Recinto ", Amount: money.New(200, "")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Type != models.FeeFacility || created.Name != "Recinto" || created.Amount.Currency != "ARS" {
+		t.Fatalf("unexpected rule: %+v", created)
+	}
+
+	venue := "v1"
+	empty := ""
+	missing := "missing"
+	cases := []struct {
+		name string
+		rule models.FeeRule
+		want error
+	}{
+		{"no scope", models.FeeRule{EventID: &empty, Name: "Servicio", RateBps: 1000}, utils.ErrInvalidFeeRule},
+		{"both scopes", models.FeeRule{EventID: &event, VenueID: &venue, Name: "Servicio", RateBps: 1000}, utils.ErrInvalidFeeRule},
+		{"unknown type", models.FeeRule{EventID: &event, Type: "BOOKING", Name: "Servicio", RateBps: 1000}, utils.ErrInvalidFeeRule},
+		{"no name", models.FeeRule{EventID: &event, RateBps: 1000}, utils.ErrInvalidFeeRule},
+		{"rate out of range", models.FeeRule{EventID: &event, Name: "Servicio", RateBps: 20000}, utils.ErrInvalidFeeRule},
+		{"no fee", models.FeeRule{EventID: &event, Name: "Servicio"}, utils.ErrInvalidFeeRule},
+		{"venue fixed without currency", models.FeeRule{VenueID: &venue, Name: "Servicio", Amount: money.New(100, "")}, utils.ErrInvalidFeeRule},
+		{"fixed in other currency", models.FeeRule{EventID: &event, Name: "Servicio", Amount: money.New(100, "USD")}, utils.ErrCurrencyMismatch},
+		{"missing event", models.FeeRule{EventID: &missing, Name: "Servicio", RateBps: 1000}, utils.ErrEventNotFound},
+		{"missing venue", models.FeeRule{VenueID: &missing, Name: "Servicio", RateBps: 1000}, utils.ErrVenueNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := tc.rule
+			if err := svc.CreateFeeRule(&rule); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPricingService_CreateTaxRate(t *testing.T) {
+	var created *models.TaxRate
+	svc := NewPricingService(&mockPricingRepo{
+		createTaxRateFn: func(rate *models.TaxRate) error { created = rate; return nil },
+	}, &mockEventRepo{}, &mockVenueRepo{
+		findByIDFn: func(string) (*models.Venue, error) { return &models.Venue{}, nil },
+	})
+
+	venue := "v1"
+	if err := svc.CreateTaxRate(&models.TaxRate{VenueID: &venue, Name: "IVA 21%", RateBps: 2100, AppliesTo: []models.OrderLineKind{" service_fee "}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(created.AppliesTo) != 1 || created.AppliesTo[0] != models.LineServiceFee {
+		t.Fatalf("unexpected rate: %+v", created)
+	}
+
+	cases := []models.TaxRate{
+		{VenueID: &venue, RateBps: 2100},
+		{VenueID: &venue, Name: "IVA", RateBps: 0},
+		{VenueID: &venue, Name: "IVA", RateBps: 2100, AppliesTo: []models.OrderLineKind{models.LineTax}},
+	}
+	for _, rate := range cases {
+		if err := svc.CreateTaxRate(&rate); !errors.Is(err, utils.ErrInvalidTaxRate) {
+			t.Fatalf("expected ErrInvalidTaxRate for %+v, got %v", rate, err)
+		}
+	}
+}
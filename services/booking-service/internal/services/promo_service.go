@@ -0,0 +1,310 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Códigos promocionales: letras, números, guiones y guiones bajos; se comparan en mayúsculas
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{4,32}$`)
+
+// PromoService administra los códigos promocionales y calcula su descuento sobre el carrito
+type PromoService struct {
+	repo       repositories.PromoCodeRepository
+	repoEvents repositories.EventRepository
+	now        func() time.Time
+}
+
+func NewPromoService(repo repositories.PromoCodeRepository, repoEvents repositories.EventRepository) *PromoService {
+	return &PromoService{repo: repo, repoEvents: repoEvents, now: time.Now}
+}
+
+// PromoQuote es el código aplicado a un carrito: las líneas con el precio ya descontado (lo que
+// cobra la pasarela) y los ajustes por línea que se guardan en la orden
+type PromoQuote struct {
+	Promo       *models.PromoCode
+	Items       []CheckoutItem
+	Adjustments []models.OrderAdjustment
+	Discount    int64 // En centavos
+}
+
+// CreateCode valida y crea un código. Un código de un evento toma la moneda del evento; uno
+// global con descuento fijo necesita la moneda explícita
+func (s *PromoService) CreateCode(promo *models.PromoCode) error {
+	promo.Code = normalizePromoCode(promo.Code)
+	if !promoCodePattern.MatchString(promo.Code) {
+		return fmt.Errorf("%w: code must have 4 to 32 letters, digits, '-' or '_'", utils.ErrInvalidPromoCode)
+	}
+	if promo.EventID != nil && strings.TrimSpace(*promo.EventID) == "" {
+		promo.EventID = nil
+	}
+
+	var eventCurrency string
+	if promo.EventID != nil {
+		event, err := s.repoEvents.FindByID(*promo.EventID)
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			return utils.ErrEventNotFound
+		}
+		eventCurrency = event.Price.Currency
+	}
+
+	if err := validatePromoCode(promo, eventCurrency); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.FindByCode(promo.Code)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return utils.ErrPromoCodeTaken
+	}
+
+	promo.Uses = 0
+	return s.repo.Create(promo)
+}
+
+// GetCodes lista los códigos; con eventID, los del evento y los globales
+func (s *PromoService) GetCodes(eventID string) ([]models.PromoCode, error) {
+	return s.repo.FindAll(eventID)
+}
+
+func (s *PromoService) GetCode(id string) (*models.PromoCode, error) {
+	promo, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if promo == nil {
+		return nil, utils.ErrPromoCodeNotFound
+	}
+	return promo, nil
+}
+
+// DeleteCode borra el código; las órdenes que ya lo usaron conservan su descuento
+func (s *PromoService) DeleteCode(id string) error {
+	return s.repo.Delete(id)
+}
+
+// Apply valida el código para el carrito del usuario y calcula el descuento. No gasta usos:
+// el uso se registra al crear la orden, con el código bloqueado para respetar los topes
+func (s *PromoService) Apply(code, eventID, userID, currency string, items []CheckoutItem) (*PromoQuote, error) {
+	code = normalizePromoCode(code)
+	if code == "" {
+		return nil, fmt.Errorf("%w: code is required", utils.ErrInvalidPromoCode)
+	}
+
+	promo, err := s.repo.FindByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if promo == nil {
+		return nil, utils.ErrPromoCodeNotFound
+	}
+	if promo.EventID != nil && *promo.EventID != eventID {
+		return nil, fmt.Errorf("%w: not valid for this event", utils.ErrPromoNotApplicable)
+	}
+	if !promo.ActiveAt(s.now()) {
+		return nil, utils.ErrPromoCodeInactive
+	}
+	if promo.MaxUses > 0 && promo.Uses >= promo.MaxUses {
+		return nil, utils.ErrPromoCodeExhausted
+	}
+	if promo.MaxUsesPerUser > 0 {
+		used, err := s.repo.CountRedemptionsByUser(promo.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= int64(promo.MaxUsesPerUser) {
+			return nil, utils.ErrPromoCodeExhausted
+		}
+	}
+
+	return applyPromo(promo, currency, items)
+}
+
+// applyPromo reparte el descuento entre las entradas de las secciones alcanzadas. Si las
+// entradas de una línea quedan con descuentos distintos (el resto de un descuento fijo) la
+// línea se parte en dos para que la pasarela cobre exactamente el total de la orden
+func applyPromo(promo *models.PromoCode, currency string, items []CheckoutItem) (*PromoQuote, error) {
+	var cartTotal, eligibleTotal int64
+	var eligibleCount int
+	for _, item := range items {
+		quantity := checkoutQuantity(item)
+		cartTotal += item.Amount * quantity
+		if promo.AppliesToSection(item.Section) {
+			eligibleTotal += item.Amount * quantity
+			eligibleCount += int(quantity)
+		}
+	}
+	if eligibleCount == 0 {
+		return nil, fmt.Errorf("%w: no tickets in the promo sections", utils.ErrPromoNotApplicable)
+	}
+	if eligibleCount < promo.MinQuantity {
+		return nil, fmt.Errorf("%w: requires at least %d tickets", utils.ErrPromoNotApplicable, promo.MinQuantity)
+	}
+
+	// Descuento de cada entrada alcanzada, en el orden de las líneas
+	var unitDiscounts []int64
+	switch promo.Type {
+	case models.DiscountPercent:
+		for _, item := range items {
+			if !promo.AppliesToSection(item.Section) {
+				continue
+			}
+			for i := int64(0); i < checkoutQuantity(item); i++ {
+				unitDiscounts = append(unitDiscounts, item.Amount*int64(promo.PercentOff)/100)
+			}
+		}
+	case models.DiscountFixed:
+		if !strings.EqualFold(promo.AmountOff.Currency, currency) {
+			return nil, fmt.Errorf("%w: promo is in %s", utils.ErrPromoNotApplicable, promo.AmountOff.Currency)
+		}
+		if eligibleTotal == 0 {
+			return nil, fmt.Errorf("%w: nothing to discount", utils.ErrPromoNotApplicable)
+		}
+		var weights []int64
+		for _, item := range items {
+			if !promo.AppliesToSection(item.Section) {
+				continue
+			}
+			for i := int64(0); i < checkoutQuantity(item); i++ {
+				weights = append(weights, item.Amount)
+			}
+		}
+		parts, err := money.New(min(promo.AmountOff.Amount, eligibleTotal), currency).Allocate(weights)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			unitDiscounts = append(unitDiscounts, part.Amount)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown discount type %s", utils.ErrInvalidPromoCode, promo.Type)
+	}
+
+	quote := &PromoQuote{Promo: promo}
+	next := 0
+	for _, item := range items {
+		if !promo.AppliesToSection(item.Section) {
+			quote.Items = append(quote.Items, item)
+			continue
+		}
+
+		// Entradas de la línea agrupadas por descuento
+		type group struct {
+			discount int64
+			quantity int64
+		}
+		var groups []group
+		for i := int64(0); i < checkoutQuantity(item); i++ {
+			discount := unitDiscounts[next]
+			next++
+			if n := len(groups); n > 0 && groups[n-1].discount == discount {
+				groups[n-1].quantity++
+				continue
+			}
+			groups = append(groups, group{discount: discount, quantity: 1})
+		}
+
+		for _, g := range groups {
+			line := item
+			line.Amount = item.Amount - g.discount
+			line.Quantity = g.quantity
+			if g.discount > 0 {
+				line.Name = fmt.Sprintf("%s (%s)", item.Name, promo.Code)
+				quote.Adjustments = append(quote.Adjustments, models.OrderAdjustment{
+					Type:        models.AdjustmentPromo,
+					Code:        promo.Code,
+					ItemID:      item.SeatID,
+					Description: item.Name,
+					Quantity:    int(g.quantity),
+					Amount:      -g.discount * g.quantity,
+				})
+				quote.Discount += g.discount * g.quantity
+			}
+			quote.Items = append(quote.Items, line)
+		}
+	}
+
+	if quote.Discount == 0 {
+		return nil, fmt.Errorf("%w: nothing to discount", utils.ErrPromoNotApplicable)
+	}
+	// Las pasarelas no cobran órdenes en cero: un código no puede dejar la orden gratis
+	if quote.Discount >= cartTotal {
+		return nil, fmt.Errorf("%w: the order total must stay above zero", utils.ErrPromoNotApplicable)
+	}
+	return quote, nil
+}
+
+// validatePromoCode controla el tipo de descuento, los topes y el período de validez
+func validatePromoCode(promo *models.PromoCode, eventCurrency string) error {
+	promo.Type = models.DiscountType(strings.ToUpper(strings.TrimSpace(string(promo.Type))))
+	switch promo.Type {
+	case models.DiscountPercent:
+		if promo.PercentOff < 1 || promo.PercentOff > 100 {
+			return fmt.Errorf("%w: percentOff must be between 1 and 100", utils.ErrInvalidPromoCode)
+		}
+		promo.AmountOff = money.Money{}
+	case models.DiscountFixed:
+		if promo.AmountOff.Amount <= 0 {
+			return fmt.Errorf("%w: amountOff must be positive", utils.ErrInvalidPromoCode)
+		}
+		currency := promo.AmountOff.Currency
+		if currency == "" {
+			currency = eventCurrency
+		}
+		normalized, err := money.NormalizeCurrency(currency)
+		if err != nil || currency == "" {
+			return fmt.Errorf("%w: amountOff needs a valid currency", utils.ErrInvalidPromoCode)
+		}
+		if eventCurrency != "" && !strings.EqualFold(normalized, eventCurrency) {
+			return fmt.Errorf("%w: event is sold in %s", utils.ErrCurrencyMismatch, eventCurrency)
+		}
+		promo.AmountOff.Currency = normalized
+		promo.PercentOff = 0
+	default:
+		return fmt.Errorf("%w: type must be PERCENT or FIXED", utils.ErrInvalidPromoCode)
+	}
+
+	if promo.MinQuantity < 0 || promo.MaxUses < 0 || promo.MaxUsesPerUser < 0 {
+		return fmt.Errorf("%w: minQuantity, maxUses and maxUsesPerUser cannot be negative", utils.ErrInvalidPromoCode)
+	}
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && !promo.ValidUntil.After(*promo.ValidFrom) {
+		return fmt.Errorf("%w: validUntil must be after validFrom", utils.ErrInvalidPromoCode)
+	}
+
+	sections := make([]string, 0, len(promo.Sections))
+	for _, section := range promo.Sections {
+		if section = strings.TrimSpace(section); section != "" {
+			sections = append(sections, section)
+		}
+	}
+	promo.Sections = sections
+
+	// Un 100% sobre todas las secciones dejaría siempre la orden en cero, que applyPromo
+	// rechaza: solo tiene sentido limitado a secciones, junto a entradas que se pagan
+	if promo.Type == models.DiscountPercent && promo.PercentOff == 100 && len(promo.Sections) == 0 {
+		return fmt.Errorf("%w: percentOff 100 requires sections, orders cannot be free", utils.ErrInvalidPromoCode)
+	}
+	return nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func checkoutQuantity(item CheckoutItem) int64 {
+	if item.Quantity <= 0 {
+		return 1
+	}
+	return item.Quantity
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockPromoRepo struct {
+	createFn                 func(*models.PromoCode) error
+	findByIDFn               func(string) (*models.PromoCode, error)
+	findByCodeFn             func(string) (*models.PromoCode, error)
+	findAllFn                func(string) ([]models.PromoCode, error)
+	deleteFn                 func(string) error
+	countRedemptionsByUserFn func(string, string) (int64, error)
+}
+
+func (m *mockPromoRepo) Create(promo *models.PromoCode) error { return m.createFn(promo) }
+func (m *mockPromoRepo) FindByID(id string) (*models.PromoCode, error) {
+	return m.findByIDFn(id)
+}
+func (m *mockPromoRepo) FindByCode(code string) (*models.PromoCode, error) {
+	return m.findByCodeFn(code)
+}
+func (m *mockPromoRepo) FindAll(eventID string) ([]models.PromoCode, error) {
+	return m.findAllFn(eventID)
+}
+func (m *mockPromoRepo) Delete(id string) error { return m.deleteFn(id) }
+func (m *mockPromoRepo) CountRedemptionsByUser(promoID, userID string) (int64, error) {
+	return m.countRedemptionsByUserFn(promoID, userID)
+}
+
+func promosByCode(promos ...models.PromoCode) func(string) (*models.PromoCode, error) {
+	return func(code string) (*models.PromoCode, error) {
+		for _, p := range promos {
+			if p.Code == code {
+				return &p, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func sumItems(items []CheckoutItem) int64 {
+	var total int64
+	for _, item := range items {
+		total += item.Amount * checkoutQuantity(item)
+	}
+	return total
+}
+
+func TestApplyPromo_PercentOnlyDiscountsPromoSections(t *testing.T) {
+	promo := &models.PromoCode{Code: "PLATEA20", Type: models.DiscountPercent, PercentOff: 20, Sections: []string{"platea"}}
+	items := []CheckoutItem{
+		{SeatID: "s1", Name: "Asiento 1 - PLATEA", Amount: 10000, Quantity: 1, Section: "PLATEA"},
+		{SeatID: "s2", Name: "Asiento 2 - PALCO", Amount: 30000, Quantity: 1, Section: "PALCO"},
+	}
+
+	quote, err := applyPromo(promo, "ARS", items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quote.Discount != 2000 || sumItems(quote.Items) != 38000 {
+		t.Fatalf("unexpected discount %d, charged %d", quote.Discount, sumItems(quote.Items))
+	}
+	if len(quote.Adjustments) != 1 || quote.Adjustments[0].ItemID != "s1" || quote.Adjustments[0].Amount != -2000 {
+		t.Fatalf("unexpected adjustments: %+v", quote.Adjustments)
+	}
+	if quote.Items[0].Name != "Asiento 1 - PLATEA (PLATEA20)" || quote.Items[1].Amount != 30000 {
+		t.Fatalf("unexpected items: %+v", quote.Items)
+	}
+}
+
+func TestApplyPromo_FullPercentOnSomeSections(t *testing.T) {
+	promo := &models.PromoCode{Code: "PLATEAGRATIS", Type: models.DiscountPercent, PercentOff: 100, Sections: []string{"PLATEA"}}
+	items := []CheckoutItem{
+		{SeatID: "s1", Name: "Asiento 1 - PLATEA", Amount: 10000, Quantity: 1, Section: "PLATEA"},
+		{SeatID: "s2", Name: "Asiento 2 - PALCO", Amount: 30000, Quantity: 1, Section: "PALCO"},
+	}
+
+	quote, err := applyPromo(promo, "ARS", items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quote.Discount != 10000 || sumItems(quote.Items) != 30000 {
+		t.Fatalf("unexpected discount %d, charged %d", quote.Discount, sumItems(quote.Items))
+	}
+
+	// Con solo entradas de la sección la orden quedaría en cero
+	if _, err := applyPromo(promo, "ARS", items[:1]); !errors.Is(err, utils.ErrPromoNotApplicable) {
+		t.Fatalf("expected ErrPromoNotApplicable for a free order, got %v", err)
+	}
+}
+
+func TestApplyPromo_FixedSplitsLinesToKeepTotal(t *testing.T) {
+	promo := &models.PromoCode{Code: "MENOS1000", Type: models.DiscountFixed, AmountOff: money.New(1000, "ARS")}
+	items := []CheckoutItem{
+		{SeatID: "ga1", Name: "Admisión general - Campo", Amount: 5000, Quantity: 3, Section: "Campo"},
+	}
+
+	quote, err := applyPromo(promo, "ARS", items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quote.Discount != 1000 || sumItems(quote.Items) != 14000 {
+		t.Fatalf("unexpected discount %d, charged %d", quote.Discount, sumItems(quote.Items))
+	}
+	// 1000 entre 3 entradas: una con 334 y dos con 333
+	if len(quote.Items) != 2 || len(quote.Adjustments) != 2 {
+		t.Fatalf("expected the line split in two, got %+v", quote.Items)
+	}
+	var adjusted int64
+	var quantity int
+	for _, adj := range quote.Adjustments {
+		adjusted += adj.Amount
+		quantity += adj.Quantity
+	}
+	if adjusted != -1000 || quantity != 3 {
+		t.Fatalf("unexpected adjustments: %+v", quote.Adjustments)
+	}
+}
+
+func TestApplyPromo_Rejections(t *testing.T) {
+	items := []CheckoutItem{
+		{SeatID: "s1", Amount: 10000, Quantity: 1, Section: "PLATEA"},
+		{SeatID: "s2", Amount: 10000, Quantity: 1, Section: "PLATEA"},
+	}
+	cases := []struct {
+		name  string
+		promo models.PromoCode
+	}{
+		{"other sections", models.PromoCode{Type: models.DiscountPercent, PercentOff: 10, Sections: []string{"PALCO"}}},
+		{"min quantity", models.PromoCode{Type: models.DiscountPercent, PercentOff: 10, MinQuantity: 3}},
+		{"other currency", models.PromoCode{Type: models.DiscountFixed, AmountOff: money.New(500, "USD")}},
+		{"free order", models.PromoCode{Type: models.DiscountPercent, PercentOff: 100}},
+		{"fixed over total", models.PromoCode{Type: models.DiscountFixed, AmountOff: money.New(50000, "ARS")}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := applyPromo(&tc.promo, "ARS", items); !errors.Is(err, utils.ErrPromoNotApplicable) {
+				t.Fatalf("expected ErrPromoNotApplicable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPromoService_Apply(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	event := "e1"
+	other := "e2"
+
+	svc := NewPromoService(&mockPromoRepo{
+		findByCodeFn: promosByCode(
+			models.PromoCode{BaseModel: models.BaseModel{ID: "p1"}, Code: "FANS10", EventID: &event, Type: models.DiscountPercent, PercentOff: 10, MaxUsesPerUser: 1},
+			models.PromoCode{Code: "OTHER", EventID: &other, Type: models.DiscountPercent, PercentOff: 10},
+			models.PromoCode{Code: "SOON", Type: models.DiscountPercent, PercentOff: 10, ValidFrom: &later},
+			models.PromoCode{Code: "USEDUP", Type: models.DiscountPercent, PercentOff: 10, MaxUses: 5, Uses: 5},
+		),
+		countRedemptionsByUserFn: func(_, userID string) (int64, error) {
+			if userID == "repeat" {
+				return 1, nil
+			}
+			return 0, nil
+		},
+	}, &mockEventRepo{})
+	svc.now = func() time.Time { return now }
+	items := []CheckoutItem{{SeatID: "s1", Amount: 10000, Quantity: 1}}
+
+	quote, err := svc.Apply(" fans10 ", "e1", "u1", "ARS", items)
+	if err != nil || quote.Discount != 1000 || quote.Promo.ID != "p1" {
+		t.Fatalf("unexpected quote %+v err=%v", quote, err)
+	}
+
+	cases := []struct {
+		name   string
+		code   string
+		userID string
+		want   error
+	}{
+		{"empty", "  ", "u1", utils.ErrInvalidPromoCode},
+		{"missing", "NOPE", "u1", utils.ErrPromoCodeNotFound},
+		{"other event", "OTHER", "u1", utils.ErrPromoNotApplicable},
+		{"not started", "SOON", "u1", utils.ErrPromoCodeInactive},
+		{"no uses left", "USEDUP", "u1", utils.ErrPromoCodeExhausted},
+		{"per user cap", "FANS10", "repeat", utils.ErrPromoCodeExhausted},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.Apply(tc.code, "e1", tc.userID, "ARS", items); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPromoService_CreateCode(t *testing.T) {
+	var created *models.PromoCode
+	svc := NewPromoService(&mockPromoRepo{
+		findByCodeFn: promosByCode(models.PromoCode{Code: "TAKEN"}),
+		createFn:     func(promo *models.PromoCode) error { created = promo; return nil },
+	}, &mockEventRepo{
+		findByIDFn: func(id string) (*models.Event, error) {
+			if id != "e1" {
+				return nil, nil
+			}
+			return &models.Event{Price: money.New(1000, "ARS")}, nil
+		},
+	})
+
+	event := "e1"
+	if err := svc.CreateCode(&models.PromoCode{Code: " vip-500 ", EventID: &event, Type: "fixed", AmountOff: money.New(500, ""), Uses: 3, Sections: []string{" VIP ", ""}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Code != "VIP-500" || created.Type != models.DiscountFixed || created.AmountOff.Currency != "ARS" || created.Uses != 0 || len(created.Sections) != 1 || created.Sections[0] != "VIP" {
+		t.Fatalf("unexpected code: %+v", created)
+	}
+
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	missing := "missing"
+	cases := []struct {
+		name  string
+		promo models.PromoCode
+		want  error
+	}{
+		{"invalid code", models.PromoCode{Code: "A B", Type: models.DiscountPercent, PercentOff: 10}, utils.ErrInvalidPromoCode},
+		{"unknown type", models.PromoCode{Code: "VALID1", Type: "BOGO"}, utils.ErrInvalidPromoCode},
+		{"percent out of range", models.PromoCode{Code: "VALID1", Type: models.DiscountPercent, PercentOff: 120}, utils.ErrInvalidPromoCode},
+		{"full percent on every section", models.PromoCode{Code: "VALID1", Type: models.DiscountPercent, PercentOff: 100, Sections: []string{" "}}, utils.ErrInvalidPromoCode},
+		{"global fixed without currency", models.PromoCode{Code: "VALID1", Type: models.DiscountFixed, AmountOff: money.New(500, "")}, utils.ErrInvalidPromoCode},
+		{"fixed in other currency", models.PromoCode{Code: "VALID1", EventID: &event, Type: models.DiscountFixed, AmountOff: money.New(500, "USD")}, utils.ErrCurrencyMismatch},
+		{"negative caps", models.PromoCode{Code: "VALID1", Type: models.DiscountPercent, PercentOff: 10, MaxUses: -1}, utils.ErrInvalidPromoCode},
+		{"window", models.PromoCode{Code: "VALID1", Type: models.DiscountPercent, PercentOff: 10, ValidFrom: &now, ValidUntil: &before}, utils.ErrInvalidPromoCode},
+		{"missing event", models.PromoCode{Code: "VALID1", EventID: &missing, Type: models.DiscountPercent, PercentOff: 10}, utils.ErrEventNotFound},
+		{"taken", models.PromoCode{Code: "taken", Type: models.DiscountPercent, PercentOff: 10}, utils.ErrPromoCodeTaken},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			promo := tc.promo
+			if err := svc.CreateCode(&promo); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
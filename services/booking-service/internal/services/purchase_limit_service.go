@@ -0,0 +1,43 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/utils"
+	"fmt"
+)
+
+// PurchaseLimitService controla los límites de compra por evento (por usuario, email y tarjeta)
+// contra las órdenes PENDING y COMPLETED del evento
+type PurchaseLimitService struct {
+	repo repositories.PurchaseLimitRepository
+}
+
+func NewPurchaseLimitService(repo repositories.PurchaseLimitRepository) *PurchaseLimitService {
+	return &PurchaseLimitService{repo: repo}
+}
+
+// Check valida que el comprador pueda sumar requested entradas del evento antes de bloquear.
+// Es un aviso temprano: el conteo definitivo se repite al crear la orden y el de la tarjeta
+// de esta compra al confirmar el pago, que es cuando la pasarela la informa
+func (s *PurchaseLimitService) Check(eventID string, buyer models.Buyer, requested int) error {
+	limits, err := s.repo.FindLimits(eventID)
+	if err != nil {
+		return err
+	}
+	if limits == nil {
+		return nil
+	}
+	if limits.ID == "" {
+		limits.ID = eventID
+	}
+	return repositories.CheckPurchaseLimits(s.repo, limits, buyer, requested)
+}
+
+// validatePurchaseLimits controla que los límites de compra del evento no sean negativos
+func validatePurchaseLimits(event *models.Event) error {
+	if event.MaxTicketsPerUser < 0 || event.MaxTicketsPerEmail < 0 || event.MaxTicketsPerCard < 0 {
+		return fmt.Errorf("%w: limits cannot be negative", utils.ErrInvalidPurchaseLimit)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"testing"
+)
+
+type mockPurchaseLimitRepo struct {
+	findLimitsFn           func(string) (*models.Event, error)
+	countByUserFn          func(string, string) (int64, error)
+	countByEmailFn         func(string, string) (int64, error)
+	countByCardsFn         func(string, []string) (int64, error)
+	findCardFingerprintsFn func(string) ([]string, error)
+}
+
+func (m *mockPurchaseLimitRepo) FindLimits(eventID string) (*models.Event, error) {
+	return m.findLimitsFn(eventID)
+}
+func (m *mockPurchaseLimitRepo) CountByUser(eventID, userID string) (int64, error) {
+	return m.countByUserFn(eventID, userID)
+}
+func (m *mockPurchaseLimitRepo) CountByEmail(eventID, email string) (int64, error) {
+	return m.countByEmailFn(eventID, email)
+}
+func (m *mockPurchaseLimitRepo) CountByCards(eventID string, fingerprints []string) (int64, error) {
+	return m.countByCardsFn(eventID, fingerprints)
+}
+func (m *mockPurchaseLimitRepo) FindCardFingerprints(userID string) ([]string, error) {
+	return m.findCardFingerprintsFn(userID)
+}
+
+func TestPurchaseLimitService_Check(t *testing.T) {
+	var countedEmail string
+	var countedCards []string
+	repo := &mockPurchaseLimitRepo{
+		findLimitsFn: func(eventID string) (*models.Event, error) {
+			switch eventID {
+			case "open":
+				return &models.Event{}, nil
+			case "limited":
+				return &models.Event{MaxTicketsPerUser: 4, MaxTicketsPerEmail: 6, MaxTicketsPerCard: 5}, nil
+			}
+			return nil, nil
+		},
+		countByUserFn: func(_, userID string) (int64, error) {
+			if userID == "heavy" {
+				return 3, nil
+			}
+			return 1, nil
+		},
+		countByEmailFn: func(_, email string) (int64, error) {
+			countedEmail = email
+			if email == "shared@mail.com" {
+				return 5, nil
+			}
+			return 0, nil
+		},
+		findCardFingerprintsFn: func(userID string) ([]string, error) {
+			if userID == "carded" {
+				return []string{"fp1"}, nil
+			}
+			return nil, nil
+		},
+		countByCardsFn: func(_ string, fingerprints []string) (int64, error) {
+			countedCards = fingerprints
+			if len(fingerprints) > 0 {
+				return 4, nil
+			}
+			return 0, nil
+		},
+	}
+	svc := NewPurchaseLimitService(repo)
+
+	if err := svc.Check("open", models.Buyer{UserID: "heavy"}, 100); err != nil {
+		t.Fatalf("expected no limits, got %v", err)
+	}
+	if err := svc.Check("missing", models.Buyer{UserID: "u1"}, 100); err != nil {
+		t.Fatalf("expected missing event to skip limits, got %v", err)
+	}
+	if err := svc.Check("limited", models.Buyer{UserID: "u1", Email: " Fan@Mail.com "}, 3); err != nil {
+		t.Fatalf("expected purchase within limits, got %v", err)
+	}
+	if countedEmail != "fan@mail.com" {
+		t.Fatalf("expected normalized email, got %q", countedEmail)
+	}
+
+	cases := []struct {
+		name      string
+		buyer     models.Buyer
+		requested int
+		limit     string
+		remaining int
+	}{
+		{"user", models.Buyer{UserID: "heavy"}, 2, "user", 1},
+		{"email", models.Buyer{UserID: "u1", Email: "shared@mail.com"}, 2, "email", 1},
+		{"card", models.Buyer{UserID: "carded"}, 2, "card", 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := svc.Check("limited", tc.buyer, tc.requested)
+			var exceeded *utils.PurchaseLimitError
+			if !errors.As(err, &exceeded) || !errors.Is(err, utils.ErrPurchaseLimitExceeded) {
+				t.Fatalf("expected purchase limit error, got %v", err)
+			}
+			if exceeded.Limit != tc.limit || exceeded.Remaining() != tc.remaining {
+				t.Fatalf("unexpected limit error: %+v remaining=%d", exceeded, exceeded.Remaining())
+			}
+		})
+	}
+	if len(countedCards) != 1 || countedCards[0] != "fp1" {
+		t.Fatalf("expected user's cards to be counted, got %v", countedCards)
+	}
+}
+
+func TestValidatePurchaseLimits(t *testing.T) {
+	if err := validatePurchaseLimits(&models.Event{MaxTicketsPerUser: 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := validatePurchaseLimits(&models.Event{MaxTicketsPerCard: -1}); !errors.Is(err, utils.ErrInvalidPurchaseLimit) {
+		t.Fatalf("expected ErrInvalidPurchaseLimit, got %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// refundStaleAfter es cuánto espera el reconciliador antes de retomar un reembolso PENDING,
+// para no pisarse con el pedido que todavía lo está procesando
+const refundStaleAfter = 2 * time.Minute
+
+// RefundRequest es el pedido de reembolso que se envía a la pasarela
+type RefundRequest struct {
+	// Provider es la pasarela que cobró la orden (Checkout.PaymentProvider)
+	Provider       string
+	PaymentID      string
+	Amount         int64
+	Currency       string
+	IdempotencyKey string
+	Reason         string
+}
+
+// RefundResult es la respuesta de la pasarela a un reembolso
+type RefundResult struct {
+	ID     string
+	Status string
+}
+
+// PaymentRefunder emite reembolsos en la pasarela de pago
+type PaymentRefunder interface {
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+}
+
+// RefundService devuelve total o parcialmente una orden pagada
+type RefundService struct {
+	orders    repositories.BookingOrderRepository
+	seats     repositories.SeatRepository
+	checkouts repositories.CheckoutRepository
+	refunds   repositories.RefundRepository
+	refunder  PaymentRefunder
+	emails    EmailService
+	now       func() time.Time
+}
+
+func NewRefundService(
+	orders repositories.BookingOrderRepository,
+	seats repositories.SeatRepository,
+	checkouts repositories.CheckoutRepository,
+	refunds repositories.RefundRepository,
+	refunder PaymentRefunder,
+	emails EmailService,
+) *RefundService {
+	return &RefundService{
+		orders:    orders,
+		seats:     seats,
+		checkouts: checkouts,
+		refunds:   refunds,
+		refunder:  refunder,
+		emails:    emails,
+		now:       time.Now,
+	}
+}
+
+// RefundOrder reembolsa los asientos indicados de la orden (o todo lo que quede, incluidas las
+// entradas de admisión general, si no se indica ninguno). Solo el dueño de la orden o una llamada interna pueden pedirlo
+func (s *RefundService) RefundOrder(ctx context.Context, orderID, userID string, seatIDs []string, reason string) (*models.BookingOrder, *models.Refund, error) {
+	order, err := s.orders.FindByID(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, utils.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if userID != "internal" && userID != order.UserID {
+		return nil, nil, utils.ErrOrderForbidden
+	}
+	if order.Status != models.PaymentCompleted && order.Status != models.PaymentPartiallyRefunded {
+		return nil, nil, &utils.InvalidTransitionError{From: string(order.Status), To: string(models.PaymentRefunded)}
+	}
+
+	remaining := order.ActiveSeatIDs()
+	if len(remaining) == 0 && len(order.GAItems) == 0 {
+		return nil, nil, utils.ErrNothingToRefund
+	}
+
+	toRefund, err := refundableSeats(remaining, seatIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Las entradas de admisión general no tienen ID: solo se devuelven en un reembolso total
+	all := len(seatIDs) == 0 || (len(toRefund) == len(remaining) && len(order.GAItems) == 0)
+	amount, err := s.refundAmount(order, toRefund, all)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checkout, err := s.checkouts.FindByOrderID(order.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch checkout: %w", err)
+	}
+
+	pending := &models.Refund{
+		OrderID:     order.ID,
+		SeatIDs:     toRefund,
+		Amount:      amount,
+		Currency:    checkout.Total.Currency,
+		Reason:      reason,
+		RequestedBy: userID,
+		IncludeGA:   all && len(order.GAItems) > 0,
+	}
+	if err := s.refunds.CreatePending(pending); err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.issue(ctx, pending, checkout)
+	if err != nil {
+		return nil, pending, err
+	}
+
+	updated, err := s.complete(ctx, pending, result.ID, checkout)
+	if err != nil {
+		// El reembolso ya salió en la pasarela: queda PENDING con su ID y lo completa ResumePending
+		return nil, pending, err
+	}
+	return updated, pending, nil
+}
+
+// issue envía el reembolso a la pasarela y guarda su ID. El ID del reembolso local es la clave
+// de idempotencia: un reintento no duplica el reembolso. Si la pasarela lo rechaza queda FAILED
+func (s *RefundService) issue(ctx context.Context, refund *models.Refund, checkout *models.Checkout) (*RefundResult, error) {
+	result, err := s.refunder.Refund(ctx, RefundRequest{
+		Provider:       checkout.PaymentProvider,
+		PaymentID:      checkout.PaymentIntentID,
+		Amount:         refund.Amount,
+		Currency:       refund.Currency,
+		IdempotencyKey: "refund-" + refund.ID,
+		Reason:         refund.Reason,
+	})
+	if err != nil {
+		if markErr := s.refunds.MarkFailed(refund.ID, err.Error()); markErr != nil {
+			log.Printf("⚠️ No se pudo marcar como fallido el reembolso %s: %v", refund.ID, markErr)
+		}
+		refund.Status = models.RefundFailed
+		refund.Error = err.Error()
+		return nil, fmt.Errorf("%w: %v", utils.ErrRefundFailed, err)
+	}
+
+	if err := s.refunds.RecordProviderRefund(refund.ID, result.ID); err != nil {
+		log.Printf("⚠️ No se pudo guardar el reembolso %s de la pasarela para %s: %v", result.ID, refund.ID, err)
+	}
+	refund.ProviderRefundID = result.ID
+	return result, nil
+}
+
+// complete aplica en la orden un reembolso que la pasarela ya emitió y avisa al cliente
+func (s *RefundService) complete(ctx context.Context, refund *models.Refund, providerRefundID string, checkout *models.Checkout) (*models.BookingOrder, error) {
+	updated, err := s.refunds.CompleteRefund(&repositories.RefundCompletion{
+		RefundID:         refund.ID,
+		ProviderRefundID: providerRefundID,
+		OrderID:          refund.OrderID,
+		SeatIDs:          refund.SeatIDs,
+		IncludeGA:        refund.IncludeGA,
+		Amount:           refund.Amount,
+		ChangedBy:        refund.RequestedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	refund.Status = models.RefundSucceeded
+	refund.ProviderRefundID = providerRefundID
+
+	// El email no es crítico: el reembolso ya quedó registrado
+	if s.emails != nil && checkout.CustomerEmail != "" {
+		tickets := len(refund.SeatIDs)
+		if refund.IncludeGA {
+			tickets += models.GATicketCount(updated.GAItems)
+		}
+		if err := s.emails.SendRefundEmail(ctx, checkout.CustomerEmail, checkout.CustomerName, refund.OrderID, money.New(refund.Amount, refund.Currency), tickets); err != nil {
+			log.Printf("⚠️ No se pudo enviar el email de reembolso de la orden %s: %v", refund.OrderID, err)
+		}
+	}
+
+	log.Printf("💸 Orden %s reembolsada (%d asientos, %d centavos)", refund.OrderID, len(refund.SeatIDs), refund.Amount)
+	return updated, nil
+}
+
+// ResumePending completa los reembolsos que quedaron PENDING porque el proceso falló después
+// de llamar a la pasarela; mientras siguen PENDING bloquean cualquier otro reembolso de la
+// orden. Devuelve cuántos se completaron
+func (s *RefundService) ResumePending(ctx context.Context) (int, error) {
+	refunds, err := s.refunds.FindStalePending(s.now().Add(-refundStaleAfter), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for i := range refunds {
+		if err := s.resume(ctx, &refunds[i]); err != nil {
+			log.Printf("⚠️ No se pudo completar el reembolso pendiente %s de la orden %s: %v", refunds[i].ID, refunds[i].OrderID, err)
+			continue
+		}
+		completed++
+	}
+	return completed, nil
+}
+
+func (s *RefundService) resume(ctx context.Context, refund *models.Refund) error {
+	checkout, err := s.checkouts.FindByOrderID(refund.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch checkout: %w", err)
+	}
+
+	// Sin ID de la pasarela el proceso se cortó antes o durante la llamada: con la misma clave de
+	// idempotencia la pasarela devuelve el reembolso ya emitido en vez de crear otro
+	if refund.ProviderRefundID == "" {
+		if _, err := s.issue(ctx, refund, checkout); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.complete(ctx, refund, refund.ProviderRefundID, checkout)
+	return err
+}
+
+// Start lanza el reconciliador de reembolsos pendientes en segundo plano. El canal devuelto se
+// cierra cuando terminó luego de cancelar el contexto
+func (s *RefundService) Start(ctx context.Context, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if completed, err := s.ResumePending(ctx); err != nil {
+					log.Printf("⚠️ Reembolsos: error al buscar reembolsos pendientes: %v", err)
+				} else if completed > 0 {
+					log.Printf("💸 Reembolsos: %d reembolsos pendientes completados", completed)
+				}
+			}
+		}
+	}()
+
+	return done
+}
+
+// refundableSeats valida que los asientos pedidos pertenezcan a la orden y no estén reembolsados.
+// Sin asientos pedidos se reembolsa todo lo que queda
+func refundableSeats(remaining, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return remaining, nil
+	}
+
+	allowed := make(map[string]bool, len(remaining))
+	for _, id := range remaining {
+		allowed[id] = true
+	}
+
+	seen := make(map[string]bool, len(requested))
+	seats := make([]string, 0, len(requested))
+	for _, id := range requested {
+		if !allowed[id] {
+			return nil, fmt.Errorf("%w: %s", utils.ErrInvalidRefundSeats, id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		seats = append(seats, id)
+	}
+	return seats, nil
+}
+
+// refundAmount calcula el importe en centavos. Si se devuelve todo lo que queda se reembolsa
+// el saldo de la orden; si no, lo pagado por los asientos más su parte de los cargos e
+// impuestos, sin pasarse del saldo
+func (s *RefundService) refundAmount(order *models.BookingOrder, seatIDs []string, all bool) (int64, error) {
+	balance := order.Total.Amount - order.RefundedAmount
+	if balance <= 0 {
+		return 0, utils.ErrNothingToRefund
+	}
+	if all {
+		return balance, nil
+	}
+
+	// Cada asiento se devuelve al precio que se pagó: el de su línea TICKET (la foto del
+	// checkout) sin el descuento del código promocional. Las órdenes sin líneas usan el
+	// precio actual del asiento
+	paid := make(map[string]money.Money, len(seatIDs))
+	for _, line := range order.Lines {
+		if line.Kind == models.LineTicket && line.ItemID != "" {
+			paid[line.ItemID] = money.New(line.UnitAmount, line.Currency)
+		}
+	}
+	var missing []string
+	for _, id := range seatIDs {
+		if _, ok := paid[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		seats, err := s.seats.FindByIDs(missing)
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch seats: %w", err)
+		}
+		if len(seats) != len(missing) {
+			return 0, utils.ErrInvalidRefundSeats
+		}
+		for _, seat := range seats {
+			paid[seat.ID] = seat.Price
+		}
+	}
+
+	prices := make([]money.Money, 0, len(seatIDs))
+	for _, id := range seatIDs {
+		prices = append(prices, money.New(paid[id].Amount-order.DiscountFor(id), paid[id].Currency))
+	}
+	amount, err := money.Sum(order.Total.Currency, prices...)
+	if err != nil {
+		return 0, err
+	}
+
+	// Cargos e impuestos: a cada entrada le toca la parte proporcional a lo que se pagó por
+	// ella. El redondeo es hacia abajo; el resto se devuelve con el último reembolso (el saldo)
+	totals := models.TotalsOf(order.Lines)
+	if extras, tickets := totals.Fees+totals.Taxes, totals.FaceValue+totals.Discount; extras > 0 && tickets > 0 {
+		amount.Amount += extras * amount.Amount / tickets
+	}
+	return min(amount.Amount, balance), nil
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeRefunder struct {
+	requests []RefundRequest
+	err      error
+}
+
+func (f *fakeRefunder) Refund(_ context.Context, req RefundRequest) (*RefundResult, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &RefundResult{ID: "re_1", Status: "succeeded"}, nil
+}
+
+type mockRefundRepo struct {
+	created     []*models.Refund
+	failed      []string
+	recorded    map[string]string
+	completed   []*repositories.RefundCompletion
+	stale       []models.Refund
+	createErr   error
+	completeErr error
+}
+
+func (m *mockRefundRepo) FindByOrderID(string) ([]models.Refund, error) { return nil, nil }
+func (m *mockRefundRepo) CreatePending(refund *models.Refund) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	refund.ID = "22222222-2222-2222-2222-222222222222"
+	refund.Status = models.RefundPending
+	m.created = append(m.created, refund)
+	return nil
+}
+func (m *mockRefundRepo) MarkFailed(id, _ string) error {
+	m.failed = append(m.failed, id)
+	return nil
+}
+func (m *mockRefundRepo) RecordProviderRefund(id, providerRefundID string) error {
+	if m.recorded == nil {
+		m.recorded = make(map[string]string)
+	}
+	m.recorded[id] = providerRefundID
+	return nil
+}
+func (m *mockRefundRepo) FindStalePending(time.Time, int) ([]models.Refund, error) {
+	return m.stale, nil
+}
+func (m *mockRefundRepo) CompleteRefund(c *repositories.RefundCompletion) (*models.BookingOrder, error) {
+	if m.completeErr != nil {
+		return nil, m.completeErr
+	}
+	m.completed = append(m.completed, c)
+	return &models.BookingOrder{Status: models.PaymentRefunded}, nil
+}
+
+const refundOrderID = "11111111-1111-1111-1111-111111111111"
+
+func newRefundTestService(order *models.BookingOrder, refunds *mockRefundRepo, refunder *fakeRefunder, emails EmailService) *RefundService {
+	orders := &mockBookingOrderRepo{
+		findByIDFn: func(string) (*models.BookingOrder, error) { return order, nil },
+	}
+	seats := &mockSeatRepo{
+		findByIDsFn: func(ids []string) ([]models.Seat, error) {
+			out := make([]models.Seat, 0, len(ids))
+			for _, id := range ids {
+				s := models.Seat{Price: money.New(2550, "USD"), EventID: "e1"}
+				s.ID = id
+				out = append(out, s)
+			}
+			return out, nil
+		},
+	}
+	checkouts := &mockCheckoutRepo{
+		findByOrderFn: func(string) (*models.Checkout, error) {
+			return &models.Checkout{PaymentIntentID: "pi_1", Total: money.New(7650, "USD"), CustomerEmail: "a@a.com", CustomerName: "Ana"}, nil
+		},
+	}
+	return NewRefundService(orders, seats, checkouts, refunds, refunder, emails)
+}
+
+func paidOrder() *models.BookingOrder {
+	order := &models.BookingOrder{UserID: "u1", Total: money.New(7650, "USD"), Status: models.PaymentCompleted, SeatIDs: []string{"s1", "s2", "s3"}}
+	order.ID = refundOrderID
+	return order
+}
+
+func TestRefundService_RefundOrder_Full(t *testing.T) {
+	refunds := &mockRefundRepo{}
+	refunder := &fakeRefunder{}
+	emails := &mockEmailServiceForPayment{}
+	svc := newRefundTestService(paidOrder(), refunds, refunder, emails)
+
+	_, refund, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", nil, "event cancelled")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refund.Status != models.RefundSucceeded || refund.ProviderRefundID != "re_1" {
+		t.Fatalf("unexpected refund: %+v", refund)
+	}
+	if len(refunder.requests) != 1 || refunder.requests[0].Amount != 7650 || refunder.requests[0].PaymentID != "pi_1" {
+		t.Fatalf("unexpected provider request: %+v", refunder.requests)
+	}
+	if refunder.requests[0].IdempotencyKey == "" {
+		t.Fatalf("expected idempotency key")
+	}
+	if len(refunds.completed) != 1 || len(refunds.completed[0].SeatIDs) != 3 {
+		t.Fatalf("expected all seats refunded, got %+v", refunds.completed)
+	}
+	if len(emails.refundedTo) != 1 || emails.refundedTo[0] != "a@a.com" {
+		t.Fatalf("expected refund email, got %v", emails.refundedTo)
+	}
+}
+
+func TestRefundService_RefundOrder_PartialUsesSeatPrices(t *testing.T) {
+	refunds := &mockRefundRepo{}
+	refunder := &fakeRefunder{}
+	svc := newRefundTestService(paidOrder(), refunds, refunder, nil)
+
+	_, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", []string{"s2", "s2"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refunder.requests[0].Amount != 2550 {
+		t.Fatalf("expected 2550 cents, got %d", refunder.requests[0].Amount)
+	}
+	if got := refunds.completed[0].SeatIDs; len(got) != 1 || got[0] != "s2" {
+		t.Fatalf("expected only s2 refunded, got %v", got)
+	}
+}
+
+func TestRefundService_RefundOrder_PartialSubtractsPromoDiscount(t *testing.T) {
+	order := paidOrder()
+	order.Total = money.New(7140, "USD")
+	order.Adjustments = []models.OrderAdjustment{
+		{Type: models.AdjustmentPromo, Code: "FANS20", ItemID: "s2", Quantity: 1, Amount: -510},
+	}
+	refunder := &fakeRefunder{}
+	svc := newRefundTestService(order, &mockRefundRepo{}, refunder, nil)
+
+	if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", []string{"s2"}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refunder.requests[0].Amount != 2040 {
+		t.Fatalf("expected 2040 cents (price minus discount), got %d", refunder.requests[0].Amount)
+	}
+}
+
+func TestRefundService_RefundOrder_PartialReturnsShareOfFeesAndTaxes(t *testing.T) {
+	order := paidOrder()
+	order.Lines = []models.OrderLine{
+		{Kind: models.LineTicket, ItemID: "s1", Quantity: 1, UnitAmount: 2550, Amount: 2550, Currency: "USD"},
+		{Kind: models.LineTicket, ItemID: "s2", Quantity: 1, UnitAmount: 2550, Amount: 2550, Currency: "USD"},
+		{Kind: models.LineTicket, ItemID: "s3", Quantity: 1, UnitAmount: 2550, Amount: 2550, Currency: "USD"},
+		{Kind: models.LineServiceFee, Quantity: 3, UnitAmount: 300, Amount: 900, Currency: "USD"},
+		{Kind: models.LineTax, Quantity: 1, UnitAmount: 1796, Amount: 1796, Currency: "USD"},
+	}
+	order.Total = money.New(10346, "USD")
+	refunder := &fakeRefunder{}
+	svc := newRefundTestService(order, &mockRefundRepo{}, refunder, nil)
+
+	if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", []string{"s2"}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 2550 de la entrada + un tercio (redondeado hacia abajo) de 900 de cargos y 1796 de IVA
+	if refunder.requests[0].Amount != 3448 {
+		t.Fatalf("expected 3448 cents, got %d", refunder.requests[0].Amount)
+	}
+}
+
+func TestRefundService_RefundOrder_PartialUsesCheckoutPrice(t *testing.T) {
+	// Los asientos se compraron a 2000 y después el precio pasó a 2550
+	order := paidOrder()
+	order.Lines = []models.OrderLine{
+		{Kind: models.LineTicket, ItemID: "s1", Quantity: 1, UnitAmount: 2000, Amount: 2000, Currency: "USD"},
+		{Kind: models.LineTicket, ItemID: "s2", Quantity: 1, UnitAmount: 2000, Amount: 2000, Currency: "USD"},
+		{Kind: models.LineTicket, ItemID: "s3", Quantity: 1, UnitAmount: 2000, Amount: 2000, Currency: "USD"},
+	}
+	order.Total = money.New(6000, "USD")
+	refunder := &fakeRefunder{}
+	svc := newRefundTestService(order, &mockRefundRepo{}, refunder, nil)
+
+	if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", []string{"s2"}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refunder.requests[0].Amount != 2000 {
+		t.Fatalf("expected the 2000 cents paid at checkout, got %d", refunder.requests[0].Amount)
+	}
+}
+
+func TestRefundService_RefundOrder_SkipsAlreadyRefundedSeats(t *testing.T) {
+	order := paidOrder()
+	order.Status = models.PaymentPartiallyRefunded
+	order.RefundedSeatIDs = []string{"s1"}
+	order.RefundedAmount = 2550
+
+	refunds := &mockRefundRepo{}
+	refunder := &fakeRefunder{}
+	svc := newRefundTestService(order, refunds, refunder, nil)
+
+	if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", []string{"s1"}, ""); !errors.Is(err, utils.ErrInvalidRefundSeats) {
+		t.Fatalf("expected ErrInvalidRefundSeats, got %v", err)
+	}
+
+	if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", nil, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refunder.requests[0].Amount != 5100 {
+		t.Fatalf("expected remaining balance 5100, got %d", refunder.requests[0].Amount)
+	}
+	if got := refunds.completed[0].SeatIDs; len(got) != 2 {
+		t.Fatalf("expected remaining 2 seats, got %v", got)
+	}
+}
+
+func TestRefundService_RefundOrder_Rejections(t *testing.T) {
+	t.Run("other user", func(t *testing.T) {
+		svc := newRefundTestService(paidOrder(), &mockRefundRepo{}, &fakeRefunder{}, nil)
+		if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u2", nil, ""); !errors.Is(err, utils.ErrOrderForbidden) {
+			t.Fatalf("expected ErrOrderForbidden, got %v", err)
+		}
+	})
+
+	t.Run("internal caller allowed", func(t *testing.T) {
+		svc := newRefundTestService(paidOrder(), &mockRefundRepo{}, &fakeRefunder{}, nil)
+		if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "internal", nil, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("pending order", func(t *testing.T) {
+		order := paidOrder()
+		order.Status = models.PaymentPending
+		svc := newRefundTestService(order, &mockRefundRepo{}, &fakeRefunder{}, nil)
+		if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", nil, ""); !errors.Is(err, utils.ErrInvalidOrderTransition) {
+			t.Fatalf("expected ErrInvalidOrderTransition, got %v", err)
+		}
+	})
+
+	t.Run("refund in progress", func(t *testing.T) {
+		refunder := &fakeRefunder{}
+		svc := newRefundTestService(paidOrder(), &mockRefundRepo{createErr: utils.ErrRefundInProgress}, refunder, nil)
+		if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", nil, ""); !errors.Is(err, utils.ErrRefundInProgress) {
+			t.Fatalf("expected ErrRefundInProgress, got %v", err)
+		}
+		if len(refunder.requests) != 0 {
+			t.Fatalf("expected provider not to be called")
+		}
+	})
+}
+
+func TestRefundService_RefundOrder_ProviderFailureMarksRefundFailed(t *testing.T) {
+	refunds := &mockRefundRepo{}
+	svc := newRefundTestService(paidOrder(), refunds, &fakeRefunder{err: errors.New("card_declined")}, nil)
+
+	_, refund, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", nil, "")
+	if !errors.Is(err, utils.ErrRefundFailed) {
+		t.Fatalf("expected ErrRefundFailed, got %v", err)
+	}
+	if refund == nil || refund.Status != models.RefundFailed {
+		t.Fatalf("expected failed refund, got %+v", refund)
+	}
+	if len(refunds.failed) != 1 || len(refunds.completed) != 0 {
+		t.Fatalf("expected refund marked failed and not completed: %+v", refunds)
+	}
+}
+
+func TestRefundService_ResumePending_CompletesWithStoredProviderRefund(t *testing.T) {
+	refunds := &mockRefundRepo{completeErr: errors.New("connection reset")}
+	refunder := &fakeRefunder{}
+	emails := &mockEmailServiceForPayment{}
+	svc := newRefundTestService(paidOrder(), refunds, refunder, emails)
+
+	if _, _, err := svc.RefundOrder(context.Background(), refundOrderID, "u1", []string{"s2"}, ""); err == nil {
+		t.Fatalf("expected completion error")
+	}
+	pending := refunds.created[0]
+	if refunds.recorded[pending.ID] != "re_1" || len(refunds.failed) != 0 {
+		t.Fatalf("expected provider refund stored on the pending refund, got %+v", refunds)
+	}
+
+	refunds.completeErr = nil
+	refunds.stale = []models.Refund{*pending}
+	completed, err := svc.ResumePending(context.Background())
+	if err != nil || completed != 1 {
+		t.Fatalf("expected one refund completed, got %d err=%v", completed, err)
+	}
+	if len(refunder.requests) != 1 {
+		t.Fatalf("expected no second provider refund, got %+v", refunder.requests)
+	}
+	c := refunds.completed[0]
+	if c.RefundID != pending.ID || c.ProviderRefundID != "re_1" || c.Amount != 2550 || len(c.SeatIDs) != 1 || c.ChangedBy != "u1" {
+		t.Fatalf("unexpected completion: %+v", c)
+	}
+	if len(emails.refundedTo) != 1 {
+		t.Fatalf("expected refund email once completed, got %v", emails.refundedTo)
+	}
+}
+
+func TestRefundService_ResumePending_ReissuesWithSameIdempotencyKey(t *testing.T) {
+	refunds := &mockRefundRepo{stale: []models.Refund{{
+		BaseModel:   models.BaseModel{ID: "33333333-3333-3333-3333-333333333333"},
+		OrderID:     refundOrderID,
+		SeatIDs:     []string{"s1"},
+		Amount:      2550,
+		Currency:    "USD",
+		Status:      models.RefundPending,
+		RequestedBy: "u1",
+	}}}
+	refunder := &fakeRefunder{}
+	svc := newRefundTestService(paidOrder(), refunds, refunder, nil)
+
+	if completed, err := svc.ResumePending(context.Background()); err != nil || completed != 1 {
+		t.Fatalf("expected one refund completed, got %d err=%v", completed, err)
+	}
+	if len(refunder.requests) != 1 || refunder.requests[0].IdempotencyKey != "refund-33333333-3333-3333-3333-333333333333" {
+		t.Fatalf("expected provider call with the original idempotency key, got %+v", refunder.requests)
+	}
+	if len(refunds.completed) != 1 || refunds.completed[0].ProviderRefundID != "re_1" {
+		t.Fatalf("unexpected completion: %+v", refunds.completed)
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"log"
+	"sort"
+	"strings"
+)
+
+// SeatRule es una regla que valida una selección de asientos antes de bloquearla
+type SeatRule interface {
+	Name() string
+	// Enabled indica si el evento tiene activada la regla
+	Enabled(event *models.Event) bool
+	// Violations devuelve los asientos que la selección deja en mal estado (vacío si es válida)
+	Violations(rows []seatRow, selected map[string]bool) []string
+}
+
+// Reglas disponibles por nombre (HOLD_SEAT_RULES)
+var seatRuleRegistry = map[string]SeatRule{
+	OrphanSeatRuleName: orphanSeatRule{},
+}
+
+// seatRulesFor arma el motor de reglas a partir de los nombres configurados
+func seatRulesFor(names []string) []SeatRule {
+	rules := make([]SeatRule, 0, len(names))
+	for _, name := range names {
+		rule, ok := seatRuleRegistry[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			log.Printf("Warning: unknown seat rule %q, skipping", name)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+const OrphanSeatRuleName = "orphan"
+
+// orphanSeatRule rechaza selecciones que dejan una butaca libre suelta entre ocupadas
+// (o entre una ocupada y el borde de la fila o un pasillo)
+type orphanSeatRule struct{}
+
+func (orphanSeatRule) Name() string { return OrphanSeatRuleName }
+
+func (orphanSeatRule) Enabled(event *models.Event) bool { return event.PreventOrphanSeats }
+
+func (orphanSeatRule) Violations(rows []seatRow, selected map[string]bool) []string {
+	var orphans []string
+	for _, row := range rows {
+		touched := false
+		for _, seat := range row.seats {
+			if selected[seat.ID] {
+				touched = true
+				break
+			}
+		}
+		if !touched {
+			continue
+		}
+
+		before := freeRuns(row.seats, func(seat models.Seat) bool { return seat.Status == models.StatusAvailable })
+		after := freeRuns(row.seats, func(seat models.Seat) bool {
+			return seat.Status == models.StatusAvailable && !selected[seat.ID]
+		})
+		// Solo cuentan los huecos que crea esta selección, no los que ya existían
+		for i, seat := range row.seats {
+			if after[i] == 1 && before[i] > 1 {
+				orphans = append(orphans, seat.ID)
+			}
+		}
+	}
+	return orphans
+}
+
+// freeRuns devuelve, para cada asiento libre, el largo del tramo de asientos libres contiguos
+// al que pertenece (0 si está ocupado). Los pasillos y los saltos de posición cortan el tramo
+func freeRuns(seats []models.Seat, free func(models.Seat) bool) []int {
+	runs := make([]int, len(seats))
+	start := -1
+	for i := 0; i <= len(seats); i++ {
+		if i < len(seats) && start >= 0 && free(seats[i]) && seatsAdjacent(seats[i-1], seats[i]) {
+			continue
+		}
+		if start >= 0 {
+			setRun(runs, start, i)
+			start = -1
+		}
+		if i < len(seats) && free(seats[i]) {
+			start = i
+		}
+	}
+	return runs
+}
+
+func setRun(runs []int, from, to int) {
+	for j := from; j < to; j++ {
+		runs[j] = to - from
+	}
+}
+
+// checkSeatRules valida la selección contra las reglas activas del evento. Si la selección
+// viola una regla pero no hay ninguna alternativa válida en la sección, se permite
+func (s *SeatService) checkSeatRules(eventID string, seats []models.Seat) error {
+	if len(s.rules) == 0 || len(seats) == 0 {
+		return nil
+	}
+
+	event, err := s.repoEvents.FindByID(eventID)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return nil
+	}
+	rules := rulesEnabledFor(s.rules, event)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	bySection := make(map[string][]models.Seat)
+	for _, seat := range seats {
+		key := strings.ToUpper(seat.Section)
+		bySection[key] = append(bySection[key], seat)
+	}
+
+	for _, rule := range rules {
+		for _, picked := range bySection {
+			rows := sectionRows(event.Seats, picked[0].Section)
+			selected := make(map[string]bool, len(picked))
+			for _, seat := range picked {
+				selected[seat.ID] = true
+			}
+
+			stranded := rule.Violations(rows, selected)
+			if len(stranded) == 0 {
+				continue
+			}
+			suggestion := suggestSeats(rule, rows, picked)
+			if suggestion == nil {
+				continue
+			}
+			return &utils.SeatRuleError{Rule: rule.Name(), SeatIDs: stranded, SuggestedSeatIDs: suggestion}
+		}
+	}
+	return nil
+}
+
+// enabledRules devuelve las reglas configuradas que el evento tiene activadas
+func (s *SeatService) enabledRules(eventID string) ([]SeatRule, error) {
+	if len(s.rules) == 0 {
+		return nil, nil
+	}
+	event, err := s.repoEvents.FindByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, nil
+	}
+	return rulesEnabledFor(s.rules, event), nil
+}
+
+func rulesEnabledFor(rules []SeatRule, event *models.Event) []SeatRule {
+	var enabled []SeatRule
+	for _, rule := range rules {
+		if rule.Enabled(event) {
+			enabled = append(enabled, rule)
+		}
+	}
+	return enabled
+}
+
+// rulesFilter acepta los bloques que no violan ninguna regla (nil si no hay reglas)
+func rulesFilter(rules []SeatRule, rows []seatRow) func([]models.Seat) bool {
+	if len(rules) == 0 {
+		return nil
+	}
+	return func(block []models.Seat) bool {
+		selected := make(map[string]bool, len(block))
+		for _, seat := range block {
+			selected[seat.ID] = true
+		}
+		for _, rule := range rules {
+			if len(rule.Violations(rows, selected)) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// suggestSeats busca un bloque de la misma cantidad de asientos juntos que cumpla la regla,
+// lo más cerca posible de lo elegido (misma fila primero). nil si no hay alternativa
+func suggestSeats(rule SeatRule, rows []seatRow, picked []models.Seat) []string {
+	quantity := len(picked)
+	// Fila y posición (ya normalizadas por sectionRows) de lo elegido
+	pickedIDs := make(map[string]bool, quantity)
+	for _, seat := range picked {
+		pickedIDs[seat.ID] = true
+	}
+	anchorRow, anchorPos := "", 0
+	for _, row := range rows {
+		for _, seat := range row.seats {
+			if pickedIDs[seat.ID] {
+				if anchorRow == "" {
+					anchorRow = row.label
+				}
+				anchorPos += seat.Position
+			}
+		}
+	}
+	anchorPos /= quantity
+
+	type candidate struct {
+		ids      []string
+		sameRow  bool
+		rowIndex int
+		distance int
+	}
+	var candidates []candidate
+	free := func(seat models.Seat) bool { return seat.Status == models.StatusAvailable }
+
+	for i, row := range rows {
+		for start := 0; start+quantity <= len(row.seats); start++ {
+			block := row.seats[start : start+quantity]
+			if !blockAvailable(block, free) {
+				continue
+			}
+			selected := make(map[string]bool, quantity)
+			ids := make([]string, 0, quantity)
+			for _, seat := range block {
+				selected[seat.ID] = true
+				ids = append(ids, seat.ID)
+			}
+			if len(rule.Violations(rows, selected)) > 0 {
+				continue
+			}
+			distance := (block[0].Position+block[quantity-1].Position)/2 - anchorPos
+			if distance < 0 {
+				distance = -distance
+			}
+			candidates = append(candidates, candidate{ids: ids, sameRow: row.label == anchorRow, rowIndex: i, distance: distance})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.sameRow != b.sameRow {
+			return a.sameRow
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		return a.rowIndex < b.rowIndex
+	})
+	return candidates[0].ids
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"booking-service/internal/config"
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOrphanSeatRule_Violations(t *testing.T) {
+	cases := []struct {
+		name   string
+		layout string
+		pick   []int
+		want   []string
+	}{
+		{"edge pick keeps the row sellable", "......", []int{1, 2}, nil},
+		{"middle pick strands a seat", "......", []int{2, 3}, []string{"PLATEA-A1"}},
+		{"strands seats on both sides", ".....", []int{2, 3, 4}, []string{"PLATEA-A1", "PLATEA-A5"}},
+		{"gap next to a sold seat", "x.....", []int{3, 4}, []string{"PLATEA-A2"}},
+		{"filling a gap exactly is fine", "x..x..", []int{2, 3}, nil},
+		{"existing single gaps are not the buyer's fault", ".x....", []int{3, 4}, nil},
+		{"aisle ends the run", "..|...", []int{2}, []string{"PLATEA-A1"}},
+		{"aisle protects the other side", "..|...", []int{3}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows := sectionRows(row("PLATEA", "A", tc.layout), "PLATEA")
+			selected := map[string]bool{}
+			for _, pos := range tc.pick {
+				selected[fmt.Sprintf("PLATEA-A%d", pos)] = true
+			}
+			got := orphanSeatRule{}.Violations(rows, selected)
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSeatRulesFor_SkipsUnknownRules(t *testing.T) {
+	rules := seatRulesFor([]string{" Orphan ", "nope"})
+	if len(rules) != 1 || rules[0].Name() != OrphanSeatRuleName {
+		t.Fatalf("unexpected rules: %v", rules)
+	}
+}
+
+// orphanRuleService arma un SeatService con la regla "orphan" sobre un inventario en memoria
+func orphanRuleService(inv *seatInventory, preventOrphans bool) *SeatService {
+	repo := inv.repo()
+	repo.findByIDFn = func(id string) (*models.Seat, error) {
+		inv.mu.Lock()
+		defer inv.mu.Unlock()
+		seat := *inv.seats[id]
+		return &seat, nil
+	}
+	repo.lockSeatFn = func(id, userId, holdID string, expiresAt time.Time) error {
+		return repo.lockSeatsFn([]string{id}, userId, holdID, expiresAt)
+	}
+	events := &mockEventRepoForSeat{findByIDFn: func(id string) (*models.Event, error) {
+		seats, _ := repo.findByEventIDFn(id)
+		return &models.Event{BaseModel: models.BaseModel{ID: id}, PreventOrphanSeats: preventOrphans, Seats: seats}, nil
+	}}
+	return NewSeatService(repo, events, nil, nil, nil, nil, nil, config.HoldPolicy{SeatRules: []string{"orphan"}})
+}
+
+func TestSeatService_LockSeats_OrphanRule(t *testing.T) {
+	t.Run("rejects and suggests an alternative", func(t *testing.T) {
+		inv := newSeatInventory(row("PLATEA", "A", "x......"))
+		svc := orphanRuleService(inv, true)
+
+		_, err := svc.LockSeats([]string{"PLATEA-A3", "PLATEA-A4"}, "u1")
+		var violation *utils.SeatRuleError
+		if !errors.As(err, &violation) || !errors.Is(err, utils.ErrSeatRuleViolation) {
+			t.Fatalf("expected seat rule error, got %v", err)
+		}
+		if violation.Rule != OrphanSeatRuleName || fmt.Sprint(violation.SeatIDs) != "[PLATEA-A2]" {
+			t.Fatalf("unexpected violation: %+v", violation)
+		}
+		if fmt.Sprint(violation.SuggestedSeatIDs) != "[PLATEA-A2 PLATEA-A3]" {
+			t.Fatalf("expected closest valid block as suggestion, got %v", violation.SuggestedSeatIDs)
+		}
+		if inv.seats["PLATEA-A3"].Status != models.StatusAvailable {
+			t.Fatalf("rejected selection must not lock seats")
+		}
+
+		if _, err := svc.LockSeats(violation.SuggestedSeatIDs, "u1"); err != nil {
+			t.Fatalf("suggestion should be accepted, got %v", err)
+		}
+	})
+
+	t.Run("disabled for the event", func(t *testing.T) {
+		inv := newSeatInventory(row("PLATEA", "A", "x......"))
+		if _, err := orphanRuleService(inv, false).LockSeats([]string{"PLATEA-A3", "PLATEA-A4"}, "u1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("allowed when there is no alternative", func(t *testing.T) {
+		inv := newSeatInventory(row("PLATEA", "A", "x...x"), row("VIP", "A", "...."))
+		if _, err := orphanRuleService(inv, true).LockSeats([]string{"PLATEA-A2", "PLATEA-A3"}, "u1"); err != nil {
+			t.Fatalf("expected selection allowed without alternatives, got %v", err)
+		}
+	})
+
+	t.Run("single seat hold", func(t *testing.T) {
+		inv := newSeatInventory(row("PLATEA", "A", "....."))
+		svc := orphanRuleService(inv, true)
+		_, err := svc.LockSeat("PLATEA-A2", "u1")
+		var violation *utils.SeatRuleError
+		if !errors.As(err, &violation) || fmt.Sprint(violation.SuggestedSeatIDs) != "[PLATEA-A1]" {
+			t.Fatalf("expected suggestion of the row edge, got %v", err)
+		}
+		if _, err := svc.LockSeat("PLATEA-A1", "u1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSeatService_FindBestAvailable_AvoidsOrphans(t *testing.T) {
+	inv := newSeatInventory(row("PLATEA", "A", "......"))
+
+	// Sin la regla elige el bloque centrado y deja A1 y A6 sueltas
+	_, seats, err := orphanRuleService(newSeatInventory(row("PLATEA", "A", "......")), false).FindBestAvailable("e1", "PLATEA", 4, true, "u1")
+	if err != nil || fmt.Sprint(seatIDs(seats)) != "[PLATEA-A2 PLATEA-A3 PLATEA-A4 PLATEA-A5]" {
+		t.Fatalf("unexpected selection without rule: %v %v", seatIDs(seats), err)
+	}
+
+	_, seats, err = orphanRuleService(inv, true).FindBestAvailable("e1", "PLATEA", 4, true, "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(seatIDs(seats)) != "[PLATEA-A1 PLATEA-A2 PLATEA-A3 PLATEA-A4]" {
+		t.Fatalf("expected block against the row edge, got %v", seatIDs(seats))
+	}
+}
@@ -1,32 +1,89 @@
 package services
 
 import (
+	"booking-service/internal/config"
 	"booking-service/internal/models"
 	"booking-service/internal/repositories"
 	"booking-service/pkg/utils"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type SeatService struct {
 	repo       repositories.SeatRepository
 	repoEvents repositories.EventRepository
+	repoTiers  repositories.PriceTierRepository
+	repoGA     repositories.GeneralAdmissionRepository
+	// Ventana de venta y accesos de preventa; sin repositorio no se controla la ventana
+	repoPresale repositories.PresaleRepository
+	// Órdenes PENDING del hold y sus sesiones de pago, que se cierran al liberarlo; opcionales
+	repoOrders repositories.BookingOrderRepository
+	sessions   SessionExpirer
+	holdPolicy config.HoldPolicy
+	rules      []SeatRule
 }
 
-func NewSeatService(repo repositories.SeatRepository, repoEvents repositories.EventRepository) *SeatService {
-	return &SeatService{repo: repo, repoEvents: repoEvents}
+func NewSeatService(repo repositories.SeatRepository, repoEvents repositories.EventRepository, repoTiers repositories.PriceTierRepository, repoGA repositories.GeneralAdmissionRepository, repoPresale repositories.PresaleRepository, repoOrders repositories.BookingOrderRepository, sessions SessionExpirer, holdPolicy config.HoldPolicy) *SeatService {
+	return &SeatService{repo: repo, repoEvents: repoEvents, repoTiers: repoTiers, repoGA: repoGA, repoPresale: repoPresale, repoOrders: repoOrders, sessions: sessions, holdPolicy: holdPolicy, rules: seatRulesFor(holdPolicy.SeatRules)}
 }
 
 func (s *SeatService) CreateSeat(seat *models.Seat) error {
-	if seat.Price < 0 {
+	if seat.Price.IsNegative() {
 		return errors.New("seat price cannot be negative")
 	}
 
+	// El asiento se cobra en la moneda del evento
+	event, err := s.repoEvents.FindByID(seat.EventID)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return utils.ErrEventNotFound
+	}
+	if seat.Price.Currency != "" && !strings.EqualFold(seat.Price.Currency, event.Price.Currency) {
+		return fmt.Errorf("%w: %s != %s", utils.ErrCurrencyMismatch, seat.Price.Currency, event.Price.Currency)
+	}
+	seat.Price.Currency = event.Price.Currency
+
+	if seat.TierID != nil {
+		if err := s.applyTier(seat); err != nil {
+			return err
+		}
+	}
+
 	return s.repo.Create(seat)
 }
 
+// El asiento toma sección y precio de su categoría, respetando la capacidad
+func (s *SeatService) applyTier(seat *models.Seat) error {
+	tier, err := s.repoTiers.FindByID(*seat.TierID)
+	if err != nil {
+		return err
+	}
+	if tier == nil || tier.EventID != seat.EventID {
+		return utils.ErrTierNotFound
+	}
+	if tier.Capacity > 0 {
+		seats, err := s.repoTiers.CountSeats(tier.ID)
+		if err != nil {
+			return err
+		}
+		if seats >= int64(tier.Capacity) {
+			return utils.ErrTierFull
+		}
+	}
+
+	seat.Section = tier.Name
+	seat.Price = tier.Price
+	return nil
+}
+
 func (s *SeatService) GetSeats() ([]models.Seat, error) {
 	return s.repo.FindAlls()
 }
@@ -71,18 +128,443 @@ func (s *SeatService) GetSeatByEventId(eventId string) ([]models.Seat, error) {
 	return s.repo.FindSeatByEventId(eventId)
 }
 
-// Bloquear asiento por 15 minutos
-func (s *SeatService) LockSeat(id string, userId string) error {
+// Bloquea un asiento según la HoldPolicy
+func (s *SeatService) LockSeat(id string, userId string) (*models.SeatHold, error) {
 	seat, err := s.repo.FindByID(id)
 	if err != nil {
-		return errors.New("seat not found")
+		return nil, errors.New("seat not found")
 	}
 
 	if seat.Status != models.StatusAvailable {
-		return errors.New("seat is not available")
+		return nil, errors.New("seat is not available")
+	}
+
+	now := time.Now()
+	if err := s.checkSalesWindow(seat.EventID, userId, now); err != nil {
+		return nil, err
+	}
+	if err := s.checkHoldLimit(seat.EventID, userId, 1, now); err != nil {
+		return nil, err
+	}
+	if err := s.checkSeatRules(seat.EventID, []models.Seat{*seat}); err != nil {
+		return nil, err
+	}
+
+	hold := &models.SeatHold{
+		ID:        uuid.NewString(),
+		UserID:    userId,
+		SeatIDs:   []string{id},
+		ExpiresAt: now.Add(s.holdPolicy.TTLFor(seat.EventID, seat.Section)),
+	}
+	if err := s.repo.LockSeat(id, userId, hold.ID, hold.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// Bloquea varios asientos a la vez (todo o nada) bajo un mismo hold.
+// La expiración es el TTL más corto entre los asientos pedidos
+func (s *SeatService) LockSeats(ids []string, userId string) (*models.SeatHold, error) {
+	return s.lockSeats("", ids, userId)
+}
+
+// LockEventSeats es LockSeats para quien ya validó el acceso a un evento (sala de espera,
+// límites de compra): rechaza los asientos que pertenecen a otro evento
+func (s *SeatService) LockEventSeats(eventID string, ids []string, userId string) (*models.SeatHold, error) {
+	return s.lockSeats(eventID, ids, userId)
+}
+
+func (s *SeatService) lockSeats(eventID string, ids []string, userId string) (*models.SeatHold, error) {
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	if len(unique) == 0 {
+		return nil, errors.New("no seats to lock")
+	}
+
+	seats, err := s.repo.FindByIDs(unique)
+	if err != nil && seats == nil {
+		return nil, err
+	}
+
+	found := make(map[string]struct{}, len(seats))
+	for _, seat := range seats {
+		found[seat.ID] = struct{}{}
+	}
+	var missing []string
+	for _, id := range unique {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &utils.SeatsUnavailableError{SeatIDs: missing}
+	}
+	if eventID != "" {
+		for _, seat := range seats {
+			if seat.EventID != eventID {
+				return nil, fmt.Errorf("%w: seat %s does not belong to event %s", utils.ErrInvalidSeatRequest, seat.ID, eventID)
+			}
+		}
+	}
+
+	now := time.Now()
+	var ttl time.Duration
+	perEvent := make(map[string]int)
+	for _, seat := range seats {
+		perEvent[seat.EventID]++
+		if t := s.holdPolicy.TTLFor(seat.EventID, seat.Section); ttl == 0 || t < ttl {
+			ttl = t
+		}
+	}
+	for eventID, n := range perEvent {
+		if err := s.checkSalesWindow(eventID, userId, now); err != nil {
+			return nil, err
+		}
+		if err := s.checkHoldLimit(eventID, userId, n, now); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.rules) > 0 {
+		byEvent := make(map[string][]models.Seat, len(perEvent))
+		for _, seat := range seats {
+			byEvent[seat.EventID] = append(byEvent[seat.EventID], seat)
+		}
+		for eventID, picked := range byEvent {
+			if err := s.checkSeatRules(eventID, picked); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	hold := &models.SeatHold{
+		ID:        uuid.NewString(),
+		UserID:    userId,
+		SeatIDs:   unique,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.repo.LockSeats(unique, userId, hold.ID, hold.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// HoldCart bloquea bajo un mismo hold los asientos y las entradas de admisión general de un
+// carrito (todo o nada). Sin entradas de admisión general es igual a LockSeats
+func (s *SeatService) HoldCart(seatIDs []string, gaItems []models.GAItem, userId string) (*models.SeatHold, error) {
+	for _, item := range gaItems {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity must be positive", utils.ErrInvalidSeatRequest)
+		}
+	}
+	gaItems = models.MergeGAItems(gaItems)
+	if len(gaItems) == 0 {
+		return s.LockSeats(seatIDs, userId)
+	}
+	if s.repoGA == nil {
+		return nil, utils.ErrGASectionNotFound
+	}
+
+	now := time.Now()
+	sections := make([]*models.GASection, 0, len(gaItems))
+	perEvent := make(map[string]int)
+	for i, item := range gaItems {
+		section, err := s.repoGA.FindSectionByID(item.SectionID)
+		if err != nil {
+			return nil, err
+		}
+		if section == nil {
+			return nil, fmt.Errorf("%w: %s", utils.ErrGASectionNotFound, item.SectionID)
+		}
+		gaItems[i].Section = section.Name
+		sections = append(sections, section)
+		perEvent[section.EventID] += item.Quantity
+	}
+	// La ventana de venta se controla antes de bloquear nada: no hay nada que deshacer
+	for eventID := range perEvent {
+		if err := s.checkSalesWindow(eventID, userId, now); err != nil {
+			return nil, err
+		}
+	}
+
+	var hold *models.SeatHold
+	if len(seatIDs) > 0 {
+		var err error
+		if hold, err = s.LockSeats(seatIDs, userId); err != nil {
+			return nil, err
+		}
+	} else {
+		hold = &models.SeatHold{ID: uuid.NewString(), UserID: userId, SeatIDs: []string{}}
+	}
+	// Si falla la parte de admisión general se devuelven los asientos recién bloqueados
+	rollback := func() {
+		if len(hold.SeatIDs) > 0 {
+			_, _ = s.repo.ReleaseHold(hold.ID, userId)
+		}
+	}
+
+	// El límite por usuario cuenta asientos y entradas juntos (los asientos ya quedaron bloqueados)
+	for eventID, n := range perEvent {
+		if err := s.checkHoldLimit(eventID, userId, n, now); err != nil {
+			rollback()
+			return nil, err
+		}
+	}
+
+	// Con asientos, las entradas vencen junto con ellos; si no, con el menor TTL de los sectores
+	if hold.ExpiresAt.IsZero() {
+		for _, section := range sections {
+			expiresAt := now.Add(s.holdPolicy.TTLFor(section.EventID, section.Name))
+			if hold.ExpiresAt.IsZero() || expiresAt.Before(hold.ExpiresAt) {
+				hold.ExpiresAt = expiresAt
+			}
+		}
+	}
+
+	if _, err := s.repoGA.Hold(hold.ID, userId, gaItems, hold.ExpiresAt); err != nil {
+		rollback()
+		return nil, err
+	}
+
+	hold.GAItems = gaItems
+	return hold, nil
+}
+
+// checkSalesWindow valida que el evento esté a la venta para el usuario: antes de SalesStart
+// solo compran quienes canjearon un código de preventa, y después de SalesEnd nadie
+func (s *SeatService) checkSalesWindow(eventID, userId string, now time.Time) error {
+	if s.repoPresale == nil {
+		return nil
+	}
+	return checkSalesWindow(s.repoPresale, eventID, userId, now)
+}
+
+// checkHoldLimit valida que el usuario no supere el máximo de asientos (y entradas de admisión
+// general) bloqueados por evento
+func (s *SeatService) checkHoldLimit(eventID, userId string, requested int, now time.Time) error {
+	max := s.holdPolicy.MaxSeatsPerUser
+	if max <= 0 {
+		return nil
+	}
+
+	held, err := s.repo.CountActiveLocksByUser(eventID, userId, now)
+	if err != nil {
+		return err
+	}
+	if s.repoGA != nil {
+		tickets, err := s.repoGA.CountActiveByUser(eventID, userId, now)
+		if err != nil {
+			return err
+		}
+		held += tickets
+	}
+
+	if int(held)+requested > max {
+		return fmt.Errorf("%w: max %d seats per event, %d already held", utils.ErrHoldLimitExceeded, max, held)
+	}
+	return nil
+}
+
+// findOwnedHold carga los asientos y entradas de admisión general del hold y valida que
+// pertenezcan al usuario
+func (s *SeatService) findOwnedHold(holdID, userId string) ([]models.Seat, []models.GAHold, error) {
+	seats, err := s.repo.FindByHoldID(holdID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var gaHolds []models.GAHold
+	if s.repoGA != nil {
+		if gaHolds, err = s.repoGA.FindActiveHolds(holdID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(seats) == 0 && len(gaHolds) == 0 {
+		return nil, nil, utils.ErrHoldNotFound
+	}
+
+	for _, seat := range seats {
+		if seat.LockedBy == nil || *seat.LockedBy != userId {
+			return nil, nil, utils.ErrHoldForbidden
+		}
+	}
+	for _, h := range gaHolds {
+		if h.UserID != userId {
+			return nil, nil, utils.ErrHoldForbidden
+		}
+	}
+	return seats, gaHolds, nil
+}
+
+// ExtendHold extiende la expiración de un hold vigente del usuario
+func (s *SeatService) ExtendHold(holdID, userId string) (*models.SeatHold, error) {
+	seats, gaHolds, err := s.findOwnedHold(holdID, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	current := now
+	extensions := 0
+	for _, seat := range seats {
+		if seat.LockExpiresAt == nil || !seat.LockExpiresAt.After(now) {
+			return nil, utils.ErrHoldNotFound
+		}
+		if seat.LockExpiresAt.After(current) {
+			current = *seat.LockExpiresAt
+		}
+		if seat.HoldExtensions > extensions {
+			extensions = seat.HoldExtensions
+		}
+	}
+	for _, h := range gaHolds {
+		if !h.ExpiresAt.After(now) {
+			return nil, utils.ErrHoldNotFound
+		}
+		if h.ExpiresAt.After(current) {
+			current = h.ExpiresAt
+		}
+		if h.Extensions > extensions {
+			extensions = h.Extensions
+		}
+	}
+
+	if extensions >= s.holdPolicy.MaxExtensions {
+		return nil, utils.ErrHoldExtensionLimit
 	}
 
-	// Bloquear por 10 minutos
-	expiresAt := time.Now().Add(10 * time.Minute)
-	return s.repo.LockSeat(id, userId, expiresAt)
+	extension := s.holdPolicy.ExtensionTTL
+	if extension <= 0 {
+		if len(seats) > 0 {
+			extension = s.holdPolicy.TTLFor(seats[0].EventID, seats[0].Section)
+		} else {
+			extension = s.holdPolicy.TTLFor(gaHolds[0].EventID, "")
+		}
+	}
+	expiresAt := current.Add(extension)
+
+	var updated int64
+	if len(seats) > 0 {
+		if updated, err = s.repo.ExtendHold(holdID, userId, expiresAt, s.holdPolicy.MaxExtensions, now); err != nil {
+			return nil, err
+		}
+	}
+	if len(gaHolds) > 0 {
+		gaUpdated, err := s.repoGA.ExtendHold(holdID, userId, expiresAt, s.holdPolicy.MaxExtensions, now)
+		if err != nil {
+			return nil, err
+		}
+		updated += gaUpdated
+	}
+	if updated == 0 {
+		return nil, utils.ErrHoldExtensionLimit
+	}
+
+	seatIDs := make([]string, 0, len(seats))
+	for _, seat := range seats {
+		seatIDs = append(seatIDs, seat.ID)
+	}
+	var gaItems []models.GAItem
+	for _, h := range gaHolds {
+		gaItems = append(gaItems, models.GAItem{SectionID: h.SectionID, Quantity: h.Quantity})
+	}
+
+	return &models.SeatHold{
+		ID:         holdID,
+		UserID:     userId,
+		SeatIDs:    seatIDs,
+		GAItems:    models.MergeGAItems(gaItems),
+		ExpiresAt:  expiresAt,
+		Extensions: extensions + 1,
+	}, nil
+}
+
+// ReleaseHold devuelve al inventario todos los asientos y entradas de admisión general del hold
+// del usuario. Igual que el lock reaper, cancela la orden PENDING del hold (recuperando el uso
+// del código promocional), cierra su sesión de pago y recalcula la disponibilidad del evento
+func (s *SeatService) ReleaseHold(holdID, userId string) error {
+	seats, gaHolds, err := s.findOwnedHold(holdID, userId)
+	if err != nil {
+		return err
+	}
+
+	// La orden se cancela antes de liberar el inventario: un pago que llegue después se
+	// reembolsa en vez de vender asientos que ya no están reservados
+	cancelled, err := s.cancelHoldOrders(holdID, userId, seats)
+	if err != nil {
+		return err
+	}
+
+	if len(seats) > 0 {
+		if _, err := s.repo.ReleaseHold(holdID, userId); err != nil {
+			return err
+		}
+	}
+	if len(gaHolds) > 0 {
+		if _, err := s.repoGA.ReleaseHold(holdID, userId); err != nil {
+			return err
+		}
+	}
+
+	expireSessions(s.sessions, cancelled)
+
+	// Los holds de admisión general ya recalculan la disponibilidad en el repositorio
+	eventIDs := make(map[string]struct{})
+	for _, seat := range seats {
+		eventIDs[seat.EventID] = struct{}{}
+	}
+	for eventID := range eventIDs {
+		if err := s.repoEvents.UpdateAvailability(eventID); err != nil {
+			log.Printf("⚠️ No se pudo actualizar disponibilidad del evento %s: %v", eventID, err)
+		}
+	}
+	return nil
+}
+
+// cancelHoldOrders cancela las órdenes PENDING del usuario creadas sobre el hold
+func (s *SeatService) cancelHoldOrders(holdID, userId string, seats []models.Seat) ([]models.BookingOrder, error) {
+	if s.repoOrders == nil {
+		return nil, nil
+	}
+
+	orders, err := s.repoOrders.FindPendingByUserIDs([]string{userId})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending orders: %w", err)
+	}
+
+	heldSeats := make(map[string]struct{}, len(seats))
+	for _, seat := range seats {
+		heldSeats[seat.ID] = struct{}{}
+	}
+
+	var toCancel []string
+	for _, o := range orders {
+		if o.HoldID != nil && *o.HoldID == holdID {
+			toCancel = append(toCancel, o.ID)
+			continue
+		}
+		for _, seatID := range o.SeatIDs {
+			if _, ok := heldSeats[seatID]; ok {
+				toCancel = append(toCancel, o.ID)
+				break
+			}
+		}
+	}
+
+	cancelled, err := s.repoOrders.CancelPending(toCancel, userId, "hold released by user")
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel pending orders: %w", err)
+	}
+	return cancelled, nil
 }
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"booking-service/internal/config"
 	"booking-service/internal/models"
+	"booking-service/pkg/money"
 	"booking-service/pkg/utils"
 	"errors"
 	"testing"
@@ -15,10 +17,18 @@ type mockSeatRepo struct {
 	findAllFn         func() ([]models.Seat, error)
 	findByIDFn        func(string) (*models.Seat, error)
 	updateStatusFn    func(string, models.SeatStatus) error
-	lockSeatFn        func(string, string, time.Time) error
+	lockSeatFn        func(string, string, string, time.Time) error
+	lockSeatsFn       func([]string, string, string, time.Time) error
+	findByHoldIDFn    func(string) ([]models.Seat, error)
+	extendHoldFn      func(string, string, time.Time, int, time.Time) (int64, error)
+	releaseHoldFn     func(string, string) (int64, error)
 	unlockIfExpiredFn func(string, time.Time) error
+	findExpiredFn     func(time.Time, int) ([]models.Seat, error)
+	releaseExpiredFn  func([]string, time.Time) ([]models.Seat, error)
+	countLocksFn      func(string, string, time.Time) (int64, error)
 	findByEventIDFn   func(string) ([]models.Seat, error)
 	findByIDsFn       func([]string) ([]models.Seat, error)
+	createForEventFn  func(string, string, []models.Seat) error
 }
 
 func (m *mockSeatRepo) Create(seat *models.Seat) error { return m.createFn(seat) }
@@ -27,15 +37,38 @@ func (m *mockSeatRepo) FindByID(id string) (*models.Seat, error) { return m.find
 func (m *mockSeatRepo) UpdateStatus(id string, status models.SeatStatus) error {
 	return m.updateStatusFn(id, status)
 }
-func (m *mockSeatRepo) LockSeat(id, userId string, expiresAt time.Time) error {
-	return m.lockSeatFn(id, userId, expiresAt)
+func (m *mockSeatRepo) LockSeat(id, userId, holdID string, expiresAt time.Time) error {
+	return m.lockSeatFn(id, userId, holdID, expiresAt)
+}
+func (m *mockSeatRepo) LockSeats(ids []string, userId, holdID string, expiresAt time.Time) error {
+	return m.lockSeatsFn(ids, userId, holdID, expiresAt)
+}
+func (m *mockSeatRepo) FindByHoldID(holdID string) ([]models.Seat, error) { return m.findByHoldIDFn(holdID) }
+func (m *mockSeatRepo) ExtendHold(holdID, userId string, expiresAt time.Time, max int, now time.Time) (int64, error) {
+	return m.extendHoldFn(holdID, userId, expiresAt, max, now)
+}
+func (m *mockSeatRepo) ReleaseHold(holdID, userId string) (int64, error) {
+	return m.releaseHoldFn(holdID, userId)
 }
 func (m *mockSeatRepo) UnlockIfExpired(id string, now time.Time) error { return m.unlockIfExpiredFn(id, now) }
+func (m *mockSeatRepo) FindExpiredLocks(now time.Time, limit int) ([]models.Seat, error) {
+	return m.findExpiredFn(now, limit)
+}
+func (m *mockSeatRepo) ReleaseExpiredLocks(ids []string, now time.Time) ([]models.Seat, error) {
+	return m.releaseExpiredFn(ids, now)
+}
+func (m *mockSeatRepo) CountActiveLocksByUser(eventID, userId string, now time.Time) (int64, error) {
+	return m.countLocksFn(eventID, userId, now)
+}
 func (m *mockSeatRepo) FindSeatByEventId(id string) ([]models.Seat, error) { return m.findByEventIDFn(id) }
 func (m *mockSeatRepo) FindByIDs(ids []string) ([]models.Seat, error) { return m.findByIDsFn(ids) }
+func (m *mockSeatRepo) CreateForEvent(eventID, venueID string, seats []models.Seat) error {
+	return m.createForEventFn(eventID, venueID, seats)
+}
 
 type mockEventRepoForSeat struct {
-	findByIDFn func(string) (*models.Event, error)
+	findByIDFn           func(string) (*models.Event, error)
+	updateAvailabilityFn func(string) error
 }
 
 func (m *mockEventRepoForSeat) Create(*models.Event) error { panic("not used") }
@@ -43,15 +76,53 @@ func (m *mockEventRepoForSeat) FindByID(id string) (*models.Event, error) { retu
 func (m *mockEventRepoForSeat) FindAll(models.EventFilter) ([]models.Event, error) { panic("not used") }
 func (m *mockEventRepoForSeat) Update(*models.Event) error { panic("not used") }
 func (m *mockEventRepoForSeat) Delete(string) error { panic("not used") }
-func (m *mockEventRepoForSeat) UpdateAvailability(string) error { panic("not used") }
+func (m *mockEventRepoForSeat) UpdateAvailability(eventID string) error {
+	if m.updateAvailabilityFn == nil {
+		return nil
+	}
+	return m.updateAvailabilityFn(eventID)
+}
 
 func TestSeatService_CreateSeat_RejectsNegativePrice(t *testing.T) {
-	svc := NewSeatService(&mockSeatRepo{}, &mockEventRepoForSeat{})
-	if err := svc.CreateSeat(&models.Seat{Price: -1}); err == nil {
+	svc := NewSeatService(&mockSeatRepo{}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, config.HoldPolicy{})
+	if err := svc.CreateSeat(&models.Seat{Price: money.New(-1, "ARS")}); err == nil {
 		t.Fatalf("expected validation error")
 	}
 }
 
+func TestSeatService_CreateSeat_UsesEventCurrency(t *testing.T) {
+	var created *models.Seat
+	svc := NewSeatService(
+		&mockSeatRepo{createFn: func(s *models.Seat) error { created = s; return nil }},
+		&mockEventRepoForSeat{findByIDFn: func(id string) (*models.Event, error) {
+			if id != "e1" {
+				return nil, nil
+			}
+			return &models.Event{Price: money.New(100000, "ARS")}, nil
+		}},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		config.HoldPolicy{},
+	)
+
+	if err := svc.CreateSeat(&models.Seat{EventID: "e1", Price: money.New(2550, "")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Price != money.New(2550, "ARS") {
+		t.Fatalf("expected event currency, got %v", created.Price)
+	}
+
+	if err := svc.CreateSeat(&models.Seat{EventID: "e1", Price: money.New(2550, "USD")}); !errors.Is(err, utils.ErrCurrencyMismatch) {
+		t.Fatalf("expected currency mismatch, got %v", err)
+	}
+	if err := svc.CreateSeat(&models.Seat{EventID: "missing"}); !errors.Is(err, utils.ErrEventNotFound) {
+		t.Fatalf("expected event not found, got %v", err)
+	}
+}
+
 func TestSeatService_GetSeat_MapsRecordNotFound(t *testing.T) {
 	svc := NewSeatService(
 		&mockSeatRepo{
@@ -59,6 +130,12 @@ func TestSeatService_GetSeat_MapsRecordNotFound(t *testing.T) {
 			findByIDFn:        func(string) (*models.Seat, error) { return nil, gorm.ErrRecordNotFound },
 		},
 		&mockEventRepoForSeat{findByIDFn: func(string) (*models.Event, error) { return nil, nil }},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		config.HoldPolicy{},
 	)
 
 	_, err := svc.GetSeat("s1")
@@ -71,6 +148,12 @@ func TestSeatService_UpdateSeatStatus_ValidationAndNotFound(t *testing.T) {
 	svc := NewSeatService(
 		&mockSeatRepo{updateStatusFn: func(string, models.SeatStatus) error { return gorm.ErrRecordNotFound }},
 		&mockEventRepoForSeat{},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		config.HoldPolicy{},
 	)
 
 	if err := svc.UpdateSeatStatus("s1", models.SeatStatus("BAD")); err == nil {
@@ -90,32 +173,376 @@ func TestSeatService_LockSeat(t *testing.T) {
 				findByIDFn: func(string) (*models.Seat, error) { return &models.Seat{Status: models.StatusSold}, nil },
 			},
 			&mockEventRepoForSeat{},
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			config.HoldPolicy{},
 		)
-		if err := svc.LockSeat("s1", "u1"); err == nil {
+		if _, err := svc.LockSeat("s1", "u1"); err == nil {
 			t.Fatalf("expected error for non-available seat")
 		}
 	})
 
-	t.Run("available", func(t *testing.T) {
+	t.Run("available uses section ttl", func(t *testing.T) {
 		called := false
 		svc := NewSeatService(
 			&mockSeatRepo{
-				findByIDFn: func(string) (*models.Seat, error) { return &models.Seat{Status: models.StatusAvailable}, nil },
-				lockSeatFn: func(id, user string, expires time.Time) error {
+				findByIDFn: func(string) (*models.Seat, error) {
+					return &models.Seat{Status: models.StatusAvailable, EventID: "e1", Section: "VIP"}, nil
+				},
+				countLocksFn: func(string, string, time.Time) (int64, error) { return 0, nil },
+				lockSeatFn: func(id, user, holdID string, expires time.Time) error {
 					called = true
-					if id != "s1" || user != "u1" || time.Until(expires) <= 0 {
+					if id != "s1" || user != "u1" || holdID == "" || time.Until(expires) <= 0 {
 						t.Fatalf("unexpected lock args: %s %s %v", id, user, expires)
 					}
 					return nil
 				},
 			},
 			&mockEventRepoForSeat{},
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			config.HoldPolicy{
+				DefaultTTL:      10 * time.Minute,
+				SectionTTL:      map[string]time.Duration{"VIP": 20 * time.Minute},
+				MaxSeatsPerUser: 4,
+			},
 		)
-		if err := svc.LockSeat("s1", "u1"); err != nil {
+		hold, err := svc.LockSeat("s1", "u1")
+		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		if !called {
 			t.Fatalf("expected LockSeat repo call")
 		}
+		if ttl := time.Until(hold.ExpiresAt); ttl < 19*time.Minute || ttl > 20*time.Minute {
+			t.Fatalf("expected ~20m section ttl, got %v", ttl)
+		}
+	})
+
+	t.Run("hold limit exceeded", func(t *testing.T) {
+		svc := NewSeatService(
+			&mockSeatRepo{
+				findByIDFn: func(string) (*models.Seat, error) {
+					return &models.Seat{Status: models.StatusAvailable, EventID: "e1"}, nil
+				},
+				countLocksFn: func(string, string, time.Time) (int64, error) { return 2, nil },
+			},
+			&mockEventRepoForSeat{},
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			config.HoldPolicy{MaxSeatsPerUser: 2},
+		)
+		if _, err := svc.LockSeat("s1", "u1"); !errors.Is(err, utils.ErrHoldLimitExceeded) {
+			t.Fatalf("expected ErrHoldLimitExceeded, got %v", err)
+		}
+	})
+}
+
+func TestSeatService_LockSeats(t *testing.T) {
+	seatsByID := func(ids []string) ([]models.Seat, error) {
+		out := make([]models.Seat, 0, len(ids))
+		for _, id := range ids {
+			if id == "missing" {
+				continue
+			}
+			section := "GENERAL"
+			if id == "s2" {
+				section = "VIP"
+			}
+			out = append(out, models.Seat{BaseModel: models.BaseModel{ID: id}, EventID: "e1", Section: section, Status: models.StatusAvailable})
+		}
+		if len(out) != len(ids) {
+			return out, errors.New("some seats were not found")
+		}
+		return out, nil
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		svc := NewSeatService(&mockSeatRepo{}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, config.HoldPolicy{})
+		if _, err := svc.LockSeats([]string{"", ""}, "u1"); err == nil {
+			t.Fatalf("expected error for empty seat list")
+		}
+	})
+
+	t.Run("dedupes ids and uses shortest ttl", func(t *testing.T) {
+		var got []string
+		svc := NewSeatService(
+			&mockSeatRepo{
+				findByIDsFn: seatsByID,
+				lockSeatsFn: func(ids []string, user, holdID string, expires time.Time) error {
+					got = ids
+					return nil
+				},
+			},
+			&mockEventRepoForSeat{},
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			config.HoldPolicy{
+				DefaultTTL: 15 * time.Minute,
+				SectionTTL: map[string]time.Duration{"VIP": 5 * time.Minute},
+			},
+		)
+		hold, err := svc.LockSeats([]string{"s1", "s2", "s1"}, "u1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0] != "s1" || got[1] != "s2" {
+			t.Fatalf("expected deduped ids, got %v", got)
+		}
+		if hold.ID == "" || len(hold.SeatIDs) != 2 {
+			t.Fatalf("expected hold with id and 2 seats, got %+v", hold)
+		}
+		if ttl := time.Until(hold.ExpiresAt); ttl <= 0 || ttl > 5*time.Minute {
+			t.Fatalf("expected shortest (VIP) ttl, got %v", ttl)
+		}
 	})
+
+	t.Run("reports missing seats", func(t *testing.T) {
+		svc := NewSeatService(&mockSeatRepo{findByIDsFn: seatsByID}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, config.HoldPolicy{})
+		_, err := svc.LockSeats([]string{"s1", "missing"}, "u1")
+		var unavailable *utils.SeatsUnavailableError
+		if !errors.As(err, &unavailable) || len(unavailable.SeatIDs) != 1 || unavailable.SeatIDs[0] != "missing" {
+			t.Fatalf("expected SeatsUnavailableError for missing seat, got %v", err)
+		}
+	})
+
+	t.Run("rejects seats of another event", func(t *testing.T) {
+		locked := false
+		svc := NewSeatService(
+			&mockSeatRepo{
+				findByIDsFn: seatsByID,
+				lockSeatsFn: func([]string, string, string, time.Time) error {
+					locked = true
+					return nil
+				},
+			},
+			&mockEventRepoForSeat{}, nil, nil, nil, nil, nil, config.HoldPolicy{},
+		)
+		if _, err := svc.LockEventSeats("e2", []string{"s1"}, "u1"); !errors.Is(err, utils.ErrInvalidSeatRequest) || locked {
+			t.Fatalf("expected ErrInvalidSeatRequest without locking, got %v", err)
+		}
+		if _, err := svc.LockEventSeats("e1", []string{"s1"}, "u1"); err != nil || !locked {
+			t.Fatalf("expected seats of the event locked, got %v", err)
+		}
+	})
+
+	t.Run("hold limit counts whole cart", func(t *testing.T) {
+		svc := NewSeatService(
+			&mockSeatRepo{
+				findByIDsFn:  seatsByID,
+				countLocksFn: func(string, string, time.Time) (int64, error) { return 1, nil },
+			},
+			&mockEventRepoForSeat{},
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			config.HoldPolicy{MaxSeatsPerUser: 2},
+		)
+		if _, err := svc.LockSeats([]string{"s1", "s2"}, "u1"); !errors.Is(err, utils.ErrHoldLimitExceeded) {
+			t.Fatalf("expected ErrHoldLimitExceeded, got %v", err)
+		}
+	})
+
+	t.Run("propagates unavailable seats", func(t *testing.T) {
+		svc := NewSeatService(
+			&mockSeatRepo{
+				findByIDsFn: seatsByID,
+				lockSeatsFn: func([]string, string, string, time.Time) error {
+					return &utils.SeatsUnavailableError{SeatIDs: []string{"s2"}}
+				},
+			},
+			&mockEventRepoForSeat{},
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			config.HoldPolicy{},
+		)
+		_, err := svc.LockSeats([]string{"s1", "s2"}, "u1")
+		var unavailable *utils.SeatsUnavailableError
+		if !errors.As(err, &unavailable) || len(unavailable.SeatIDs) != 1 || unavailable.SeatIDs[0] != "s2" {
+			t.Fatalf("expected SeatsUnavailableError for s2, got %v", err)
+		}
+		if !errors.Is(err, utils.ErrSeatsUnavailable) {
+			t.Fatalf("expected error to wrap ErrSeatsUnavailable")
+		}
+	})
+}
+
+func TestSeatService_ExtendHold(t *testing.T) {
+	u1 := "u1"
+	other := "u2"
+	future := time.Now().Add(2 * time.Minute)
+	past := time.Now().Add(-time.Minute)
+	policy := config.HoldPolicy{MaxExtensions: 1, ExtensionTTL: 5 * time.Minute}
+
+	t.Run("not found", func(t *testing.T) {
+		svc := NewSeatService(&mockSeatRepo{findByHoldIDFn: func(string) ([]models.Seat, error) { return nil, nil }}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, policy)
+		if _, err := svc.ExtendHold("h1", "u1"); !errors.Is(err, utils.ErrHoldNotFound) {
+			t.Fatalf("expected ErrHoldNotFound, got %v", err)
+		}
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		svc := NewSeatService(&mockSeatRepo{findByHoldIDFn: func(string) ([]models.Seat, error) {
+			return []models.Seat{{LockedBy: &other, LockExpiresAt: &future}}, nil
+		}}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, policy)
+		if _, err := svc.ExtendHold("h1", "u1"); !errors.Is(err, utils.ErrHoldForbidden) {
+			t.Fatalf("expected ErrHoldForbidden, got %v", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		svc := NewSeatService(&mockSeatRepo{findByHoldIDFn: func(string) ([]models.Seat, error) {
+			return []models.Seat{{LockedBy: &u1, LockExpiresAt: &past}}, nil
+		}}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, policy)
+		if _, err := svc.ExtendHold("h1", "u1"); !errors.Is(err, utils.ErrHoldNotFound) {
+			t.Fatalf("expected ErrHoldNotFound for expired hold, got %v", err)
+		}
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		svc := NewSeatService(&mockSeatRepo{findByHoldIDFn: func(string) ([]models.Seat, error) {
+			return []models.Seat{{LockedBy: &u1, LockExpiresAt: &future, HoldExtensions: 1}}, nil
+		}}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, policy)
+		if _, err := svc.ExtendHold("h1", "u1"); !errors.Is(err, utils.ErrHoldExtensionLimit) {
+			t.Fatalf("expected ErrHoldExtensionLimit, got %v", err)
+		}
+	})
+
+	t.Run("extends from current expiry", func(t *testing.T) {
+		var gotExpiry time.Time
+		svc := NewSeatService(&mockSeatRepo{
+			findByHoldIDFn: func(string) ([]models.Seat, error) {
+				return []models.Seat{{BaseModel: models.BaseModel{ID: "s1"}, LockedBy: &u1, LockExpiresAt: &future}}, nil
+			},
+			extendHoldFn: func(holdID, user string, expiresAt time.Time, max int, now time.Time) (int64, error) {
+				gotExpiry = expiresAt
+				return 1, nil
+			},
+		}, &mockEventRepoForSeat{}, nil, nil, nil, nil, nil, policy)
+
+		hold, err := svc.ExtendHold("h1", "u1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !gotExpiry.Equal(future.Add(5*time.Minute)) || !hold.ExpiresAt.Equal(gotExpiry) {
+			t.Fatalf("expected expiry extended by 5m, got %v", gotExpiry)
+		}
+		if hold.Extensions != 1 || len(hold.SeatIDs) != 1 {
+			t.Fatalf("unexpected hold: %+v", hold)
+		}
+	})
+}
+
+func TestSeatService_ReleaseHold(t *testing.T) {
+	u1 := "u1"
+	h1 := "h1"
+	released := false
+	var cancelled []string
+	var updated []string
+	orders := &mockBookingOrderRepo{
+		findPendingByUserIDsFn: func(ids []string) ([]models.BookingOrder, error) {
+			if len(ids) != 1 || ids[0] != "u1" {
+				t.Fatalf("unexpected users: %v", ids)
+			}
+			return []models.BookingOrder{
+				{BaseModel: models.BaseModel{ID: "o1"}, HoldID: &h1},
+				{BaseModel: models.BaseModel{ID: "o2"}, SeatIDs: []string{"other"}},
+			}, nil
+		},
+		cancelPendingFn: func(ids []string, changedBy, _ string) ([]models.BookingOrder, error) {
+			if released {
+				t.Fatalf("order must be cancelled before releasing the seats")
+			}
+			cancelled = append(cancelled, ids...)
+			return []models.BookingOrder{{BaseModel: models.BaseModel{ID: "o1"}, PaymentProvider: FakeProviderName, PaymentSessionID: "cs_1"}}, nil
+		},
+	}
+	fake := NewFakePaymentProvider()
+	svc := NewSeatService(&mockSeatRepo{
+		findByHoldIDFn: func(string) ([]models.Seat, error) {
+			return []models.Seat{{BaseModel: models.BaseModel{ID: "s1"}, EventID: "e1", LockedBy: &u1}}, nil
+		},
+		releaseHoldFn: func(holdID, user string) (int64, error) {
+			released = holdID == "h1" && user == "u1"
+			return 1, nil
+		},
+	}, &mockEventRepoForSeat{updateAvailabilityFn: func(id string) error { updated = append(updated, id); return nil }}, nil, nil, nil, orders, NewPaymentProviders(fake), config.HoldPolicy{})
+
+	if err := svc.ReleaseHold("h1", "u1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !released {
+		t.Fatalf("expected ReleaseHold repo call")
+	}
+	if len(cancelled) != 1 || cancelled[0] != "o1" {
+		t.Fatalf("expected the hold's order cancelled, got %v", cancelled)
+	}
+	if len(fake.Expired) != 1 || fake.Expired[0] != "cs_1" {
+		t.Fatalf("expected payment session expired, got %v", fake.Expired)
+	}
+	if len(updated) != 1 || updated[0] != "e1" {
+		t.Fatalf("expected availability updated, got %v", updated)
+	}
+
+	if err := svc.ReleaseHold("h1", "u2"); !errors.Is(err, utils.ErrHoldForbidden) {
+		t.Fatalf("expected ErrHoldForbidden for other user, got %v", err)
+	}
+}
+
+func TestSeatService_CreateSeat_UsesTier(t *testing.T) {
+	var created *models.Seat
+	seats := int64(0)
+	tiers := &mockPriceTierRepo{
+		findByIDFn: func(id string) (*models.PriceTier, error) {
+			if id != "t1" {
+				return nil, nil
+			}
+			return &models.PriceTier{BaseModel: models.BaseModel{ID: "t1"}, EventID: "e1", Name: "VIP", Price: money.New(3000000, "ARS"), Capacity: 1}, nil
+		},
+		countSeatsFn: func(string) (int64, error) { return seats, nil },
+	}
+	svc := NewSeatService(
+		&mockSeatRepo{createFn: func(s *models.Seat) error { created = s; return nil }},
+		eventsWithARS(),
+		tiers,
+		nil,
+		nil,
+		nil,
+		nil,
+		config.HoldPolicy{},
+	)
+
+	tierID := "t1"
+	if err := svc.CreateSeat(&models.Seat{EventID: "e1", Number: "1", Section: "Otra", TierID: &tierID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Section != "VIP" || created.Price != money.New(3000000, "ARS") {
+		t.Fatalf("expected tier section and price, got %s %v", created.Section, created.Price)
+	}
+
+	seats = 1
+	if err := svc.CreateSeat(&models.Seat{EventID: "e1", TierID: &tierID}); !errors.Is(err, utils.ErrTierFull) {
+		t.Fatalf("expected tier full, got %v", err)
+	}
+	missing := "t2"
+	if err := svc.CreateSeat(&models.Seat{EventID: "e1", TierID: &missing}); !errors.Is(err, utils.ErrTierNotFound) {
+		t.Fatalf("expected tier not found, got %v", err)
+	}
 }
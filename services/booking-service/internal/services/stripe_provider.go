@@ -0,0 +1,298 @@
+package services
+
+import (
+	"booking-service/internal/messaging"
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v76"
+	checkoutsession "github.com/stripe/stripe-go/v76/checkout/session"
+	"github.com/stripe/stripe-go/v76/paymentintent"
+	"github.com/stripe/stripe-go/v76/refund"
+	"github.com/stripe/stripe-go/v76/webhook"
+)
+
+const StripeProviderName = "STRIPE"
+
+// Duración mínima de una checkout session que acepta Stripe (+1 minuto de margen). Si el hold
+// vence antes, la orden se cancela y la sesión se cierra con ExpireSession
+const stripeMinSessionTTL = 31 * time.Minute
+
+// Stripe limita cada valor de metadata a 500 caracteres
+const stripeMaxSeatsMetadata = 450
+
+// StripeConfig es la configuración de la pasarela Stripe
+type StripeConfig struct {
+	SecretKey        string
+	WebhookSecret    string
+	WebhookTolerance time.Duration
+}
+
+// StripeProvider implementa PaymentProvider con clientes propios: no usa la key global stripe.Key
+type StripeProvider struct {
+	cfg      StripeConfig
+	sessions checkoutsession.Client
+	intents  paymentintent.Client
+	refunds  refund.Client
+}
+
+func NewStripeProvider(cfg StripeConfig) *StripeProvider {
+	if cfg.WebhookTolerance <= 0 {
+		cfg.WebhookTolerance = webhook.DefaultTolerance
+	}
+	backend := stripe.GetBackend(stripe.APIBackend)
+	return &StripeProvider{
+		cfg:      cfg,
+		sessions: checkoutsession.Client{B: backend, Key: cfg.SecretKey},
+		intents:  paymentintent.Client{B: backend, Key: cfg.SecretKey},
+		refunds:  refund.Client{B: backend, Key: cfg.SecretKey},
+	}
+}
+
+func (p *StripeProvider) Name() string { return StripeProviderName }
+
+func (p *StripeProvider) CreateSession(ctx context.Context, req CheckoutSessionRequest) (*CheckoutSession, error) {
+	params := stripeSessionParams(req, time.Now())
+	params.Context = ctx
+
+	s, err := p.sessions.New(params)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckoutSession{ID: s.ID, URL: s.URL, ExpiresAt: time.Unix(s.ExpiresAt, 0)}, nil
+}
+
+// stripeSessionParams arma la sesión de Stripe con la metadata que luego lee el webhook
+func stripeSessionParams(req CheckoutSessionRequest, now time.Time) *stripe.CheckoutSessionParams {
+	// Stripe espera el código ISO en minúsculas
+	currency := strings.ToLower(req.Currency)
+	lineItems := make([]*stripe.CheckoutSessionLineItemParams, 0, len(req.Items))
+	for _, item := range req.Items {
+		quantity := item.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+		lineItems = append(lineItems, &stripe.CheckoutSessionLineItemParams{
+			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+				Currency:   stripe.String(currency),
+				UnitAmount: stripe.Int64(item.Amount),
+				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+					Name: stripe.String(item.Name),
+				},
+			},
+			Quantity: stripe.Int64(quantity),
+		})
+	}
+
+	seatsMetadata := strings.Join(req.SeatIDs, ",")
+	if len(seatsMetadata) > stripeMaxSeatsMetadata {
+		seatsMetadata = messaging.SeatIDsInOrder
+	}
+	metadata := map[string]string{
+		"user_id":  req.UserID,
+		"seat_ids": seatsMetadata,
+		"ga_items": models.FormatGAItems(req.GAItems),
+		"event_id": req.EventID,
+		"order_id": req.OrderID,
+	}
+	if req.PromoCode != "" {
+		metadata["promo_code"] = req.PromoCode
+	}
+
+	// Stripe exige que la sesión dure al menos 30 minutos; si el hold es más corto, al
+	// cancelar la orden se cierra la sesión (y un pago que llegue igual se reembolsa)
+	expiresAt := req.ExpiresAt
+	if minExpiry := now.Add(stripeMinSessionTTL); expiresAt.Before(minExpiry) {
+		expiresAt = minExpiry
+	}
+
+	// Stripe reemplaza el placeholder por el ID de la sesión al volver al front
+	successURL := req.SuccessURL
+	if strings.Contains(successURL, "?") {
+		successURL += "&session_id={CHECKOUT_SESSION_ID}"
+	} else {
+		successURL += "?session_id={CHECKOUT_SESSION_ID}"
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		Mode:       stripe.String(string(stripe.CheckoutSessionModePayment)),
+		LineItems:  lineItems,
+		ExpiresAt:  stripe.Int64(expiresAt.Unix()),
+		SuccessURL: stripe.String(successURL),
+		CancelURL:  stripe.String(req.CancelURL),
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: metadata,
+		},
+		Metadata: metadata,
+	}
+	// El pagador no puede cambiar el email: los límites de compra por email cuentan con este
+	if req.Email != "" {
+		params.CustomerEmail = stripe.String(req.Email)
+	}
+	return params
+}
+
+// ExpireSession cierra la checkout session; Stripe rechaza cerrar una sesión ya pagada
+func (p *StripeProvider) ExpireSession(ctx context.Context, sessionID string) error {
+	params := &stripe.CheckoutSessionExpireParams{}
+	params.Context = ctx
+	_, err := p.sessions.Expire(sessionID, params)
+	return err
+}
+
+func (p *StripeProvider) RetrievePayment(ctx context.Context, paymentID string) (*PaymentDetails, error) {
+	params := &stripe.PaymentIntentParams{}
+	params.Context = ctx
+	params.AddExpand("customer")
+	params.AddExpand("latest_charge")
+
+	pi, err := p.intents.Get(paymentID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &PaymentDetails{
+		ID:       pi.ID,
+		Status:   string(pi.Status),
+		Amount:   pi.Amount,
+		Currency: string(pi.Currency),
+	}
+	if pi.LatestCharge != nil && pi.LatestCharge.BillingDetails != nil {
+		details.Customer.Email = pi.LatestCharge.BillingDetails.Email
+		details.Customer.Name = pi.LatestCharge.BillingDetails.Name
+	}
+	if pi.LatestCharge != nil && pi.LatestCharge.PaymentMethodDetails != nil && pi.LatestCharge.PaymentMethodDetails.Card != nil {
+		details.Customer.CardFingerprint = pi.LatestCharge.PaymentMethodDetails.Card.Fingerprint
+	}
+	if pi.Customer != nil {
+		if details.Customer.Email == "" {
+			details.Customer.Email = pi.Customer.Email
+			details.Customer.Name = pi.Customer.Name
+		}
+		if pi.Customer.ID != "" {
+			id := pi.Customer.ID
+			details.Customer.CustomerID = &id
+		}
+	}
+	if details.Customer.Email == "" {
+		details.Customer.Email = pi.ReceiptEmail
+	}
+	return details, nil
+}
+
+func (p *StripeProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(req.PaymentID),
+	}
+	// Sin importe Stripe devuelve el pago completo
+	if req.Amount > 0 {
+		params.Amount = stripe.Int64(req.Amount)
+	}
+	params.Context = ctx
+	if req.IdempotencyKey != "" {
+		params.SetIdempotencyKey(req.IdempotencyKey)
+	}
+	params.AddMetadata("reason", req.Reason)
+
+	re, err := p.refunds.New(params)
+	if err != nil {
+		return nil, err
+	}
+	return &RefundResult{ID: re.ID, Status: string(re.Status)}, nil
+}
+
+// Estructuras para parsear el JSON que envía Stripe
+type StripeWebhookReq struct {
+	ID     string     `json:"id"`
+	Type   string     `json:"type"`
+	Object string     `json:"object"`
+	Data   StripeData `json:"data"`
+}
+
+type StripeData struct {
+	Object StripeObject `json:"object"`
+}
+
+type StripeObject struct {
+	ID            string         `json:"id"`             // El PaymentIntent ID o Session ID
+	PaymentStatus string         `json:"payment_status"` // "paid", "unpaid"
+	Status        string         `json:"status"`         // payment_intent.* usa "succeeded", "requires_payment_method", etc.
+	Metadata      StripeMetadata `json:"metadata"`
+	AmountTotal   float64        `json:"amount_total"`
+	Amount        float64        `json:"amount"`
+	PaymentIntent string         `json:"payment_intent"`
+	Currency      string         `json:"currency"`
+}
+
+type StripeMetadata struct {
+	UserID  string `json:"user_id"`
+	SeatIDs string `json:"seat_ids"`
+	GAItems string `json:"ga_items"`
+	EventID string `json:"event_id"`
+	OrderID string `json:"order_id"`
+}
+
+// ParseWebhook verifica Stripe-Signature sobre el body crudo y arma el mensaje interno
+func (p *StripeProvider) ParseWebhook(_ context.Context, req WebhookRequest) (*messaging.BookingMessage, error) {
+	payload := req.Payload
+	if p.cfg.WebhookSecret == "" {
+		return nil, utils.ErrWebhookNotConfigured
+	}
+
+	// La firma se calcula sobre el body crudo: no se puede parsear antes de verificar
+	err := webhook.ValidatePayloadWithTolerance(payload, req.Headers.Get("Stripe-Signature"), p.cfg.WebhookSecret, p.cfg.WebhookTolerance)
+	if errors.Is(err, webhook.ErrTooOld) {
+		return nil, utils.ErrWebhookSignatureExpired
+	}
+	if err != nil {
+		return nil, utils.ErrInvalidWebhookSignature
+	}
+
+	var event StripeWebhookReq
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidWebhookPayload, err)
+	}
+
+	eventID := strings.TrimSpace(event.ID)
+	if eventID == "" {
+		eventID = uuid.NewString()
+	}
+
+	obj := event.Data.Object
+	status := strings.TrimSpace(obj.PaymentStatus)
+	if status == "" {
+		status = strings.TrimSpace(obj.Status)
+	}
+
+	amount := obj.AmountTotal
+	if amount == 0 {
+		amount = obj.Amount
+	}
+
+	msg := &messaging.BookingMessage{
+		UserID:            obj.Metadata.UserID,
+		Amount:            amount, // Stripe manda centavos
+		Currency:          strings.ToLower(strings.TrimSpace(obj.Currency)),
+		SeatIDs:           obj.Metadata.SeatIDs,
+		GAItems:           obj.Metadata.GAItems,
+		EventID:           obj.Metadata.EventID,
+		Status:            status,
+		PaymentProviderID: strings.TrimSpace(obj.PaymentIntent),
+		OrderID:           obj.Metadata.OrderID,
+		Nonce:             eventID,
+		StripeEventID:     eventID,
+		StripeEventType:   strings.TrimSpace(event.Type),
+		Provider:          StripeProviderName,
+	}
+	if msg.PaymentProviderID == "" {
+		msg.PaymentProviderID = strings.TrimSpace(obj.ID)
+	}
+	return msg, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"booking-service/internal/messaging"
+	"booking-service/pkg/utils"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stripe/stripe-go/v76/webhook"
+)
+
+func TestStripeSessionParams(t *testing.T) {
+	now := time.Now()
+	req := CheckoutSessionRequest{
+		OrderID:    "o1",
+		UserID:     "u1",
+		EventID:    "e1",
+		SeatIDs:    []string{"s1", "s2"},
+		Currency:   "usd",
+		Items:      []CheckoutItem{{SeatID: "s1", Name: "Asiento 1 - A", Amount: 1000}, {SeatID: "s2", Name: "Asiento 2 - A", Amount: 1500, Quantity: 1}},
+		SuccessURL: "https://front/success",
+		CancelURL:  "https://front/cancel",
+		ExpiresAt:  now.Add(10 * time.Minute),
+	}
+
+	params := stripeSessionParams(req, now)
+	if len(params.LineItems) != 2 || *params.LineItems[1].PriceData.UnitAmount != 1500 || *params.LineItems[0].Quantity != 1 {
+		t.Fatalf("unexpected line items: %+v", params.LineItems)
+	}
+	if params.Metadata["order_id"] != "o1" || params.PaymentIntentData.Metadata["seat_ids"] != "s1,s2" {
+		t.Fatalf("unexpected metadata: %v", params.Metadata)
+	}
+	if *params.SuccessURL != "https://front/success?session_id={CHECKOUT_SESSION_ID}" {
+		t.Fatalf("unexpected success url %s", *params.SuccessURL)
+	}
+	if got := time.Unix(*params.ExpiresAt, 0); got.Before(now.Add(30 * time.Minute)) {
+		t.Fatalf("expected session to last at least Stripe's minimum, got %v", got.Sub(now))
+	}
+
+	if _, ok := params.Metadata["promo_code"]; ok || params.CustomerEmail != nil {
+		t.Fatalf("expected no promo code nor email, got %v", params.Metadata)
+	}
+	req.PromoCode = "FANS10"
+	req.Email = "fan@mail.com"
+	if promoParams := stripeSessionParams(req, now); promoParams.Metadata["promo_code"] != "FANS10" || *promoParams.CustomerEmail != "fan@mail.com" {
+		t.Fatalf("expected promo code metadata and customer email, got %v", promoParams.Metadata)
+	}
+
+	req.SeatIDs = strings.Split(strings.Repeat("11111111-1111-1111-1111-111111111111,", 20), ",")
+	if got := stripeSessionParams(req, now).Metadata["seat_ids"]; got != messaging.SeatIDsInOrder {
+		t.Fatalf("expected long seat list to be replaced, got %q", got)
+	}
+}
+
+func TestStripeProvider_ParseWebhook(t *testing.T) {
+	const secret = "whsec_test"
+	provider := NewStripeProvider(StripeConfig{WebhookSecret: secret, WebhookTolerance: time.Minute})
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_1","status":"succeeded","amount":2500,"currency":"USD","metadata":{"user_id":"u1","seat_ids":"s1","order_id":"o1"}}}}`)
+
+	headers := func(ts time.Time, secret string) http.Header {
+		signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: secret, Timestamp: ts})
+		h := http.Header{}
+		h.Set("Stripe-Signature", signed.Header)
+		return h
+	}
+
+	parse := func(h http.Header) (*messaging.BookingMessage, error) {
+		return provider.ParseWebhook(context.Background(), WebhookRequest{Payload: payload, Headers: h})
+	}
+
+	msg, err := parse(headers(time.Now(), secret))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Provider != StripeProviderName || msg.PaymentProviderID != "pi_1" || msg.Amount != 2500 || msg.Currency != "usd" || !msg.IsPaid() {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+
+	if _, err := parse(headers(time.Now(), "whsec_other")); !errors.Is(err, utils.ErrInvalidWebhookSignature) {
+		t.Fatalf("expected ErrInvalidWebhookSignature, got %v", err)
+	}
+	if _, err := parse(headers(time.Now().Add(-time.Hour), secret)); !errors.Is(err, utils.ErrWebhookSignatureExpired) {
+		t.Fatalf("expected ErrWebhookSignatureExpired, got %v", err)
+	}
+	if _, err := NewStripeProvider(StripeConfig{}).ParseWebhook(context.Background(), WebhookRequest{Payload: payload, Headers: headers(time.Now(), secret)}); !errors.Is(err, utils.ErrWebhookNotConfigured) {
+		t.Fatalf("expected ErrWebhookNotConfigured, got %v", err)
+	}
+}
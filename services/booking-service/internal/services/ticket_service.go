@@ -13,6 +13,7 @@ type TicketService struct {
 	orderRepo  repositories.BookingOrderRepository
 	seatRepo   repositories.SeatRepository
 	eventRepo  repositories.EventRepository
+	gaRepo     repositories.GeneralAdmissionRepository
 }
 
 func NewTicketService(
@@ -20,12 +21,14 @@ func NewTicketService(
 	orderRepo repositories.BookingOrderRepository,
 	seatRepo repositories.SeatRepository,
 	eventRepo repositories.EventRepository,
+	gaRepo repositories.GeneralAdmissionRepository,
 ) *TicketService {
 	return &TicketService{
 		ticketRepo: ticketRepo,
 		orderRepo:  orderRepo,
 		seatRepo:   seatRepo,
 		eventRepo:  eventRepo,
+		gaRepo:     gaRepo,
 	}
 }
 
@@ -38,12 +41,15 @@ func (s *TicketService) CreateTicketFromOrder(
 		return nil, errors.New("checkout and order are required")
 	}
 
-	seats, err := s.seatRepo.FindByIDs(order.SeatIDs)
-	if err != nil || len(seats) == 0 {
+	seats, err := s.seatRepo.FindByIDs(order.ActiveSeatIDs())
+	if err != nil || (len(seats) == 0 && len(order.GAItems) == 0) {
 		return nil, fmt.Errorf("failed to fetch seats: %w", err)
 	}
 
-	eventID := seats[0].EventID
+	eventID, err := s.orderEventID(seats, order)
+	if err != nil {
+		return nil, err
+	}
 	event, err := s.eventRepo.FindByID(eventID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch event: %w", err)
@@ -52,8 +58,8 @@ func (s *TicketService) CreateTicketFromOrder(
 	ticket := &models.TicketPDF{
 		PaymentProvider: checkout.PaymentProvider,
 		PaymentIntentID: checkout.PaymentIntentID,
-		Currency:        checkout.Currency,
-		Amount:          checkout.Amount,
+		Currency:        checkout.Total.Currency,
+		Amount:          checkout.Total.Amount,
 		Name:            checkout.CustomerName,
 		Email:           checkout.CustomerEmail,
 		CustomerID:      checkout.CustomerID,
@@ -61,6 +67,8 @@ func (s *TicketService) CreateTicketFromOrder(
 		EventName:       event.Name,
 		EventHour:       event.Date.Format("15:04"),
 		Items:           seats,
+		GAItems:         order.GAItems,
+		Lines:           order.Lines,
 		PDFVersion:      1,
 	}
 
@@ -150,21 +158,49 @@ func (s *TicketService) loadTicketItems(ticket *models.TicketPDF) error {
 		return fmt.Errorf("failed to fetch order: %w", err)
 	}
 
-	seats, err := s.seatRepo.FindByIDs(order.SeatIDs)
+	seats, err := s.seatRepo.FindByIDs(order.ActiveSeatIDs())
 	if err != nil {
 		return fmt.Errorf("failed to fetch seats: %w", err)
 	}
 
-	if len(seats) > 0 {
-		event, err := s.eventRepo.FindByID(seats[0].EventID)
+	if len(seats) > 0 || len(order.GAItems) > 0 {
+		eventID, err := s.orderEventID(seats, order)
+		if err != nil {
+			return err
+		}
+		event, err := s.eventRepo.FindByID(eventID)
 		if err != nil {
 			return fmt.Errorf("failed to fetch event: %w", err)
 		}
 
-		ticket.EventName = event.Name
-		ticket.EventHour = event.Date.Format("15:04")
+		if event != nil {
+			ticket.EventName = event.Name
+			ticket.EventHour = event.Date.Format("15:04")
+		}
 	}
 
 	ticket.Items = seats
+	ticket.GAItems = order.GAItems
+	ticket.Lines = order.Lines
 	return nil
 }
+
+// orderEventID obtiene el evento de la orden desde sus asientos o, si solo tiene entradas de
+// admisión general, desde el sector
+func (s *TicketService) orderEventID(seats []models.Seat, order *models.BookingOrder) (string, error) {
+	if len(seats) > 0 {
+		return seats[0].EventID, nil
+	}
+	if s.gaRepo == nil || len(order.GAItems) == 0 {
+		return "", errors.New("order has no seats")
+	}
+
+	section, err := s.gaRepo.FindSectionByID(order.GAItems[0].SectionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch general admission section: %w", err)
+	}
+	if section == nil {
+		return "", fmt.Errorf("failed to fetch general admission section: %s", order.GAItems[0].SectionID)
+	}
+	return section.EventID, nil
+}
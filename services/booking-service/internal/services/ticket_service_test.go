@@ -2,6 +2,8 @@ package services
 
 import (
 	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/money"
 	"errors"
 	"testing"
 	"time"
@@ -33,10 +35,20 @@ func (m *mockSeatRepoForTicket) Create(*models.Seat) error { panic("not used") }
 func (m *mockSeatRepoForTicket) FindAlls() ([]models.Seat, error) { panic("not used") }
 func (m *mockSeatRepoForTicket) FindByID(string) (*models.Seat, error) { panic("not used") }
 func (m *mockSeatRepoForTicket) UpdateStatus(string, models.SeatStatus) error { panic("not used") }
-func (m *mockSeatRepoForTicket) LockSeat(string, string, time.Time) error { panic("not used") }
+func (m *mockSeatRepoForTicket) LockSeat(string, string, string, time.Time) error { panic("not used") }
+func (m *mockSeatRepoForTicket) LockSeats([]string, string, string, time.Time) error { panic("not used") }
+func (m *mockSeatRepoForTicket) FindByHoldID(string) ([]models.Seat, error) { panic("not used") }
+func (m *mockSeatRepoForTicket) ExtendHold(string, string, time.Time, int, time.Time) (int64, error) {
+	panic("not used")
+}
+func (m *mockSeatRepoForTicket) ReleaseHold(string, string) (int64, error) { panic("not used") }
 func (m *mockSeatRepoForTicket) UnlockIfExpired(string, time.Time) error { panic("not used") }
+func (m *mockSeatRepoForTicket) FindExpiredLocks(time.Time, int) ([]models.Seat, error) { panic("not used") }
+func (m *mockSeatRepoForTicket) ReleaseExpiredLocks([]string, time.Time) ([]models.Seat, error) { panic("not used") }
+func (m *mockSeatRepoForTicket) CountActiveLocksByUser(string, string, time.Time) (int64, error) { panic("not used") }
 func (m *mockSeatRepoForTicket) FindSeatByEventId(string) ([]models.Seat, error) { panic("not used") }
 func (m *mockSeatRepoForTicket) FindByIDs(ids []string) ([]models.Seat, error) { return m.findByIDsFn(ids) }
+func (m *mockSeatRepoForTicket) CreateForEvent(string, string, []models.Seat) error { panic("not used") }
 
 type mockOrderRepoForTicket struct {
 	findByIDFn func(string) (*models.BookingOrder, error)
@@ -45,9 +57,18 @@ type mockOrderRepoForTicket struct {
 func (m *mockOrderRepoForTicket) Create(*models.BookingOrder) error { panic("not used") }
 func (m *mockOrderRepoForTicket) FindAll() ([]models.BookingOrder, error) { panic("not used") }
 func (m *mockOrderRepoForTicket) FindByID(id string) (*models.BookingOrder, error) { return m.findByIDFn(id) }
-func (m *mockOrderRepoForTicket) UpdateStatus(string, models.PaymentStatus) error { panic("not used") }
-func (m *mockOrderRepoForTicket) Update(string, models.PaymentStatus, string) error { panic("not used") }
+func (m *mockOrderRepoForTicket) ChangeStatus(*repositories.StatusChange) error { panic("not used") }
+func (m *mockOrderRepoForTicket) FindStatusHistory(string) ([]models.BookingOrderStatusHistory, error) {
+	panic("not used")
+}
 func (m *mockOrderRepoForTicket) FindAllOrdersByUserID(string) ([]models.BookingOrder, error) { panic("not used") }
+func (m *mockOrderRepoForTicket) FindPendingByUserIDs([]string) ([]models.BookingOrder, error) {
+	panic("not used")
+}
+func (m *mockOrderRepoForTicket) CancelPending([]string, string, string) ([]models.BookingOrder, error) {
+	panic("not used")
+}
+func (m *mockOrderRepoForTicket) AttachPaymentSession(string, string) error { panic("not used") }
 
 type mockEventRepoForTicket struct {
 	findByIDFn func(string) (*models.Event, error)
@@ -61,7 +82,7 @@ func (m *mockEventRepoForTicket) Delete(string) error { panic("not used") }
 func (m *mockEventRepoForTicket) UpdateAvailability(string) error { panic("not used") }
 
 func TestTicketService_CreateTicketFromOrder_ValidationsAndSuccess(t *testing.T) {
-	svc := NewTicketService(&mockTicketRepo{}, &mockOrderRepoForTicket{}, &mockSeatRepoForTicket{}, &mockEventRepoForTicket{})
+	svc := NewTicketService(&mockTicketRepo{}, &mockOrderRepoForTicket{}, &mockSeatRepoForTicket{}, &mockEventRepoForTicket{}, nil)
 	if _, err := svc.CreateTicketFromOrder(nil, &models.BookingOrder{}); err == nil {
 		t.Fatalf("expected nil checkout/order validation error")
 	}
@@ -82,9 +103,10 @@ func TestTicketService_CreateTicketFromOrder_ValidationsAndSuccess(t *testing.T)
 		&mockEventRepoForTicket{findByIDFn: func(string) (*models.Event, error) {
 			return &models.Event{Name: "Rock Fest", Date: time.Date(2026, 6, 1, 19, 45, 0, 0, time.UTC)}, nil
 		}},
+		nil,
 	)
 
-	checkout := &models.Checkout{PaymentProvider: "STRIPE", PaymentIntentID: "pi_1", Currency: "USD", Amount: 1000, CustomerName: "Ana", CustomerEmail: "a@a.com"}
+	checkout := &models.Checkout{PaymentProvider: "STRIPE", PaymentIntentID: "pi_1", Total: money.New(1000, "USD"), CustomerName: "Ana", CustomerEmail: "a@a.com"}
 	order := &models.BookingOrder{BaseModel: models.BaseModel{ID: "o1"}, SeatIDs: []string{"s1"}}
 	got, err := svc.CreateTicketFromOrder(checkout, order)
 	if err != nil {
@@ -105,6 +127,7 @@ func TestTicketService_UpdateTicketPDF_IncrementsVersion(t *testing.T) {
 		&mockOrderRepoForTicket{},
 		&mockSeatRepoForTicket{},
 		&mockEventRepoForTicket{},
+		nil,
 	)
 
 	if err := svc.UpdateTicketPDF("t1", []byte("pdf")); err != nil {
@@ -121,6 +144,7 @@ func TestTicketService_ValidateTicketOwnership(t *testing.T) {
 		&mockOrderRepoForTicket{findByIDFn: func(string) (*models.BookingOrder, error) { return &models.BookingOrder{UserID: "u2"}, nil }},
 		&mockSeatRepoForTicket{},
 		&mockEventRepoForTicket{},
+		nil,
 	)
 
 	if err := svc.ValidateTicketOwnership("t1", "u1"); err == nil {
@@ -136,6 +160,7 @@ func TestTicketService_GetTicketByID_LoadsItems(t *testing.T) {
 		&mockEventRepoForTicket{findByIDFn: func(string) (*models.Event, error) {
 			return &models.Event{Name: "Show", Date: time.Date(2026, 2, 1, 18, 0, 0, 0, time.UTC)}, nil
 		}},
+		nil,
 	)
 
 	ticket, err := svc.GetTicketByID("t1")
@@ -153,6 +178,7 @@ func TestTicketService_CreateTicketFromOrder_SeatFetchFailure(t *testing.T) {
 		&mockOrderRepoForTicket{},
 		&mockSeatRepoForTicket{findByIDsFn: func([]string) ([]models.Seat, error) { return nil, errors.New("db") }},
 		&mockEventRepoForTicket{},
+		nil,
 	)
 
 	_, err := svc.CreateTicketFromOrder(&models.Checkout{}, &models.BookingOrder{SeatIDs: []string{"s1"}})
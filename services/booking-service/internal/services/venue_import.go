@@ -0,0 +1,152 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Columnas del CSV de importación; section y number son obligatorias
+var venueCSVColumns = []string{"section", "row", "number", "x", "y", "aisle_left", "aisle_right"}
+
+// ParseVenueJSON lee un plano en formato VenueLayout (secciones > filas > butacas)
+func ParseVenueJSON(r io.Reader) (*models.Venue, error) {
+	var layout models.VenueLayout
+	if err := json.NewDecoder(r).Decode(&layout); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidVenue, err)
+	}
+	return VenueFromLayout(layout)
+}
+
+// VenueFromLayout aplana el layout en butacas; la posición es el orden dentro de la fila
+func VenueFromLayout(layout models.VenueLayout) (*models.Venue, error) {
+	venue := &models.Venue{Name: strings.TrimSpace(layout.Name), Address: strings.TrimSpace(layout.Address)}
+	for _, section := range layout.Sections {
+		for _, row := range section.Rows {
+			for i, seat := range row.Seats {
+				venue.Seats = append(venue.Seats, models.VenueSeat{
+					Section:    strings.TrimSpace(section.Name),
+					Row:        strings.TrimSpace(row.Label),
+					Number:     strings.TrimSpace(seat.Number),
+					Position:   i + 1,
+					X:          seat.X,
+					Y:          seat.Y,
+					AisleLeft:  seat.AisleLeft,
+					AisleRight: seat.AisleRight,
+				})
+			}
+		}
+	}
+
+	if err := validateVenue(venue); err != nil {
+		return nil, err
+	}
+	return venue, nil
+}
+
+// ParseVenueCSV lee un plano con encabezado section,row,number[,x,y,aisle_left,aisle_right].
+// Las butacas de una fila se ordenan según aparecen en el archivo
+func ParseVenueCSV(r io.Reader, name, address string) (*models.Venue, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing CSV header", utils.ErrInvalidVenue)
+	}
+	columns := make(map[string]int, len(header))
+	for i, col := range header {
+		columns[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	for _, required := range []string{"section", "number"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: CSV header must include %s (columns: %s)", utils.ErrInvalidVenue, required, strings.Join(venueCSVColumns, ","))
+		}
+	}
+
+	venue := &models.Venue{Name: strings.TrimSpace(name), Address: strings.TrimSpace(address)}
+	positions := make(map[[2]string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", utils.ErrInvalidVenue, line, err)
+		}
+
+		field := func(col string) string {
+			if i, ok := columns[col]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		seat := models.VenueSeat{Section: field("section"), Row: field("row"), Number: field("number")}
+		if seat.X, err = parseOptionalFloat(field("x")); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid x", utils.ErrInvalidVenue, line)
+		}
+		if seat.Y, err = parseOptionalFloat(field("y")); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid y", utils.ErrInvalidVenue, line)
+		}
+		if seat.AisleLeft, err = parseOptionalBool(field("aisle_left")); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid aisle_left", utils.ErrInvalidVenue, line)
+		}
+		if seat.AisleRight, err = parseOptionalBool(field("aisle_right")); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid aisle_right", utils.ErrInvalidVenue, line)
+		}
+
+		key := [2]string{seat.Section, seat.Row}
+		positions[key]++
+		seat.Position = positions[key]
+		venue.Seats = append(venue.Seats, seat)
+	}
+
+	if err := validateVenue(venue); err != nil {
+		return nil, err
+	}
+	return venue, nil
+}
+
+func validateVenue(venue *models.Venue) error {
+	if venue.Name == "" {
+		return fmt.Errorf("%w: name cannot be empty", utils.ErrInvalidVenue)
+	}
+	if len(venue.Seats) == 0 {
+		return fmt.Errorf("%w: venue has no seats", utils.ErrInvalidVenue)
+	}
+
+	seen := make(map[[3]string]bool, len(venue.Seats))
+	for _, seat := range venue.Seats {
+		if seat.Section == "" || seat.Number == "" {
+			return fmt.Errorf("%w: every seat needs a section and a number", utils.ErrInvalidVenue)
+		}
+		key := [3]string{strings.ToUpper(seat.Section), strings.ToUpper(seat.Row), strings.ToUpper(seat.Number)}
+		if seen[key] {
+			return fmt.Errorf("%w: duplicated seat %s %s-%s", utils.ErrInvalidVenue, seat.Section, seat.Row, seat.Number)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func parseOptionalFloat(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func parseOptionalBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
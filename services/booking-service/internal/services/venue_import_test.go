@@ -0,0 +1,97 @@
+package services
+
+import (
+	"booking-service/pkg/utils"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseVenueJSON(t *testing.T) {
+	venue, err := ParseVenueJSON(strings.NewReader(`{
+		"name": "Teatro Opera",
+		"sections": [
+			{"name": "PLATEA", "rows": [
+				{"label": "A", "seats": [{"number": "1", "x": 10, "y": 5, "aisleLeft": true}, {"number": "2", "x": 12, "y": 5}]},
+				{"label": "B", "seats": [{"number": "1", "x": 10, "y": 8}]}
+			]},
+			{"name": "PALCO", "rows": [{"label": "", "seats": [{"number": "P1"}]}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if venue.Name != "Teatro Opera" || len(venue.Seats) != 4 {
+		t.Fatalf("unexpected venue: %+v", venue)
+	}
+	first, second := venue.Seats[0], venue.Seats[1]
+	if first.Section != "PLATEA" || first.Row != "A" || first.Position != 1 || !first.AisleLeft || first.X != 10 {
+		t.Fatalf("unexpected first seat: %+v", first)
+	}
+	if second.Position != 2 || venue.Seats[2].Position != 1 {
+		t.Fatalf("expected positions to restart per row, got %d and %d", second.Position, venue.Seats[2].Position)
+	}
+}
+
+func TestParseVenueCSV(t *testing.T) {
+	csv := "Section,Row,Number,X,Y,Aisle_Right\n" +
+		"PLATEA,A,1,0,0,false\n" +
+		"PLATEA,A,2,1.5,0,true\n" +
+		"PLATEA,B,1,0,1,\n"
+	venue, err := ParseVenueCSV(strings.NewReader(csv), "Luna Park", "Bouchard 465")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if venue.Name != "Luna Park" || venue.Address != "Bouchard 465" || len(venue.Seats) != 3 {
+		t.Fatalf("unexpected venue: %+v", venue)
+	}
+	if s := venue.Seats[1]; s.Position != 2 || s.X != 1.5 || !s.AisleRight || s.AisleLeft {
+		t.Fatalf("unexpected second seat: %+v", s)
+	}
+	if s := venue.Seats[2]; s.Row != "B" || s.Position != 1 {
+		t.Fatalf("unexpected third seat: %+v", s)
+	}
+}
+
+func TestParseVenue_Invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func() error
+	}{
+		{"json without name", func() error {
+			_, err := ParseVenueJSON(strings.NewReader(`{"sections":[{"name":"A","rows":[{"label":"1","seats":[{"number":"1"}]}]}]}`))
+			return err
+		}},
+		{"json without seats", func() error {
+			_, err := ParseVenueJSON(strings.NewReader(`{"name":"X","sections":[]}`))
+			return err
+		}},
+		{"malformed json", func() error {
+			_, err := ParseVenueJSON(strings.NewReader(`{`))
+			return err
+		}},
+		{"csv without number column", func() error {
+			_, err := ParseVenueCSV(strings.NewReader("section,row\nA,1\n"), "X", "")
+			return err
+		}},
+		{"csv duplicated seat", func() error {
+			_, err := ParseVenueCSV(strings.NewReader("section,row,number\nA,1,1\na,1,1\n"), "X", "")
+			return err
+		}},
+		{"csv invalid coordinate", func() error {
+			_, err := ParseVenueCSV(strings.NewReader("section,row,number,x\nA,1,1,left\n"), "X", "")
+			return err
+		}},
+		{"csv empty", func() error {
+			_, err := ParseVenueCSV(strings.NewReader(""), "X", "")
+			return err
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.run(); !errors.Is(err, utils.ErrInvalidVenue) {
+				t.Fatalf("expected invalid venue, got %v", err)
+			}
+		})
+	}
+}
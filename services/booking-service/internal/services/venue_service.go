@@ -0,0 +1,127 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/utils"
+	"fmt"
+	"strings"
+)
+
+type VenueService struct {
+	repo       repositories.VenueRepository
+	repoEvents repositories.EventRepository
+	repoSeats  repositories.SeatRepository
+	repoTiers  repositories.PriceTierRepository
+}
+
+func NewVenueService(repo repositories.VenueRepository, repoEvents repositories.EventRepository, repoSeats repositories.SeatRepository, repoTiers repositories.PriceTierRepository) *VenueService {
+	return &VenueService{repo: repo, repoEvents: repoEvents, repoSeats: repoSeats, repoTiers: repoTiers}
+}
+
+func (s *VenueService) CreateVenue(venue *models.Venue) error {
+	if err := validateVenue(venue); err != nil {
+		return err
+	}
+	return s.repo.Create(venue)
+}
+
+func (s *VenueService) GetVenue(id string) (*models.Venue, error) {
+	venue, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if venue == nil {
+		return nil, utils.ErrVenueNotFound
+	}
+	return venue, nil
+}
+
+func (s *VenueService) GetVenues() ([]models.Venue, error) {
+	return s.repo.FindAll()
+}
+
+func (s *VenueService) DeleteVenue(id string) error {
+	if _, err := s.GetVenue(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// GenerateSeats crea todo el inventario del evento a partir del plano del recinto.
+// Cada sección usa la categoría indicada en sectionTiers o, si no, la categoría del evento
+// con el mismo nombre; sin categoría el asiento toma el precio base del evento
+func (s *VenueService) GenerateSeats(eventID, venueID string, sectionTiers map[string]string) ([]models.Seat, error) {
+	event, err := s.repoEvents.FindByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, utils.ErrEventNotFound
+	}
+	venue, err := s.GetVenue(venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	tiers, err := s.repoTiers.FindByEventID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	tierByID := make(map[string]models.PriceTier, len(tiers))
+	tierByName := make(map[string]models.PriceTier, len(tiers))
+	for _, t := range tiers {
+		tierByID[t.ID] = t
+		tierByName[strings.ToUpper(t.Name)] = t
+	}
+	explicit := make(map[string]models.PriceTier, len(sectionTiers))
+	for section, tierID := range sectionTiers {
+		tier, ok := tierByID[tierID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", utils.ErrTierNotFound, tierID)
+		}
+		explicit[strings.ToUpper(section)] = tier
+	}
+
+	seats := make([]models.Seat, 0, len(venue.Seats))
+	perTier := make(map[string]int)
+	for _, vs := range venue.Seats {
+		seat := models.Seat{
+			EventID:    eventID,
+			Section:    vs.Section,
+			Row:        vs.Row,
+			Number:     vs.Number,
+			Position:   vs.Position,
+			X:          vs.X,
+			Y:          vs.Y,
+			AisleLeft:  vs.AisleLeft,
+			AisleRight: vs.AisleRight,
+			Price:      event.Price,
+			Status:     models.StatusAvailable,
+		}
+
+		tier, ok := explicit[strings.ToUpper(vs.Section)]
+		if !ok {
+			tier, ok = tierByName[strings.ToUpper(vs.Section)]
+		}
+		if ok {
+			tierID := tier.ID
+			seat.TierID = &tierID
+			seat.Price = tier.Price
+			perTier[tier.ID]++
+		}
+		seats = append(seats, seat)
+	}
+
+	// El evento no tiene asientos todavía (lo verifica el repositorio), así que alcanza con el plano
+	for tierID, count := range perTier {
+		if tier := tierByID[tierID]; tier.Capacity > 0 && count > tier.Capacity {
+			return nil, fmt.Errorf("%w: %s has capacity %d, venue needs %d", utils.ErrTierFull, tier.Name, tier.Capacity, count)
+		}
+	}
+
+	if err := s.repoSeats.CreateForEvent(eventID, venue.ID, seats); err != nil {
+		return nil, err
+	}
+	return seats, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/money"
+	"booking-service/pkg/utils"
+	"errors"
+	"testing"
+)
+
+type mockVenueRepo struct {
+	createFn   func(*models.Venue) error
+	findByIDFn func(string) (*models.Venue, error)
+	findAllFn  func() ([]models.Venue, error)
+	deleteFn   func(string) error
+}
+
+func (m *mockVenueRepo) Create(venue *models.Venue) error          { return m.createFn(venue) }
+func (m *mockVenueRepo) FindByID(id string) (*models.Venue, error) { return m.findByIDFn(id) }
+func (m *mockVenueRepo) FindAll() ([]models.Venue, error)          { return m.findAllFn() }
+func (m *mockVenueRepo) Delete(id string) error                    { return m.deleteFn(id) }
+
+func testVenue() *models.Venue {
+	return &models.Venue{
+		BaseModel: models.BaseModel{ID: "v1"},
+		Name:      "Teatro",
+		Seats: []models.VenueSeat{
+			{Section: "VIP", Row: "A", Number: "1", Position: 1, X: 1},
+			{Section: "VIP", Row: "A", Number: "2", Position: 2, X: 2, AisleRight: true},
+			{Section: "Platea Alta", Row: "F", Number: "1", Position: 1},
+			{Section: "Pullman", Row: "P", Number: "1", Position: 1},
+		},
+	}
+}
+
+func TestVenueService_GenerateSeats(t *testing.T) {
+	tiers := &mockPriceTierRepo{findByEventIDFn: func(string) ([]models.PriceTier, error) {
+		return []models.PriceTier{
+			{BaseModel: models.BaseModel{ID: "vip"}, EventID: "e1", Name: "vip", Price: money.New(5000, "ARS")},
+			{BaseModel: models.BaseModel{ID: "platea"}, EventID: "e1", Name: "Platea", Price: money.New(3000, "ARS")},
+		}, nil
+	}}
+	var gotEvent, gotVenue string
+	var created []models.Seat
+	seats := &mockSeatRepo{createForEventFn: func(eventID, venueID string, s []models.Seat) error {
+		gotEvent, gotVenue, created = eventID, venueID, s
+		return nil
+	}}
+	venues := &mockVenueRepo{findByIDFn: func(id string) (*models.Venue, error) {
+		if id != "v1" {
+			return nil, nil
+		}
+		return testVenue(), nil
+	}}
+	svc := NewVenueService(venues, eventsWithARS(), seats, tiers)
+
+	out, err := svc.GenerateSeats("e1", "v1", map[string]string{"platea alta": "platea"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotEvent != "e1" || gotVenue != "v1" || len(created) != 4 || len(out) != 4 {
+		t.Fatalf("unexpected batch: event=%s venue=%s seats=%d", gotEvent, gotVenue, len(created))
+	}
+
+	// VIP por nombre, Platea Alta por mapeo explícito, Pullman sin categoría (precio del evento)
+	want := []struct {
+		tier  string
+		price int64
+	}{{"vip", 5000}, {"vip", 5000}, {"platea", 3000}, {"", 100000}}
+	for i, w := range want {
+		seat := created[i]
+		tierID := ""
+		if seat.TierID != nil {
+			tierID = *seat.TierID
+		}
+		if tierID != w.tier || seat.Price != money.New(w.price, "ARS") || seat.Status != models.StatusAvailable {
+			t.Fatalf("seat %d: expected tier %q price %d, got %q %v", i, w.tier, w.price, tierID, seat.Price)
+		}
+	}
+	if created[1].Section != "VIP" || created[1].Row != "A" || created[1].Position != 2 || !created[1].AisleRight || created[1].X != 2 {
+		t.Fatalf("expected venue layout copied to seat, got %+v", created[1])
+	}
+}
+
+func TestVenueService_GenerateSeats_Errors(t *testing.T) {
+	tiers := &mockPriceTierRepo{findByEventIDFn: func(string) ([]models.PriceTier, error) {
+		return []models.PriceTier{{BaseModel: models.BaseModel{ID: "vip"}, EventID: "e1", Name: "VIP", Capacity: 1}}, nil
+	}}
+	venues := &mockVenueRepo{findByIDFn: func(id string) (*models.Venue, error) {
+		if id != "v1" {
+			return nil, nil
+		}
+		return testVenue(), nil
+	}}
+	svc := NewVenueService(venues, eventsWithARS(), &mockSeatRepo{}, tiers)
+
+	if _, err := svc.GenerateSeats("missing", "v1", nil); !errors.Is(err, utils.ErrEventNotFound) {
+		t.Fatalf("expected event not found, got %v", err)
+	}
+	if _, err := svc.GenerateSeats("e1", "missing", nil); !errors.Is(err, utils.ErrVenueNotFound) {
+		t.Fatalf("expected venue not found, got %v", err)
+	}
+	if _, err := svc.GenerateSeats("e1", "v1", map[string]string{"Pullman": "other"}); !errors.Is(err, utils.ErrTierNotFound) {
+		t.Fatalf("expected tier not found, got %v", err)
+	}
+	// La sección VIP tiene 2 butacas y la categoría capacidad 1
+	if _, err := svc.GenerateSeats("e1", "v1", nil); !errors.Is(err, utils.ErrTierFull) {
+		t.Fatalf("expected tier full, got %v", err)
+	}
+}
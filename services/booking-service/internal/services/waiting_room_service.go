@@ -0,0 +1,224 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repositories"
+	"booking-service/pkg/utils"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Audiencia de los tokens de la sala de espera: un JWT de login firmado con el mismo
+// secreto no sirve como pase
+const queueTokenAudience = "seatguard-waiting-room"
+
+// queueClaims usa uid en vez de sub para que el pase no sirva como token de login
+type queueClaims struct {
+	UserID   string `json:"uid"`
+	EventID  string `json:"evt"`
+	Position int64  `json:"pos"`
+	AdmitAt  int64  `json:"adm"` // Milisegundos: con tasas altas las admisiones están a menos de un segundo
+	jwt.RegisteredClaims
+}
+
+// WaitingRoomService administra la sala de espera de los eventos con WaitingRoomRate > 0:
+// reparte lugares en la fila, firma los pases y controla la admisión a la selección de asientos
+type WaitingRoomService struct {
+	repo   repositories.WaitingRoomRepository
+	secret []byte
+	// Tiempo que el usuario admitido puede usar la selección de asientos
+	window time.Duration
+	now    func() time.Time
+}
+
+func NewWaitingRoomService(repo repositories.WaitingRoomRepository, secret string, window time.Duration) *WaitingRoomService {
+	if window <= 0 {
+		window = 15 * time.Minute
+	}
+	return &WaitingRoomService{
+		repo:   repo,
+		secret: []byte(secret),
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Join pone al usuario en la fila del evento y devuelve su pase firmado. Si el evento no
+// tiene sala de espera el usuario queda admitido sin token
+func (s *WaitingRoomService) Join(eventID, userID string) (*models.QueueStatus, error) {
+	rate, err := s.rate(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if rate == 0 {
+		return &models.QueueStatus{Admitted: true}, nil
+	}
+
+	now := s.now()
+	entry, err := s.repo.Enqueue(eventID, userID, time.Minute/time.Duration(rate), s.window, now)
+	if err != nil {
+		return nil, err
+	}
+
+	pass := models.QueuePass{
+		EntryID:   entry.ID,
+		EventID:   entry.EventID,
+		UserID:    entry.UserID,
+		Position:  entry.Position,
+		AdmitAt:   entry.AdmitAt,
+		ExpiresAt: entry.ExpiresAt,
+	}
+	token, err := s.sign(pass)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.status(pass, now)
+	if err != nil {
+		return nil, err
+	}
+	status.Token = token
+	return status, nil
+}
+
+// Status devuelve el lugar actual en la fila del pase del usuario
+func (s *WaitingRoomService) Status(eventID, userID, token string) (*models.QueueStatus, error) {
+	pass, err := s.verify(eventID, userID, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.status(*pass, s.now())
+}
+
+// CheckAdmission controla el acceso a la selección de asientos de un evento. Sin sala de
+// espera no hace falta pase (devuelve nil, nil); con sala exige un pase admitido y vigente
+func (s *WaitingRoomService) CheckAdmission(eventID, userID, token string) (*models.QueuePass, error) {
+	rate, err := s.rate(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if rate == 0 {
+		return nil, nil
+	}
+	if token == "" {
+		return nil, utils.ErrQueueTokenRequired
+	}
+
+	pass, err := s.verify(eventID, userID, token)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if now.Before(pass.AdmitAt) {
+		ahead, err := s.repo.CountAhead(eventID, pass.AdmitAt, now)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &utils.QueueNotAdmittedError{AdmitAt: pass.AdmitAt, Position: ahead + 1}
+	}
+	return pass, nil
+}
+
+// Start lanza la limpieza periódica de las entradas vencidas. El canal devuelto se cierra
+// cuando el worker terminó luego de cancelar el contexto
+func (s *WaitingRoomService) Start(ctx context.Context, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if purged, err := s.repo.PurgeExpired(s.now()); err != nil {
+					log.Printf("⚠️ Sala de espera: error al limpiar entradas vencidas: %v", err)
+				} else if purged > 0 {
+					log.Printf("🧹 Sala de espera: %d entradas vencidas borradas", purged)
+				}
+			}
+		}
+	}()
+
+	return done
+}
+
+func (s *WaitingRoomService) rate(eventID string) (int, error) {
+	rate, err := s.repo.FindRate(eventID)
+	if err != nil {
+		return 0, err
+	}
+	if rate == nil {
+		return 0, utils.ErrEventNotFound
+	}
+	return *rate, nil
+}
+
+func (s *WaitingRoomService) status(pass models.QueuePass, now time.Time) (*models.QueueStatus, error) {
+	status := &models.QueueStatus{
+		Admitted:  pass.Admitted(now),
+		AdmitAt:   &pass.AdmitAt,
+		ExpiresAt: &pass.ExpiresAt,
+	}
+	if now.Before(pass.AdmitAt) {
+		ahead, err := s.repo.CountAhead(pass.EventID, pass.AdmitAt, now)
+		if err != nil {
+			return nil, err
+		}
+		status.Position = ahead + 1
+		status.EstimatedWaitSeconds = int64(pass.AdmitAt.Sub(now).Round(time.Second) / time.Second)
+	}
+	return status, nil
+}
+
+func (s *WaitingRoomService) sign(pass models.QueuePass) (string, error) {
+	claims := queueClaims{
+		UserID:   pass.UserID,
+		EventID:  pass.EventID,
+		Position: pass.Position,
+		AdmitAt:  pass.AdmitAt.UnixMilli(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        pass.EntryID,
+			Audience:  jwt.ClaimStrings{queueTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(s.now()),
+			ExpiresAt: jwt.NewNumericDate(pass.ExpiresAt),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+// verify valida la firma del pase y que sea del evento y el usuario pedidos
+func (s *WaitingRoomService) verify(eventID, userID, token string) (*models.QueuePass, error) {
+	var claims queueClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(queueTokenAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, utils.ErrQueueAdmissionExpired
+	}
+	if err != nil || claims.EventID != eventID || claims.UserID != userID {
+		return nil, utils.ErrInvalidQueueToken
+	}
+
+	return &models.QueuePass{
+		EntryID:   claims.ID,
+		EventID:   claims.EventID,
+		UserID:    claims.UserID,
+		Position:  claims.Position,
+		AdmitAt:   time.UnixMilli(claims.AdmitAt),
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
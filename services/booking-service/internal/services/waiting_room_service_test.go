@@ -0,0 +1,132 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/pkg/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type mockWaitingRoomRepo struct {
+	findRateFn     func(string) (*int, error)
+	enqueueFn      func(string, string, time.Duration, time.Duration, time.Time) (*models.WaitingRoomEntry, error)
+	countAheadFn   func(string, time.Time, time.Time) (int64, error)
+	purgeExpiredFn func(time.Time) (int64, error)
+}
+
+func (m *mockWaitingRoomRepo) FindRate(eventID string) (*int, error) { return m.findRateFn(eventID) }
+func (m *mockWaitingRoomRepo) Enqueue(eventID, userID string, interval, window time.Duration, now time.Time) (*models.WaitingRoomEntry, error) {
+	return m.enqueueFn(eventID, userID, interval, window, now)
+}
+func (m *mockWaitingRoomRepo) CountAhead(eventID string, admitAt, now time.Time) (int64, error) {
+	return m.countAheadFn(eventID, admitAt, now)
+}
+func (m *mockWaitingRoomRepo) PurgeExpired(now time.Time) (int64, error) {
+	return m.purgeExpiredFn(now)
+}
+
+func rateFor(rates map[string]int) func(string) (*int, error) {
+	return func(eventID string) (*int, error) {
+		rate, ok := rates[eventID]
+		if !ok {
+			return nil, nil
+		}
+		return &rate, nil
+	}
+}
+
+func TestWaitingRoomService_JoinAndAdmission(t *testing.T) {
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	admitAt := now.Add(2 * time.Minute)
+
+	var interval time.Duration
+	repo := &mockWaitingRoomRepo{
+		findRateFn: rateFor(map[string]int{"e1": 120, "open": 0}),
+		enqueueFn: func(eventID, userID string, i, window time.Duration, at time.Time) (*models.WaitingRoomEntry, error) {
+			interval = i
+			return &models.WaitingRoomEntry{BaseModel: models.BaseModel{ID: "q1"}, EventID: eventID, UserID: userID, Position: 240, AdmitAt: admitAt, ExpiresAt: admitAt.Add(window)}, nil
+		},
+		countAheadFn: func(string, time.Time, time.Time) (int64, error) { return 239, nil },
+	}
+	svc := NewWaitingRoomService(repo, "secret", 10*time.Minute)
+	svc.now = func() time.Time { return now }
+
+	// Sin sala de espera: admitido sin pase
+	if status, err := svc.Join("open", "u1"); err != nil || !status.Admitted || status.Token != "" {
+		t.Fatalf("expected open event admitted without token, got %+v err=%v", status, err)
+	}
+	if pass, err := svc.CheckAdmission("open", "u1", ""); err != nil || pass != nil {
+		t.Fatalf("expected no check for open event, got %+v err=%v", pass, err)
+	}
+	if _, err := svc.Join("missing", "u1"); !errors.Is(err, utils.ErrEventNotFound) {
+		t.Fatalf("expected event not found, got %v", err)
+	}
+
+	status, err := svc.Join("e1", "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if interval != 500*time.Millisecond {
+		t.Fatalf("expected 120/min to space admissions 500ms apart, got %v", interval)
+	}
+	if status.Token == "" || status.Admitted || status.Position != 240 || status.EstimatedWaitSeconds != 120 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	// Todavía no admitido: 403 con el lugar en la fila
+	_, err = svc.CheckAdmission("e1", "u1", status.Token)
+	var notAdmitted *utils.QueueNotAdmittedError
+	if !errors.As(err, &notAdmitted) || !notAdmitted.AdmitAt.Equal(admitAt) || notAdmitted.Position != 240 {
+		t.Fatalf("expected not admitted error, got %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		eventID string
+		userID  string
+		token   string
+		want    error
+	}{
+		{"missing token", "e1", "u1", "", utils.ErrQueueTokenRequired},
+		{"other user", "e1", "u2", status.Token, utils.ErrInvalidQueueToken},
+		{"garbage", "e1", "u1", "abc", utils.ErrInvalidQueueToken},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.CheckAdmission(tc.eventID, tc.userID, tc.token); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	now = admitAt.Add(time.Second)
+	pass, err := svc.CheckAdmission("e1", "u1", status.Token)
+	if err != nil || pass.EntryID != "q1" || pass.Position != 240 {
+		t.Fatalf("expected admitted pass, got %+v err=%v", pass, err)
+	}
+	if current, err := svc.Status("e1", "u1", status.Token); err != nil || !current.Admitted || current.Position != 0 {
+		t.Fatalf("expected admitted status, got %+v err=%v", current, err)
+	}
+
+	now = admitAt.Add(11 * time.Minute)
+	if _, err := svc.CheckAdmission("e1", "u1", status.Token); !errors.Is(err, utils.ErrQueueAdmissionExpired) {
+		t.Fatalf("expected expired admission, got %v", err)
+	}
+}
+
+func TestWaitingRoomService_RejectsLoginToken(t *testing.T) {
+	repo := &mockWaitingRoomRepo{findRateFn: rateFor(map[string]int{"e1": 60})}
+	svc := NewWaitingRoomService(repo, "secret", time.Minute)
+
+	// Un JWT de login firmado con el mismo secreto no es un pase
+	login, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "u1", "uid": "u1", "evt": "e1", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+
+	if _, err := svc.CheckAdmission("e1", "u1", login); !errors.Is(err, utils.ErrInvalidQueueToken) {
+		t.Fatalf("expected invalid token, got %v", err)
+	}
+}
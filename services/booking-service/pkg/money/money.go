@@ -0,0 +1,211 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+// DefaultCurrency es la moneda de los eventos que no indican otra
+const DefaultCurrency = "ARS"
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount out of range")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Decimales (exponente ISO 4217) de las monedas aceptadas
+var exponents = map[string]int{
+	"ARS": 2,
+	"BRL": 2,
+	"CLP": 0,
+	"COP": 2,
+	"EUR": 2,
+	"MXN": 2,
+	"PEN": 2,
+	"PYG": 0,
+	"USD": 2,
+	"UYU": 2,
+}
+
+// Money es un importe en unidades menores (centavos) de una moneda ISO 4217.
+// En los modelos se guarda como dos columnas (gorm embedded)
+type Money struct {
+	Amount   int64  `gorm:"not null;default:0" json:"amount"`
+	Currency string `gorm:"type:varchar(3);not null;default:'ARS'" json:"currency"`
+}
+
+// New arma un importe en unidades menores; la moneda se normaliza a mayúsculas
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(strings.TrimSpace(currency))}
+}
+
+// NormalizeCurrency valida el código ISO y lo devuelve en mayúsculas; vacío es DefaultCurrency
+func NormalizeCurrency(currency string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	if _, ok := exponents[code]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	return code, nil
+}
+
+// Exponent devuelve la cantidad de decimales de la moneda (2 si no se conoce)
+func Exponent(currency string) int {
+	if exp, ok := exponents[strings.ToUpper(strings.TrimSpace(currency))]; ok {
+		return exp
+	}
+	return 2
+}
+
+// FromMajor convierte un importe en unidades (p. ej. 25.5 pesos) a unidades menores,
+// redondeando al centavo más cercano (las mitades se alejan del cero)
+func FromMajor(value float64, currency string) (Money, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Money{}, ErrInvalidAmount
+	}
+	minor := math.Round(value * math.Pow10(Exponent(currency)))
+	if minor >= math.MaxInt64 || minor < math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return New(int64(minor), currency), nil
+}
+
+// Major devuelve el importe en unidades, solo para mostrar o para APIs que no usan centavos
+func (m Money) Major() float64 {
+	return float64(m.Amount) / math.Pow10(Exponent(m.Currency))
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+// Add suma dos importes de la misma moneda
+func (m Money) Add(other Money) (Money, error) {
+	if !strings.EqualFold(m.Currency, other.Currency) {
+		return Money{}, fmt.Errorf("%w: %s != %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	sum := m.Amount + other.Amount
+	// Overflow: dos sumandos del mismo signo no pueden dar un resultado de signo opuesto
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return New(sum, m.Currency), nil
+}
+
+// Sub resta dos importes de la misma moneda
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Mul multiplica el importe por una cantidad (p. ej. entradas)
+func (m Money) Mul(quantity int64) (Money, error) {
+	if quantity == 0 || m.Amount == 0 {
+		return New(0, m.Currency), nil
+	}
+	product := m.Amount * quantity
+	if product/quantity != m.Amount || (m.Amount == -1 && quantity == math.MinInt64) || (quantity == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrOverflow
+	}
+	return New(product, m.Currency), nil
+}
+
+// Sum suma importes que deben estar todos en la moneda indicada
+func Sum(currency string, items ...Money) (Money, error) {
+	total := New(0, currency)
+	for _, item := range items {
+		var err error
+		if total, err = total.Add(item); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Allocate reparte el importe en partes proporcionales a los pesos sin perder centavos:
+// el resto se asigna a las partes con mayor fracción. Las partes siempre suman m
+func (m Money) Allocate(weights []int64) ([]Money, error) {
+	if len(weights) == 0 {
+		return nil, ErrInvalidAmount
+	}
+	var total int64
+	for _, w := range weights {
+		if w < 0 || total+w < total {
+			return nil, ErrInvalidAmount
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	sign := int64(1)
+	amount := m.Amount
+	if amount < 0 {
+		if amount == math.MinInt64 {
+			return nil, ErrOverflow
+		}
+		sign, amount = -1, -amount
+	}
+
+	parts := make([]Money, len(weights))
+	remainders := make([]uint64, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		quotient, remainder := mulDiv(amount, w, total)
+		parts[i] = New(quotient, m.Currency)
+		remainders[i] = remainder
+		allocated += quotient
+	}
+
+	// Los centavos que quedan van uno a uno a las partes con mayor resto
+	for left := amount - allocated; left > 0; left-- {
+		best := -1
+		for i := range weights {
+			if weights[i] == 0 {
+				continue
+			}
+			if best == -1 || remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		parts[best].Amount++
+		remainders[best] = 0
+	}
+
+	for i := range parts {
+		parts[i].Amount *= sign
+	}
+	return parts, nil
+}
+
+// mulDiv calcula a*b/c y el resto para a, b, c >= 0 sin overflow intermedio
+func mulDiv(a, b, c int64) (int64, uint64) {
+	q, r := a/c, a%c
+	// a*b/c = q*b + r*b/c; como r < c el producto r*b de 128 bits se puede dividir por c
+	hi, lo := bits.Mul64(uint64(r), uint64(b))
+	rq, rr := bits.Div64(hi, lo, uint64(c))
+	return q*b + int64(rq), rr
+}
+
+// String formatea el importe con sus decimales: "ARS 1500.50"
+func (m Money) String() string {
+	exp := Exponent(m.Currency)
+	if exp == 0 {
+		return fmt.Sprintf("%s %d", m.Currency, m.Amount)
+	}
+	sign, abs := "", uint64(m.Amount)
+	if m.Amount < 0 {
+		sign, abs = "-", uint64(-m.Amount)
+	}
+	unit := uint64(math.Pow10(exp))
+	return fmt.Sprintf("%s %s%d.%0*d", m.Currency, sign, abs/unit, exp, abs%unit)
+}
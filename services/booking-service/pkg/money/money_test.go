@@ -0,0 +1,186 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+func TestNormalizeCurrency(t *testing.T) {
+	if c, err := NormalizeCurrency(" ars "); err != nil || c != "ARS" {
+		t.Fatalf("expected ARS, got %q (%v)", c, err)
+	}
+	if c, err := NormalizeCurrency(""); err != nil || c != DefaultCurrency {
+		t.Fatalf("expected default currency, got %q (%v)", c, err)
+	}
+	if _, err := NormalizeCurrency("XXX"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Fatalf("expected unknown currency, got %v", err)
+	}
+}
+
+func TestFromMajor(t *testing.T) {
+	cases := []struct {
+		value    float64
+		currency string
+		want     int64
+	}{
+		{25.5, "ARS", 2550},
+		{0.1 + 0.2, "USD", 30},
+		{19.99, "USD", 1999},
+		{1.005, "USD", 100}, // 1.005 no es representable: vale 1.00499...
+		{-2.5, "ARS", -250},
+		{1500, "CLP", 1500},
+		{0.5, "CLP", 1},
+	}
+	for _, c := range cases {
+		got, err := FromMajor(c.value, c.currency)
+		if err != nil || got.Amount != c.want {
+			t.Fatalf("FromMajor(%v, %s) = %v (%v), want %d", c.value, c.currency, got, err, c.want)
+		}
+	}
+	if _, err := FromMajor(math.NaN(), "ARS"); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("expected invalid amount for NaN, got %v", err)
+	}
+	if _, err := FromMajor(1e18, "ARS"); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected overflow, got %v", err)
+	}
+}
+
+func TestAddAndMul(t *testing.T) {
+	if _, err := New(100, "ARS").Add(New(100, "USD")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected currency mismatch, got %v", err)
+	}
+	if _, err := New(math.MaxInt64, "ARS").Add(New(1, "ARS")); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected overflow on add, got %v", err)
+	}
+	if _, err := New(math.MaxInt64/2+1, "ARS").Mul(2); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected overflow on mul, got %v", err)
+	}
+	if got, err := New(1999, "ARS").Mul(3); err != nil || got.Amount != 5997 {
+		t.Fatalf("unexpected product %v (%v)", got, err)
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := map[Money]string{
+		New(150050, "ARS"): "ARS 1500.50",
+		New(-5, "USD"):     "USD -0.05",
+		New(1500, "CLP"):   "CLP 1500",
+	}
+	for m, want := range cases {
+		if got := m.String(); got != want {
+			t.Fatalf("String() = %q, want %q", got, want)
+		}
+	}
+}
+
+// Propiedades: las sumas en centavos no pierden ni inventan centavos
+
+func TestProperty_SumIsOrderIndependent(t *testing.T) {
+	prop := func(amounts []int32, seed int64) bool {
+		items := make([]Money, len(amounts))
+		for i, a := range amounts {
+			items[i] = New(int64(a), "ARS")
+		}
+		total, err := Sum("ARS", items...)
+		if err != nil {
+			return false
+		}
+
+		shuffled := append([]Money(nil), items...)
+		rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		again, err := Sum("ARS", shuffled...)
+		return err == nil && again == total
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProperty_FromMajorRoundTrips(t *testing.T) {
+	// Cualquier importe con dos decimales vuelve exacto a los mismos centavos
+	prop := func(cents int32) bool {
+		m, err := FromMajor(float64(cents)/100, "ARS")
+		if err != nil || m.Amount != int64(cents) {
+			return false
+		}
+		back, err := FromMajor(m.Major(), "ARS")
+		return err == nil && back == m
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProperty_SumOfConvertedPricesMatchesCents(t *testing.T) {
+	// Sumar precios ya convertidos a centavos da lo mismo que sumar los centavos:
+	// el total nunca depende de cómo se redondeó en float
+	prop := func(prices []uint16) bool {
+		items := make([]Money, len(prices))
+		var want int64
+		for i, p := range prices {
+			m, err := FromMajor(float64(p)/100, "USD")
+			if err != nil {
+				return false
+			}
+			items[i] = m
+			want += int64(p)
+		}
+		total, err := Sum("USD", items...)
+		return err == nil && total.Amount == want
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProperty_AllocateKeepsTotal(t *testing.T) {
+	prop := func(amount int64, raw []uint32) bool {
+		if amount == math.MinInt64 {
+			amount = 0
+		}
+		weights := make([]int64, 0, len(raw))
+		var nonZero bool
+		for _, w := range raw {
+			weights = append(weights, int64(w%1000))
+			nonZero = nonZero || w%1000 > 0
+		}
+		if !nonZero {
+			weights = append(weights, 1)
+		}
+
+		parts, err := New(amount, "ARS").Allocate(weights)
+		if err != nil || len(parts) != len(weights) {
+			return false
+		}
+		var sum int64
+		for i, p := range parts {
+			if weights[i] == 0 && p.Amount != 0 {
+				return false
+			}
+			if (amount >= 0 && p.Amount < 0) || (amount < 0 && p.Amount > 0) {
+				return false
+			}
+			sum += p.Amount
+		}
+		return sum == amount
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	parts, err := New(1000, "ARS").Allocate([]int64{1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parts[0].Amount != 334 || parts[1].Amount != 333 || parts[2].Amount != 333 {
+		t.Fatalf("unexpected allocation %v", parts)
+	}
+	if _, err := New(1000, "ARS").Allocate([]int64{0, 0}); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("expected invalid weights, got %v", err)
+	}
+}
@@ -12,7 +12,7 @@ func GetCorsConfig() gin.HandlerFunc {
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH"},
 		AllowCredentials: true,
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Internal-Secret"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Internal-Secret", "X-Queue-Token"},
 		MaxAge:           12 * time.Hour,
 	})
 }
@@ -10,6 +10,12 @@ import (
 
 func ExtractToken(c *gin.Context) (string, error) {
 	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" && c.IsWebsocket() {
+		// El WebSocket del navegador no permite headers en el handshake: el token viaja en la query
+		if token := c.Query("access_token"); token != "" {
+			return token, nil
+		}
+	}
 	if authHeader == "" {
 		return "", errors.New("authorization header is required")
 	}
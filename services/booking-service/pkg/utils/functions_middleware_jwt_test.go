@@ -32,3 +32,21 @@ func TestExtractToken(t *testing.T) {
 		t.Fatalf("expected token extraction, got token=%q err=%v", tok, err)
 	}
 }
+
+func TestExtractToken_WebsocketQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/?access_token=abc.def.ghi", nil)
+
+	// Sin upgrade a WebSocket el token en la query no se acepta
+	if _, err := ExtractToken(ctx); err == nil {
+		t.Fatalf("expected missing header error")
+	}
+
+	ctx.Request.Header.Set("Connection", "Upgrade")
+	ctx.Request.Header.Set("Upgrade", "websocket")
+	tok, err := ExtractToken(ctx)
+	if err != nil || tok != "abc.def.ghi" {
+		t.Fatalf("expected token from query, got token=%q err=%v", tok, err)
+	}
+}
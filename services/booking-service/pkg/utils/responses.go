@@ -1,7 +1,243 @@
 package utils
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 var ErrEventNotFound = errors.New("event not found")
 
 var ErrSeatNotFound = errors.New("seat not found")
+
+var ErrSeatsUnavailable = errors.New("seats not available")
+
+var ErrNoAdjacentSeats = errors.New("not enough adjacent seats available")
+
+var ErrInvalidSeatRequest = errors.New("invalid seat request")
+
+var ErrSeatRuleViolation = errors.New("seat selection violates event rules")
+
+var ErrHoldLimitExceeded = errors.New("hold limit exceeded")
+
+var ErrHoldNotFound = errors.New("hold not found or expired")
+
+var ErrHoldForbidden = errors.New("hold does not belong to user")
+
+var ErrHoldExtensionLimit = errors.New("hold cannot be extended anymore")
+
+var ErrOrderNotFound = errors.New("booking order not found")
+
+var ErrInvalidOrderTransition = errors.New("invalid booking order status transition")
+
+var ErrOrderVersionConflict = errors.New("booking order was modified concurrently")
+
+var ErrOrderForbidden = errors.New("booking order does not belong to user")
+
+var ErrRefundInProgress = errors.New("a refund is already in progress for this order")
+
+var ErrNothingToRefund = errors.New("no refundable seats left in this order")
+
+var ErrInvalidRefundSeats = errors.New("seats are not refundable for this order")
+
+var ErrRefundFailed = errors.New("payment provider refund failed")
+
+var ErrWebhookNotConfigured = errors.New("webhook secret not configured")
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+var ErrWebhookSignatureExpired = errors.New("webhook signature expired")
+
+var ErrInvalidWebhookPayload = errors.New("invalid webhook payload")
+
+// ErrWebhookIgnored indica una notificación válida que no corresponde a un pago (se responde 200)
+var ErrWebhookIgnored = errors.New("webhook notification ignored")
+
+var ErrUnknownPaymentProvider = errors.New("unknown payment provider")
+
+// La moneda es fija por evento: asientos y checkouts usan la del evento
+var ErrCurrencyMismatch = errors.New("currency does not match the event currency")
+
+var ErrEventCurrencyChange = errors.New("event currency cannot be changed")
+
+var ErrTierNotFound = errors.New("price tier not found")
+
+var ErrInvalidTier = errors.New("invalid price tier")
+
+var ErrTierNameTaken = errors.New("price tier name already used in this event")
+
+var ErrTierInUse = errors.New("price tier still has seats")
+
+var ErrTierFull = errors.New("price tier capacity reached")
+
+var ErrVenueNotFound = errors.New("venue not found")
+
+var ErrInvalidVenue = errors.New("invalid venue layout")
+
+var ErrEventHasSeats = errors.New("event already has seats")
+
+var ErrGASectionNotFound = errors.New("general admission section not found")
+
+var ErrInvalidGASection = errors.New("invalid general admission section")
+
+var ErrGASectionNameTaken = errors.New("general admission section name already used in this event")
+
+var ErrGASectionInUse = errors.New("general admission section has held or sold tickets")
+
+var ErrGASoldOut = errors.New("not enough general admission capacity")
+
+var ErrInvalidWaitingRoom = errors.New("invalid waiting room settings")
+
+var ErrQueueTokenRequired = errors.New("queue token required: join the event waiting room first")
+
+var ErrInvalidQueueToken = errors.New("invalid queue token")
+
+var ErrQueueAdmissionExpired = errors.New("queue admission expired: join the waiting room again")
+
+var ErrQueueNotAdmitted = errors.New("not admitted from the waiting room yet")
+
+var ErrInvalidSalesWindow = errors.New("invalid sales window")
+
+var ErrSalesNotStarted = errors.New("tickets are not on sale yet")
+
+var ErrSalesEnded = errors.New("ticket sales have ended")
+
+var ErrInvalidPresaleCode = errors.New("invalid presale code")
+
+var ErrPresaleCodeTaken = errors.New("presale code already exists for this event")
+
+var ErrPresaleCodeNotFound = errors.New("presale code not found")
+
+var ErrPresaleCodeExhausted = errors.New("presale code has no uses left")
+
+var ErrInvalidPurchaseLimit = errors.New("invalid purchase limit")
+
+var ErrPurchaseLimitExceeded = errors.New("purchase limit exceeded")
+
+var ErrInvalidPromoCode = errors.New("invalid promo code")
+
+var ErrPromoCodeTaken = errors.New("promo code already exists")
+
+var ErrPromoCodeNotFound = errors.New("promo code not found")
+
+var ErrPromoCodeExhausted = errors.New("promo code has no uses left")
+
+var ErrPromoCodeInactive = errors.New("promo code is not valid at this time")
+
+var ErrPromoNotApplicable = errors.New("promo code does not apply to this cart")
+
+var ErrInvalidFeeRule = errors.New("invalid fee rule")
+
+var ErrFeeRuleNotFound = errors.New("fee rule not found")
+
+var ErrInvalidTaxRate = errors.New("invalid tax rate")
+
+var ErrTaxRateNotFound = errors.New("tax rate not found")
+
+// SeatsUnavailableError indica qué asientos no pudieron bloquearse en un hold multiple
+type SeatsUnavailableError struct {
+	SeatIDs []string
+}
+
+func (e *SeatsUnavailableError) Error() string {
+	return fmt.Sprintf("seats not available: %s", strings.Join(e.SeatIDs, ","))
+}
+
+func (e *SeatsUnavailableError) Unwrap() error {
+	return ErrSeatsUnavailable
+}
+
+// SeatRuleError indica que una selección viola una regla del evento (p. ej. deja butacas
+// sueltas) y sugiere una alternativa válida
+type SeatRuleError struct {
+	Rule             string
+	SeatIDs          []string // Asientos que quedarían mal
+	SuggestedSeatIDs []string
+}
+
+func (e *SeatRuleError) Error() string {
+	return fmt.Sprintf("seat selection violates %s rule: would strand seats %s", e.Rule, strings.Join(e.SeatIDs, ","))
+}
+
+func (e *SeatRuleError) Unwrap() error {
+	return ErrSeatRuleViolation
+}
+
+// GASoldOutError indica qué sectores de admisión general no tienen cupo para lo pedido
+type GASoldOutError struct {
+	SectionIDs []string
+}
+
+func (e *GASoldOutError) Error() string {
+	return fmt.Sprintf("not enough general admission capacity: %s", strings.Join(e.SectionIDs, ","))
+}
+
+func (e *GASoldOutError) Unwrap() error {
+	return ErrGASoldOut
+}
+
+// QueueNotAdmittedError indica cuándo se habilita el pase y cuántas personas hay delante
+type QueueNotAdmittedError struct {
+	AdmitAt  time.Time
+	Position int64
+}
+
+func (e *QueueNotAdmittedError) Error() string {
+	return fmt.Sprintf("not admitted from the waiting room until %s", e.AdmitAt.Format(time.RFC3339))
+}
+
+func (e *QueueNotAdmittedError) Unwrap() error {
+	return ErrQueueNotAdmitted
+}
+
+// SalesNotStartedError indica cuándo abre la venta general y, si el evento tiene preventa,
+// desde cuándo se puede comprar con un código
+type SalesNotStartedError struct {
+	SalesStart   time.Time
+	PresaleStart *time.Time
+}
+
+func (e *SalesNotStartedError) Error() string {
+	return fmt.Sprintf("tickets are not on sale until %s", e.SalesStart.Format(time.RFC3339))
+}
+
+func (e *SalesNotStartedError) Unwrap() error {
+	return ErrSalesNotStarted
+}
+
+// PurchaseLimitError indica qué límite de compra del evento se supera (user, email o card)
+// y cuántas entradas le quedan al comprador
+type PurchaseLimitError struct {
+	Limit     string
+	Max       int
+	Purchased int64
+	Requested int
+}
+
+// Remaining devuelve las entradas que todavía puede comprar
+func (e *PurchaseLimitError) Remaining() int {
+	return max(e.Max-int(e.Purchased), 0)
+}
+
+func (e *PurchaseLimitError) Error() string {
+	return fmt.Sprintf("purchase limit exceeded: max %d tickets per %s for this event, %d remaining", e.Max, e.Limit, e.Remaining())
+}
+
+func (e *PurchaseLimitError) Unwrap() error {
+	return ErrPurchaseLimitExceeded
+}
+
+// InvalidTransitionError indica un cambio de estado no permitido para una orden
+type InvalidTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot change booking order status from %s to %s", e.From, e.To)
+}
+
+func (e *InvalidTransitionError) Unwrap() error {
+	return ErrInvalidOrderTransition
+}